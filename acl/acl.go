@@ -0,0 +1,289 @@
+// Package acl decides access to hierarchical resources, such as URL paths,
+// object store keys or configuration keys, from rules that allow or deny the
+// resources below a prefix. The rules are stored in a radix tree keyed by
+// their prefixes so that the rules that apply to a resource are found by
+// following its path from the root.
+//
+// Prefixes match whole path segments: the rule "/admin" applies to "/admin"
+// and "/admin/users" but not to "/administrator". A rule ending with "*", such
+// as "/tmp*" or "/home/*", instead matches every resource whose path starts
+// with the bytes before the "*", so "/tmp*" applies to "/tmp2" as well.
+// Resource paths are cleaned before they are evaluated, so "/public/../admin"
+// is matched as "/admin".
+package acl
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+// ErrInvalid is returned when a pattern or effect is not valid.
+var ErrInvalid = errors.New("acl: invalid rule")
+
+// Effect is the decision of a rule.
+type Effect uint8
+
+// The effects of rules. The zero Effect is not a valid effect for a rule.
+const (
+	Allow Effect = iota + 1
+	Deny
+)
+
+// String returns "allow" or "deny".
+func (e Effect) String() string {
+	switch e {
+	case Allow:
+		return "allow"
+	case Deny:
+		return "deny"
+	}
+	return fmt.Sprintf("Effect(%d)", uint8(e))
+}
+
+// Mode is the way the rules that apply to a resource are combined.
+type Mode uint8
+
+const (
+	// MostSpecific decides by the rules with the longest prefix that
+	// applies to the resource, so that an allow for "/admin/status"
+	// overrides a deny for "/admin".
+	MostSpecific Mode = iota
+	// DenyOverrides denies a resource if any rule that applies to it is a
+	// deny, so that a deny for "/admin" cannot be overridden by a rule for a
+	// resource below it.
+	DenyOverrides
+)
+
+// Rule is a pattern and its effect.
+type Rule struct {
+	Pattern string
+	Effect  Effect
+}
+
+// Decision is the result of evaluating a resource.
+type Decision struct {
+	// Effect is the effect of Rule, or Deny if no rule applies.
+	Effect Effect
+	// Rule is the rule that decided, valid if Matched is true.
+	Rule Rule
+	// Matched reports whether any rule applies to the resource.
+	Matched bool
+}
+
+// entry holds the rules for a key. The rules that match whole segments are
+// kept apart from those that match any bytes, which have the same key as the
+// segment rules for the prefix ending in a slash.
+type entry struct {
+	depth int // length of the key
+	seg   [2]bool
+	raw   [2]bool
+}
+
+// ACL is a set of rules. A resource to which no rule applies is denied, and
+// when rules with the same prefix both allow and deny a resource the deny
+// takes precedence, so every decision is deterministic regardless of the order
+// in which the rules were added. An ACL is not safe for concurrent use.
+type ACL struct {
+	mode Mode
+	tree *radixtree.RadixTree[*entry]
+	size int
+}
+
+// New returns an ACL without rules that combines rules in the given mode.
+func New(mode Mode) *ACL {
+	return &ACL{mode: mode, tree: radixtree.New[*entry]()}
+}
+
+// parse returns the key of a pattern and whether it matches any bytes rather
+// than whole segments. A pattern must start with a slash and must not have
+// empty, "." or ".." segments, except that it may end with "/*".
+func parse(pattern string) ([]byte, bool, error) {
+	base, raw := strings.CutSuffix(pattern, "*")
+	if !strings.HasPrefix(base, "/") || strings.Contains(base, "*") {
+		return nil, false, fmt.Errorf("%w: pattern %q", ErrInvalid, pattern)
+	}
+	segments := strings.Split(base[1:], "/")
+	for i, s := range segments {
+		last := i == len(segments)-1
+		if s == "." || s == ".." || s == "" && (!last || !raw && base != "/") {
+			return nil, false, fmt.Errorf("%w: pattern %q", ErrInvalid, pattern)
+		}
+	}
+	if raw {
+		return []byte(base), true, nil
+	}
+	return key(base), false, nil
+}
+
+// key returns the key of a cleaned path, which is the path followed by a
+// slash so that it only has the keys of its enclosing segments as prefixes.
+func key(p string) []byte {
+	if p == "/" {
+		return []byte(p)
+	}
+	return []byte(p + "/")
+}
+
+// pattern returns the pattern of the rules of an entry for a key.
+func pattern(key []byte, raw bool) string {
+	switch {
+	case raw:
+		return string(key) + "*"
+	case len(key) == 1:
+		return "/"
+	}
+	return string(key[:len(key)-1])
+}
+
+// Add adds a rule with the pattern and effect. Adding a rule that is already in
+// the ACL has no effect. It returns an error wrapping ErrInvalid if the pattern
+// is not valid or the effect is neither Allow nor Deny.
+func (a *ACL) Add(pattern string, effect Effect) error {
+	if effect != Allow && effect != Deny {
+		return fmt.Errorf("%w: effect %v", ErrInvalid, effect)
+	}
+	k, raw, err := parse(pattern)
+	if err != nil {
+		return err
+	}
+	e, ok := a.tree.Get(k)
+	if !ok {
+		e = &entry{depth: len(k)}
+		a.tree.Insert(k, e)
+	}
+	rules := &e.seg
+	if raw {
+		rules = &e.raw
+	}
+	if !rules[effect-1] {
+		rules[effect-1] = true
+		a.size++
+	}
+	return nil
+}
+
+// Remove removes the rule with the pattern and effect and returns true, or
+// false if it is not in the ACL.
+func (a *ACL) Remove(pattern string, effect Effect) bool {
+	if effect != Allow && effect != Deny {
+		return false
+	}
+	k, raw, err := parse(pattern)
+	if err != nil {
+		return false
+	}
+	e, ok := a.tree.Get(k)
+	if !ok {
+		return false
+	}
+	rules := &e.seg
+	if raw {
+		rules = &e.raw
+	}
+	if !rules[effect-1] {
+		return false
+	}
+	rules[effect-1] = false
+	a.size--
+	if e.seg == [2]bool{} && e.raw == [2]bool{} {
+		a.tree.Remove(k)
+	}
+	return true
+}
+
+// Len returns the number of rules.
+func (a *ACL) Len() int {
+	return a.size
+}
+
+// Rules returns every rule in ascending order of their patterns' keys, so that
+// a rule comes before the rules for the resources below it, with the segment
+// rule for a prefix before the "*" rule and an allow before a deny.
+func (a *ACL) Rules() []Rule {
+	var rules []Rule
+	for k, e := range a.tree.All() {
+		for _, effect := range []Effect{Allow, Deny} {
+			if e.seg[effect-1] {
+				rules = append(rules, Rule{Pattern: pattern(k, false), Effect: effect})
+			}
+		}
+		for _, effect := range []Effect{Allow, Deny} {
+			if e.raw[effect-1] {
+				rules = append(rules, Rule{Pattern: pattern(k, true), Effect: effect})
+			}
+		}
+	}
+	return rules
+}
+
+// Matching returns the rules that apply to the resource, from the most
+// specific to the least, with a deny before an allow for the same prefix. The
+// resource is a slash-separated path, which is cleaned and made absolute
+// first.
+func (a *ACL) Matching(resource string) []Rule {
+	var rules []Rule
+	a.match(resource, func(r Rule) bool {
+		rules = append(rules, r)
+		return true
+	})
+	return rules
+}
+
+// match calls f for the rules that apply to the resource in the order of
+// Matching until f returns false.
+func (a *ACL) match(resource string, f func(r Rule) bool) {
+	p := path.Clean("/" + resource)
+	k := key(p)
+	for {
+		// The longest key that is a prefix of the key of the path holds
+		// the most specific of the remaining rules.
+		e, ok := a.tree.LongestPrefix(k)
+		if !ok {
+			return
+		}
+		k = k[:e.depth]
+		// A rule matching any bytes only applies to the resource if its
+		// key is a prefix of the path itself, not just of its key.
+		applies := e.depth <= len(p)
+		for _, effect := range []Effect{Deny, Allow} {
+			if e.seg[effect-1] && !f(Rule{Pattern: pattern(k, false), Effect: effect}) {
+				return
+			}
+			if applies && e.raw[effect-1] && !f(Rule{Pattern: pattern(k, true), Effect: effect}) {
+				return
+			}
+		}
+		if e.depth <= 1 {
+			return
+		}
+		k = k[:e.depth-1]
+	}
+}
+
+// Evaluate decides whether the resource is allowed, combining the rules that
+// apply to it according to the mode of the ACL. The resource is cleaned and
+// made absolute as by Matching.
+func (a *ACL) Evaluate(resource string) Decision {
+	d := Decision{Effect: Deny}
+	a.match(resource, func(r Rule) bool {
+		switch {
+		case !d.Matched:
+			d = Decision{Effect: r.Effect, Rule: r, Matched: true}
+			return a.mode == DenyOverrides && r.Effect == Allow
+		case r.Effect == Deny:
+			d = Decision{Effect: Deny, Rule: r, Matched: true}
+			return false
+		}
+		return true
+	})
+	return d
+}
+
+// Allowed reports whether Evaluate allows the resource.
+func (a *ACL) Allowed(resource string) bool {
+	return a.Evaluate(resource).Effect == Allow
+}
@@ -0,0 +1,104 @@
+package acl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	rules := []Rule{
+		{"/", Allow},
+		{"/admin", Deny},
+		{"/admin/status", Allow},
+		{"/tmp*", Deny},
+		{"/home/*", Deny},
+		{"/home", Allow},
+		{"/shared", Allow},
+		{"/shared", Deny},
+	}
+	for _, mode := range []Mode{MostSpecific, DenyOverrides} {
+		a := New(mode)
+		for _, r := range rules {
+			if err := a.Add(r.Pattern, r.Effect); err != nil {
+				t.Fatalf("Add(%q, %v) returned error %v", r.Pattern, r.Effect, err)
+			}
+		}
+		if a.Len() != len(rules) {
+			t.Errorf("Len() = %d, want %d", a.Len(), len(rules))
+		}
+		for resource, want := range map[string]Rule{
+			"/index.html":       {"/", Allow},
+			"/administrator":    {"/", Allow},
+			"/admin":            {"/admin", Deny},
+			"/admin/users":      {"/admin", Deny},
+			"/public/../admin":  {"/admin", Deny},
+			"admin":             {"/admin", Deny},
+			"/admin/status":     {"/admin/status", Allow},
+			"/admin/status/db":  {"/admin/status", Allow},
+			"/tmp2":             {"/tmp*", Deny},
+			"/home":             {"/home", Allow},
+			"/home/me":          {"/home/*", Deny},
+			"/shared/docs":      {"/shared", Deny},
+			"/admin/status/../": {"/admin", Deny},
+		} {
+			if mode == DenyOverrides && want.Pattern == "/admin/status" {
+				want = Rule{"/admin", Deny}
+			}
+			got := a.Evaluate(resource)
+			if !got.Matched || got.Rule != want || got.Effect != want.Effect {
+				t.Errorf("mode %d: Evaluate(%q) = %+v, want %+v", mode, resource, got, want)
+			}
+			if a.Allowed(resource) != (want.Effect == Allow) {
+				t.Errorf("mode %d: Allowed(%q) = %v", mode, resource, !a.Allowed(resource))
+			}
+		}
+	}
+
+	a := New(MostSpecific)
+	a.Add("/public", Allow)
+	if d := a.Evaluate("/private"); d.Matched || d.Effect != Deny {
+		t.Errorf("Evaluate without a matching rule = %+v, want an unmatched deny", d)
+	}
+}
+
+func TestMatching(t *testing.T) {
+	a := New(MostSpecific)
+	for _, r := range []Rule{{"/", Allow}, {"/a", Allow}, {"/a/*", Deny}, {"/a", Deny}, {"/a/b*", Allow}, {"/ab", Deny}} {
+		a.Add(r.Pattern, r.Effect)
+	}
+	want := []Rule{{"/a/b*", Allow}, {"/a", Deny}, {"/a/*", Deny}, {"/a", Allow}, {"/", Allow}}
+	if got := a.Matching("/a/bc"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Matching(/a/bc)\n got: %v\nwant: %v", got, want)
+	}
+	want = []Rule{{"/a", Deny}, {"/a", Allow}, {"/", Allow}}
+	if got := a.Matching("/a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Matching(/a)\n got: %v\nwant: %v", got, want)
+	}
+
+	want = []Rule{{"/", Allow}, {"/a", Allow}, {"/a", Deny}, {"/a/*", Deny}, {"/a/b*", Allow}, {"/ab", Deny}}
+	if got := a.Rules(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Rules()\n got: %v\nwant: %v", got, want)
+	}
+	if !a.Remove("/a/*", Deny) || a.Remove("/a/*", Deny) || a.Remove("/a/*", Allow) {
+		t.Error("Remove(/a/*) did not remove the rule exactly once")
+	}
+	if a.Len() != len(want)-1 {
+		t.Errorf("Len() after Remove = %d, want %d", a.Len(), len(want)-1)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	a := New(MostSpecific)
+	for _, pattern := range []string{"", "*", "admin", "/a/", "/a//b", "/a/./b", "/a/..", "/a*b", "/a**"} {
+		if err := a.Add(pattern, Allow); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Add(%q) returned %v, want %v", pattern, err, ErrInvalid)
+		}
+	}
+	if err := a.Add("/a", 0); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Add with the zero Effect returned %v, want %v", err, ErrInvalid)
+	}
+	if a.Len() != 0 {
+		t.Errorf("Len() after invalid rules = %d, want 0", a.Len())
+	}
+}
@@ -0,0 +1,128 @@
+package radixtree
+
+import "bytes"
+
+// aggregator computes the aggregate of a subtree from the aggregates of its
+// children.
+type aggregator[T any] interface {
+	aggregate(n *node[T]) any
+	// value returns the aggregate of a single value.
+	value(v T) any
+}
+
+// monoid is an aggregator for aggregates of type A.
+type monoid[T, A any] struct {
+	zero      A
+	combine   func(a, b A) A
+	fromValue func(value T) A
+}
+
+func (m *monoid[T, A]) value(v T) any {
+	return m.fromValue(v)
+}
+
+func (m *monoid[T, A]) aggregate(n *node[T]) any {
+	a := m.zero
+	if n.hasValue() {
+		a = m.combine(a, m.fromValue(n.leaf.value))
+	}
+	for _, child := range n.children.nodes {
+		a = m.combine(a, child.agg.(A))
+	}
+	return a
+}
+
+// Aggregate returns the aggregate of the values whose keys start with the given
+// prefix for a tree created with WithAggregate. The boolean return value is
+// false if no key starts with the prefix or the tree does not maintain
+// aggregates. Aggregate panics if A is not the aggregate type of the tree.
+func Aggregate[A, T any](t *RadixTree[T], prefix []byte) (A, bool) {
+	var zero A
+	if t.agg == nil || t.size == 0 {
+		return zero, false
+	}
+	n := t.find(prefix)
+	if n == nil || n.count == 0 {
+		return zero, false
+	}
+	return n.agg.(A), true
+}
+
+// Reduce folds the entries whose keys start with the given prefix into a single
+// result in ascending key order, starting from init and calling fn with the
+// result so far and each key and value in turn, and returns the final result.
+// Unlike collecting the values with Find it allocates nothing per entry, and
+// unlike Aggregate it needs no option and any fold can be computed, at a cost
+// proportional to the number of entries with the prefix. The key passed to fn
+// is only valid until fn returns. If fn modifies the tree Reduce panics with
+// ErrModified.
+func Reduce[A, T any](t *RadixTree[T], prefix []byte, init A, fn func(acc A, key []byte, value T) A) A {
+	acc := init
+	n, key := t.findPath(prefix, t.borrow())
+	if n != nil {
+		gen := t.gen
+		key, _ = walkNodes(n, key, -1, func(key []byte, n *node[T]) bool {
+			acc = fn(acc, key, n.leaf.value)
+			return t.unmodified(gen)
+		})
+	}
+	t.release(key)
+	return acc
+}
+
+// update recomputes the count and aggregate of n from its value and children.
+func (t *RadixTree[T]) update(n *node[T]) {
+	n.count = 0
+	if n.hasValue() {
+		n.count = 1
+	}
+	for _, child := range n.children.nodes {
+		n.count += child.count
+	}
+	if t.agg != nil {
+		n.agg = t.agg.aggregate(n)
+	}
+}
+
+// refresh recomputes the aggregates of the nodes on a path from the root,
+// deepest first. Mutations that only add or remove a single value keep the
+// counts up to date themselves.
+func (t *RadixTree[T]) refresh(path []*node[T]) {
+	if t.agg == nil {
+		return
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i].agg = t.agg.aggregate(path[i])
+	}
+}
+
+// updateAll updates every node in the subtree rooted at n.
+func (t *RadixTree[T]) updateAll(n *node[T]) {
+	if t.agg == nil {
+		return
+	}
+	for _, child := range n.children.nodes {
+		t.updateAll(child)
+	}
+	t.update(n)
+}
+
+// updateKey updates the nodes on the path to key in the subtree rooted at n,
+// including the first node that diverges from key.
+func (t *RadixTree[T]) updateKey(n *node[T], key []byte) {
+	path := append(t.path[:0], n)
+	for len(key) > 0 {
+		if n = n.children.get(key[0]); n == nil {
+			break
+		}
+		path = append(path, n)
+		if !bytes.HasPrefix(key, n.prefix) {
+			break
+		}
+		key = key[len(n.prefix):]
+	}
+	t.path = path
+	for i := len(path) - 1; i >= 0; i-- {
+		t.update(path[i])
+	}
+}
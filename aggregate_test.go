@@ -0,0 +1,106 @@
+package radixtree
+
+import (
+	"strings"
+	"testing"
+)
+
+func concat(a, b string) string {
+	return a + b
+}
+
+func identity(s string) string {
+	return s
+}
+
+// checkAggregates compares the aggregate of every prefix of the given keys with
+// the concatenation of the values under that prefix.
+func checkAggregates(t *testing.T, name string, tree *RadixTree[string], keys []string) {
+	t.Helper()
+	prefixes := map[string]bool{"": true, "zz": true}
+	for _, key := range keys {
+		for i := range key {
+			prefixes[key[:i+1]] = true
+		}
+	}
+	for prefix := range prefixes {
+		values := tree.Find([]byte(prefix))
+		want := strings.Join(values, "")
+		got, ok := Aggregate[string](tree, []byte(prefix))
+		if ok != (len(values) > 0) || got != want {
+			t.Errorf("%s: Aggregate(%s)\n got: (%s, %t)\nwant: (%s, %t)", name, prefix, got, ok, want, len(values) > 0)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	opt := WithAggregate("", concat, identity)
+	tree := New[string](opt)
+	if _, ok := Aggregate[string](tree, nil); ok {
+		t.Errorf("Aggregate on an empty tree returned true")
+	}
+
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	checkAggregates(t, "Insert", tree, words)
+
+	tree.Insert([]byte("toad"), "TOAD")
+	checkAggregates(t, "update", tree, words)
+
+	for _, key := range []string{"wit", "to", "macro", "aardwolf"} {
+		tree.Remove([]byte(key))
+	}
+	checkAggregates(t, "Remove", tree, words)
+
+	left, right := tree.Split([]byte("tob"))
+	checkAggregates(t, "Split left", left, words)
+	checkAggregates(t, "Split right", right, words)
+
+	tree, err := Join(left, right)
+	if err != nil {
+		t.Fatalf("Join returned error %v", err)
+	}
+	checkAggregates(t, "Join", tree, words)
+
+	// Grafted trees built without the aggregate are recomputed.
+	tree.Graft([]byte("wi"), build([]string{"n", "nd", "zard"}))
+	checkAggregates(t, "Graft", tree, append(words, "wind", "wizard"))
+
+	d := tree.Detach([]byte("wi"), true)
+	checkAggregates(t, "Detach", tree, words)
+	checkAggregates(t, "Detached", d, words)
+}
+
+func TestAggregateTypeMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("New with a mismatched aggregate type did not panic")
+		}
+	}()
+	New[int](WithAggregate("", concat, identity))
+}
+
+func TestReduce(t *testing.T) {
+	tree := build(words)
+	for _, prefix := range []string{"", "to", "macroa", "wink", "x"} {
+		var want string
+		for _, key := range words {
+			if strings.HasPrefix(key, prefix) {
+				want += key + ","
+			}
+		}
+		got := Reduce(tree, []byte(prefix), "", func(acc string, key []byte, value string) string {
+			if string(key) != value {
+				t.Errorf("Reduce passed key %q with value %q", key, value)
+			}
+			return acc + value + ","
+		})
+		if got != want {
+			t.Errorf("Reduce(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+	if n := Reduce(tree, []byte("toad"), 10, func(n int, key []byte, _ string) int { return n + len(key) }); n != 10+4+5+8 {
+		t.Errorf("Reduce summing key lengths = %d, want %d", n, 10+4+5+8)
+	}
+}
@@ -0,0 +1,90 @@
+package radixtree
+
+// Allocator provides the memory for the key bytes stored in a tree created with
+// WithAllocator, so that they can be placed in an arena, off the Go heap or
+// instrumented. Each node of the tree owns the bytes of its compressed prefix
+// so every slice returned by Alloc is passed to Free exactly once, unchanged,
+// when the node is removed or its prefix is replaced. Memory still held by a
+// tree that is no longer used is not freed.
+type Allocator interface {
+	// Alloc returns a slice of length n.
+	Alloc(n int) []byte
+	// Free releases a slice returned by Alloc.
+	Free(b []byte)
+}
+
+// allocator wraps the Allocator of a tree so that trees sharing it can be
+// recognised.
+type allocator struct {
+	Allocator
+}
+
+// own returns b if the tree has no allocator, otherwise a copy of b in memory
+// from the allocator.
+func (t *RadixTree[T]) own(b []byte) []byte {
+	if t.opts.alloc == nil || len(b) == 0 {
+		return b
+	}
+	p := t.opts.alloc.Alloc(len(b))
+	copy(p, b)
+	return p
+}
+
+// ownKey is like own for the bytes of a key passed by the caller, which are
+// also copied if the tree was created with WithKeyCopy.
+func (t *RadixTree[T]) ownKey(b []byte) []byte {
+	if t.opts.copyKeys && t.opts.alloc == nil && len(b) > 0 {
+		return append([]byte(nil), b...)
+	}
+	return t.own(b)
+}
+
+// free returns the prefix b of a node that is no longer used to the allocator.
+func (t *RadixTree[T]) free(b []byte) {
+	if t.opts.alloc != nil && len(b) > 0 {
+		t.opts.alloc.Free(b)
+	}
+}
+
+// setPrefix replaces the prefix of n by prefix, which may refer to the current
+// prefix.
+func (t *RadixTree[T]) setPrefix(n *node[T], prefix []byte) {
+	old := n.prefix
+	n.prefix = t.own(prefix)
+	if t.opts.alloc != nil {
+		t.free(old)
+	}
+}
+
+// freeAll returns the prefixes of every node in the subtree rooted at n to the
+// allocator.
+func (t *RadixTree[T]) freeAll(n *node[T]) {
+	if t.opts.alloc == nil {
+		return
+	}
+	t.free(n.prefix)
+	for _, child := range n.children.nodes {
+		t.freeAll(child)
+	}
+}
+
+// moveKeys moves the prefixes of the subtree rooted at n, which are owned by
+// the given allocator, to the allocator of the tree if it differs.
+func (t *RadixTree[T]) moveKeys(n *node[T], from *allocator) {
+	if from == t.opts.alloc {
+		return
+	}
+	if old := n.prefix; len(old) > 0 {
+		if t.opts.alloc != nil {
+			n.prefix = t.own(old)
+		} else {
+			n.prefix = append([]byte(nil), old...)
+		}
+		if from != nil {
+			from.Free(old)
+		}
+	}
+	for _, child := range n.children.nodes {
+		t.moveKeys(child, from)
+	}
+}
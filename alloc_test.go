@@ -0,0 +1,153 @@
+package radixtree
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// trackingAllocator records the slices it has allocated and not yet freed.
+type trackingAllocator struct {
+	t    *testing.T
+	live map[*byte]int
+}
+
+func newTrackingAllocator(t *testing.T) *trackingAllocator {
+	return &trackingAllocator{t: t, live: map[*byte]int{}}
+}
+
+func (a *trackingAllocator) Alloc(n int) []byte {
+	b := make([]byte, n)
+	a.live[&b[0]] = n
+	return b
+}
+
+func (a *trackingAllocator) Free(b []byte) {
+	if n, ok := a.live[&b[0]]; !ok || n != len(b) {
+		a.t.Fatalf("Free(%q) of a slice that is not live", b)
+	}
+	delete(a.live, &b[0])
+}
+
+// check verifies that the prefixes of the nodes in the trees are exactly the
+// live allocations.
+func (a *trackingAllocator) check(name string, trees ...*RadixTree[int]) {
+	a.t.Helper()
+	seen := map[*byte]bool{}
+	var walk func(n *node[int], root bool)
+	walk = func(n *node[int], root bool) {
+		if !root {
+			p := &n.prefix[0]
+			if l, ok := a.live[p]; !ok || l != len(n.prefix) || cap(n.prefix) != l {
+				a.t.Errorf("%s: prefix %q is not a live allocation", name, n.prefix)
+			} else if seen[p] {
+				a.t.Errorf("%s: prefix %q is shared", name, n.prefix)
+			}
+			seen[p] = true
+		}
+		for _, child := range n.children.nodes {
+			walk(child, false)
+		}
+	}
+	for _, tree := range trees {
+		walk(tree.root, true)
+	}
+	if len(seen) != len(a.live) {
+		a.t.Errorf("%s: %d prefixes but %d live allocations", name, len(seen), len(a.live))
+	}
+}
+
+func TestWithAllocator(t *testing.T) {
+	a := newTrackingAllocator(t)
+	tree := New[int](WithAllocator(a))
+	r := rand.New(rand.NewSource(1))
+	key := func() []byte {
+		return []byte(fmt.Sprintf("%x", r.Intn(4096)))
+	}
+
+	for i := 0; i < 2000; i++ {
+		k := key()
+		tree.Insert(k, i)
+		// The tree copies the key.
+		k[0] = 'z'
+	}
+	a.check("Insert", tree)
+	verify(t, tree)
+	for i := 0; i < 1000; i++ {
+		tree.Remove(key())
+	}
+	a.check("Remove", tree)
+
+	left, right := tree.Split([]byte("8"))
+	a.check("Split", left, right)
+	tree, _ = Join(left, right)
+	a.check("Join", tree)
+
+	var entries []Entry[int]
+	var keys [][]byte
+	for i := 0; i < 500; i++ {
+		entries = append(entries, Entry[int]{key(), i})
+		keys = append(keys, key())
+	}
+	tree.InsertMany(entries)
+	a.check("InsertMany", tree)
+	tree.RemoveMany(keys)
+	a.check("RemoveMany", tree)
+	tree.DeleteRange([]byte("3"), []byte("5a"))
+	a.check("DeleteRange", tree)
+
+	// An empty range leaves the nodes on the paths to its bounds as they
+	// were.
+	empty := New[int](WithAllocator(a))
+	for _, k := range []string{"abAAa", "abbAab", "a"} {
+		empty.Insert([]byte(k), 0)
+	}
+	if n := empty.DeleteRange([]byte("abaa"), []byte("abb")); n != 0 {
+		t.Errorf("DeleteRange of an empty range removed %d keys", n)
+	}
+	if n := empty.DeleteRange([]byte("b"), nil); n != 0 {
+		t.Errorf("DeleteRange past the last key removed %d keys", n)
+	}
+	a.check("DeleteRange of an empty range", tree, empty)
+	empty.Insert([]byte("abAA"), 1)
+	a.check("Insert after deleting an empty range", tree, empty)
+	verify(t, empty)
+	for _, k := range empty.AppendKeys(nil, nil) {
+		empty.Remove(k)
+	}
+
+	d := tree.Detach([]byte("a"), true)
+	a.check("Detach", tree, d)
+	tree.Graft([]byte("q"), d)
+	a.check("Graft", tree)
+
+	// Trees with different allocators or none copy the keys they adopt.
+	plain := New[int]()
+	plain.Insert([]byte("ab"), 1)
+	plain.Insert([]byte("ac"), 2)
+	tree.Graft([]byte("p"), plain)
+	a.check("Graft without an allocator", tree)
+	other := New[int]()
+	tree.CopyRange(other, []byte("q"), []byte("r"), nil)
+	a.check("CopyRange", tree)
+	d = tree.Detach([]byte("q"), false)
+	plain.Graft(nil, d)
+	a.check("Graft into a tree without an allocator", tree)
+	verify(t, plain)
+	verify(t, other)
+
+	lines := "aa\naab\nab\nb\n"
+	if err := tree.LoadSorted(strings.NewReader(lines), func(line []byte) ([]byte, int, error) {
+		return line, 0, nil
+	}); err != nil {
+		t.Fatalf("LoadSorted returned error %v", err)
+	}
+	a.check("LoadSorted", tree)
+	verify(t, tree)
+
+	for _, k := range tree.AppendKeys(nil, nil) {
+		tree.Remove(k)
+	}
+	a.check("Remove all", tree)
+}
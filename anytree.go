@@ -0,0 +1,65 @@
+package radixtree
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// AnyTree is a radix tree whose values may be of any type, for trees that
+// genuinely hold values of different types such as plugin registries keyed by
+// path. It has every method of RadixTree[any] and the functions GetAs,
+// LongestPrefixAs and FindAs read values as a given type, checking it at run
+// time.
+type AnyTree struct {
+	*RadixTree[any]
+}
+
+// NewAny creates and returns an empty AnyTree configured with the given
+// options.
+func NewAny(opts ...Option) *AnyTree {
+	return &AnyTree{New[any](opts...)}
+}
+
+// GetAs returns the value of key as type V. It returns ErrNotFound if the key
+// is not in the tree and an error wrapping ErrWrongType if its value is not a
+// V.
+func GetAs[V any](t *AnyTree, key []byte) (V, error) {
+	v, err := t.GetErr(key)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return as[V](key, v)
+}
+
+// LongestPrefixAs is like GetAs for the value of the longest key in the tree
+// that is a prefix of the given key.
+func LongestPrefixAs[V any](t *AnyTree, key []byte) (V, error) {
+	v, err := t.LongestPrefixErr(key)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return as[V](key, v)
+}
+
+// FindAs returns the values of type V whose keys start with the given prefix
+// in ascending key order, skipping values of other types.
+func FindAs[V any](t *AnyTree, prefix []byte) []V {
+	var vs []V
+	t.Walk(prefix, func(value any) bool {
+		if v, ok := value.(V); ok {
+			vs = append(vs, v)
+		}
+		return true
+	})
+	return vs
+}
+
+func as[V any](key []byte, value any) (V, error) {
+	v, ok := value.(V)
+	if !ok {
+		return v, fmt.Errorf("%w: %q holds %T, not %v", ErrWrongType, key, value, reflect.TypeOf((*V)(nil)).Elem())
+	}
+	return v, nil
+}
@@ -0,0 +1,67 @@
+package radixtree
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetAs(t *testing.T) {
+	tree := NewAny()
+	tree.Insert([]byte("plugins/count"), 3)
+	tree.Insert([]byte("plugins/name"), "radix")
+	tree.Insert([]byte("plugins/reader"), strings.NewReader(""))
+
+	if v, err := GetAs[int](tree, []byte("plugins/count")); err != nil || v != 3 {
+		t.Errorf("GetAs[int] = %d, %v, want 3", v, err)
+	}
+	if _, err := GetAs[io.Reader](tree, []byte("plugins/reader")); err != nil {
+		t.Errorf("GetAs[io.Reader] returned error %v", err)
+	}
+	_, err := GetAs[int](tree, []byte("plugins/name"))
+	if !errors.Is(err, ErrWrongType) {
+		t.Errorf("GetAs[int] of a string returned %v, want ErrWrongType", err)
+	}
+	if want := `"plugins/name" holds string, not int`; err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("GetAs error = %v, want it to end with %s", err, want)
+	}
+	if _, err := GetAs[int](tree, []byte("plugins/missing")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAs of a missing key returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestLongestPrefixAs(t *testing.T) {
+	tree := NewAny()
+	tree.Insert([]byte("/"), "root")
+	tree.Insert([]byte("/api"), 1)
+
+	if v, err := LongestPrefixAs[string](tree, []byte("/static/app.js")); err != nil || v != "root" {
+		t.Errorf("LongestPrefixAs = %q, %v, want root", v, err)
+	}
+	if _, err := LongestPrefixAs[string](tree, []byte("/api/users")); !errors.Is(err, ErrWrongType) {
+		t.Errorf("LongestPrefixAs of an int returned %v, want ErrWrongType", err)
+	}
+	if _, err := LongestPrefixAs[string](tree, []byte("api")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LongestPrefixAs without a prefix returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestFindAs(t *testing.T) {
+	tree := NewAny()
+	for i, w := range []string{"a", "ab", "abc", "abd", "b"} {
+		if i%2 == 0 {
+			tree.Insert([]byte(w), i)
+		} else {
+			tree.Insert([]byte(w), fmt.Sprint(i))
+		}
+	}
+	if got, want := FindAs[int](tree, []byte("a")), []int{0, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAs[int] = %v, want %v", got, want)
+	}
+	if got, want := FindAs[string](tree, []byte("ab")), []string{"1", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAs[string] = %v, want %v", got, want)
+	}
+}
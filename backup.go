@@ -0,0 +1,217 @@
+package radixtree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// backupMagic starts every backup, followed by the format version.
+var backupMagic = []byte("RDXB\x01")
+
+// The kinds of records in a backup. Removals record how keys were taken out of
+// the tree: a single key, the keys in a range or the keys with a prefix.
+const (
+	backupEnd = iota
+	backupPut
+	backupKey
+	backupRange
+	backupPrefix
+)
+
+// removal is the record of keys being removed from a tree with modification
+// tracking, kept for BackupSince. For a range a nil end is the end of the
+// tree.
+type removal struct {
+	gen   uint64
+	kind  byte
+	start []byte
+	end   []byte
+}
+
+// logRemoval records that the keys described by kind, start and end were
+// removed in the current generation, if BackupSince has been called, and
+// reports their removal to the watches of the keys.
+func (t *RadixTree[T]) logRemoval(kind byte, start, end []byte) {
+	t.notifyRemoved(kind, start, end)
+	if !t.logRemovals {
+		return
+	}
+	r := removal{gen: t.gen, kind: kind, start: append([]byte(nil), start...)}
+	if end != nil {
+		r.end = append([]byte(nil), end...)
+	}
+	t.removals = append(t.removals, r)
+}
+
+// ForgetRemovals discards the record of the removals made up to and including
+// generation gen, which BackupSince only needs for backups since an earlier
+// generation. BackupSince discards them itself, so ForgetRemovals is only
+// needed to release the record of a tree whose backups have stopped, or to
+// drop it up to a generation that a backup has been kept since by other means.
+func (t *RadixTree[T]) ForgetRemovals(gen uint64) {
+	i := 0
+	for i < len(t.removals) && t.removals[i].gen <= gen {
+		i++
+	}
+	t.removals = append(t.removals[:0:0], t.removals[i:]...)
+	t.removalsFrom = max(t.removalsFrom, gen)
+}
+
+// BackupSince writes an incremental backup of the changes made to the tree
+// after generation gen to w and returns the generation of the backup, to pass
+// to the next call. The backup holds the entries inserted or updated after
+// gen, as visited by WalkModifiedSince, with values encoded by encode, and the
+// removals made after gen, so that RestoreInto brings a copy of the tree as it
+// was at gen up to date. A backup since generation 0 holds every entry. The
+// tree must have been created with WithModificationTracking.
+//
+// The tree records its removals from the first call of BackupSince onwards,
+// so a tree that is never backed up keeps no record of them; the first backup
+// is therefore since generation 0 or the generation it is called at. Each call
+// discards the record of the removals up to gen, so the backups must be taken
+// since generations that do not decrease, as in a chain of backups each since
+// the generation returned by the previous one. A backup since a generation
+// whose removals are no longer recorded fails with ErrRemovalsDiscarded.
+func (t *RadixTree[T]) BackupSince(gen uint64, w io.Writer, encode func(value T) ([]byte, error)) (uint64, error) {
+	if !t.logRemovals {
+		t.logRemovals = true
+		t.removalsFrom = t.gen
+	}
+	if gen != 0 && gen < t.removalsFrom {
+		return 0, ErrRemovalsDiscarded
+	}
+	t.ForgetRemovals(gen)
+
+	bw := bufio.NewWriter(w)
+	var buf [binary.MaxVarintLen64]byte
+	uvarint := func(x uint64) {
+		bw.Write(buf[:binary.PutUvarint(buf[:], x)])
+	}
+	field := func(b []byte) {
+		uvarint(uint64(len(b)))
+		bw.Write(b)
+	}
+
+	bw.Write(backupMagic)
+	uvarint(t.gen)
+	for _, r := range t.removals {
+		if r.gen <= gen {
+			continue
+		}
+		bw.WriteByte(r.kind)
+		field(r.start)
+		if r.kind == backupRange {
+			if r.end == nil {
+				bw.WriteByte(0)
+			} else {
+				bw.WriteByte(1)
+				field(r.end)
+			}
+		}
+	}
+
+	var err error
+	t.WalkModifiedSince(gen, func(key []byte, value T) bool {
+		var b []byte
+		if b, err = encode(value); err != nil {
+			return false
+		}
+		bw.WriteByte(backupPut)
+		field(key)
+		field(b)
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	bw.WriteByte(backupEnd)
+	return t.gen, bw.Flush()
+}
+
+// RestoreInto applies a backup written by BackupSince to the tree, decoding
+// each value with decode. The tree should hold the entries of the backed up
+// tree as they were at the generation the backup was taken since, for example
+// by restoring the backups up to that generation in order onto an empty tree.
+// The removals in the backup are applied before its entries are inserted. The
+// whole backup is read before the tree is changed, so if the input is
+// malformed ErrCorrupt is returned and, as for an error from decode, the tree
+// is left unchanged. If the entries would exceed a limit of the tree the error
+// is returned as by InsertManyErr once the removals are applied, and none of
+// the entries are inserted.
+func (t *RadixTree[T]) RestoreInto(r io.Reader, decode func(b []byte) (T, error)) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(backupMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || !bytes.Equal(magic, backupMagic) {
+		return corrupt(err)
+	}
+	if _, err := binary.ReadUvarint(br); err != nil {
+		return corrupt(err)
+	}
+	read := func() ([]byte, error) {
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		return readN(br, nil, n)
+	}
+
+	var removals []removal
+	var entries []Entry[T]
+	for {
+		kind, err := br.ReadByte()
+		if err != nil {
+			return corrupt(err)
+		}
+		if kind == backupEnd {
+			break
+		}
+		key, err := read()
+		if err != nil {
+			return corrupt(err)
+		}
+		switch kind {
+		case backupPut:
+			b, err := read()
+			if err != nil {
+				return corrupt(err)
+			}
+			v, err := decode(b)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, Entry[T]{Key: key, Value: v})
+		case backupKey, backupPrefix:
+			removals = append(removals, removal{kind: kind, start: key})
+		case backupRange:
+			rm := removal{kind: kind, start: key}
+			hasEnd, err := br.ReadByte()
+			if err == nil && hasEnd == 1 {
+				rm.end, err = read()
+			} else if err == nil && hasEnd != 0 {
+				err = fmt.Errorf("range end flag %d", hasEnd)
+			}
+			if err != nil {
+				return corrupt(err)
+			}
+			removals = append(removals, rm)
+		default:
+			return corrupt(fmt.Errorf("unknown record %d", kind))
+		}
+	}
+
+	for _, rm := range removals {
+		switch rm.kind {
+		case backupKey:
+			t.Remove(rm.start)
+		case backupRange:
+			t.DeleteRange(rm.start, rm.end)
+		case backupPrefix:
+			t.Detach(rm.start, false)
+		}
+	}
+	_, err := t.InsertManyErr(entries)
+	return err
+}
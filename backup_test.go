@@ -0,0 +1,110 @@
+package radixtree
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestBackupSince(t *testing.T) {
+	tree := New[string](WithModificationTracking())
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	var full bytes.Buffer
+	gen, err := tree.BackupSince(0, &full, encodeString)
+	if err != nil {
+		t.Fatalf("BackupSince(0) returned error %v", err)
+	}
+	tree.ForgetRemovals(gen)
+
+	tree.Insert([]byte("toad"), "TOAD")
+	tree.Insert([]byte("abacus"), "abacus")
+	tree.Remove([]byte("winkle"))
+	tree.RemoveMany([][]byte{[]byte("babble"), []byte("missing")})
+	tree.DeleteRange([]byte("mac"), []byte("mad"))
+	tree.Detach([]byte("toady"), false)
+	tree.Insert([]byte("macrame"), "macrame")
+	var incr bytes.Buffer
+	if _, err := tree.BackupSince(gen, &incr, encodeString); err != nil {
+		t.Fatalf("BackupSince(%d) returned error %v", gen, err)
+	}
+	if incr.Len() >= full.Len() {
+		t.Errorf("incremental backup of %d bytes is not smaller than the full backup of %d", incr.Len(), full.Len())
+	}
+
+	restored := New[string]()
+	for _, b := range []*bytes.Buffer{&full, &incr} {
+		if err := restored.RestoreInto(bytes.NewReader(b.Bytes()), decodeString); err != nil {
+			t.Fatalf("RestoreInto returned error %v", err)
+		}
+	}
+	if got, want := restored.ToMap(), tree.ToMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored tree\n got: %v\nwant: %v", got, want)
+	}
+
+	// A malformed backup leaves the tree unchanged.
+	want := restored.ToMap()
+	for n := 0; n < incr.Len(); n++ {
+		if err := restored.RestoreInto(bytes.NewReader(incr.Bytes()[:n]), decodeString); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("RestoreInto of %d bytes returned %v, want %v", n, err, ErrCorrupt)
+		}
+	}
+	if got := restored.ToMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("tree after malformed backups\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestForgetRemovals(t *testing.T) {
+	tree := New[string](WithModificationTracking())
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	// Removals are only recorded once the tree is backed up.
+	tree.Remove([]byte("abacus"))
+	if len(tree.removals) != 0 {
+		t.Errorf("tree that was never backed up recorded removals %v", tree.removals)
+	}
+	full, err := tree.BackupSince(0, io.Discard, encodeString)
+	if err != nil {
+		t.Fatalf("BackupSince(0) returned error %v", err)
+	}
+
+	tree.Remove([]byte("toad"))
+	gen := tree.Generation()
+	tree.Remove([]byte("winkle"))
+	tree.ForgetRemovals(gen)
+	if len(tree.removals) != 1 || string(tree.removals[0].start) != "winkle" {
+		t.Errorf("removals after ForgetRemovals(%d) = %v, want winkle", gen, tree.removals)
+	}
+	if _, err := tree.BackupSince(full, io.Discard, encodeString); !errors.Is(err, ErrRemovalsDiscarded) {
+		t.Errorf("BackupSince of forgotten removals returned %v, want %v", err, ErrRemovalsDiscarded)
+	}
+
+	// Each backup discards the removals it no longer needs.
+	next, err := tree.BackupSince(gen, io.Discard, encodeString)
+	if err != nil {
+		t.Fatalf("BackupSince(%d) returned error %v", gen, err)
+	}
+	tree.Remove([]byte("wink"))
+	if _, err := tree.BackupSince(next, io.Discard, encodeString); err != nil {
+		t.Fatalf("BackupSince(%d) returned error %v", next, err)
+	}
+	if len(tree.removals) != 1 || string(tree.removals[0].start) != "wink" {
+		t.Errorf("removals after BackupSince(%d) = %v, want wink", next, tree.removals)
+	}
+
+	// RemoveMany records only the keys it removed.
+	tree.RemoveMany([][]byte{[]byte("missing"), []byte("toa"), []byte("toa"), []byte("wink")})
+	if len(tree.removals) != 2 || string(tree.removals[1].start) != "toa" {
+		t.Errorf("removals after RemoveMany = %v, want wink and toa", tree.removals)
+	}
+
+	untracked := build(words)
+	untracked.Remove([]byte("toad"))
+	if len(untracked.removals) != 0 {
+		t.Errorf("tree without modification tracking recorded removals %v", untracked.removals)
+	}
+}
@@ -0,0 +1,339 @@
+package radixtree
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Entry is a key and its value.
+type Entry[T any] struct {
+	Key   []byte
+	Value T
+}
+
+// InsertMany inserts every entry into the tree and returns the number of keys
+// that were not already in the tree. The result is the same as calling Insert
+// for each entry in turn, so if several entries have the same key the last
+// one's value is kept, but the entries are sorted and built into a subtree
+// along its right edge which is then merged into the tree in a single pass
+// rather than searching from the root for each entry. The entries slice is not
+// modified and, unlike with Insert, the keys are copied. Like Insert, it
+// panics if a key would exceed a limit of the tree, leaving the tree
+// unchanged; use InsertManyErr to have the error returned instead.
+func (t *RadixTree[T]) InsertMany(entries []Entry[T]) int {
+	n, err := t.InsertManyErr(entries)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// InsertManyErr is like InsertMany but returns an error, leaving the tree
+// unchanged, if a key is longer than the limit set by WithMaxKeyLen or if
+// inserting the keys would exceed a limit set by WithMaxEntries,
+// WithMaxKeyBytes or WithPrefixLimit, as for InsertErr.
+func (t *RadixTree[T]) InsertManyErr(entries []Entry[T]) (int, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return t.opts.order.compare(entries[order[i]].Key, entries[order[j]].Key) < 0
+	})
+	b := quotaBatch[T]{t: t}
+	if t.limited() {
+		for i, j := range order {
+			key := entries[j].Key
+			if i > 0 && bytes.Equal(key, entries[order[i-1]].Key) {
+				continue
+			}
+			if err := b.add(key); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	base, gen := t.seq, t.gen
+	l := newLoader(t)
+	for i := 0; i < len(order); {
+		// Of the entries with the same key the first gives its position
+		// in insertion order and the last gives its value.
+		j := i + 1
+		for j < len(order) && bytes.Equal(entries[order[i]].Key, entries[order[j]].Key) {
+			j++
+		}
+		lf := l.add(entries[order[i]].Key, entries[order[j-1]].Value)
+		if t.opts.insertionOrder {
+			lf.seq = base + uint64(order[i]) + 1
+		}
+		i = j
+	}
+	if t.opts.insertionOrder {
+		t.seq = base + uint64(len(entries))
+	}
+	replaced := l.merge()
+	t.addKeyBytes(gen, b.bytes)
+	return l.size - replaced, nil
+}
+
+// ContainsAll returns true if every key is in the tree. The keys are looked up
+// together as by GetMany and the search stops at the first key that is
+// missing. It returns true if there are no keys.
+func (t *RadixTree[T]) ContainsAll(keys [][]byte) bool {
+	queries := t.queries(keys)
+	if len(queries) < len(keys) {
+		return false
+	}
+	return lookupSorted(t.root, queries, func(_ query, lf *leaf[T]) bool {
+		return lf != nil
+	})
+}
+
+// ContainsAny returns true if any of the keys is in the tree. The keys are
+// looked up together as by GetMany and the search stops at the first key that
+// is found. It returns false if there are no keys.
+func (t *RadixTree[T]) ContainsAny(keys [][]byte) bool {
+	return !lookupSorted(t.root, t.queries(keys), func(_ query, lf *leaf[T]) bool {
+		return lf == nil
+	})
+}
+
+// GetMany looks up every key and returns their values and whether each was
+// found, in the order of the keys. Like Get it returns the zero value for type
+// T and false for a key that is not in the tree. The keys are sorted and looked
+// up in a single pass over the tree, so the nodes on the path to a prefix that
+// several keys share are visited once rather than once per key. The keys slice
+// is not modified.
+func (t *RadixTree[T]) GetMany(keys [][]byte) ([]T, []bool) {
+	values := make([]T, len(keys))
+	found := make([]bool, len(keys))
+	lookupSorted(t.root, t.queries(keys), func(q query, lf *leaf[T]) bool {
+		if lf != nil {
+			values[q.i] = lf.value
+			found[q.i] = true
+		}
+		return true
+	})
+	return values, found
+}
+
+// LongestPrefixMany resolves LongestPrefix for every key and returns the values
+// and whether a prefix of each was found, in the order of the keys. The keys
+// are sorted and matched in a single pass over the tree as by GetMany, so keys
+// that share a prefix, such as addresses in the same network, follow the path
+// to it once. The keys slice is not modified.
+func (t *RadixTree[T]) LongestPrefixMany(keys [][]byte) ([]T, []bool) {
+	values := make([]T, len(keys))
+	found := make([]bool, len(keys))
+	queries := make([]query, len(keys))
+	for i, key := range keys {
+		queries[i] = query{key: key, i: i}
+	}
+	sortQueries(queries)
+	longestSorted(t.root, queries, t.root.leaf, func(q query, lf *leaf[T]) {
+		if lf != nil {
+			values[q.i] = lf.value
+			found[q.i] = true
+		}
+	})
+	return values, found
+}
+
+// query is a key looked up by a batched lookup and its index in the keys.
+type query struct {
+	key []byte
+	i   int
+}
+
+// queries returns the keys that the bloom filter, if any, does not rule out
+// with their indexes, sorted by key.
+func (t *RadixTree[T]) queries(keys [][]byte) []query {
+	queries := make([]query, 0, len(keys))
+	for i, key := range keys {
+		if t.filter == nil || t.filter.mayContain(key) {
+			queries = append(queries, query{key: key, i: i})
+		}
+	}
+	sortQueries(queries)
+	return queries
+}
+
+// sortQueries sorts the queries by key.
+func sortQueries(queries []query) {
+	sort.Slice(queries, func(i, j int) bool {
+		return bytes.Compare(queries[i].key, queries[j].key) < 0
+	})
+}
+
+// lookupSorted looks up the queries, whose keys are relative to n and in
+// ascending order, in the subtree rooted at n and executes f for each of them
+// with the leaf of its key, or nil if the key is not in the tree. If f returns
+// false the search stops and lookupSorted returns false. The queries slice is
+// overwritten.
+func lookupSorted[T any](n *node[T], queries []query, f func(q query, lf *leaf[T]) bool) bool {
+	for len(queries) > 0 && len(queries[0].key) == 0 {
+		if !f(queries[0], n.leaf) {
+			return false
+		}
+		queries = queries[1:]
+	}
+
+	for len(queries) > 0 {
+		// The keys that start with the same byte can only be below the
+		// same child.
+		j := 1
+		for j < len(queries) && queries[j].key[0] == queries[0].key[0] {
+			j++
+		}
+		group := queries[:j]
+		queries = queries[j:]
+
+		child := n.children.get(group[0].key[0])
+		rest := group[:0]
+		for _, q := range group {
+			if child == nil || !bytes.HasPrefix(q.key, child.prefix) {
+				if !f(q, nil) {
+					return false
+				}
+				continue
+			}
+			rest = append(rest, query{key: q.key[len(child.prefix):], i: q.i})
+		}
+		if len(rest) > 0 && !lookupSorted(child, rest, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// longestSorted is like lookupSorted but executes f for each query with the
+// leaf of the longest key that is a prefix of it, where last is the leaf of the
+// longest such key above n, and never stops.
+func longestSorted[T any](n *node[T], queries []query, last *leaf[T], f func(q query, lf *leaf[T])) {
+	for len(queries) > 0 && len(queries[0].key) == 0 {
+		f(queries[0], last)
+		queries = queries[1:]
+	}
+
+	for len(queries) > 0 {
+		j := 1
+		for j < len(queries) && queries[j].key[0] == queries[0].key[0] {
+			j++
+		}
+		group := queries[:j]
+		queries = queries[j:]
+
+		child := n.children.get(group[0].key[0])
+		rest := group[:0]
+		for _, q := range group {
+			if child == nil || !bytes.HasPrefix(q.key, child.prefix) {
+				f(q, last)
+				continue
+			}
+			rest = append(rest, query{key: q.key[len(child.prefix):], i: q.i})
+		}
+		if len(rest) > 0 {
+			next := last
+			if child.hasValue() {
+				next = child.leaf
+			}
+			longestSorted(child, rest, next, f)
+		}
+	}
+}
+
+// RemoveMany removes the given keys from the tree and returns the number of
+// keys that were removed. The keys are sorted and removed in a single pass
+// over the tree, visiting the nodes on the paths to several keys once rather
+// than once per key. The keys slice is not modified.
+func (t *RadixTree[T]) RemoveMany(keys [][]byte) int {
+	t.unshareAll()
+	sorted := append([][]byte(nil), keys...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+	// Only the keys in the tree are recorded as removed, which removeSorted
+	// does not report, so they are looked up first if they are needed.
+	var found [][]byte
+	if t.logRemovals || t.watches != nil {
+		for i, key := range sorted {
+			if (i == 0 || !bytes.Equal(key, sorted[i-1])) && t.lookup(key) != nil {
+				found = append(found, key)
+			}
+		}
+	}
+	removed := t.removeSorted(t.root, sorted)
+	if removed > 0 {
+		t.size -= removed
+		t.removed += removed
+		t.gen++
+		t.detached++
+		for _, key := range found {
+			t.logRemoval(backupKey, key, nil)
+		}
+	}
+	return removed
+}
+
+// RemoveManyErr is like RemoveMany with an error result, as required by Tree.
+// Removing keys from a RadixTree cannot fail so the error is always nil.
+func (t *RadixTree[T]) RemoveManyErr(keys [][]byte) (int, error) {
+	return t.RemoveMany(keys), nil
+}
+
+// removeSorted removes the keys, which are relative to n and in ascending
+// order, from the subtree rooted at n and returns the number of keys removed.
+// The keys slice is overwritten.
+func (t *RadixTree[T]) removeSorted(n *node[T], keys [][]byte) int {
+	removed := 0
+	for len(keys) > 0 && len(keys[0]) == 0 {
+		if n.hasValue() {
+			n.leaf = nil
+			removed++
+		}
+		keys = keys[1:]
+	}
+
+	for len(keys) > 0 {
+		// The keys that start with the same byte can only be below the
+		// same child.
+		j := 1
+		for j < len(keys) && keys[j][0] == keys[0][0] {
+			j++
+		}
+		group := keys[:j]
+		keys = keys[j:]
+
+		i := n.children.index(group[0][0])
+		if i < 0 {
+			continue
+		}
+		child := n.children.nodes[i]
+		rest := group[:0]
+		for _, key := range group {
+			if bytes.HasPrefix(key, child.prefix) {
+				rest = append(rest, key[len(child.prefix):])
+			}
+		}
+		if r := t.removeSorted(child, rest); r > 0 {
+			removed += r
+			switch {
+			case t.opts.lazyDelete:
+				// The nodes are left for Compact.
+			case !child.hasValue() && child.children.len() == 0:
+				n.children.remove(i)
+				t.free(child.prefix)
+			case !child.hasValue() && child.children.len() == 1 && !t.opts.deferMerge:
+				t.merge(child)
+			}
+		}
+	}
+
+	if removed > 0 {
+		t.update(n)
+	}
+	return removed
+}
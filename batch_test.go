@@ -0,0 +1,172 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInsertMany(t *testing.T) {
+	tree := New[string](WithInsertionOrder(), WithAggregate("", concat, identity))
+	tree.Insert([]byte("toad"), "old")
+	tree.Insert([]byte("zebra"), "zebra")
+
+	// The entries are out of order and "macro" appears twice.
+	var entries []Entry[string]
+	for i := len(words) - 1; i >= 0; i-- {
+		entries = append(entries, Entry[string]{[]byte(words[i]), words[i]})
+	}
+	entries = append(entries, Entry[string]{[]byte("macro"), "macro"})
+	if got, want := tree.InsertMany(entries), len(words)-1; got != want {
+		t.Errorf("InsertMany = %d, want %d", got, want)
+	}
+	verify(t, tree)
+	checkAggregates(t, "InsertMany", tree, words)
+	if got, want := tree.Len(), len(words)+1; got != want {
+		t.Errorf("Len = %d, want %d", got, want)
+	}
+	if v, _ := tree.Get([]byte("toad")); v != "toad" {
+		t.Errorf("Get(toad) = %q, want \"toad\"", v)
+	}
+
+	// The order matches inserting the entries one at a time.
+	want := []string{"toad", "zebra"}
+	for i := len(words) - 1; i >= 0; i-- {
+		if words[i] != "toad" {
+			want = append(want, words[i])
+		}
+	}
+	if got := walkByInsertion(tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkByInsertion after InsertMany\n got: %v\nwant: %v", got, want)
+	}
+	if string(entries[0].Key) != words[len(words)-1] {
+		t.Errorf("InsertMany modified its argument")
+	}
+
+	tree.Insert([]byte("aaa"), "aaa")
+	if got := walkByInsertion(tree); got[len(got)-1] != "aaa" {
+		t.Errorf("key inserted after InsertMany is not last in insertion order: %v", got)
+	}
+	if got := tree.InsertMany(nil); got != 0 {
+		t.Errorf("InsertMany(nil) = %d, want 0", got)
+	}
+}
+
+func TestRemoveMany(t *testing.T) {
+	tree := New[string](WithAggregate("", concat, identity))
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	tree.Insert(nil, "")
+
+	keys := [][]byte{[]byte("wit"), []byte("toa"), []byte(""), []byte("macro"), []byte("toad"), []byte("toa"), []byte("x"), []byte("mac"), []byte("aardvark")}
+	if got := tree.RemoveMany(keys); got != 6 {
+		t.Errorf("RemoveMany = %d, want 6", got)
+	}
+	if string(keys[0]) != "wit" {
+		t.Errorf("RemoveMany modified its argument")
+	}
+	verify(t, tree)
+	checkAggregates(t, "RemoveMany", tree, words)
+
+	var want []string
+	for _, key := range words {
+		switch key {
+		case "wit", "toa", "macro", "toad", "aardvark":
+		default:
+			want = append(want, key)
+		}
+	}
+	if got := tree.Values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Values after RemoveMany\n got: %v\nwant: %v", got, want)
+	}
+
+	if got := tree.RemoveMany([][]byte{[]byte("wit")}); got != 0 {
+		t.Errorf("RemoveMany of a removed key = %d, want 0", got)
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithBloomFilter(100, 0.01)}} {
+		tree := New[string](opts...)
+		for _, w := range words {
+			tree.Insert([]byte(w), w)
+		}
+		tree.Insert(nil, "root")
+
+		var keys [][]byte
+		for _, k := range []string{"toad", "", "wink", "toadst", "macro", "to", "x", "toad", "mac", "wilting"} {
+			keys = append(keys, []byte(k))
+		}
+		values, found := tree.GetMany(keys)
+		for i, key := range keys {
+			want, ok := tree.Get(key)
+			if values[i] != want || found[i] != ok {
+				t.Errorf("GetMany %q = (%q, %t), want (%q, %t)", key, values[i], found[i], want, ok)
+			}
+		}
+		if values, found := tree.GetMany(nil); len(values) != 0 || len(found) != 0 {
+			t.Errorf("GetMany(nil) returned results")
+		}
+	}
+}
+
+func TestContainsAll(t *testing.T) {
+	tree := build(words)
+	for _, test := range []struct {
+		keys []string
+		want bool
+	}{
+		{nil, true},
+		{[]string{"toad", "to", "toadyism", "wink"}, true},
+		{[]string{"toad", "toadst", "wink"}, false},
+		{[]string{"toad", ""}, false},
+	} {
+		var keys [][]byte
+		for _, k := range test.keys {
+			keys = append(keys, []byte(k))
+		}
+		if got := tree.ContainsAll(keys); got != test.want {
+			t.Errorf("ContainsAll(%q) = %t, want %t", test.keys, got, test.want)
+		}
+	}
+}
+
+func TestContainsAny(t *testing.T) {
+	tree := build(words)
+	for _, test := range []struct {
+		keys []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"toadst", "x", "wi", "toad"}, true},
+		{[]string{"toadst", "x", "wi", "t", ""}, false},
+	} {
+		var keys [][]byte
+		for _, k := range test.keys {
+			keys = append(keys, []byte(k))
+		}
+		if got := tree.ContainsAny(keys); got != test.want {
+			t.Errorf("ContainsAny(%q) = %t, want %t", test.keys, got, test.want)
+		}
+	}
+}
+
+func TestLongestPrefixMany(t *testing.T) {
+	for _, root := range []bool{false, true} {
+		tree := build(words)
+		if root {
+			tree.Insert(nil, "root")
+		}
+		var keys [][]byte
+		for _, k := range []string{"toadstool", "", "winkles", "toadyisms", "macroanalysts", "t", "x", "toadstool", "mac", "wilted", "abacus", "aardvarks"} {
+			keys = append(keys, []byte(k))
+		}
+		values, found := tree.LongestPrefixMany(keys)
+		for i, key := range keys {
+			want, ok := tree.LongestPrefix(key)
+			if values[i] != want || found[i] != ok {
+				t.Errorf("LongestPrefixMany %q = (%q, %t), want (%q, %t)", key, values[i], found[i], want, ok)
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package radixtree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sync/atomic"
+)
+
+// bloom is a bloom filter over the keys inserted into a tree.
+type bloom struct {
+	bits []uint64
+	k    uint64
+}
+
+// newBloom returns a bloom filter sized to hold n keys with a false positive
+// rate of p.
+func newBloom(n int, p float64) *bloom {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+	return &bloom{
+		bits: make([]uint64, (uint64(m)+63)/64),
+		k:    uint64(k),
+	}
+}
+
+// clone returns a copy of the filter.
+func (b *bloom) clone() *bloom {
+	return &bloom{bits: append([]uint64(nil), b.bits...), k: b.k}
+}
+
+// union adds every key in o to b. It returns false if the filters have
+// different sizes and so cannot be combined.
+func (b *bloom) union(o *bloom) bool {
+	if len(b.bits) != len(o.bits) || b.k != o.k {
+		return false
+	}
+	for i, w := range o.bits {
+		b.bits[i] |= w
+	}
+	return true
+}
+
+func (b *bloom) add(key []byte) {
+	h1, h2 := bloomHash(key)
+	m := uint64(len(b.bits)) * 64
+	for i := uint64(0); i < b.k; i++ {
+		bit := (h1 + i*h2) % m
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (b *bloom) mayContain(key []byte) bool {
+	h1, h2 := bloomHash(key)
+	m := uint64(len(b.bits)) * 64
+	for i := uint64(0); i < b.k; i++ {
+		bit := (h1 + i*h2) % m
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// addAtomic is like add for a filter shared between goroutines, which must
+// all use addAtomic and mayContainAtomic.
+func (b *bloom) addAtomic(key []byte) {
+	h1, h2 := bloomHash(key)
+	m := uint64(len(b.bits)) * 64
+	for i := uint64(0); i < b.k; i++ {
+		bit := (h1 + i*h2) % m
+		atomic.OrUint64(&b.bits[bit/64], 1<<(bit%64))
+	}
+}
+
+// mayContainAtomic is like mayContain for a filter updated with addAtomic.
+func (b *bloom) mayContainAtomic(key []byte) bool {
+	h1, h2 := bloomHash(key)
+	m := uint64(len(b.bits)) * 64
+	for i := uint64(0); i < b.k; i++ {
+		bit := (h1 + i*h2) % m
+		if atomic.LoadUint64(&b.bits[bit/64])&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Bloom is an approximate set of keys exported from a tree by ExportBloom, for
+// processes that only need to know whether a key may be in the tree, such as
+// edge servers that skip a lookup for keys that cannot exist. A Bloom is safe
+// for concurrent use.
+type Bloom struct {
+	f *bloom
+}
+
+// bloomMagic starts every encoded Bloom, followed by the format version.
+var bloomMagic = []byte("RDXF\x01")
+
+// ExportBloom returns a bloom filter of every key in the tree, sized for the
+// number of keys so that a key that is not in the tree is reported as present
+// with probability fpRate. A rate that is not between 0 and 1 is taken as 0.01.
+// The filter is independent of the tree, so later changes to the tree are not
+// reflected in it.
+func (t *RadixTree[T]) ExportBloom(fpRate float64) *Bloom {
+	f := newBloom(t.size, fpRate)
+	key := t.borrow()
+	key, _ = walkNodes(t.root, key, -1, func(key []byte, _ *node[T]) bool {
+		f.add(key)
+		return true
+	})
+	t.release(key)
+	return &Bloom{f: f}
+}
+
+// MayContain returns false if key was not in the tree the filter was exported
+// from and true if it may have been.
+func (b *Bloom) MayContain(key []byte) bool {
+	return b.f.mayContain(key)
+}
+
+// MarshalBinary encodes the filter in a format that UnmarshalBinary reads on
+// any platform.
+func (b *Bloom) MarshalBinary() ([]byte, error) {
+	data := append([]byte(nil), bloomMagic...)
+	data = binary.AppendUvarint(data, b.f.k)
+	data = binary.AppendUvarint(data, uint64(len(b.f.bits)))
+	for _, w := range b.f.bits {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a filter encoded by MarshalBinary. It returns
+// ErrCorrupt if the data is malformed.
+func (b *Bloom) UnmarshalBinary(data []byte) error {
+	rest, ok := bytes.CutPrefix(data, bloomMagic)
+	if !ok {
+		return ErrCorrupt
+	}
+	k, n := binary.Uvarint(rest)
+	if n <= 0 || k < 1 {
+		return ErrCorrupt
+	}
+	rest = rest[n:]
+	words, n := binary.Uvarint(rest)
+	if n <= 0 || words < 1 || (len(rest)-n)%8 != 0 || uint64(len(rest)-n)/8 != words {
+		return ErrCorrupt
+	}
+	// A filter never has more hash functions than bits, and more would
+	// let a crafted filter make MayContain loop for as long as it likes.
+	if k > words*64 {
+		return ErrCorrupt
+	}
+	rest = rest[n:]
+	f := &bloom{bits: make([]uint64, words), k: k}
+	for i := range f.bits {
+		f.bits[i] = binary.LittleEndian.Uint64(rest[i*8:])
+	}
+	b.f = f
+	return nil
+}
+
+// bloomHash derives the two hashes used for double hashing from the hash of
+// the key.
+func bloomHash(key []byte) (uint64, uint64) {
+	h := hashKey(key)
+	return h, h>>32 | h<<32 | 1
+}
+
+// hashKey returns the 64-bit FNV-1a hash of key.
+func hashKey(key []byte) uint64 {
+	h := uint64(14695981039346656037)
+	for _, c := range key {
+		h ^= uint64(c)
+		h *= 1099511628211
+	}
+	return h
+}
@@ -0,0 +1,118 @@
+package radixtree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	tree := New[string](WithBloomFilter(len(words), 0.01))
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+
+	for _, want := range words {
+		if got, ok := tree.Get([]byte(want)); !ok || got != want {
+			t.Errorf("Get(%s)\n got: (%s, %t)\nwant: (%s, true)", want, got, ok, want)
+		}
+	}
+
+	for _, key := range []string{"", "aard", "toadie", "zebra"} {
+		if tree.Contains([]byte(key)) {
+			t.Errorf("Contains(%s) returned true for a non-existent key", key)
+		}
+	}
+
+	// Removed keys stay in the filter but must not be found in the tree.
+	tree.Remove([]byte("toad"))
+	if tree.Contains([]byte("toad")) {
+		t.Errorf("Contains(toad) returned true after remove")
+	}
+}
+
+func TestBloomFalsePositiveRate(t *testing.T) {
+	n := 10000
+	b := newBloom(n, 0.01)
+	for i := 0; i < n; i++ {
+		b.add([]byte(strconv.Itoa(i)))
+	}
+
+	for i := 0; i < n; i++ {
+		if !b.mayContain([]byte(strconv.Itoa(i))) {
+			t.Fatalf("mayContain(%d) returned false for an added key", i)
+		}
+	}
+
+	fp := 0
+	for i := n; i < 2*n; i++ {
+		if b.mayContain([]byte(strconv.Itoa(i))) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / float64(n); rate > 0.02 {
+		t.Errorf("false positive rate\n got: %f\nwant: <= 0.02", rate)
+	}
+}
+
+func TestExportBloom(t *testing.T) {
+	tree := New[int]()
+	for i := 0; i < 1000; i++ {
+		tree.Insert([]byte("key"+strconv.Itoa(i)), i)
+	}
+	data, _ := tree.ExportBloom(0.01).MarshalBinary()
+	var b Bloom
+	if err := b.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if !b.MayContain([]byte("key" + strconv.Itoa(i))) {
+			t.Fatalf("MayContain(key%d) = false for a key in the tree", i)
+		}
+	}
+	positives := 0
+	for i := 0; i < 10000; i++ {
+		if b.MayContain([]byte("other" + strconv.Itoa(i))) {
+			positives++
+		}
+	}
+	if positives > 300 {
+		t.Errorf("%d false positives in 10000 lookups, want about 100", positives)
+	}
+
+	for _, n := range []int{0, 3, 6, len(data) - 1} {
+		if err := b.UnmarshalBinary(data[:n]); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("UnmarshalBinary of %d bytes = %v, want ErrCorrupt", n, err)
+		}
+	}
+
+	// A crafted number of hash functions would make MayContain loop for
+	// practically forever, but a filter for a tiny rate, with more than 64,
+	// is read back.
+	crafted := binary.AppendUvarint(append([]byte(nil), bloomMagic...), 1<<62)
+	crafted = binary.AppendUvarint(crafted, 1)
+	crafted = binary.LittleEndian.AppendUint64(crafted, ^uint64(0))
+	if err := b.UnmarshalBinary(crafted); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("UnmarshalBinary with k = 1<<62 = %v, want ErrCorrupt", err)
+	}
+	strict, _ := tree.ExportBloom(1e-30).MarshalBinary()
+	if err := b.UnmarshalBinary(strict); err != nil || b.f.k <= 64 || !b.MayContain([]byte("key1")) {
+		t.Errorf("UnmarshalBinary of a filter with k = %d: %v", b.f.k, err)
+	}
+
+	// Neither the Bloom decoder nor RestoreInto accepts the other format.
+	tracked := New[string](WithModificationTracking())
+	tracked.Insert([]byte("a"), "a")
+	var backup bytes.Buffer
+	if _, err := tracked.BackupSince(0, &backup, encodeString); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.UnmarshalBinary(backup.Bytes()); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("UnmarshalBinary of a backup = %v, want ErrCorrupt", err)
+	}
+	if err := New[string]().RestoreInto(bytes.NewReader(data), decodeString); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("RestoreInto of a Bloom = %v, want ErrCorrupt", err)
+	}
+}
@@ -0,0 +1,67 @@
+package radixtree
+
+import "bytes"
+
+// cache is a small 2-way set associative cache of lookup results. Entries are
+// tagged with the generation of the tree they were computed against so any
+// mutation of the tree invalidates every entry at once.
+type cache[T any] struct {
+	sets []cacheSet[T]
+}
+
+type cacheSet[T any] struct {
+	ways [2]cacheEntry[T]
+	lru  int
+}
+
+type cacheEntry[T any] struct {
+	key   []byte
+	value T
+	found bool
+	gen   uint64
+	valid bool
+}
+
+// newCache returns a cache that holds up to size entries.
+func newCache[T any](size int) *cache[T] {
+	sets := (size + 1) / 2
+	if sets < 1 {
+		sets = 1
+	}
+	return &cache[T]{sets: make([]cacheSet[T], sets)}
+}
+
+// get returns the cached result for key. The final boolean value reports
+// whether a result computed at generation gen was found.
+func (c *cache[T]) get(key []byte, gen uint64) (T, bool, bool) {
+	s := &c.sets[hashKey(key)%uint64(len(c.sets))]
+	for i := range s.ways {
+		e := &s.ways[i]
+		if e.valid && e.gen == gen && bytes.Equal(e.key, key) {
+			s.lru = 1 - i
+			return e.value, e.found, true
+		}
+	}
+	var zero T
+	return zero, false, false
+}
+
+// put stores the result of a lookup for key computed at generation gen,
+// replacing the least recently used entry in its set.
+func (c *cache[T]) put(key []byte, gen uint64, value T, found bool) {
+	s := &c.sets[hashKey(key)%uint64(len(c.sets))]
+	i := s.lru
+	for j := range s.ways {
+		if !s.ways[j].valid || s.ways[j].gen != gen {
+			i = j
+			break
+		}
+	}
+	e := &s.ways[i]
+	e.key = append(e.key[:0], key...)
+	e.value = value
+	e.found = found
+	e.gen = gen
+	e.valid = true
+	s.lru = 1 - i
+}
@@ -0,0 +1,60 @@
+package radixtree
+
+import "testing"
+
+func TestLookupCache(t *testing.T) {
+	tree := New[string](WithLookupCache(4))
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+
+	// Repeated lookups must keep returning the same results when they are
+	// served from the cache.
+	for i := 0; i < 3; i++ {
+		for _, want := range words {
+			if got, ok := tree.Get([]byte(want)); !ok || got != want {
+				t.Errorf("Get(%s)\n got: (%s, %t)\nwant: (%s, true)", want, got, ok, want)
+			}
+		}
+		if got, ok := tree.LongestPrefix([]byte("winkley")); !ok || got != "winkle" {
+			t.Errorf("LongestPrefix(winkley)\n got: (%s, %t)\nwant: (winkle, true)", got, ok)
+		}
+		if got, ok := tree.Get([]byte("aard")); ok || got != "" {
+			t.Errorf("Get(aard)\n got: (%s, %t)\nwant: (\"\", false)", got, ok)
+		}
+	}
+
+	// Mutations must invalidate cached results.
+	tree.Insert([]byte("aard"), "aard")
+	if got, ok := tree.Get([]byte("aard")); !ok || got != "aard" {
+		t.Errorf("Get(aard) after insert\n got: (%s, %t)\nwant: (aard, true)", got, ok)
+	}
+
+	tree.Insert([]byte("winkley"), "winkley")
+	if got, ok := tree.LongestPrefix([]byte("winkley")); !ok || got != "winkley" {
+		t.Errorf("LongestPrefix(winkley) after insert\n got: (%s, %t)\nwant: (winkley, true)", got, ok)
+	}
+
+	tree.Remove([]byte("toad"))
+	if got, ok := tree.Get([]byte("toad")); ok || got != "" {
+		t.Errorf("Get(toad) after remove\n got: (%s, %t)\nwant: (\"\", false)", got, ok)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := newCache[int](2)
+	c.put([]byte("a"), 0, 1, true)
+	c.put([]byte("b"), 0, 2, true)
+	c.get([]byte("a"), 0)
+	c.put([]byte("c"), 0, 3, true)
+
+	if v, _, hit := c.get([]byte("a"), 0); !hit || v != 1 {
+		t.Errorf("get(a) after eviction\n got: (%d, %t)\nwant: (1, true)", v, hit)
+	}
+	if _, _, hit := c.get([]byte("b"), 0); hit {
+		t.Errorf("get(b) returned the least recently used entry")
+	}
+	if _, _, hit := c.get([]byte("a"), 1); hit {
+		t.Errorf("get returned an entry from a stale generation")
+	}
+}
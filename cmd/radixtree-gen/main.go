@@ -0,0 +1,240 @@
+// Command radixtree-gen generates Go source for matching a fixed set of keys,
+// such as keywords or route prefixes, without a tree at run time. The keys are
+// built into a radix tree whose nodes become nested comparisons of the bytes
+// of the input, so the generated functions allocate nothing.
+//
+// Usage:
+//
+//	radixtree-gen [-pkg name] [-name prefix] [-o output] [-var name] input
+//
+// The input is a text file with a key on each line or, with -var, a Go source
+// file in which the named variable or constant is initialized by a composite
+// literal of string literals, such as a slice of keywords. It is typically run
+// with go:generate:
+//
+//	//go:generate go run github.com/jhm/go-radixtree/v2/cmd/radixtree-gen -pkg lexer -name Keyword -o keywords_gen.go keywords.txt
+//
+// For the name Keyword the generated file declares KeywordKeys, the keys in
+// ascending order, and the functions
+//
+//	func KeywordGet(s string) (int, bool)
+//	func KeywordContains(s string) bool
+//	func KeywordLongestPrefix(s string) (ordinal, length int, ok bool)
+//
+// where the ordinal of a key is its index in KeywordKeys.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+var errUsage = errors.New("usage")
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(os.Stderr, "usage: radixtree-gen [-pkg name] [-name prefix] [-o output] [-var name] input")
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "radixtree-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("radixtree-gen", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	pkg := fs.String("pkg", "", "package of the generated file, by default that of $GOPACKAGE")
+	name := fs.String("name", "Keys", "prefix of the generated identifiers")
+	output := fs.String("o", "", "file to write, by default standard output")
+	variable := fs.String("var", "", "read the keys from this variable of a Go source file")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	if *pkg == "" {
+		if *pkg = os.Getenv("GOPACKAGE"); *pkg == "" {
+			return fmt.Errorf("no package given with -pkg or $GOPACKAGE")
+		}
+	}
+	if !token.IsIdentifier(*name) {
+		return fmt.Errorf("name %q is not an identifier", *name)
+	}
+
+	var keys []string
+	var err error
+	if *variable != "" {
+		keys, err = readSource(fs.Arg(0), *variable)
+	} else {
+		keys, err = readLines(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	src, err := generate(*pkg, *name, keys, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*output, src, 0o666)
+}
+
+// readLines returns the lines of a file, without their line endings.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var keys []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		keys = append(keys, strings.TrimSuffix(s.Text(), "\r"))
+	}
+	return keys, s.Err()
+}
+
+// readSource returns the string literals of the composite literal that
+// initializes the named variable or constant in a Go source file.
+func readSource(path, name string) ([]string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for i, id := range vs.Names {
+				if id.Name != name || i >= len(vs.Values) {
+					continue
+				}
+				lit, ok := vs.Values[i].(*ast.CompositeLit)
+				if !ok {
+					return nil, fmt.Errorf("%s: %s is not initialized by a composite literal", fset.Position(id.Pos()), name)
+				}
+				return literals(fset, lit)
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s: no variable %s", path, name)
+}
+
+// literals returns the values of the elements of a composite literal, which
+// must be string literals.
+func literals(fset *token.FileSet, lit *ast.CompositeLit) ([]string, error) {
+	var keys []string
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = kv.Value
+		}
+		basic, ok := elt.(*ast.BasicLit)
+		if !ok || basic.Kind != token.STRING {
+			return nil, fmt.Errorf("%s: element is not a string literal", fset.Position(elt.Pos()))
+		}
+		key, err := strconv.Unquote(basic.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fset.Position(elt.Pos()), err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// generate returns the formatted source of the matcher for the keys. The
+// arguments are recorded in the header of the file.
+func generate(pkg, name string, keys, args []string) ([]byte, error) {
+	sort.Strings(keys)
+	t := radixtree.New[int]()
+	var sorted []string
+	for _, key := range keys {
+		if len(sorted) > 0 && sorted[len(sorted)-1] == key {
+			continue
+		}
+		t.Insert([]byte(key), len(sorted))
+		sorted = append(sorted, key)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by radixtree-gen %s; DO NOT EDIT.\n\n", strings.Join(args, " "))
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "// %sKeys holds the keys in ascending order. The ordinal of a key is its index.\n", name)
+	fmt.Fprintf(&b, "var %sKeys = [...]string{\n", name)
+	for _, key := range sorted {
+		fmt.Fprintf(&b, "%q,\n", key)
+	}
+	fmt.Fprintf(&b, "}\n\n")
+
+	fmt.Fprintf(&b, "// %sGet returns the ordinal of s and true, or false if s is not a key.\n", name)
+	fmt.Fprintf(&b, "func %sGet(s string) (int, bool) {\n", name)
+	fmt.Fprintf(&b, "ordinal, n, ok := %sLongestPrefix(s)\nreturn ordinal, ok && n == len(s)\n}\n\n", name)
+	fmt.Fprintf(&b, "// %sContains reports whether s is a key.\n", name)
+	fmt.Fprintf(&b, "func %sContains(s string) bool {\n_, ok := %sGet(s)\nreturn ok\n}\n\n", name, name)
+	fmt.Fprintf(&b, "// %sLongestPrefix returns the ordinal and length of the longest key that is\n", name)
+	fmt.Fprintf(&b, "// a prefix of s and true, or false if no key is a prefix of s.\n")
+	fmt.Fprintf(&b, "func %sLongestPrefix(s string) (ordinal, length int, ok bool) {\n", name)
+	writeNode(&b, t.Root(), 0)
+	fmt.Fprintf(&b, "return ordinal, length, ok\n}\n")
+	return format.Source(b.Bytes())
+}
+
+// writeNode writes the comparisons for the node, whose key is depth bytes
+// long and has already been matched, and the nodes below it.
+func writeNode(b *bytes.Buffer, n radixtree.NodeView[int], depth int) {
+	if ordinal, ok := n.Value(); ok {
+		fmt.Fprintf(b, "ordinal, length, ok = %d, %d, true\n", ordinal, depth)
+	}
+	children := n.Children()
+	if len(children) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "if len(s) > %d {\nswitch s[%d] {\n", depth, depth)
+	for _, child := range children {
+		prefix := child.Prefix()
+		fmt.Fprintf(b, "case %s:\n", byteLit(prefix[0]))
+		end := depth + len(prefix)
+		if len(prefix) > 1 {
+			fmt.Fprintf(b, "if len(s) >= %d && s[%d:%d] == %q {\n", end, depth+1, end, prefix[1:])
+		}
+		writeNode(b, child, end)
+		if len(prefix) > 1 {
+			fmt.Fprintf(b, "}\n")
+		}
+	}
+	fmt.Fprintf(b, "}\n}\n")
+}
+
+// byteLit returns a Go literal for the byte: a character literal for printable
+// ASCII and a number otherwise, so that the byte of a UTF-8 encoding is not
+// written as a character it does not encode.
+func byteLit(c byte) string {
+	if c >= 0x20 && c < 0x7f {
+		return strconv.QuoteRune(rune(c))
+	}
+	return fmt.Sprintf("0x%02x", c)
+}
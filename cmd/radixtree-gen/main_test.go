@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runOutput(t *testing.T, args ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := run(args, &out); err != nil {
+		t.Fatalf("run(%q) = %v", args, err)
+	}
+	return out.String()
+}
+
+// check is compiled with the generated code to test its behaviour.
+const check = `package main
+
+import "fmt"
+
+func main() {
+	for _, s := range []string{"", "a", "to", "toa", "toad", "toadstool", "tx", "wink", "winkle", "will", "wi", "zoo", "café", "caf"} {
+		ordinal, n, ok := WordLongestPrefix(s)
+		get, found := WordGet(s)
+		fmt.Println(s, ordinal, n, ok, get, found, WordContains(s))
+	}
+	fmt.Println(len(WordKeys), WordKeys[0])
+}
+`
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated code")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir := t.TempDir()
+	input := filepath.Join(dir, "words.txt")
+	keys := "to\ntoad\nwink\nwinkle\nwill\ntoa\nto\ncafé\n"
+	if err := os.WriteFile(input, []byte(keys), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := runOutput(t, "-pkg", "main", "-name", "Word", input)
+	if !strings.HasPrefix(src, "// Code generated by radixtree-gen") {
+		t.Errorf("generated code starts with %q, want the generated code header", strings.SplitN(src, "\n", 2)[0])
+	}
+
+	files := map[string]string{
+		"go.mod":      "module gen\n\ngo 1.23\n",
+		"main.go":     check,
+		"word_gen.go": src,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(gobin, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run: %v\n%s", err, out)
+	}
+	want := strings.Join([]string{
+		" 0 0 false 0 false false",
+		"a 0 0 false 0 false false",
+		"to 1 2 true 1 true true",
+		"toa 2 3 true 2 true true",
+		"toad 3 4 true 3 true true",
+		"toadstool 3 4 true 3 false false",
+		"tx 0 0 false 0 false false",
+		"wink 5 4 true 5 true true",
+		"winkle 6 6 true 6 true true",
+		"will 4 4 true 4 true true",
+		"wi 0 0 false 0 false false",
+		"zoo 0 0 false 0 false false",
+		"café 0 5 true 0 true true",
+		"caf 0 0 false 0 false false",
+		"7 café",
+		"",
+	}, "\n")
+	if string(out) != want {
+		t.Errorf("generated matcher printed\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestRunSource(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "keywords.go")
+	code := "package lexer\n\nvar keywords = []string{\"if\", \"else\", `for`}\n\nvar other = 1\n"
+	if err := os.WriteFile(input, []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := runOutput(t, "-pkg", "lexer", "-var", "keywords", input)
+	if !strings.Contains(src, "var KeysKeys = [...]string{\n\t\"else\",\n\t\"for\",\n\t\"if\",\n}") {
+		t.Errorf("generated code does not list the sorted keywords:\n%s", src)
+	}
+	for _, args := range [][]string{
+		{"-pkg", "lexer", "-var", "other", input},
+		{"-pkg", "lexer", "-var", "missing", input},
+		{"-pkg", "lexer", "-name", "not valid", input},
+	} {
+		if err := run(args, &bytes.Buffer{}); err == nil {
+			t.Errorf("run(%q) succeeded, want an error", args)
+		}
+	}
+	if err := run(nil, &bytes.Buffer{}); !errors.Is(err, errUsage) {
+		t.Errorf("run(nil) = %v, want a usage error", err)
+	}
+}
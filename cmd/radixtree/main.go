@@ -0,0 +1,273 @@
+// Command radixtree builds, queries and converts serialized radix trees with
+// string values so that snapshot files can be inspected without writing a Go
+// program.
+//
+// Usage:
+//
+//	radixtree build [-from text|csv] [-to compact|text|csv] [-o output] [input]
+//	radixtree convert -from format -to format [-o output] [input]
+//	radixtree get [-format format] file key
+//	radixtree find [-format format] [-limit n] file prefix
+//	radixtree longest-prefix [-format format] file key
+//	radixtree stats [-format format] file
+//
+// The formats are compact, the front-coded binary format written by
+// ExportCompact, text, with a key and value separated by a tab on each line,
+// and csv, with a key and value in each record. In the text format keys cannot
+// contain tabs or newlines and values cannot contain newlines. The input of
+// build and convert is read from standard input and their output is written to
+// standard output unless a file is named.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+type tree = radixtree.RadixTree[string]
+
+type command struct {
+	usage string
+	run   func(args []string, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"build": {
+		"build [-from text|csv] [-to compact|text|csv] [-o output] [input]",
+		func(args []string, stdout io.Writer) error {
+			return convert("build", "text", "compact", args, stdout)
+		},
+	},
+	"convert": {
+		"convert -from format -to format [-o output] [input]",
+		func(args []string, stdout io.Writer) error {
+			return convert("convert", "", "", args, stdout)
+		},
+	},
+	"get":            {"get [-format format] file key", get},
+	"find":           {"find [-format format] [-limit n] file prefix", find},
+	"longest-prefix": {"longest-prefix [-format format] file key", longestPrefix},
+	"stats":          {"stats [-format format] file", stats},
+}
+
+var errUsage = errors.New("usage")
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if errors.Is(err, errUsage) {
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "radixtree:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	for _, name := range []string{"build", "convert", "get", "find", "longest-prefix", "stats"} {
+		fmt.Fprintln(os.Stderr, "  radixtree", commands[name].usage)
+	}
+}
+
+// run executes the command named by the first argument.
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+	return cmd.run(args[1:], stdout)
+}
+
+func newFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parse parses the flags of a command that takes a file followed by want
+// further arguments and returns the tree read from the file and the arguments.
+func parse(fs *flag.FlagSet, args []string, want int) (*tree, []string, error) {
+	format := fs.String("format", "compact", "format of the file")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() != want+1 {
+		return nil, nil, errUsage
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	t, err := read(f, *format)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+	return t, fs.Args()[1:], nil
+}
+
+func convert(name, from, to string, args []string, stdout io.Writer) error {
+	fs := newFlags(name)
+	fs.StringVar(&from, "from", from, "format of the input")
+	fs.StringVar(&to, "to", to, "format of the output")
+	output := fs.String("o", "", "output file")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if from == "" || to == "" || fs.NArg() > 1 {
+		return errUsage
+	}
+
+	var r io.Reader = os.Stdin
+	if fs.NArg() == 1 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	t, err := read(r, from)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return write(stdout, t, to)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := write(f, t, to); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func get(args []string, stdout io.Writer) error {
+	t, args, err := parse(newFlags("get"), args, 1)
+	if err != nil {
+		return err
+	}
+	v, err := t.GetErr([]byte(args[0]))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, v)
+	return err
+}
+
+func find(args []string, stdout io.Writer) error {
+	fs := newFlags("find")
+	limit := fs.Int("limit", 0, "maximum number of entries, or 0 for no limit")
+	t, args, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(stdout)
+	n := 0
+	for it := t.FindIter([]byte(args[0])); it.Next() && (*limit <= 0 || n < *limit); n++ {
+		fmt.Fprintf(w, "%s\t%s\n", it.Key(), it.Value())
+	}
+	return w.Flush()
+}
+
+func longestPrefix(args []string, stdout io.Writer) error {
+	t, args, err := parse(newFlags("longest-prefix"), args, 1)
+	if err != nil {
+		return err
+	}
+	v, err := t.LongestPrefixErr([]byte(args[0]))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, v)
+	return err
+}
+
+func stats(args []string, stdout io.Writer) error {
+	t, _, err := parse(newFlags("stats"), args, 0)
+	if err != nil {
+		return err
+	}
+	s := t.StatsDetail()
+	m := t.MemUsage()
+	w := bufio.NewWriter(stdout)
+	fmt.Fprintf(w, "keys\t%d\n", t.Len())
+	fmt.Fprintf(w, "nodes\t%d\n", s.Nodes)
+	fmt.Fprintf(w, "height\t%d\n", s.Height)
+	fmt.Fprintf(w, "fan-out\t%v\n", s.FanOut)
+	fmt.Fprintf(w, "prefix lengths\t%v\n", s.PrefixLen)
+	fmt.Fprintf(w, "memory\t%d\n", m.Total())
+	return w.Flush()
+}
+
+// read returns a tree holding the entries read from r in the given format.
+func read(r io.Reader, format string) (*tree, error) {
+	t := radixtree.New[string]()
+	switch format {
+	case "compact":
+		err := t.ImportCompact(bufio.NewReader(r), func(b []byte) (string, error) {
+			return string(b), nil
+		})
+		return t, err
+	case "text":
+		s := bufio.NewScanner(r)
+		s.Buffer(nil, 1<<30)
+		for s.Scan() {
+			key, value, _ := bytes.Cut(s.Bytes(), []byte("\t"))
+			t.Insert(append([]byte(nil), key...), string(value))
+		}
+		return t, s.Err()
+	case "csv":
+		err := t.ImportCSV(r, func(s string) (string, error) {
+			return s, nil
+		})
+		return t, err
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// write writes the entries of t to w in the given format.
+func write(w io.Writer, t *tree, format string) error {
+	switch format {
+	case "compact":
+		bw := bufio.NewWriter(w)
+		err := t.ExportCompact(bw, func(v string) ([]byte, error) {
+			return []byte(v), nil
+		})
+		if err != nil {
+			return err
+		}
+		return bw.Flush()
+	case "text":
+		bw := bufio.NewWriter(w)
+		for it := t.FindIter(nil); it.Next(); {
+			if strings.ContainsAny(string(it.Key()), "\t\n") || strings.ContainsRune(it.Value(), '\n') {
+				return fmt.Errorf("key %q cannot be written as text", it.Key())
+			}
+			fmt.Fprintf(bw, "%s\t%s\n", it.Key(), it.Value())
+		}
+		return bw.Flush()
+	case "csv":
+		return t.ExportCSV(w, func(v string) (string, error) {
+			return v, nil
+		})
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+func runOutput(t *testing.T, args ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := run(args, &out); err != nil {
+		t.Fatalf("run(%q) = %v", args, err)
+	}
+	return out.String()
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	csv := "banana,yellow\napple,red\n\"apple pie\",\"warm, sweet\"\napricot,orange\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := filepath.Join(dir, "snapshot")
+	runOutput(t, "build", "-from", "csv", "-o", snapshot, input)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"get", snapshot, "apple"}, "red\n"},
+		{[]string{"find", snapshot, "ap"}, "apple\tred\napple pie\twarm, sweet\napricot\torange\n"},
+		{[]string{"find", "-limit", "1", snapshot, "ap"}, "apple\tred\n"},
+		{[]string{"longest-prefix", snapshot, "apple tart"}, "red\n"},
+		{[]string{"convert", "-from", "compact", "-to", "csv", snapshot}, "apple,red\napple pie,\"warm, sweet\"\napricot,orange\nbanana,yellow\n"},
+	}
+	for _, tt := range tests {
+		if got := runOutput(t, tt.args...); got != tt.want {
+			t.Errorf("run(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	if got := runOutput(t, "stats", snapshot); !strings.HasPrefix(got, "keys\t4\n") {
+		t.Errorf("stats = %q, want it to start with the number of keys", got)
+	}
+	if err := run([]string{"get", snapshot, "cherry"}, &bytes.Buffer{}); !errors.Is(err, radixtree.ErrNotFound) {
+		t.Errorf("get of a missing key = %v, want ErrNotFound", err)
+	}
+	for _, args := range [][]string{nil, {"dump"}, {"get", snapshot}, {"convert", input}} {
+		if err := run(args, &bytes.Buffer{}); !errors.Is(err, errUsage) {
+			t.Errorf("run(%q) = %v, want a usage error", args, err)
+		}
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	text := "b\t2\na\t1\nc\n"
+	if err := os.WriteFile(input, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := runOutput(t, "convert", "-from", "text", "-to", "text", input), "a\t1\nb\t2\nc\t\n"; got != want {
+		t.Errorf("convert = %q, want %q", got, want)
+	}
+}
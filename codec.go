@@ -0,0 +1,146 @@
+package radixtree
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+)
+
+// The first byte of every value stored by a CodecTree says how the rest of it
+// is stored.
+const (
+	codecRaw   = 0
+	codecFlate = 1
+)
+
+// CodecTree is a radix tree that stores its values encoded as bytes, for trees
+// whose values are large blobs that take less memory encoded and compressed
+// than decoded. Values are encoded when they are inserted and decoded each
+// time they are read, and encoded values of at least a threshold length are
+// compressed with DEFLATE, so the tree trades the time to encode and decode
+// for its memory.
+type CodecTree[T any] struct {
+	tree      *RadixTree[[]byte]
+	encode    func(value T) ([]byte, error)
+	decode    func(b []byte) (T, error)
+	threshold int
+}
+
+// NewCodec creates and returns an empty CodecTree that encodes values with
+// encode and decodes them with decode, compressing the encoded values whose
+// length is at least threshold bytes. A negative threshold disables
+// compression. A compressed value is only kept if it is shorter than the
+// encoded one. The underlying tree is configured with the given options;
+// options that depend on the value type, such as WithAggregate, cannot be
+// used.
+func NewCodec[T any](encode func(value T) ([]byte, error), decode func(b []byte) (T, error), threshold int, opts ...Option) *CodecTree[T] {
+	return &CodecTree[T]{tree: New[[]byte](opts...), encode: encode, decode: decode, threshold: threshold}
+}
+
+// Contains returns true if the key is in the tree.
+func (c *CodecTree[T]) Contains(key []byte) bool {
+	return c.tree.Contains(key)
+}
+
+// Get returns the decoded value of key and true, or the zero value for type T
+// and false if the key is not in the tree. The error is that of decode or
+// wraps ErrCorruptValue if the stored value cannot be decompressed.
+func (c *CodecTree[T]) Get(key []byte) (T, bool, error) {
+	b, ok := c.tree.Get(key)
+	if !ok {
+		var zero T
+		return zero, false, nil
+	}
+	v, err := c.value(key, b)
+	return v, true, err
+}
+
+// Insert encodes the value and stores it for key, replacing any value it
+// already has. It returns the error of encode, or of InsertErr if the key
+// exceeds a limit of the tree, in which case the tree is not changed. Unlike
+// RadixTree.Insert the previous value is not returned since that would require
+// decoding it.
+func (c *CodecTree[T]) Insert(key []byte, value T) error {
+	b, err := c.encode(value)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.tree.InsertErr(key, c.pack(b))
+	return err
+}
+
+// Len returns the number of keys in the tree.
+func (c *CodecTree[T]) Len() int {
+	return c.tree.Len()
+}
+
+// Remove removes the key from the tree and returns true, or false if it was not
+// in the tree.
+func (c *CodecTree[T]) Remove(key []byte) bool {
+	_, ok := c.tree.Remove(key)
+	return ok
+}
+
+// Size returns the total number of bytes of the values as stored in the tree.
+func (c *CodecTree[T]) Size() int {
+	n := 0
+	c.tree.Walk(nil, func(b []byte) bool {
+		n += len(b)
+		return true
+	})
+	return n
+}
+
+// Walk executes f for each key that starts with the given prefix and its
+// decoded value, in ascending key order. If f returns false Walk stops. If a
+// value cannot be decoded Walk stops and returns the error.
+func (c *CodecTree[T]) Walk(prefix []byte, f func(key []byte, value T) bool) error {
+	for key, b := range c.tree.AllPrefix(prefix) {
+		v, err := c.value(key, b)
+		if err != nil {
+			return err
+		}
+		if !f(key, v) {
+			break
+		}
+	}
+	return nil
+}
+
+// pack returns the encoded value b as it is stored, compressed if that makes
+// it shorter.
+func (c *CodecTree[T]) pack(b []byte) []byte {
+	if c.threshold >= 0 && len(b) >= c.threshold {
+		var buf bytes.Buffer
+		buf.WriteByte(codecFlate)
+		w, _ := flate.NewWriter(&buf, flate.BestSpeed)
+		w.Write(b)
+		w.Close()
+		if buf.Len() < len(b)+1 {
+			return buf.Bytes()
+		}
+	}
+	return append([]byte{codecRaw}, b...)
+}
+
+// value decodes the stored value b of key.
+func (c *CodecTree[T]) value(key, b []byte) (T, error) {
+	var zero T
+	if len(b) == 0 {
+		return zero, fmt.Errorf("%w: %q is empty", ErrCorruptValue, key)
+	}
+	switch b[0] {
+	case codecRaw:
+		b = b[1:]
+	case codecFlate:
+		r := flate.NewReader(bytes.NewReader(b[1:]))
+		var err error
+		if b, err = io.ReadAll(r); err != nil {
+			return zero, fmt.Errorf("%w: %q: %v", ErrCorruptValue, key, err)
+		}
+	default:
+		return zero, fmt.Errorf("%w: %q has unknown encoding %d", ErrCorruptValue, key, b[0])
+	}
+	return c.decode(b)
+}
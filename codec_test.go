@@ -0,0 +1,68 @@
+package radixtree
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCodecTree(t *testing.T) {
+	encode := func(s string) ([]byte, error) {
+		if s == "" {
+			return nil, errors.New("empty value")
+		}
+		return []byte(s), nil
+	}
+	decode := func(b []byte) (string, error) { return string(b), nil }
+	c := NewCodec(encode, decode, 64)
+
+	blob := strings.Repeat("radix ", 100)
+	if err := c.Insert([]byte("big"), blob); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if err := c.Insert([]byte("small"), "tiny"); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if err := c.Insert([]byte("bad"), ""); err == nil || c.Contains([]byte("bad")) {
+		t.Errorf("Insert of a value that fails to encode = %v", err)
+	}
+	limited := NewCodec(encode, decode, 64, WithMaxKeyLen(4))
+	if err := limited.Insert([]byte("toolong"), "v"); !errors.Is(err, ErrKeyTooLong) || limited.Len() != 0 {
+		t.Errorf("Insert of a key over the limit = %v, want %v", err, ErrKeyTooLong)
+	}
+	if n := c.Size(); n >= len(blob) {
+		t.Errorf("Size = %d, want less than %d", n, len(blob))
+	}
+	for key, want := range map[string]string{"big": blob, "small": "tiny"} {
+		if v, ok, err := c.Get([]byte(key)); !ok || err != nil || v != want {
+			t.Errorf("Get(%q) = (%.10q, %t, %v), want (%.10q, true, nil)", key, v, ok, err, want)
+		}
+	}
+	if _, ok, err := c.Get([]byte("none")); ok || err != nil {
+		t.Errorf("Get of a missing key = (%t, %v)", ok, err)
+	}
+
+	var keys []string
+	if err := c.Walk([]byte("b"), func(key []byte, v string) bool {
+		keys = append(keys, string(key))
+		return true
+	}); err != nil || !reflect.DeepEqual(keys, []string{"big"}) {
+		t.Errorf("Walk visited %q with error %v", keys, err)
+	}
+
+	// A damaged value is reported rather than decoded.
+	p, _ := c.tree.GetRef([]byte("big"))
+	*p = bytes.Clone(*p)[:len(*p)/2]
+	if _, _, err := c.Get([]byte("big")); !errors.Is(err, ErrCorruptValue) {
+		t.Errorf("Get of a truncated value returned %v, want %v", err, ErrCorruptValue)
+	}
+	if err := c.Walk(nil, func([]byte, string) bool { return true }); !errors.Is(err, ErrCorruptValue) {
+		t.Errorf("Walk over a truncated value returned %v, want %v", err, ErrCorruptValue)
+	}
+
+	if !c.Remove([]byte("small")) || c.Remove([]byte("small")) || c.Len() != 1 {
+		t.Errorf("Remove left Len = %d", c.Len())
+	}
+}
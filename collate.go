@@ -0,0 +1,71 @@
+package radixtree
+
+import (
+	"bytes"
+	"sort"
+)
+
+// byteOrder gives the rank of every byte in a custom collation. The ranks are
+// distinct so that bytes that collate equally are ordered by their value. A nil
+// byteOrder is the natural order of bytes.
+type byteOrder [256]uint16
+
+func newByteOrder(table [256]byte) *byteOrder {
+	var o byteOrder
+	for b := range o {
+		o[b] = uint16(table[b])<<8 | uint16(b)
+	}
+	return &o
+}
+
+// less reports whether byte a is ordered before byte b.
+func (o *byteOrder) less(a, b byte) bool {
+	if o == nil {
+		return a < b
+	}
+	return o[a] < o[b]
+}
+
+// compare compares two keys lexicographically in the order and returns -1, 0
+// or 1 like bytes.Compare.
+func (o *byteOrder) compare(a, b []byte) int {
+	if o == nil {
+		return bytes.Compare(a, b)
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if o[a[i]] < o[b[i]] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// sortChildren sorts the children of n into the byte order of the tree.
+func (t *RadixTree[T]) sortChildren(n *node[T]) {
+	o := t.opts.order
+	c := &n.children
+	sort.Slice(c.nodes, func(i, j int) bool {
+		return o.less(c.nodes[i].prefix[0], c.nodes[j].prefix[0])
+	})
+	for i, child := range c.nodes {
+		c.keys[i] = child.prefix[0]
+	}
+}
+
+// reorder sorts the children of every node in the subtree rooted at n into the
+// byte order of the tree.
+func (t *RadixTree[T]) reorder(n *node[T]) {
+	t.sortChildren(n)
+	for _, child := range n.children.nodes {
+		t.reorder(child)
+	}
+}
@@ -0,0 +1,153 @@
+package radixtree
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// caseless is a byte order that ignores the case of ASCII letters.
+func caseless() [256]byte {
+	var table [256]byte
+	for b := range table {
+		table[b] = byte(b)
+		if 'A' <= b && b <= 'Z' {
+			table[b] = byte(b) + 'a' - 'A'
+		}
+	}
+	return table
+}
+
+// caselessKeys are in the order given by caseless. Bytes are compared one at a
+// time so "bAnd" is less than "banana" because "A" is less than "a".
+var caselessKeys = []string{"Apple", "apple", "applesauce", "Banana", "bAnd", "banana", "Cherry pie", "cherry"}
+
+func buildCaseless(keys []string) *RadixTree[string] {
+	tree := New[string](WithByteOrder(caseless()), WithAggregate("", concat, identity))
+	for _, key := range keys {
+		tree.Insert([]byte(key), key)
+	}
+	return tree
+}
+
+func TestWithByteOrder(t *testing.T) {
+	// Insert the keys in reverse to show the order comes from the table.
+	var reversed []string
+	for i := len(caselessKeys) - 1; i >= 0; i-- {
+		reversed = append(reversed, caselessKeys[i])
+	}
+	tree := buildCaseless(reversed)
+	verify(t, tree)
+	checkAggregates(t, "WithByteOrder", tree, caselessKeys)
+
+	if got := tree.Values(); !reflect.DeepEqual(got, caselessKeys) {
+		t.Errorf("Values\n got: %v\nwant: %v", got, caselessKeys)
+	}
+	if got, _ := tree.Min(); got != "Apple" {
+		t.Errorf("Min = %q, want \"Apple\"", got)
+	}
+	if got, _ := tree.Max(); got != "cherry" {
+		t.Errorf("Max = %q, want \"cherry\"", got)
+	}
+	if got, _ := tree.Successor([]byte("applesauce")); got != "Banana" {
+		t.Errorf("Successor(applesauce) = %q, want \"Banana\"", got)
+	}
+	if got, _ := tree.Predecessor([]byte("bAnd")); got != "Banana" {
+		t.Errorf("Predecessor(bAnd) = %q, want \"Banana\"", got)
+	}
+	if got := tree.Rank([]byte("b")); got != 4 {
+		t.Errorf("Rank(b) = %d, want 4", got)
+	}
+	if got := tree.Rank([]byte("BANANA")); got != 3 {
+		t.Errorf("Rank(BANANA) = %d, want 3", got)
+	}
+	if v, ok := tree.Get([]byte("APPLE")); ok {
+		t.Errorf("Get(APPLE) = %q, keys should be matched unchanged", v)
+	}
+
+	left, right := tree.Split([]byte("b"))
+	verify(t, left)
+	verify(t, right)
+	if got, want := left.Values(), caselessKeys[:4]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Split left\n got: %v\nwant: %v", got, want)
+	}
+	if _, err := Join(right, left); err != ErrOverlap {
+		t.Errorf("Join in the wrong order returned %v, want %v", err, ErrOverlap)
+	}
+	tree, _ = Join(left, right)
+	if got := tree.Values(); !reflect.DeepEqual(got, caselessKeys) {
+		t.Errorf("Values after Join\n got: %v\nwant: %v", got, caselessKeys)
+	}
+
+	if got := tree.DeleteRange([]byte("APPLES"), []byte("BAND")); got != 3 {
+		t.Errorf("DeleteRange = %d, want 3", got)
+	}
+	want := []string{"Banana", "bAnd", "banana", "Cherry pie", "cherry"}
+	if got := tree.Values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Values after DeleteRange\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestWithByteOrderMixed(t *testing.T) {
+	// Trees with the natural order are sorted into the order of the tree
+	// they are merged into.
+	natural := build([]string{"Banana", "apple", "cherry"})
+	tree := buildCaseless([]string{"Zebra"})
+	tree.Graft(nil, natural)
+	verify(t, tree)
+	checkAggregates(t, "Graft", tree, []string{"Banana", "apple", "cherry", "Zebra"})
+	want := []string{"apple", "Banana", "cherry", "Zebra"}
+	if got := tree.Values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Values after Graft\n got: %v\nwant: %v", got, want)
+	}
+
+	// "apple" is less than "Zebra" in the natural order but not in the
+	// order of the left tree.
+	left := buildCaseless([]string{"Zebra"})
+	if _, err := Join(left, build([]string{"apple"})); err != ErrOverlap {
+		t.Errorf("Join returned %v, want %v", err, ErrOverlap)
+	}
+	joined, err := Join(buildCaseless([]string{"Apple"}), build([]string{"Zebra", "banana"}))
+	if err != nil {
+		t.Fatalf("Join returned %v", err)
+	}
+	verify(t, joined)
+	if got, want := joined.Values(), []string{"Apple", "banana", "Zebra"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values after Join\n got: %v\nwant: %v", got, want)
+	}
+
+	dst := New[string]()
+	buildCaseless(caselessKeys).CopyRange(dst, nil, nil, nil)
+	verify(t, dst)
+	want = append([]string{}, caselessKeys...)
+	sort.Strings(want)
+	if got := dst.Values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Values after CopyRange\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestWithByteOrderLoad(t *testing.T) {
+	tree := New[int](WithByteOrder(caseless()))
+	input := strings.Join(caselessKeys, "=1\n") + "=1\n"
+	if err := tree.LoadSorted(strings.NewReader(input), parseEntry); err != nil {
+		t.Fatalf("LoadSorted returned error %v", err)
+	}
+	verify(t, tree)
+
+	var entries []Entry[int]
+	for _, key := range []string{"b", "B", "a"} {
+		entries = append(entries, Entry[int]{[]byte(key), 1})
+	}
+	tree.InsertMany(entries)
+	verify(t, tree)
+	var keys []string
+	tree.WalkKeys(nil, func(key []byte) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	want := []string{"Apple", "a", "apple", "applesauce", "B", "Banana", "b", "bAnd", "banana", "Cherry pie", "cherry"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys after InsertMany\n got: %v\nwant: %v", keys, want)
+	}
+}
@@ -0,0 +1,87 @@
+package radixtree
+
+import (
+	"sync"
+	"time"
+)
+
+// removeEmpty removes the nodes at the end of a path from the root that have
+// neither a value nor children.
+func (t *RadixTree[T]) removeEmpty(path []*node[T]) {
+	for i := len(path) - 1; i > 0; i-- {
+		n := path[i]
+		if n.hasValue() || n.children.len() > 0 {
+			return
+		}
+		parent := path[i-1]
+		parent.children.remove(parent.children.index(n.prefix[0]))
+		t.free(n.prefix)
+	}
+}
+
+// Compact removes the subtrees without values and merges every node other than
+// the root that has no value and a single child with its child, undoing the
+// nodes left in place by Remove in a tree created with WithDeferredMerge or
+// WithLazyDelete. Compact does nothing to other trees.
+func (t *RadixTree[T]) Compact() {
+	if t.opts.deferMerge || t.opts.lazyDelete {
+		t.unshareAll()
+		t.gen++
+		t.compactAll(t.root)
+		t.removed = 0
+	}
+}
+
+// compactAll compacts the subtree rooted at n.
+func (t *RadixTree[T]) compactAll(n *node[T]) {
+	c := &n.children
+	for i := 0; i < c.len(); {
+		child := c.nodes[i]
+		if child.count == 0 {
+			c.remove(i)
+			t.freeAll(child)
+			continue
+		}
+		t.compactAll(child)
+		i++
+	}
+	if n != t.root && !n.hasValue() && c.len() == 1 {
+		t.merge(n)
+	}
+}
+
+// CompactEvery starts a goroutine that compacts the tree every interval if any
+// keys have been removed since it was last compacted, and returns a function
+// that stops the goroutine. Since the tree is not safe for concurrent use the
+// goroutine holds mu while it looks at the tree, so mu must guard every other
+// use of the tree. It suits trees created with WithLazyDelete or
+// WithDeferredMerge, moving the cost of tidying the nodes off the paths that
+// remove keys.
+func (t *RadixTree[T]) CompactEvery(mu sync.Locker, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				mu.Lock()
+				if t.removed > 0 {
+					t.Compact()
+				}
+				mu.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
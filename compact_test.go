@@ -0,0 +1,190 @@
+package radixtree
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCompact(t *testing.T) {
+	tree := New[string](WithDeferredMerge(), WithAggregate("", concat, identity))
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	nodes := tree.StatsDetail().Nodes
+
+	var kept, removed []string
+	for i, key := range words {
+		if i%3 == 0 {
+			kept = append(kept, key)
+		} else {
+			removed = append(removed, key)
+		}
+	}
+	for _, key := range removed[:len(removed)/2] {
+		tree.Remove([]byte(key))
+	}
+	var keys [][]byte
+	for _, key := range removed[len(removed)/2:] {
+		keys = append(keys, []byte(key))
+	}
+	tree.RemoveMany(keys)
+	verify(t, tree)
+	checkAggregates(t, "Remove", tree, kept)
+
+	want := build(kept)
+	if got := tree.Values(); !reflect.DeepEqual(got, want.Values()) {
+		t.Errorf("Values after deferred removals\n got: %v\nwant: %v", got, want.Values())
+	}
+	for _, key := range kept {
+		got, ok := tree.Successor([]byte(key))
+		w, wok := want.Successor([]byte(key))
+		if got != w || ok != wok {
+			t.Errorf("Successor(%s) after deferred removals\n got: (%s, %t)\nwant: (%s, %t)", key, got, ok, w, wok)
+		}
+	}
+
+	for _, key := range removed {
+		tree.Insert([]byte(key), key)
+	}
+	if got := tree.StatsDetail().Nodes; got != nodes {
+		t.Errorf("reinserting the removed keys gave %d nodes, want the original %d", got, nodes)
+	}
+
+	for _, key := range removed {
+		tree.Remove([]byte(key))
+	}
+	tree.Compact()
+	verify(t, tree)
+	checkAggregates(t, "Compact", tree, kept)
+	opts := tree.opts
+	opts.deferMerge = false
+	strict := &RadixTree[string]{root: tree.root, size: tree.size, opts: opts}
+	verify(t, strict)
+	if got, wantNodes := tree.StatsDetail().Nodes, want.StatsDetail().Nodes; got != wantNodes {
+		t.Errorf("Compact left %d nodes, want %d", got, wantNodes)
+	}
+}
+
+func TestLazyDelete(t *testing.T) {
+	tree := New[string](WithLazyDelete(), WithAggregate("", concat, identity))
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	nodes := tree.StatsDetail().Nodes
+
+	var kept []string
+	for i, key := range words {
+		if i%4 == 0 {
+			kept = append(kept, key)
+		} else if i%2 == 0 {
+			tree.Remove([]byte(key))
+		} else {
+			tree.RemoveMany([][]byte{[]byte(key)})
+		}
+	}
+	verify(t, tree)
+	checkAggregates(t, "Remove", tree, kept)
+	if got := tree.StatsDetail().Nodes; got != nodes {
+		t.Errorf("lazy removals changed the number of nodes from %d to %d", nodes, got)
+	}
+
+	want := build(kept)
+	if got := tree.Values(); !reflect.DeepEqual(got, want.Values()) {
+		t.Errorf("Values after lazy removals\n got: %v\nwant: %v", got, want.Values())
+	}
+	for _, key := range kept {
+		got, ok := tree.Successor([]byte(key))
+		w, wok := want.Successor([]byte(key))
+		if got != w || ok != wok {
+			t.Errorf("Successor(%s)\n got: (%s, %t)\nwant: (%s, %t)", key, got, ok, w, wok)
+		}
+		got, ok = tree.Predecessor([]byte(key))
+		w, wok = want.Predecessor([]byte(key))
+		if got != w || ok != wok {
+			t.Errorf("Predecessor(%s)\n got: (%s, %t)\nwant: (%s, %t)", key, got, ok, w, wok)
+		}
+	}
+	for _, key := range words {
+		for i := 1; i <= len(key); i++ {
+			prefix := []byte(key[:i])
+			if got, w := tree.HasPrefix(prefix), want.HasPrefix(prefix); got != w {
+				t.Errorf("HasPrefix(%s) = %t, want %t", prefix, got, w)
+			}
+			got, ok := tree.MinPrefix(prefix)
+			w, wok := want.MinPrefix(prefix)
+			if got != w || ok != wok {
+				t.Errorf("MinPrefix(%s)\n got: (%s, %t)\nwant: (%s, %t)", prefix, got, ok, w, wok)
+			}
+			got, ok = tree.MaxPrefix(prefix)
+			w, wok = want.MaxPrefix(prefix)
+			if got != w || ok != wok {
+				t.Errorf("MaxPrefix(%s)\n got: (%s, %t)\nwant: (%s, %t)", prefix, got, ok, w, wok)
+			}
+			if f := tree.Freeze(); f.HasPrefix(prefix) != want.HasPrefix(prefix) {
+				t.Errorf("Frozen.HasPrefix(%s) = %t", prefix, f.HasPrefix(prefix))
+			}
+		}
+	}
+
+	tree.Compact()
+	verify(t, tree)
+	checkAggregates(t, "Compact", tree, kept)
+	opts := tree.opts
+	opts.lazyDelete = false
+	verify(t, &RadixTree[string]{root: tree.root, size: tree.size, opts: opts})
+	if got, w := tree.StatsDetail().Nodes, want.StatsDetail().Nodes; got != w {
+		t.Errorf("Compact left %d nodes, want %d", got, w)
+	}
+}
+
+func TestLazyDeleteAllocator(t *testing.T) {
+	a := newTrackingAllocator(t)
+	tree := New[int](WithLazyDelete(), WithAllocator(a))
+	for i, key := range words {
+		tree.Insert([]byte(key), i)
+	}
+	for i, key := range words {
+		if i%3 != 0 {
+			tree.Remove([]byte(key))
+		}
+	}
+	a.check("Remove", tree)
+	tree.Compact()
+	verify(t, tree)
+	a.check("Compact", tree)
+}
+
+func TestCompactEvery(t *testing.T) {
+	tree := New[string](WithLazyDelete())
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	var mu sync.Mutex
+	stop := tree.CompactEvery(&mu, time.Millisecond)
+	defer stop()
+
+	mu.Lock()
+	for _, key := range words[1:] {
+		tree.Remove([]byte(key))
+	}
+	mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		nodes := tree.StatsDetail().Nodes
+		mu.Unlock()
+		if nodes == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("tree still has %d nodes, want 2 after compaction", nodes)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	stop()
+	verify(t, tree)
+}
@@ -0,0 +1,117 @@
+// Package complete builds autocompletion indexes from text. A Builder reads
+// text, splits it into tokens and counts how often each occurs, and Build turns
+// the counts into a radix tree whose values are the tokens with their counts,
+// from which Complete returns the most frequent completions of a prefix.
+package complete
+
+import (
+	"bufio"
+	"io"
+	"iter"
+	"strings"
+	"unicode"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+// Completion is a token and the number of times it occurred.
+type Completion struct {
+	Token string
+	Count int
+}
+
+// Builder counts the tokens of text for an index. The zero value splits text
+// into words with bufio.ScanWords and normalizes them with Fold.
+type Builder struct {
+	// Split splits text into tokens. If nil, bufio.ScanWords is used.
+	Split bufio.SplitFunc
+	// Normalize maps each token to the form it is indexed under, or to the
+	// empty string to drop it. If nil, Fold is used.
+	Normalize func(token string) string
+
+	counts map[string]int
+}
+
+// Fold returns token in lower case with the characters other than letters and
+// digits removed from both ends, so that "Hello," and "hello" are counted as
+// the same word.
+func Fold(token string) string {
+	return strings.ToLower(strings.TrimFunc(token, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+// Add counts one occurrence of token after normalizing it.
+func (b *Builder) Add(token string) {
+	b.AddCount(token, 1)
+}
+
+// AddCount counts n occurrences of token after normalizing it, for counts that
+// were made elsewhere such as a query log aggregated by token.
+func (b *Builder) AddCount(token string, n int) {
+	normalize := b.Normalize
+	if normalize == nil {
+		normalize = Fold
+	}
+	if token = normalize(token); token == "" {
+		return
+	}
+	if b.counts == nil {
+		b.counts = make(map[string]int)
+	}
+	b.counts[token] += n
+}
+
+// AddTokens counts every token produced by tokens, for text that has already
+// been tokenized, after normalizing them.
+func (b *Builder) AddTokens(tokens iter.Seq[string]) {
+	for token := range tokens {
+		b.Add(token)
+	}
+}
+
+// AddText splits the text read from r into tokens and counts them. It returns
+// the error of reading r or of the split function.
+func (b *Builder) AddText(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	if b.Split != nil {
+		s.Split(b.Split)
+	} else {
+		s.Split(bufio.ScanWords)
+	}
+	for s.Scan() {
+		b.Add(s.Text())
+	}
+	return s.Err()
+}
+
+// Len returns the number of distinct tokens counted.
+func (b *Builder) Len() int {
+	return len(b.counts)
+}
+
+// Build returns a tree holding the tokens that occurred at least floor times,
+// each keyed by itself, created with the given options. The counts are kept
+// so more text can be added and the tree built again. Like radixtree.FromMap,
+// Build panics if the tokens would exceed a limit set by the options.
+func (b *Builder) Build(floor int, opts ...radixtree.Option) *radixtree.RadixTree[Completion] {
+	entries := make([]radixtree.Entry[Completion], 0, len(b.counts))
+	for token, n := range b.counts {
+		if n >= floor {
+			entries = append(entries, radixtree.Entry[Completion]{Key: []byte(token), Value: Completion{Token: token, Count: n}})
+		}
+	}
+	t := radixtree.New[Completion](opts...)
+	t.InsertMany(entries)
+	return t
+}
+
+// Complete returns up to k completions of prefix in t, most frequent first and,
+// of those that are equally frequent, in ascending order. The prefix is not
+// normalized, so it should be given in the form that the tokens were indexed
+// under.
+func Complete(t *radixtree.RadixTree[Completion], prefix string, k int) []Completion {
+	return t.TopK([]byte(prefix), k, func(a, b Completion) bool {
+		return a.Count < b.Count
+	})
+}
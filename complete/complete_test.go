@@ -0,0 +1,44 @@
+package complete
+
+import (
+	"bufio"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	var b Builder
+	text := "The tree, the trie and THE treap. Trees grow; tries don't. The end!"
+	if err := b.AddText(strings.NewReader(text)); err != nil {
+		t.Fatalf("AddText: %v", err)
+	}
+	b.AddTokens(slices.Values([]string{"trie", "--", "Tree"}))
+	b.AddCount("treap", 3)
+	if b.Len() != 10 {
+		t.Errorf("Len = %d, want 10", b.Len())
+	}
+
+	tree := b.Build(2)
+	if tree.Len() != 4 {
+		t.Errorf("Build kept %d tokens, want 4", tree.Len())
+	}
+	got := Complete(tree, "tr", 2)
+	if want := []Completion{{"treap", 4}, {"tree", 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Complete = %v, want %v", got, want)
+	}
+	if got := Complete(tree, "x", 5); len(got) != 0 {
+		t.Errorf("Complete of an unknown prefix = %v", got)
+	}
+	if got := Complete(b.Build(1), "t", 10); len(got) != 6 || got[0] != (Completion{"the", 4}) {
+		t.Errorf("Complete with no floor = %v", got)
+	}
+
+	// A custom tokenizer and normalization.
+	b = Builder{Split: bufio.ScanLines, Normalize: strings.TrimSpace}
+	b.AddText(strings.NewReader("new york\n New York\nnewark\n"))
+	if got := Complete(b.Build(1), "New", 3); !reflect.DeepEqual(got, []Completion{{"New York", 1}}) {
+		t.Errorf("Complete with custom tokens = %v", got)
+	}
+}
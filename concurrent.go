@@ -0,0 +1,438 @@
+package radixtree
+
+import (
+	"bytes"
+	"iter"
+	"runtime"
+	"sort"
+	"sync/atomic"
+)
+
+// ConcurrentTree is a radix tree that is safe for concurrent use, synchronized
+// by optimistic lock coupling as in the adaptive radix tree: every node has a
+// version word that a writer locks while it changes the node, and readers take
+// no locks but read a node's version before and after using it and restart
+// the operation if the node changed in between. A writer locks only the nodes
+// it changes, at most the parent of the key's node, the node and one child, so
+// writers to different subtrees do not wait for each other, however many
+// bytes their keys share, and readers never wait for writers except to retry.
+//
+// The prefix of a node never changes once it is linked into the tree and its
+// children are replaced as a whole, so a node whose prefix must change is
+// replaced by a copy and the old node marked obsolete.
+//
+// ConcurrentTree provides the core operations of RadixTree without its
+// options. Walk and AllPrefix do not see a consistent snapshot of the tree:
+// like Range of sync.Map they visit every key that is in the tree for the
+// whole traversal exactly once, and may or may not visit a key inserted or
+// removed during it. The zero value is an empty tree ready to use and a
+// ConcurrentTree must not be copied after first use.
+type ConcurrentTree[T any] struct {
+	root cnode[T]
+	size atomic.Int64
+}
+
+// NewConcurrentTree returns an empty ConcurrentTree.
+func NewConcurrentTree[T any]() *ConcurrentTree[T] {
+	return &ConcurrentTree[T]{}
+}
+
+// The bits of the version word of a cnode. The remaining bits count the
+// changes to the node.
+const (
+	versionObsolete = 1 << iota
+	versionLocked
+)
+
+// cnode is a node of a ConcurrentTree.
+type cnode[T any] struct {
+	version  atomic.Uint64
+	prefix   []byte
+	leaf     atomic.Pointer[leaf[T]]
+	children atomic.Pointer[cchildren[T]]
+}
+
+// cchildren are the children of a cnode in ascending order of their first
+// bytes, which are held in keys. They are never modified once stored in a node.
+type cchildren[T any] struct {
+	keys  []byte
+	nodes []*cnode[T]
+}
+
+// readLock returns the version of n, or false if n is locked or obsolete and
+// the operation must restart.
+func (n *cnode[T]) readLock() (uint64, bool) {
+	v := n.version.Load()
+	return v, v&(versionLocked|versionObsolete) == 0
+}
+
+// check returns true if n has not changed since its version was v.
+func (n *cnode[T]) check(v uint64) bool {
+	return n.version.Load() == v
+}
+
+// upgrade locks n for writing if it has not changed since its version was v,
+// and returns false if it has.
+func (n *cnode[T]) upgrade(v uint64) bool {
+	return n.version.CompareAndSwap(v, v|versionLocked)
+}
+
+// unlock releases the write lock of n.
+func (n *cnode[T]) unlock() {
+	n.version.Add(versionLocked)
+}
+
+// unlockObsolete releases the write lock of n and marks it obsolete, after n
+// has been unlinked from the tree.
+func (n *cnode[T]) unlockObsolete() {
+	n.version.Add(versionLocked | versionObsolete)
+}
+
+// copy returns a new node with the given prefix and the value and children of
+// n, which must be locked.
+func (n *cnode[T]) copy(prefix []byte) *cnode[T] {
+	c := &cnode[T]{prefix: prefix}
+	c.leaf.Store(n.leaf.Load())
+	c.children.Store(n.children.Load())
+	return c
+}
+
+// get returns the child whose prefix starts with b, or nil if there is none.
+func (c *cchildren[T]) get(b byte) *cnode[T] {
+	if i := c.index(b); i >= 0 {
+		return c.nodes[i]
+	}
+	return nil
+}
+
+// index returns the index of the child whose prefix starts with b, or -1 if
+// there is none. The children may be nil.
+func (c *cchildren[T]) index(b byte) int {
+	if c == nil {
+		return -1
+	}
+	return bytes.IndexByte(c.keys, b)
+}
+
+// len returns the number of children. The children may be nil.
+func (c *cchildren[T]) len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.nodes)
+}
+
+// with returns a copy of the children with child added.
+func (c *cchildren[T]) with(child *cnode[T]) *cchildren[T] {
+	b := child.prefix[0]
+	nc := &cchildren[T]{}
+	if c != nil {
+		i := sort.Search(len(c.keys), func(i int) bool { return c.keys[i] > b })
+		nc.keys = append(append(append(make([]byte, 0, len(c.keys)+1), c.keys[:i]...), b), c.keys[i:]...)
+		nc.nodes = append(append(append(make([]*cnode[T], 0, len(c.nodes)+1), c.nodes[:i]...), child), c.nodes[i:]...)
+		return nc
+	}
+	nc.keys = []byte{b}
+	nc.nodes = []*cnode[T]{child}
+	return nc
+}
+
+// replace returns a copy of the children with the i-th child replaced by
+// child, which starts with the same byte.
+func (c *cchildren[T]) replace(i int, child *cnode[T]) *cchildren[T] {
+	nc := &cchildren[T]{keys: c.keys, nodes: append([]*cnode[T](nil), c.nodes...)}
+	nc.nodes[i] = child
+	return nc
+}
+
+// without returns a copy of the children without the i-th child, or nil if it
+// was the only one.
+func (c *cchildren[T]) without(i int) *cchildren[T] {
+	if len(c.nodes) == 1 {
+		return nil
+	}
+	return &cchildren[T]{
+		keys:  append(append(make([]byte, 0, len(c.keys)-1), c.keys[:i]...), c.keys[i+1:]...),
+		nodes: append(append(make([]*cnode[T], 0, len(c.nodes)-1), c.nodes[:i]...), c.nodes[i+1:]...),
+	}
+}
+
+// restart yields the processor before an operation is retried, so that a
+// writer holding a lock the operation waits for can run.
+func restart() {
+	runtime.Gosched()
+}
+
+// Get returns the value of key and true, or the zero value for type T and
+// false if the key is not in the tree.
+func (t *ConcurrentTree[T]) Get(key []byte) (T, bool) {
+	for {
+		if lf, ok := t.lookup(key); ok {
+			if lf != nil {
+				return lf.value, true
+			}
+			var zero T
+			return zero, false
+		}
+		restart()
+	}
+}
+
+// Contains returns true if key is in the tree, false otherwise.
+func (t *ConcurrentTree[T]) Contains(key []byte) bool {
+	_, ok := t.Get(key)
+	return ok
+}
+
+// lookup returns the leaf of key, or nil if the key is not in the tree. It
+// returns false if a node changed during the lookup and it must be retried.
+func (t *ConcurrentTree[T]) lookup(key []byte) (*leaf[T], bool) {
+	n := &t.root
+	v, ok := n.readLock()
+	if !ok {
+		return nil, false
+	}
+	for len(key) > 0 {
+		child := n.children.Load().get(key[0])
+		if child == nil {
+			return nil, n.check(v)
+		}
+		cv, ok := child.readLock()
+		// The child is only known to belong to n if n is unchanged.
+		if !ok || !n.check(v) {
+			return nil, false
+		}
+		if !bytes.HasPrefix(key, child.prefix) {
+			return nil, child.check(cv)
+		}
+		key = key[len(child.prefix):]
+		n, v = child, cv
+	}
+	lf := n.leaf.Load()
+	return lf, n.check(v)
+}
+
+// Insert sets the value of key and returns the previous value and true, or the
+// zero value for type T and false if the key was not in the tree. The key is
+// copied.
+func (t *ConcurrentTree[T]) Insert(key []byte, value T) (T, bool) {
+	key = bytes.Clone(key)
+	for {
+		old, ok, done := t.insert(key, value)
+		if done {
+			return old, ok
+		}
+		restart()
+	}
+}
+
+// insert implements Insert, returning false for done if a node changed and the
+// insert must be retried.
+func (t *ConcurrentTree[T]) insert(key []byte, value T) (old T, ok, done bool) {
+	n := &t.root
+	v, valid := n.readLock()
+	if !valid {
+		return old, false, false
+	}
+	for len(key) > 0 {
+		children := n.children.Load()
+		i := children.index(key[0])
+		if i < 0 {
+			if !n.upgrade(v) {
+				return old, false, false
+			}
+			child := &cnode[T]{prefix: key}
+			child.leaf.Store(&leaf[T]{value: value})
+			n.children.Store(children.with(child))
+			n.unlock()
+			t.size.Add(1)
+			return old, false, true
+		}
+
+		child := children.nodes[i]
+		cv, valid := child.readLock()
+		if !valid || !n.check(v) {
+			return old, false, false
+		}
+		lcp := longestCommonPrefix(key, child.prefix)
+		if lcp < len(child.prefix) {
+			// The child is split by replacing it with a node for the
+			// common prefix holding a copy of it with the rest of its
+			// prefix, which leaves the child obsolete.
+			if !n.upgrade(v) {
+				return old, false, false
+			}
+			if !child.upgrade(cv) {
+				n.unlock()
+				return old, false, false
+			}
+			rest := child.copy(child.prefix[lcp:])
+			below := &cchildren[T]{keys: []byte{rest.prefix[0]}, nodes: []*cnode[T]{rest}}
+			mid := &cnode[T]{prefix: child.prefix[:lcp:lcp]}
+			if lcp == len(key) {
+				mid.leaf.Store(&leaf[T]{value: value})
+			} else {
+				c := &cnode[T]{prefix: key[lcp:]}
+				c.leaf.Store(&leaf[T]{value: value})
+				below = below.with(c)
+			}
+			mid.children.Store(below)
+			n.children.Store(children.replace(i, mid))
+			child.unlockObsolete()
+			n.unlock()
+			t.size.Add(1)
+			return old, false, true
+		}
+		key = key[lcp:]
+		n, v = child, cv
+	}
+
+	if !n.upgrade(v) {
+		return old, false, false
+	}
+	prev := n.leaf.Swap(&leaf[T]{value: value})
+	n.unlock()
+	if prev == nil {
+		t.size.Add(1)
+		return old, false, true
+	}
+	return prev.value, true, true
+}
+
+// Remove removes key and returns its value and true, or the zero value for
+// type T and false if the key was not in the tree. A node left without a value
+// is unlinked if it has no children and merged with its child if it has one.
+func (t *ConcurrentTree[T]) Remove(key []byte) (T, bool) {
+	for {
+		if old, ok, done := t.remove(key); done {
+			return old, ok
+		}
+		restart()
+	}
+}
+
+// remove implements Remove, returning false for done if a node changed and the
+// removal must be retried.
+func (t *ConcurrentTree[T]) remove(key []byte) (old T, ok, done bool) {
+	var parent *cnode[T]
+	var pv uint64
+	n := &t.root
+	v, valid := n.readLock()
+	if !valid {
+		return old, false, false
+	}
+	for len(key) > 0 {
+		child := n.children.Load().get(key[0])
+		if child == nil {
+			return old, false, n.check(v)
+		}
+		cv, valid := child.readLock()
+		if !valid || !n.check(v) {
+			return old, false, false
+		}
+		if !bytes.HasPrefix(key, child.prefix) {
+			return old, false, child.check(cv)
+		}
+		key = key[len(child.prefix):]
+		parent, pv = n, v
+		n, v = child, cv
+	}
+
+	lf := n.leaf.Load()
+	if lf == nil {
+		return old, false, n.check(v)
+	}
+	children := n.children.Load()
+	if parent == nil || children.len() > 1 {
+		if !n.upgrade(v) {
+			return old, false, false
+		}
+		n.leaf.Store(nil)
+		n.unlock()
+		t.size.Add(-1)
+		return lf.value, true, true
+	}
+
+	// The node is unlinked from its parent, or replaced by a copy of its
+	// only child with the prefixes joined, which needs the parent locked.
+	if !parent.upgrade(pv) {
+		return old, false, false
+	}
+	if !n.upgrade(v) {
+		parent.unlock()
+		return old, false, false
+	}
+	siblings := parent.children.Load()
+	i := siblings.index(n.prefix[0])
+	if children.len() == 0 {
+		parent.children.Store(siblings.without(i))
+	} else {
+		child := children.nodes[0]
+		cv, valid := child.readLock()
+		if !valid || !child.upgrade(cv) {
+			n.unlock()
+			parent.unlock()
+			return old, false, false
+		}
+		prefix := append(append(make([]byte, 0, len(n.prefix)+len(child.prefix)), n.prefix...), child.prefix...)
+		parent.children.Store(siblings.replace(i, child.copy(prefix)))
+		child.unlockObsolete()
+	}
+	n.unlockObsolete()
+	parent.unlock()
+	t.size.Add(-1)
+	return lf.value, true, true
+}
+
+// Len returns the number of keys in the tree.
+func (t *ConcurrentTree[T]) Len() int {
+	return int(t.size.Load())
+}
+
+// Walk calls f for each key that starts with the given prefix and its value in
+// ascending key order until f returns false. The key passed to f is a copy
+// that f may retain, and f may call any method of the tree.
+func (t *ConcurrentTree[T]) Walk(prefix []byte, f func(key []byte, value T) bool) {
+	n, key := &t.root, []byte(nil)
+	for rest := prefix; len(rest) > 0; {
+		child := n.children.Load().get(rest[0])
+		if child == nil {
+			return
+		}
+		key = append(key, child.prefix...)
+		switch {
+		case bytes.HasPrefix(rest, child.prefix):
+			rest = rest[len(child.prefix):]
+		case bytes.HasPrefix(child.prefix, rest):
+			rest = nil
+		default:
+			return
+		}
+		n = child
+	}
+	walkConcurrent(n, key, f)
+}
+
+// walkConcurrent walks the subtree rooted at n, whose key is key, for Walk. It
+// returns false if f did.
+func walkConcurrent[T any](n *cnode[T], key []byte, f func(key []byte, value T) bool) bool {
+	if lf := n.leaf.Load(); lf != nil && !f(bytes.Clone(key), lf.value) {
+		return false
+	}
+	children := n.children.Load()
+	for i := 0; i < children.len(); i++ {
+		child := children.nodes[i]
+		if !walkConcurrent(child, append(key, child.prefix...), f) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllPrefix returns an iterator over the keys that start with the given prefix
+// and their values in ascending key order, as visited by Walk.
+func (t *ConcurrentTree[T]) AllPrefix(prefix []byte) iter.Seq2[[]byte, T] {
+	return func(yield func([]byte, T) bool) {
+		t.Walk(prefix, yield)
+	}
+}
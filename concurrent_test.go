@@ -0,0 +1,97 @@
+package radixtree
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentTree(t *testing.T) {
+	tree := NewConcurrentTree[string]()
+	for _, w := range words {
+		if _, ok := tree.Insert([]byte(w), w); ok {
+			t.Errorf("Insert(%q) replaced a value", w)
+		}
+	}
+	if tree.Len() != len(words) {
+		t.Errorf("Len() = %d, want %d", tree.Len(), len(words))
+	}
+	for i, w := range words {
+		if i%2 == 0 {
+			if v, ok := tree.Remove([]byte(w)); !ok || v != w {
+				t.Errorf("Remove(%q) = %q, %t", w, v, ok)
+			}
+		}
+	}
+	for i, w := range words {
+		if v, ok := tree.Get([]byte(w)); ok != (i%2 == 1) || ok && v != w {
+			t.Errorf("Get(%q) = %q, %t after removing every other word", w, v, ok)
+		}
+	}
+	if _, ok := tree.Remove([]byte(words[0])); ok {
+		t.Errorf("Remove of a removed key returned true")
+	}
+	var got []string
+	tree.Walk(nil, func(key []byte, _ string) bool {
+		got = append(got, string(key))
+		return true
+	})
+	if len(got) != tree.Len() {
+		t.Errorf("Walk visited %d keys, want %d", len(got), tree.Len())
+	}
+}
+
+func TestConcurrentTreeParallel(t *testing.T) {
+	// The writers share a long prefix, so they contend for the same nodes
+	// near the root and split, merge and unlink nodes below it.
+	tree := NewConcurrentTree[int]()
+	const writers, n = 4, 300
+	var wg sync.WaitGroup
+	for g := 0; g < writers; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				key := []byte(fmt.Sprintf("tenant/%d/%d", i%7, i*writers+g))
+				tree.Insert(key, i)
+				if v, ok := tree.Get(key); !ok || v != i {
+					t.Errorf("Get(%q) = %d, %t after Insert", key, v, ok)
+				}
+				if i%3 == 0 {
+					if _, ok := tree.Remove(key); !ok {
+						t.Errorf("Remove(%q) did not find the key", key)
+					}
+				}
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				tree.Get([]byte(fmt.Sprintf("tenant/%d/%d", i%7, i)))
+				tree.Walk([]byte("tenant/1"), func([]byte, int) bool { return true })
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := 0
+	for g := 0; g < writers; g++ {
+		for i := 0; i < n; i++ {
+			key := []byte(fmt.Sprintf("tenant/%d/%d", i%7, i*writers+g))
+			if v, ok := tree.Get(key); ok != (i%3 != 0) || ok && v != i {
+				t.Errorf("Get(%q) = %d, %t after the writers finished", key, v, ok)
+			}
+			if i%3 != 0 {
+				want++
+			}
+		}
+	}
+	visited := 0
+	tree.Walk(nil, func([]byte, int) bool {
+		visited++
+		return true
+	})
+	if tree.Len() != want || visited != want {
+		t.Errorf("Len() = %d and Walk visited %d keys, want %d", tree.Len(), visited, want)
+	}
+}
@@ -0,0 +1,59 @@
+package radixtree
+
+import "context"
+
+// ctxCheckInterval is the number of nodes WalkCtx visits between checks of its
+// context.
+const ctxCheckInterval = 256
+
+// WalkCtx is like WalkDepth without a depth limit, visiting every key that
+// starts with the given prefix and its value in ascending key order, but stops
+// once ctx is done and returns the error of ctx. The context is checked before
+// the traversal starts and then every few hundred nodes, so that a scan over a
+// large subtree can be bounded by a deadline without checking it in f. It
+// returns nil if the traversal completes or f returns false.
+func (t *RadixTree[T]) WalkCtx(ctx context.Context, prefix []byte, f func(key []byte, value T) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	n, key := t.findPath(prefix, t.borrow())
+	var err error
+	if n != nil {
+		w := ctxWalker[T]{t: t, ctx: ctx, gen: t.gen, f: f}
+		key, _ = w.walk(n, key)
+		err = w.err
+	}
+	t.release(key)
+	return err
+}
+
+// ctxWalker holds the state of WalkCtx.
+type ctxWalker[T any] struct {
+	t     *RadixTree[T]
+	ctx   context.Context
+	gen   uint64
+	f     func(key []byte, value T) bool
+	nodes int
+	err   error
+}
+
+func (w *ctxWalker[T]) walk(n *node[T], key []byte) ([]byte, bool) {
+	if w.nodes++; w.nodes%ctxCheckInterval == 0 {
+		if w.err = w.ctx.Err(); w.err != nil {
+			return key, false
+		}
+	}
+	if n.hasValue() && !(w.f(w.t.yield(key), n.leaf.value) && w.t.unmodified(w.gen)) {
+		return key, false
+	}
+	for _, child := range n.children.nodes {
+		l := len(key)
+		var ok bool
+		key, ok = w.walk(child, append(key, child.prefix...))
+		key = key[:l]
+		if !ok {
+			return key, false
+		}
+	}
+	return key, true
+}
@@ -0,0 +1,44 @@
+package radixtree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWalkCtx(t *testing.T) {
+	tree := build(words)
+	var got []string
+	err := tree.WalkCtx(context.Background(), []byte("to"), func(key []byte, value string) bool {
+		got = append(got, string(key))
+		return true
+	})
+	if want := []string{"to", "toa", "toad", "toady", "toadyism"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkCtx(to) = %v visiting %v, want nil visiting %v", err, got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := tree.WalkCtx(ctx, nil, func([]byte, string) bool { return true }); !errors.Is(err, context.Canceled) {
+		t.Errorf("WalkCtx with a canceled context returned %v, want %v", err, context.Canceled)
+	}
+
+	// Canceling during the walk stops it within a check interval.
+	big := New[int]()
+	for i := 0; i < 10*ctxCheckInterval; i++ {
+		big.Insert([]byte(fmt.Sprintf("%05d", i)), i)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	visited := 0
+	err = big.WalkCtx(ctx, nil, func(_ []byte, i int) bool {
+		if visited++; visited == ctxCheckInterval {
+			cancel()
+		}
+		return true
+	})
+	if !errors.Is(err, context.Canceled) || visited > 3*ctxCheckInterval {
+		t.Errorf("WalkCtx canceled during the walk returned %v after %d entries", err, visited)
+	}
+}
@@ -0,0 +1,169 @@
+package radixtree
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// KeyEncoding is the way keys are written as text by ExportCSV and read back by
+// ImportCSV.
+type KeyEncoding int
+
+const (
+	// KeyRaw writes the bytes of each key unchanged. It suits keys that are
+	// text; a carriage return in a key is lost when it is read back since
+	// CSV readers normalise line endings, even within quoted fields.
+	KeyRaw KeyEncoding = iota
+	// KeyEscaped writes each key as the contents of a Go string literal,
+	// with backslash escapes for quotes, backslashes and bytes that are not
+	// printable UTF-8, so text keys stay readable.
+	KeyEscaped
+	// KeyHex writes each key as lowercase hexadecimal.
+	KeyHex
+	// KeyBase64 writes each key in standard base64 encoding with padding.
+	KeyBase64
+)
+
+func (e KeyEncoding) encode(key []byte) string {
+	switch e {
+	case KeyEscaped:
+		q := strconv.Quote(string(key))
+		return q[1 : len(q)-1]
+	case KeyHex:
+		return hex.EncodeToString(key)
+	case KeyBase64:
+		return base64.StdEncoding.EncodeToString(key)
+	}
+	return string(key)
+}
+
+func (e KeyEncoding) decode(s string) ([]byte, error) {
+	switch e {
+	case KeyEscaped:
+		u, err := strconv.Unquote(`"` + s + `"`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid escaped key %q", s)
+		}
+		return []byte(u), nil
+	case KeyHex:
+		return hex.DecodeString(s)
+	case KeyBase64:
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return []byte(s), nil
+}
+
+// CSVOption configures the format used by ExportCSV and ImportCSV.
+type CSVOption func(*csvOptions)
+
+type csvOptions struct {
+	comma   rune
+	useCRLF bool
+	keys    KeyEncoding
+}
+
+func newCSVOptions(opts []CSVOption) csvOptions {
+	o := csvOptions{comma: ','}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCSVComma sets the field delimiter, which is a comma by default.
+func WithCSVComma(r rune) CSVOption {
+	return func(o *csvOptions) {
+		o.comma = r
+	}
+}
+
+// WithCSVCRLF makes ExportCSV end records with \r\n rather than \n.
+func WithCSVCRLF() CSVOption {
+	return func(o *csvOptions) {
+		o.useCRLF = true
+	}
+}
+
+// WithCSVKeyEncoding sets the way keys are written as text, which is KeyRaw by
+// default. Fields are quoted as CSV requires whatever the encoding.
+func WithCSVKeyEncoding(e KeyEncoding) CSVOption {
+	return func(o *csvOptions) {
+		o.keys = e
+	}
+}
+
+// ExportCSV writes every entry of the tree to w as a CSV record of two fields,
+// the key and the value formatted by formatValue, in ascending key order. If
+// formatValue returns an error the export stops and the error is returned.
+func (t *RadixTree[T]) ExportCSV(w io.Writer, formatValue func(value T) (string, error), opts ...CSVOption) error {
+	o := newCSVOptions(opts)
+	cw := csv.NewWriter(w)
+	cw.Comma = o.comma
+	cw.UseCRLF = o.useCRLF
+
+	var err error
+	key := t.borrow()
+	key, _ = walkNodes(t.root, key, -1, func(key []byte, n *node[T]) bool {
+		var v string
+		if v, err = formatValue(n.leaf.value); err != nil {
+			return false
+		}
+		err = cw.Write([]string{o.keys.encode(key), v})
+		return err == nil
+	})
+	t.release(key)
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ImportCSV inserts the entries read from r, a CSV record of a key and a value
+// each, decoding the keys as configured and the values with parseValue. A
+// record with only a key passes the empty string to parseValue. The records
+// may be in any order and, if several have the same key, the last one's value
+// is kept. If a record is malformed or parseValue returns an error, the error
+// is returned annotated with the record number and none of the entries are
+// added. As with InsertManyErr, if the entries would exceed a limit of the
+// tree the error is returned and none of them are added.
+func (t *RadixTree[T]) ImportCSV(r io.Reader, parseValue func(s string) (T, error), opts ...CSVOption) error {
+	o := newCSVOptions(opts)
+	cr := csv.NewReader(r)
+	cr.Comma = o.comma
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	var entries []Entry[T]
+	for i := 1; ; i++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("radixtree: record %d: %w", i, err)
+		}
+		if len(rec) > 2 {
+			return fmt.Errorf("radixtree: record %d: %d fields, want a key and a value", i, len(rec))
+		}
+		key, err := o.keys.decode(rec[0])
+		if err != nil {
+			return fmt.Errorf("radixtree: record %d: %w", i, err)
+		}
+		var s string
+		if len(rec) == 2 {
+			s = rec[1]
+		}
+		value, err := parseValue(s)
+		if err != nil {
+			return fmt.Errorf("radixtree: record %d: %w", i, err)
+		}
+		entries = append(entries, Entry[T]{Key: key, Value: value})
+	}
+	_, err := t.InsertManyErr(entries)
+	return err
+}
@@ -0,0 +1,106 @@
+package radixtree
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func formatInt(v int) (string, error) {
+	return strconv.Itoa(v), nil
+}
+
+func TestExportCSV(t *testing.T) {
+	tree := New[int]()
+	tree.Insert([]byte("apple"), 1)
+	tree.Insert([]byte("apple pie, warm"), 2)
+	tree.Insert([]byte("a\x00\"b\""), 3)
+
+	tests := []struct {
+		opts []CSVOption
+		want string
+	}{
+		{nil, "\"a\x00\"\"b\"\"\",3\napple,1\n\"apple pie, warm\",2\n"},
+		{[]CSVOption{WithCSVKeyEncoding(KeyEscaped)}, "\"a\\x00\\\"\"b\\\"\"\",3\napple,1\n\"apple pie, warm\",2\n"},
+		{[]CSVOption{WithCSVKeyEncoding(KeyHex), WithCSVComma(';'), WithCSVCRLF()}, "6100226222;3\r\n6170706c65;1\r\n6170706c65207069652c207761726d;2\r\n"},
+		{[]CSVOption{WithCSVKeyEncoding(KeyBase64)}, "YQAiYiI=,3\nYXBwbGU=,1\nYXBwbGUgcGllLCB3YXJt,2\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := tree.ExportCSV(&buf, formatInt, tt.opts...); err != nil {
+			t.Fatalf("ExportCSV returned error %v", err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("ExportCSV = %q, want %q", buf.String(), tt.want)
+		}
+
+		got := New[int]()
+		if err := got.ImportCSV(&buf, strconv.Atoi, tt.opts...); err != nil {
+			t.Fatalf("ImportCSV returned error %v", err)
+		}
+		verify(t, got)
+		for _, key := range []string{"apple", "apple pie, warm", "a\x00\"b\""} {
+			want, _ := tree.Get([]byte(key))
+			if v, ok := got.Get([]byte(key)); !ok || v != want {
+				t.Errorf("imported %q = %d, %v, want %d", key, v, ok, want)
+			}
+		}
+	}
+
+	errFormat := errors.New("format")
+	err := tree.ExportCSV(&bytes.Buffer{}, func(int) (string, error) { return "", errFormat })
+	if !errors.Is(err, errFormat) {
+		t.Errorf("ExportCSV returned %v, want the error from formatValue", err)
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	tree := New[int]()
+	tree.Insert([]byte("banana"), 0)
+	input := "cherry,3\nbanana,2\napple\nbanana,4\n"
+	if err := tree.ImportCSV(strings.NewReader(input), func(s string) (int, error) {
+		if s == "" {
+			return -1, nil
+		}
+		return strconv.Atoi(s)
+	}); err != nil {
+		t.Fatalf("ImportCSV returned error %v", err)
+	}
+	verify(t, tree)
+	var got []string
+	tree.WalkDepth(nil, 10, func(key []byte, value int) bool {
+		got = append(got, string(key)+"="+strconv.Itoa(value))
+		return true
+	})
+	if want := []string{"apple=-1", "banana=4", "cherry=3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportCSV entries = %q, want %q", got, want)
+	}
+
+	for _, input := range []string{
+		"durian,5\nelderberry,x\n",
+		"durian,5\na,b,c\n",
+		"durian,5\n\"unterminated\n",
+	} {
+		err := tree.ImportCSV(strings.NewReader(input), strconv.Atoi)
+		if err == nil || !strings.Contains(err.Error(), "record 2") {
+			t.Errorf("ImportCSV(%q) = %v, want an error for record 2", input, err)
+		}
+		if tree.Len() != 3 {
+			t.Errorf("ImportCSV(%q) added entries before failing", input)
+		}
+	}
+	if err := tree.ImportCSV(strings.NewReader("zz,1\n"), strconv.Atoi, WithCSVKeyEncoding(KeyHex)); err == nil {
+		t.Error("ImportCSV accepted an invalid hex key")
+	}
+
+	tree = New[int](WithMaxEntries(1))
+	if err := tree.ImportCSV(strings.NewReader("a,1\nb,2\n"), strconv.Atoi); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("ImportCSV over the entries limit returned %v, want %v", err, ErrQuotaExceeded)
+	}
+	if tree.Len() != 0 {
+		t.Errorf("ImportCSV over the entries limit added entries")
+	}
+}
@@ -0,0 +1,313 @@
+// Package debug serves pages for browsing radix trees from a web browser,
+// listing the keys under a prefix a page at a time, showing their values and
+// the statistics of each tree.
+//
+// Trees are made available with Register. Importing the package registers its
+// handler with http.DefaultServeMux under the path /debug/radixtree/, in the
+// manner of net/http/pprof. Use Handler to serve the pages elsewhere.
+package debug
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+func init() {
+	http.Handle("/debug/radixtree/", Handler())
+}
+
+// pageSize is the number of keys listed on a page unless the request asks for
+// a different number with the limit parameter.
+const pageSize = 100
+
+// tree is the type-erased view of a registered tree used by the handler.
+type tree interface {
+	len() int
+	keys(prefix []byte, offset, limit int) (keys [][]byte, more bool)
+	value(key []byte) (string, bool)
+	stats() radixtree.Stats
+	memUsage() radixtree.MemUsage
+}
+
+type registered[T any] struct {
+	t      *radixtree.RadixTree[T]
+	mu     sync.Locker
+	format func(T) string
+}
+
+func (r *registered[T]) lock() func() {
+	if r.mu == nil {
+		return func() {}
+	}
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+func (r *registered[T]) len() int {
+	defer r.lock()()
+	return r.t.Len()
+}
+
+func (r *registered[T]) keys(prefix []byte, offset, limit int) ([][]byte, bool) {
+	defer r.lock()()
+	n := r.t.CountPrefix(prefix)
+	first := r.t.Rank(prefix) + offset
+	var keys [][]byte
+	for len(keys) < limit && offset+len(keys) < n {
+		key, _, _ := r.t.Select(first + len(keys))
+		keys = append(keys, key)
+	}
+	return keys, offset+len(keys) < n
+}
+
+func (r *registered[T]) value(key []byte) (string, bool) {
+	defer r.lock()()
+	v, ok := r.t.Get(key)
+	if !ok {
+		return "", false
+	}
+	if r.format == nil {
+		return fmt.Sprint(v), true
+	}
+	return r.format(v), true
+}
+
+func (r *registered[T]) stats() radixtree.Stats {
+	defer r.lock()()
+	return r.t.StatsDetail()
+}
+
+func (r *registered[T]) memUsage() radixtree.MemUsage {
+	defer r.lock()()
+	return r.t.MemUsage()
+}
+
+var (
+	treesMu sync.Mutex
+	trees   = map[string]tree{}
+)
+
+// Register makes the tree t available under the given name, replacing any tree
+// already registered with it. Values are shown using format, or fmt.Sprint if
+// format is nil. Trees are not safe for concurrent use, so if t is modified
+// while it is being served mu must be the lock that guards it; the handler
+// holds it while reading the tree. Otherwise mu may be nil.
+func Register[T any](name string, t *radixtree.RadixTree[T], mu sync.Locker, format func(T) string) {
+	add(name, &registered[T]{t: t, mu: mu, format: format})
+}
+
+func add(name string, t tree) {
+	treesMu.Lock()
+	defer treesMu.Unlock()
+	trees[name] = t
+}
+
+// Unregister removes the tree registered under the given name.
+func Unregister(name string) {
+	treesMu.Lock()
+	defer treesMu.Unlock()
+	delete(trees, name)
+}
+
+func lookup(name string) tree {
+	treesMu.Lock()
+	defer treesMu.Unlock()
+	return trees[name]
+}
+
+func names() []string {
+	treesMu.Lock()
+	defer treesMu.Unlock()
+	list := make([]string, 0, len(trees))
+	for name := range trees {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Handler returns a handler serving the pages for the registered trees. The
+// tree, prefix and key are given as query parameters so the handler can be
+// mounted under any path.
+//
+//	?                              lists the registered trees
+//	?tree=name&prefix=p&offset=n   lists the keys of a tree starting with p
+//	?tree=name&key=k               shows the value of a key
+func Handler() http.Handler {
+	return http.HandlerFunc(serve)
+}
+
+func serve(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name := q.Get("tree")
+	if name == "" {
+		var list []treeSummary
+		for _, name := range names() {
+			if t := lookup(name); t != nil {
+				list = append(list, treeSummary{Name: name, Len: t.len()})
+			}
+		}
+		render(w, indexPage, list)
+		return
+	}
+
+	t := lookup(name)
+	if t == nil {
+		http.Error(w, fmt.Sprintf("no tree named %q", name), http.StatusNotFound)
+		return
+	}
+	if key, ok := q["key"]; ok {
+		v, found := t.value([]byte(key[0]))
+		if !found {
+			http.Error(w, fmt.Sprintf("key %s not found", display([]byte(key[0]))), http.StatusNotFound)
+			return
+		}
+		render(w, valuePage, valueData{Tree: name, Key: key[0], Display: display([]byte(key[0])), Value: v})
+		return
+	}
+
+	offset, err := param(q.Get("offset"), 0)
+	if err != nil {
+		http.Error(w, "invalid offset", http.StatusBadRequest)
+		return
+	}
+	limit, err := param(q.Get("limit"), pageSize)
+	if err != nil || limit == 0 {
+		http.Error(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+	prefix := q.Get("prefix")
+	keys, more := t.keys([]byte(prefix), offset, limit)
+	d := treeData{
+		Name:   name,
+		Prefix: prefix,
+		Len:    t.len(),
+		Stats:  t.stats(),
+		Memory: t.memUsage().Total(),
+		Limit:  limit,
+	}
+	for _, key := range keys {
+		d.Keys = append(d.Keys, keyData{Key: string(key), Display: display(key)})
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		d.Prev = &prev
+	}
+	if more {
+		next := offset + len(keys)
+		d.Next = &next
+	}
+	render(w, treePage, d)
+}
+
+// param parses a non-negative integer query parameter, returning def if it is
+// absent.
+func param(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err == nil && n < 0 {
+		err = strconv.ErrRange
+	}
+	return n, err
+}
+
+// display returns key as text if it is printable UTF-8, otherwise quoted with
+// its unprintable bytes escaped.
+func display(key []byte) string {
+	if !utf8.Valid(key) {
+		return strconv.Quote(string(key))
+	}
+	for _, r := range string(key) {
+		if !strconv.IsPrint(r) {
+			return strconv.Quote(string(key))
+		}
+	}
+	return string(key)
+}
+
+func render(w http.ResponseWriter, t *template.Template, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := t.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type treeSummary struct {
+	Name string
+	Len  int
+}
+
+type keyData struct {
+	Key     string
+	Display string
+}
+
+type treeData struct {
+	Name       string
+	Prefix     string
+	Len        int
+	Stats      radixtree.Stats
+	Memory     int
+	Keys       []keyData
+	Limit      int
+	Prev, Next *int
+}
+
+type valueData struct {
+	Tree    string
+	Key     string
+	Display string
+	Value   string
+}
+
+const style = `<style>body{font-family:sans-serif}td,th{padding:0 1em 0 0;text-align:left}pre{background:#eee;padding:1em}</style>`
+
+var indexPage = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><title>radix trees</title>` + style + `</head><body>
+<h1>Radix trees</h1>
+{{if .}}<table><tr><th>Tree</th><th>Keys</th></tr>
+{{range .}}<tr><td><a href="?tree={{.Name}}">{{.Name}}</a></td><td>{{.Len}}</td></tr>
+{{end}}</table>{{else}}<p>No trees are registered.</p>{{end}}
+</body></html>
+`))
+
+var treePage = template.Must(template.New("tree").Parse(`<!DOCTYPE html>
+<html><head><title>{{.Name}}</title>` + style + `</head><body>
+<h1><a href="?">Radix trees</a> / {{.Name}}</h1>
+<table>
+<tr><th>Keys</th><td>{{.Len}}</td></tr>
+<tr><th>Nodes</th><td>{{.Stats.Nodes}}</td></tr>
+<tr><th>Height</th><td>{{.Stats.Height}}</td></tr>
+<tr><th>Fan-out</th><td>{{.Stats.FanOut}}</td></tr>
+<tr><th>Prefix lengths</th><td>{{.Stats.PrefixLen}}</td></tr>
+<tr><th>Memory</th><td>{{.Memory}} bytes</td></tr>
+</table>
+<form><input type="hidden" name="tree" value="{{.Name}}">
+<input name="prefix" value="{{.Prefix}}" placeholder="prefix"> <input type="submit" value="Find"></form>
+<ul>
+{{range .Keys}}<li><a href="?tree={{$.Name}}&amp;key={{.Key}}">{{.Display}}</a></li>
+{{else}}<li>No keys start with the prefix.</li>
+{{end}}</ul>
+<p>{{with .Prev}}<a href="?tree={{$.Name}}&amp;prefix={{$.Prefix}}&amp;offset={{.}}&amp;limit={{$.Limit}}">previous</a>{{end}}
+{{with .Next}}<a href="?tree={{$.Name}}&amp;prefix={{$.Prefix}}&amp;offset={{.}}&amp;limit={{$.Limit}}">next</a>{{end}}</p>
+</body></html>
+`))
+
+var valuePage = template.Must(template.New("value").Parse(`<!DOCTYPE html>
+<html><head><title>{{.Display}}</title>` + style + `</head><body>
+<h1><a href="?">Radix trees</a> / <a href="?tree={{.Tree}}">{{.Tree}}</a> / {{.Display}}</h1>
+<pre>{{.Value}}</pre>
+</body></html>
+`))
@@ -0,0 +1,72 @@
+package debug
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+func get(t *testing.T, query string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/radixtree/"+query, nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestHandler(t *testing.T) {
+	tree := radixtree.New[int]()
+	for i := 0; i < 25; i++ {
+		tree.Insert([]byte(fmt.Sprintf("route/%02d", i)), i)
+	}
+	tree.Insert([]byte("other"), -1)
+	tree.Insert([]byte{0xff, '\n'}, -2)
+	var mu sync.Mutex
+	Register("routes", tree, &mu, func(v int) string { return fmt.Sprintf("<%d>", v) })
+	defer Unregister("routes")
+
+	code, body := get(t, "")
+	if code != http.StatusOK || !strings.Contains(body, `<a href="?tree=routes">routes</a></td><td>27</td>`) {
+		t.Errorf("index = %d, %s", code, body)
+	}
+
+	code, body = get(t, "?tree=routes&prefix=route/&limit=10&offset=10")
+	if code != http.StatusOK {
+		t.Fatalf("tree page = %d, %s", code, body)
+	}
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf(">route/%02d<", i)
+		if got, want := strings.Contains(body, key), i >= 10 && i < 20; got != want {
+			t.Errorf("page lists %s = %v, want %v", key, got, want)
+		}
+	}
+	if !strings.Contains(body, "offset=0&amp;limit=10\">previous") || !strings.Contains(body, "offset=20&amp;limit=10\">next") {
+		t.Errorf("tree page lacks pagination links: %s", body)
+	}
+	if _, body = get(t, "?tree=routes&prefix=route/&offset=20&limit=10"); strings.Contains(body, ">next<") {
+		t.Error("last page has a next link")
+	}
+	if _, body = get(t, "?tree=routes&prefix=%ff"); !strings.Contains(body, `&#34;\xff\n&#34;`) {
+		t.Errorf("unprintable key is not quoted: %s", body)
+	}
+
+	code, body = get(t, "?tree=routes&key=route/07")
+	if code != http.StatusOK || !strings.Contains(body, "<pre>&lt;7&gt;</pre>") {
+		t.Errorf("value page = %d, %s", code, body)
+	}
+
+	for _, q := range []string{"?tree=missing", "?tree=routes&key=missing"} {
+		if code, _ := get(t, q); code != http.StatusNotFound {
+			t.Errorf("%s = %d, want %d", q, code, http.StatusNotFound)
+		}
+	}
+	for _, q := range []string{"?tree=routes&offset=-1", "?tree=routes&limit=0"} {
+		if code, _ := get(t, q); code != http.StatusBadRequest {
+			t.Errorf("%s = %d, want %d", q, code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Package dns matches domain names against policies for domains and their
+// subdomains, such as allow lists, routing rules or certificate names, by
+// keying a radix tree with the labels of each name in reverse order so that
+// every name shares a prefix with the domains it belongs to.
+//
+// A name such as "api.example.com" is keyed as "com.example.api.", with every
+// label followed by a dot so that a domain is only ever a prefix of the names
+// below it and "example.com" never matches "badexample.com". Names are folded
+// to lower case and a trailing dot, marking a fully qualified name, is
+// ignored. Internationalized names must be given in their ASCII form.
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+// ErrInvalid is returned when a name or pattern is not a valid domain name.
+var ErrInvalid = errors.New("dns: invalid domain name")
+
+// Limits on the length of a name in bytes and of each of its labels.
+const (
+	MaxNameLen  = 253
+	MaxLabelLen = 63
+)
+
+// SplitLabels returns the labels of name from left to right, folded to lower
+// case, so "API.example.com." is split into "api", "example" and "com". It
+// returns an error wrapping ErrInvalid if a label is empty, longer than
+// MaxLabelLen or has a byte other than a letter, digit, hyphen or underscore,
+// or if the name is longer than MaxNameLen. The root name "." has no labels.
+func SplitLabels(name string) ([]string, error) {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return nil, nil
+	}
+	if len(name) > MaxNameLen {
+		return nil, fmt.Errorf("%w: %q is longer than %d bytes", ErrInvalid, name, MaxNameLen)
+	}
+	labels := strings.Split(strings.ToLower(name), ".")
+	for _, label := range labels {
+		if label == "" || len(label) > MaxLabelLen {
+			return nil, fmt.Errorf("%w: %q has a label of %d bytes", ErrInvalid, name, len(label))
+		}
+		for i := 0; i < len(label); i++ {
+			if c := label[i]; !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return nil, fmt.Errorf("%w: %q has %q in label %q", ErrInvalid, name, c, label)
+			}
+		}
+	}
+	return labels, nil
+}
+
+// Key returns the key of name in a tree: its labels in reverse order, each
+// followed by a dot.
+func Key(name string) ([]byte, error) {
+	labels, err := SplitLabels(name)
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 0, len(name)+1)
+	for i := len(labels) - 1; i >= 0; i-- {
+		key = append(key, labels[i]...)
+		key = append(key, '.')
+	}
+	return key, nil
+}
+
+// Name returns the name whose key is key, the inverse of Key.
+func Name(key []byte) string {
+	labels := strings.Split(strings.TrimSuffix(string(key), "."), ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	return strings.Join(labels, ".")
+}
+
+// entry holds the values of the patterns for a domain.
+type entry[T any] struct {
+	depth       int // length of the key of the domain
+	exact       T
+	wildcard    T
+	hasExact    bool
+	hasWildcard bool
+}
+
+// Match is a pattern that matched a name and its value.
+type Match[T any] struct {
+	Pattern string
+	Value   T
+}
+
+// Tree maps patterns of domain names to values. A pattern is either a name,
+// such as "example.com", which matches only that name, or a wildcard such as
+// "*.example.com", which matches every name below the domain at any depth but
+// not the domain itself. The wildcard "*" matches every name. A Tree is not
+// safe for concurrent use.
+type Tree[T any] struct {
+	tree *radixtree.RadixTree[*entry[T]]
+	size int
+}
+
+// New returns an empty tree.
+func New[T any]() *Tree[T] {
+	return &Tree[T]{tree: radixtree.New[*entry[T]]()}
+}
+
+// parse returns the key of the domain of a pattern and whether it is a
+// wildcard.
+func parse(pattern string) ([]byte, bool, error) {
+	if pattern == "*" {
+		return nil, true, nil
+	}
+	wildcard := strings.HasPrefix(pattern, "*.")
+	if wildcard {
+		pattern = pattern[2:]
+	}
+	if strings.TrimSuffix(pattern, ".") == "" {
+		return nil, false, fmt.Errorf("%w: empty pattern", ErrInvalid)
+	}
+	key, err := Key(pattern)
+	return key, wildcard, err
+}
+
+// Insert sets the value of the pattern and returns the previous value and
+// true, or the zero value for type T and false if the pattern was not in the
+// tree. It returns an error wrapping ErrInvalid if the pattern is not a valid
+// name or wildcard.
+func (t *Tree[T]) Insert(pattern string, value T) (T, bool, error) {
+	var zero T
+	key, wildcard, err := parse(pattern)
+	if err != nil {
+		return zero, false, err
+	}
+	e, ok := t.tree.Get(key)
+	if !ok {
+		e = &entry[T]{depth: len(key)}
+		t.tree.Insert(key, e)
+	}
+	var old T
+	var had bool
+	if wildcard {
+		old, had = e.wildcard, e.hasWildcard
+		e.wildcard, e.hasWildcard = value, true
+	} else {
+		old, had = e.exact, e.hasExact
+		e.exact, e.hasExact = value, true
+	}
+	if !had {
+		t.size++
+	}
+	return old, had, nil
+}
+
+// Get returns the value of the pattern itself, which is not matched against
+// other patterns, and true, or the zero value for type T and false if the
+// pattern is not in the tree or not valid.
+func (t *Tree[T]) Get(pattern string) (T, bool) {
+	var zero T
+	key, wildcard, err := parse(pattern)
+	if err != nil {
+		return zero, false
+	}
+	e, ok := t.tree.Get(key)
+	switch {
+	case !ok:
+		return zero, false
+	case wildcard:
+		return e.wildcard, e.hasWildcard
+	}
+	return e.exact, e.hasExact
+}
+
+// Len returns the number of patterns in the tree.
+func (t *Tree[T]) Len() int {
+	return t.size
+}
+
+// Lookup returns the most specific pattern that matches name and its value,
+// and true, or false if no pattern matches. The name itself is preferred, then
+// the wildcard of its closest enclosing domain that has one. It returns an
+// error wrapping ErrInvalid if name is not a valid name.
+func (t *Tree[T]) Lookup(name string) (Match[T], bool, error) {
+	key, err := Key(name)
+	if err != nil {
+		return Match[T]{}, false, err
+	}
+	full := len(key)
+	for {
+		// The longest key that is a prefix of the name is its closest
+		// enclosing domain in the tree, or the name itself.
+		e, ok := t.tree.LongestPrefix(key)
+		if !ok {
+			return Match[T]{}, false, nil
+		}
+		switch {
+		case e.depth == full && e.hasExact:
+			return Match[T]{Pattern: Name(key[:e.depth]), Value: e.exact}, true, nil
+		case e.depth < full && e.hasWildcard:
+			pattern := "*"
+			if e.depth > 0 {
+				pattern += "." + Name(key[:e.depth])
+			}
+			return Match[T]{Pattern: pattern, Value: e.wildcard}, true, nil
+		case e.depth == 0:
+			return Match[T]{}, false, nil
+		}
+		// Look for a shorter domain, whose key ends at an earlier dot.
+		key = key[:e.depth-1]
+	}
+}
+
+// Remove removes the pattern from the tree and returns its value and true, or
+// the zero value for type T and false if the pattern was not in the tree or
+// not valid.
+func (t *Tree[T]) Remove(pattern string) (T, bool) {
+	var zero T
+	key, wildcard, err := parse(pattern)
+	if err != nil {
+		return zero, false
+	}
+	e, ok := t.tree.Get(key)
+	if !ok {
+		return zero, false
+	}
+	var old T
+	if wildcard {
+		old, ok = e.wildcard, e.hasWildcard
+		e.wildcard, e.hasWildcard = zero, false
+	} else {
+		old, ok = e.exact, e.hasExact
+		e.exact, e.hasExact = zero, false
+	}
+	if ok {
+		t.size--
+	}
+	if !e.hasExact && !e.hasWildcard {
+		t.tree.Remove(key)
+	}
+	return old, ok
+}
+
+// Walk executes f for every pattern for the domain or the names below it, and
+// its value, in the order of their keys, so that a domain comes before its
+// subdomains. The domain "" or "." walks every pattern. If f returns false
+// Walk stops. It returns an error wrapping ErrInvalid if domain is not a valid
+// name.
+func (t *Tree[T]) Walk(domain string, f func(pattern string, value T) bool) error {
+	key, err := Key(domain)
+	if err != nil {
+		return err
+	}
+	for k, e := range t.tree.AllPrefix(key) {
+		name := Name(k)
+		if e.hasExact && !f(name, e.exact) {
+			break
+		}
+		if e.hasWildcard {
+			pattern := "*"
+			if len(k) > 0 {
+				pattern += "." + name
+			}
+			if !f(pattern, e.wildcard) {
+				break
+			}
+		}
+	}
+	return nil
+}
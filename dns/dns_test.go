@@ -0,0 +1,90 @@
+package dns
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	for name, want := range map[string]string{
+		"API.Example.com.": "com.example.api.",
+		"example.com":      "com.example.",
+		"_sip._tcp.x.org":  "org.x._tcp._sip.",
+		".":                "",
+	} {
+		key, err := Key(name)
+		if err != nil || string(key) != want {
+			t.Errorf("Key(%q) = (%q, %v), want %q", name, key, err, want)
+		}
+		if got := Name(key); got != strings.ToLower(strings.TrimSuffix(name, ".")) {
+			t.Errorf("Name(%q) = %q", key, got)
+		}
+	}
+	for _, name := range []string{"a..b", ".a", "a b.com", strings.Repeat("a", 64) + ".com", strings.Repeat("a.", 127) + "com", "ex\xe4mple.com"} {
+		if _, err := Key(name); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Key(%q) = %v, want %v", name, err, ErrInvalid)
+		}
+	}
+}
+
+func TestTree(t *testing.T) {
+	tree := New[string]()
+	for _, pattern := range []string{"example.com", "*.example.com", "api.example.com", "*.internal.example.com", "*", "Bad.Example.COM."} {
+		if _, _, err := tree.Insert(pattern, pattern); err != nil {
+			t.Fatalf("Insert(%q): %v", pattern, err)
+		}
+	}
+	if old, ok, _ := tree.Insert("*", "any"); !ok || old != "*" || tree.Len() != 6 {
+		t.Errorf("replacing a pattern = (%q, %t) with Len %d", old, ok, tree.Len())
+	}
+	for _, pattern := range []string{"", "*.", "*a.com", "a..com"} {
+		if _, _, err := tree.Insert(pattern, ""); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Insert(%q) = %v, want %v", pattern, err, ErrInvalid)
+		}
+	}
+
+	for name, want := range map[string]Match[string]{
+		"example.com":                {"example.com", "example.com"},
+		"EXAMPLE.com.":               {"example.com", "example.com"},
+		"api.example.com":            {"api.example.com", "api.example.com"},
+		"v2.api.example.com":         {"*.example.com", "*.example.com"},
+		"www.example.com":            {"*.example.com", "*.example.com"},
+		"internal.example.com":       {"*.example.com", "*.example.com"},
+		"db.eu.internal.example.com": {"*.internal.example.com", "*.internal.example.com"},
+		"bad.example.com":            {"bad.example.com", "Bad.Example.COM."},
+		"badexample.com":             {"*", "any"},
+		"org":                        {"*", "any"},
+	} {
+		if m, ok, err := tree.Lookup(name); !ok || err != nil || m != want {
+			t.Errorf("Lookup(%q) = (%v, %t, %v), want %v", name, m, ok, err, want)
+		}
+	}
+	if _, _, err := tree.Lookup("a..b"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Lookup of an invalid name = %v", err)
+	}
+
+	var got []string
+	tree.Walk("example.com", func(pattern, _ string) bool {
+		got = append(got, pattern)
+		return true
+	})
+	if want := []string{"example.com", "*.example.com", "api.example.com", "bad.example.com", "*.internal.example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk = %q, want %q", got, want)
+	}
+
+	if v, ok := tree.Remove("*"); !ok || v != "any" {
+		t.Errorf("Remove(%q) = (%q, %t)", "*", v, ok)
+	}
+	if _, ok, _ := tree.Lookup("org"); ok {
+		t.Errorf("Lookup matched after removing the only wildcard")
+	}
+	tree.Remove("example.com")
+	if m, _, _ := tree.Lookup("example.com"); m.Pattern != "" {
+		t.Errorf("Lookup of a removed name matched %q", m.Pattern)
+	}
+	if v, ok := tree.Get("*.example.com"); !ok || v != "*.example.com" || tree.Len() != 4 {
+		t.Errorf("Get of a wildcard = (%q, %t) with Len %d", v, ok, tree.Len())
+	}
+}
@@ -0,0 +1,200 @@
+// Package e164 routes telephone numbers by dialing prefix, the way rate decks
+// and carrier route tables do: records such as rates or trunks are stored for
+// prefixes of E.164 numbers and a number is resolved to the records of the
+// longest prefix that it starts with.
+//
+// Numbers and prefixes are written in many ways, so both are normalized to the
+// digits of the E.164 form, without the leading plus sign, before they are
+// used. Normalize accepts numbers in international form, starting with a plus
+// sign or the 00 or 011 exit codes, and national numbers when given the
+// country code to dial them from.
+package e164
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+// MaxDigits is the greatest number of digits in an E.164 number, including the
+// country code.
+const MaxDigits = 15
+
+// ErrInvalid is returned when a number or prefix cannot be normalized.
+var ErrInvalid = errors.New("e164: invalid number")
+
+// ErrNoRoute is returned by Route when no prefix of a number has an accepted
+// record.
+var ErrNoRoute = errors.New("e164: no route")
+
+// Normalize returns the digits of the E.164 form of number, without the plus
+// sign. Spaces, dots, hyphens, slashes and parentheses are ignored. A number
+// that starts with a plus sign, 00 or, unless dialed from a country other than
+// country code 1, 011 is in international form and its country code follows. Any other number is a national number dialed from the
+// country with the given country code, which is prepended once a single
+// leading trunk prefix 0 is removed; in country code 1, where the trunk prefix
+// is itself 1, a national number of 11 digits is taken to start with the
+// country code. An empty country code means that national numbers are not
+// accepted. The result must have at most MaxDigits digits and must not start
+// with 0, otherwise ErrInvalid is returned.
+func Normalize(number, countryCode string) (string, error) {
+	digits, plus, err := strip(number)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case plus:
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	case strings.HasPrefix(digits, "011") && (countryCode == "1" || countryCode == ""):
+		digits = digits[3:]
+	case countryCode == "":
+		return "", fmt.Errorf("%w: %q is not in international form", ErrInvalid, number)
+	case countryCode == "1" && len(digits) == 11 && digits[0] == '1':
+	default:
+		digits = countryCode + strings.TrimPrefix(digits, "0")
+	}
+	if len(digits) == 0 || len(digits) > MaxDigits || digits[0] == '0' {
+		return "", fmt.Errorf("%w: %q", ErrInvalid, number)
+	}
+	return digits, nil
+}
+
+// strip returns the digits of s, ignoring the separators people write numbers
+// with, and whether s starts with a plus sign.
+func strip(s string) (digits string, plus bool, err error) {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && b.Len() == 0 && !plus:
+			plus = true
+		case strings.ContainsRune(" .-/()", r):
+		default:
+			return "", false, fmt.Errorf("%w: %q has %q at offset %d", ErrInvalid, s, r, i)
+		}
+	}
+	return b.String(), plus, nil
+}
+
+// Match is a record found for a number and the prefix it is stored for.
+type Match[R any] struct {
+	Prefix string
+	Record R
+}
+
+// Router maps dialing prefixes to records and resolves numbers to the records
+// of their longest prefix. Several records may be stored for a prefix, for
+// example the rates of competing carriers, and they are ranked by the function
+// given to NewRouter. A Router is not safe for concurrent use.
+type Router[R any] struct {
+	tree *radixtree.RadixTree[[]R]
+	less func(a, b R) bool
+}
+
+// NewRouter returns an empty router that ranks the records of a prefix with
+// less, so that a record for which less returns true is preferred over the
+// other. Records that rank equally, or all records if less is nil, are
+// preferred in the order in which they were added.
+func NewRouter[R any](less func(a, b R) bool) *Router[R] {
+	return &Router[R]{tree: radixtree.New[[]R](), less: less}
+}
+
+// prefix normalizes a prefix, which is written like a number in international
+// form but may omit the plus sign and be as short as a single digit.
+func prefix(p string) (string, error) {
+	digits, _, err := strip(p)
+	if err != nil {
+		return "", err
+	}
+	if len(digits) == 0 || len(digits) > MaxDigits || digits[0] == '0' {
+		return "", fmt.Errorf("%w: prefix %q", ErrInvalid, p)
+	}
+	return digits, nil
+}
+
+// Add stores a record for the prefix. It returns ErrInvalid if the prefix is not
+// made of up to MaxDigits digits, not starting with 0, after removing a plus
+// sign and separators.
+func (r *Router[R]) Add(p string, record R) error {
+	key, err := prefix(p)
+	if err != nil {
+		return err
+	}
+	records, _ := r.tree.Get([]byte(key))
+	i := len(records)
+	if r.less != nil {
+		i = sort.Search(len(records), func(i int) bool {
+			return r.less(record, records[i])
+		})
+	}
+	records = append(records, record)
+	copy(records[i+1:], records[i:])
+	records[i] = record
+	r.tree.Insert([]byte(key), records)
+	return nil
+}
+
+// Len returns the number of prefixes that have records.
+func (r *Router[R]) Len() int {
+	return r.tree.Len()
+}
+
+// Records returns the records stored for the prefix, best first.
+func (r *Router[R]) Records(p string) []R {
+	key, err := prefix(p)
+	if err != nil {
+		return nil
+	}
+	records, _ := r.tree.Get([]byte(key))
+	return append([]R(nil), records...)
+}
+
+// Remove removes every record stored for the prefix and returns their number.
+func (r *Router[R]) Remove(p string) int {
+	key, err := prefix(p)
+	if err != nil {
+		return 0
+	}
+	records, _ := r.tree.Remove([]byte(key))
+	return len(records)
+}
+
+// Route returns the best record of the longest prefix of number that has one.
+// The number must already be normalized, as by Normalize. It returns
+// ErrNoRoute if no prefix of the number has a record.
+func (r *Router[R]) Route(number string) (Match[R], error) {
+	return r.RouteFunc(number, nil)
+}
+
+// RouteFunc is like Route but only considers the records for which accept, if
+// not nil, returns true, for example to skip carriers that are unavailable. If
+// none of the records of the longest prefix is accepted the shorter prefixes
+// are tried in turn, so that a number falls back from a specific route to a
+// more general one.
+func (r *Router[R]) RouteFunc(number string, accept func(record R) bool) (Match[R], error) {
+	for _, m := range r.RouteAll(number) {
+		if accept == nil || accept(m.Record) {
+			return m, nil
+		}
+	}
+	return Match[R]{}, fmt.Errorf("%w for %q", ErrNoRoute, number)
+}
+
+// RouteAll returns every record for a prefix of number, from the longest
+// prefix to the shortest and, for each prefix, best first. The number must
+// already be normalized, as by Normalize.
+func (r *Router[R]) RouteAll(number string) []Match[R] {
+	var matches []Match[R]
+	for i := len(number); i > 0; i-- {
+		records, _ := r.tree.Get([]byte(number[:i]))
+		for _, record := range records {
+			matches = append(matches, Match[R]{Prefix: number[:i], Record: record})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,95 @@
+package e164
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		number, cc string
+		want       string
+	}{
+		{"+44 20 7946 0958", "", "442079460958"},
+		{"0044 (20) 7946-0958", "", "442079460958"},
+		{"011 44 20 7946 0958", "1", "442079460958"},
+		{"020 7946 0958", "44", "442079460958"},
+		{"(415) 555-2671", "1", "14155552671"},
+		{"1 415 555 2671", "1", "14155552671"},
+		{"+1.415.555.2671", "49", "14155552671"},
+		{"030/1234567", "49", "49301234567"},
+	}
+	for _, test := range tests {
+		if got, err := Normalize(test.number, test.cc); err != nil || got != test.want {
+			t.Errorf("Normalize(%q, %q) = (%q, %v), want %q", test.number, test.cc, got, err, test.want)
+		}
+	}
+
+	for _, number := range []string{"", "+", "020 7946 0958", "+44 20 ext 7", "+0 44", "++44", "+1234567890123456"} {
+		if got, err := Normalize(number, ""); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Normalize(%q) = (%q, %v), want %v", number, got, err, ErrInvalid)
+		}
+	}
+}
+
+type rate struct {
+	carrier string
+	cents   int
+}
+
+func TestRouter(t *testing.T) {
+	r := NewRouter(func(a, b rate) bool { return a.cents < b.cents })
+	for _, add := range []struct {
+		prefix string
+		rate   rate
+	}{
+		{"+1", rate{"a", 2}},
+		{"1 415", rate{"a", 3}},
+		{"1415", rate{"b", 1}},
+		{"1415", rate{"c", 1}},
+		{"44", rate{"a", 5}},
+		{"447", rate{"b", 9}},
+	} {
+		if err := r.Add(add.prefix, add.rate); err != nil {
+			t.Fatalf("Add(%q): %v", add.prefix, err)
+		}
+	}
+	if err := r.Add("0", rate{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Add of an invalid prefix = %v", err)
+	}
+	if r.Len() != 4 {
+		t.Errorf("Len = %d, want 4", r.Len())
+	}
+
+	// The cheapest records win and equal ones keep the order they were
+	// added in.
+	if want := []rate{{"b", 1}, {"c", 1}, {"a", 3}}; !reflect.DeepEqual(r.Records("+1 415"), want) {
+		t.Errorf("Records = %v, want %v", r.Records("+1 415"), want)
+	}
+	if m, err := r.Route("14155552671"); err != nil || m.Prefix != "1415" || m.Record.carrier != "b" {
+		t.Errorf("Route = (%v, %v)", m, err)
+	}
+
+	// Records that are not accepted fall back to shorter prefixes.
+	m, err := r.RouteFunc("14155552671", func(r rate) bool { return r.carrier == "a" && r.cents < 3 })
+	if err != nil || m.Prefix != "1" || m.Record.cents != 2 {
+		t.Errorf("RouteFunc = (%v, %v)", m, err)
+	}
+	if _, err := r.RouteFunc("447700900123", func(r rate) bool { return r.carrier == "c" }); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("RouteFunc with no accepted record = %v, want %v", err, ErrNoRoute)
+	}
+	if got := len(r.RouteAll("447700900123")); got != 2 {
+		t.Errorf("RouteAll found %d records, want 2", got)
+	}
+	if _, err := r.Route("33123456789"); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("Route of an unknown country = %v, want %v", err, ErrNoRoute)
+	}
+
+	if n := r.Remove("1415"); n != 3 {
+		t.Errorf("Remove = %d, want 3", n)
+	}
+	if m, err := r.Route("14155552671"); err != nil || m.Prefix != "1" {
+		t.Errorf("Route after Remove = (%v, %v)", m, err)
+	}
+}
@@ -0,0 +1,77 @@
+package radixtree
+
+import "errors"
+
+// ErrNotFound is returned by the error based lookup methods when the key is not
+// in the tree.
+var ErrNotFound = errors.New("radixtree: key not found")
+
+// ErrModified is the value a traversal panics with when the tree is modified
+// by the traversal's callback before the traversal is complete.
+var ErrModified = errors.New("radixtree: tree modified during iteration")
+
+// ErrOverlap is returned by Join when the key ranges of the trees overlap.
+var ErrOverlap = errors.New("radixtree: key ranges overlap")
+
+// GetErr is like Get but returns ErrNotFound if the key is not in the tree.
+func (t *RadixTree[T]) GetErr(key []byte) (T, error) {
+	v, ok := t.Get(key)
+	if !ok {
+		return v, ErrNotFound
+	}
+	return v, nil
+}
+
+// LongestPrefixErr is like LongestPrefix but returns ErrNotFound if no key in
+// the tree is a prefix of the given key.
+func (t *RadixTree[T]) LongestPrefixErr(key []byte) (T, error) {
+	v, ok := t.LongestPrefix(key)
+	if !ok {
+		return v, ErrNotFound
+	}
+	return v, nil
+}
+
+// RemoveErr is like Remove but returns ErrNotFound if the key is not in the
+// tree.
+func (t *RadixTree[T]) RemoveErr(key []byte) (T, error) {
+	v, ok := t.Remove(key)
+	if !ok {
+		return v, ErrNotFound
+	}
+	return v, nil
+}
+
+// ErrUnsorted is returned by LoadSorted when the keys are not in strictly
+// ascending order.
+var ErrUnsorted = errors.New("radixtree: keys not in ascending order")
+
+// ErrQuotaExceeded is wrapped by the error that InsertErr and the other
+// operations that add keys return, or that Insert and the others panic with,
+// when adding a key would exceed a limit of the tree.
+var ErrQuotaExceeded = errors.New("radixtree: quota exceeded")
+
+// ErrKeyTooLong is wrapped by the error that InsertErr and the other
+// operations that add keys return, or that Insert and the others panic with,
+// when a key is longer than the limit set by WithMaxKeyLen.
+var ErrKeyTooLong = errors.New("radixtree: key too long")
+
+// ErrCorrupt is returned by ImportCompact, RestoreInto, Bloom.UnmarshalBinary,
+// OpenMapped and the methods of Mapped when their input is not a valid export,
+// backup or file.
+var ErrCorrupt = errors.New("radixtree: corrupt input")
+
+// ErrFrozen is returned by the mutation methods of a Frozen tree.
+var ErrFrozen = errors.New("radixtree: tree is frozen")
+
+// ErrWrongType is returned by the typed accessors of AnyTree when the value of
+// a key is not of the requested type.
+var ErrWrongType = errors.New("radixtree: value has the wrong type")
+
+// ErrRemovalsDiscarded is returned by BackupSince when the removals made since
+// the generation of the backup are no longer recorded.
+var ErrRemovalsDiscarded = errors.New("radixtree: removals since generation not recorded")
+
+// ErrCorruptValue is returned by the methods of CodecTree when a stored value
+// cannot be decompressed.
+var ErrCorruptValue = errors.New("radixtree: corrupt encoded value")
@@ -0,0 +1,39 @@
+package radixtree
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetErr(t *testing.T) {
+	tree := build(words)
+
+	if got, err := tree.GetErr([]byte("toad")); err != nil || got != "toad" {
+		t.Errorf("GetErr(toad)\n got: (%s, %v)\nwant: (toad, <nil>)", got, err)
+	}
+	if got, err := tree.GetErr([]byte("aard")); !errors.Is(err, ErrNotFound) || got != "" {
+		t.Errorf("GetErr(aard)\n got: (%s, %v)\nwant: (\"\", %v)", got, err, ErrNotFound)
+	}
+}
+
+func TestLongestPrefixErr(t *testing.T) {
+	tree := build(words)
+
+	if got, err := tree.LongestPrefixErr([]byte("winkley")); err != nil || got != "winkle" {
+		t.Errorf("LongestPrefixErr(winkley)\n got: (%s, %v)\nwant: (winkle, <nil>)", got, err)
+	}
+	if got, err := tree.LongestPrefixErr([]byte("zebra")); !errors.Is(err, ErrNotFound) || got != "" {
+		t.Errorf("LongestPrefixErr(zebra)\n got: (%s, %v)\nwant: (\"\", %v)", got, err, ErrNotFound)
+	}
+}
+
+func TestRemoveErr(t *testing.T) {
+	tree := build(words)
+
+	if got, err := tree.RemoveErr([]byte("toad")); err != nil || got != "toad" {
+		t.Errorf("RemoveErr(toad)\n got: (%s, %v)\nwant: (toad, <nil>)", got, err)
+	}
+	if got, err := tree.RemoveErr([]byte("toad")); !errors.Is(err, ErrNotFound) || got != "" {
+		t.Errorf("RemoveErr(toad) twice\n got: (%s, %v)\nwant: (\"\", %v)", got, err, ErrNotFound)
+	}
+}
@@ -23,6 +23,31 @@ func ExampleRadixTree_Find() {
 	// [1 2]
 }
 
+func ExampleRadixTree_FindIter() {
+	t := New[int]()
+	t.Insert([]byte("apple"), 1)
+	t.Insert([]byte("apricot"), 2)
+	t.Insert([]byte("avocado"), 3)
+	for it := t.FindIter([]byte("ap")); it.Next(); {
+		fmt.Println(string(it.Key()), it.Value())
+	}
+	// Output:
+	// apple 1
+	// apricot 2
+}
+
+func ExampleRadixTree_FindN() {
+	t := New[int]()
+	t.Insert([]byte("John"), 1)
+	t.Insert([]byte("Jonathan"), 2)
+	t.Insert([]byte("Jonas"), 3)
+
+	vs := t.FindN([]byte("Jo"), 2)
+	fmt.Println(vs)
+	// Output:
+	// [1 3]
+}
+
 func ExampleRadixTree_Get() {
 	t := New[int]()
 	t.Insert([]byte("John"), 1)
@@ -132,3 +157,19 @@ func ExampleRadixTree_Values() {
 	// Output:
 	// [1 2 0]
 }
+
+func ExampleRadixTree_WalkDepth() {
+	t := New[int]()
+	t.Insert([]byte("a"), 1)
+	t.Insert([]byte("ab"), 2)
+	t.Insert([]byte("abc"), 3)
+	t.Insert([]byte("ac"), 4)
+	t.WalkDepth([]byte("a"), 1, func(key []byte, value int) bool {
+		fmt.Println(string(key), value)
+		return true
+	})
+	// Output:
+	// a 1
+	// ab 2
+	// ac 4
+}
@@ -0,0 +1,180 @@
+package radixtree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// compactMagic starts every compact export, followed by the format version.
+var compactMagic = []byte("RDXC\x01")
+
+// ExportCompact writes every entry of the tree to w in a compact format. The
+// keys are written first, in ascending order and front coded: each key is the
+// length of the prefix it shares with the previous key followed by the rest of
+// its bytes. The values follow as a separate stream in the same order, each
+// encoded by encode and prefixed with its length. Keys in a tree usually share
+// long prefixes so front coding makes the export much smaller than the keys
+// themselves. The tree is read twice so it must not be modified during the
+// export.
+func (t *RadixTree[T]) ExportCompact(w io.Writer, encode func(value T) ([]byte, error)) error {
+	return t.ExportPrefix(nil, w, encode)
+}
+
+// ExportPrefix is like ExportCompact for the entries whose keys start with the
+// given prefix, such as those of a single tenant, and writes their keys
+// relative to the prefix so that ImportAt can place them under any prefix of
+// another tree. ImportCompact reads the export as if the prefix were empty.
+func (t *RadixTree[T]) ExportPrefix(prefix []byte, w io.Writer, encode func(value T) ([]byte, error)) error {
+	bw := bufio.NewWriter(w)
+	var buf [binary.MaxVarintLen64]byte
+	uvarint := func(x uint64) {
+		bw.Write(buf[:binary.PutUvarint(buf[:], x)])
+	}
+
+	bw.Write(compactMagic)
+	n, key := t.findPath(prefix, t.borrow())
+	if n == nil {
+		t.release(key)
+		uvarint(0)
+		return bw.Flush()
+	}
+	uvarint(uint64(n.count))
+	var prev []byte
+	key, _ = walkNodes(n, key, -1, func(key []byte, _ *node[T]) bool {
+		key = key[len(prefix):]
+		shared := longestCommonPrefix(prev, key)
+		uvarint(uint64(shared))
+		uvarint(uint64(len(key) - shared))
+		bw.Write(key[shared:])
+		prev = append(prev[:0], key...)
+		return true
+	})
+	t.release(key)
+
+	var err error
+	walk(n, func(value T) bool {
+		var b []byte
+		if b, err = encode(value); err != nil {
+			return false
+		}
+		uvarint(uint64(len(b)))
+		bw.Write(b)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ImportCompact inserts the entries written by ExportCompact, decoding each
+// value with decode. The entries are loaded with the same bulk loader as
+// LoadSorted so the keys must be in ascending order in the byte order of the
+// tree, which they are if it matches the order of the exported tree. Values for
+// keys already in the tree replace the existing ones. If the input is
+// malformed ErrCorrupt is returned and, as for an error from decode or for a
+// key that would exceed a limit of the tree, which is reported as by
+// InsertErr, none of the entries are added.
+func (t *RadixTree[T]) ImportCompact(r io.Reader, decode func(b []byte) (T, error)) error {
+	return t.ImportAt(nil, r, decode)
+}
+
+// ImportAt is like ImportCompact but inserts each entry under the given prefix,
+// so that an export of a prefix by ExportPrefix can be moved to the same or
+// another prefix. Entries already in the tree below the prefix are kept unless
+// the import replaces their values.
+func (t *RadixTree[T]) ImportAt(prefix []byte, r io.Reader, decode func(b []byte) (T, error)) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(compactMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || !bytes.Equal(magic, compactMagic) {
+		return corrupt(err)
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return corrupt(err)
+	}
+
+	// The subtree is only merged into the tree once every value is read.
+	var zero T
+	l := newLoader(t)
+	fail := func(err error) error {
+		t.freeAll(l.root)
+		return err
+	}
+	leaves := make([]*leaf[T], 0, int(minUint64(count, 1<<20)))
+	key := append([]byte(nil), prefix...)
+	for i := uint64(0); i < count; i++ {
+		shared, err := binary.ReadUvarint(br)
+		if err != nil || shared > uint64(len(key)-len(prefix)) {
+			return fail(corrupt(err))
+		}
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return fail(corrupt(err))
+		}
+		key = key[:len(prefix)+int(shared)]
+		if key, err = readN(br, key, n); err != nil {
+			return fail(corrupt(err))
+		}
+		if l.size > 0 && t.opts.order.compare(key, l.prev) <= 0 {
+			return fail(fmt.Errorf("radixtree: entry %d: %w", i, ErrUnsorted))
+		}
+		if err := l.check(key); err != nil {
+			return fail(fmt.Errorf("radixtree: entry %d: %w", i, err))
+		}
+		leaves = append(leaves, l.add(key, zero))
+	}
+
+	var b []byte
+	for _, lf := range leaves {
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return fail(corrupt(err))
+		}
+		if b, err = readN(br, b[:0], n); err != nil {
+			return fail(corrupt(err))
+		}
+		if lf.value, err = decode(b); err != nil {
+			return fail(err)
+		}
+	}
+
+	t.updateAll(l.root)
+	l.merge()
+	return nil
+}
+
+// corrupt returns ErrCorrupt annotated with the error reading the input, if
+// any.
+func corrupt(err error) error {
+	if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrCorrupt
+	}
+	return fmt.Errorf("%w: %v", ErrCorrupt, err)
+}
+
+// readN appends n bytes read from r to buf.
+func readN(r io.Reader, buf []byte, n uint64) ([]byte, error) {
+	// Grow the buffer as bytes arrive so a corrupt length cannot force a
+	// huge allocation.
+	for n > 0 {
+		chunk := minUint64(n, 64<<10)
+		l := len(buf)
+		buf = append(buf, make([]byte, chunk)...)
+		if _, err := io.ReadFull(r, buf[l:]); err != nil {
+			return buf, err
+		}
+		n -= chunk
+	}
+	return buf, nil
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
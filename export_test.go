@@ -0,0 +1,135 @@
+package radixtree
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func encodeString(s string) ([]byte, error) {
+	return []byte(s), nil
+}
+
+func decodeString(b []byte) (string, error) {
+	return string(b), nil
+}
+
+func TestExportCompact(t *testing.T) {
+	tree := New[string](WithAggregate("", concat, identity))
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	tree.Insert(nil, "")
+
+	var buf bytes.Buffer
+	if err := tree.ExportCompact(&buf, encodeString); err != nil {
+		t.Fatalf("ExportCompact returned error %v", err)
+	}
+
+	got := New[string](WithAggregate("", concat, identity))
+	got.Insert([]byte("macro"), "old")
+	got.Insert([]byte("zebra"), "zebra")
+	if err := got.ImportCompact(bytes.NewReader(buf.Bytes()), decodeString); err != nil {
+		t.Fatalf("ImportCompact returned error %v", err)
+	}
+	verify(t, got)
+	checkAggregates(t, "ImportCompact", got, words)
+	want := append(append([]string{""}, words...), "zebra")
+	if values := got.Values(); !reflect.DeepEqual(values, want) {
+		t.Errorf("Values after ImportCompact\n got: %v\nwant: %v", values, want)
+	}
+
+	buf.Reset()
+	if err := New[int]().ExportCompact(&buf, func(int) ([]byte, error) { return nil, nil }); err != nil {
+		t.Fatalf("ExportCompact of an empty tree returned error %v", err)
+	}
+	empty := New[int]()
+	if err := empty.ImportCompact(&buf, func([]byte) (int, error) { return 0, nil }); err != nil || empty.Len() != 0 {
+		t.Errorf("ImportCompact of an empty export = %v with %d keys", err, empty.Len())
+	}
+}
+
+func TestExportCompactSize(t *testing.T) {
+	tree := New[int]()
+	raw := 0
+	for i := 0; i < 1000; i++ {
+		key := "/users/profiles/" + strconv.Itoa(100000+i)
+		tree.Insert([]byte(key), i)
+		raw += len(key)
+	}
+	var buf bytes.Buffer
+	tree.ExportCompact(&buf, func(int) ([]byte, error) { return nil, nil })
+	if buf.Len() > raw/3 {
+		t.Errorf("export is %d bytes, want at most a third of the %d bytes of keys", buf.Len(), raw)
+	}
+}
+
+func TestImportCompactErrors(t *testing.T) {
+	tree := New[int]()
+	for i := 0; i < 100; i++ {
+		tree.Insert([]byte("key"+strconv.Itoa(i)), i)
+	}
+	var buf bytes.Buffer
+	tree.ExportCompact(&buf, func(v int) ([]byte, error) {
+		return []byte(strconv.Itoa(v)), nil
+	})
+	decode := func(b []byte) (int, error) {
+		return strconv.Atoi(string(b))
+	}
+
+	for _, n := range []int{0, 3, 10, buf.Len() / 2, buf.Len() - 1} {
+		got := New[int]()
+		if err := got.ImportCompact(bytes.NewReader(buf.Bytes()[:n]), decode); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("ImportCompact of %d bytes returned %v, want %v", n, err, ErrCorrupt)
+		}
+		if got.Len() != 0 {
+			t.Errorf("ImportCompact of %d bytes added %d keys", n, got.Len())
+		}
+	}
+
+	if err := New[int]().ImportCompact(strings.NewReader("not an export"), decode); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("ImportCompact of garbage returned %v, want %v", err, ErrCorrupt)
+	}
+
+	errDecode := errors.New("decode failed")
+	got := New[int]()
+	if err := got.ImportCompact(bytes.NewReader(buf.Bytes()), func([]byte) (int, error) { return 0, errDecode }); err != errDecode || got.Len() != 0 {
+		t.Errorf("ImportCompact with a failing decode = %v with %d keys, want %v", err, got.Len(), errDecode)
+	}
+
+	errEncode := errors.New("encode failed")
+	if err := tree.ExportCompact(&buf, func(int) ([]byte, error) { return nil, errEncode }); err != errEncode {
+		t.Errorf("ExportCompact with a failing encode returned %v, want %v", err, errEncode)
+	}
+}
+
+func TestExportPrefix(t *testing.T) {
+	tree := build(words)
+	for _, prefix := range []string{"macro", "wink", "aardv", "toad", "x", ""} {
+		var buf bytes.Buffer
+		if err := tree.ExportPrefix([]byte(prefix), &buf, encodeString); err != nil {
+			t.Fatalf("ExportPrefix(%q) = %v", prefix, err)
+		}
+		got := build([]string{"tenant/a/" + prefix + "old", "tenant/b"})
+		if err := got.ImportAt([]byte("tenant/a/"), bytes.NewReader(buf.Bytes()), decodeString); err != nil {
+			t.Fatalf("ImportAt after ExportPrefix(%q) = %v", prefix, err)
+		}
+		verify(t, got)
+		want := map[string]string{"tenant/a/" + prefix + "old": "tenant/a/" + prefix + "old", "tenant/b": "tenant/b"}
+		for _, w := range words {
+			if strings.HasPrefix(w, prefix) {
+				want["tenant/a/"+w[len(prefix):]] = w
+			}
+		}
+		m := map[string]string{}
+		for k, v := range got.All() {
+			m[string(k)] = v
+		}
+		if !reflect.DeepEqual(m, want) {
+			t.Errorf("ImportAt after ExportPrefix(%q)\n got: %v\nwant: %v", prefix, m, want)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package radixtree
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Frozen is an immutable radix tree created by Freeze. Its nodes are stored
+// contiguously in breadth first order, so that the children of a node are
+// adjacent, and their prefixes are stored in a single buffer. This makes
+// lookups touch fewer cache lines than in a RadixTree and guarantees that the
+// tree cannot change. A Frozen tree is safe for concurrent use.
+type Frozen[T any] struct {
+	nodes    []frozenNode
+	prefixes []byte
+	values   []T
+	order    *byteOrder
+}
+
+// frozenNode is a node of a Frozen tree. Its prefix is prefixes[prefix:end],
+// its children are nodes[children:children+numChildren] and its value, if any,
+// is values[value-1].
+type frozenNode struct {
+	prefix      uint32
+	end         uint32
+	children    uint32
+	numChildren uint32
+	value       uint32
+}
+
+// Freeze returns an immutable copy of the tree in a layout optimised for
+// reading. The tree itself is not changed. The frozen tree orders keys in the
+// byte order of the tree.
+func (t *RadixTree[T]) Freeze() *Frozen[T] {
+	f := &Frozen[T]{
+		nodes:  make([]frozenNode, 0, t.nodeCount()),
+		values: make([]T, 0, t.size),
+		order:  t.opts.order,
+	}
+	queue := []*node[T]{t.root}
+	f.nodes = append(f.nodes, frozenNode{})
+	for i := 0; i < len(queue); i++ {
+		n := queue[i]
+		fn := &f.nodes[i]
+		fn.prefix = uint32(len(f.prefixes))
+		f.prefixes = append(f.prefixes, n.prefix...)
+		fn.end = uint32(len(f.prefixes))
+		if n.hasValue() {
+			f.values = append(f.values, n.leaf.value)
+			fn.value = uint32(len(f.values))
+		}
+		fn.children = uint32(len(queue))
+		for _, child := range n.children.nodes {
+			// Subtrees left without values by lazy deletion are
+			// dropped.
+			if child.count > 0 {
+				queue = append(queue, child)
+				f.nodes = append(f.nodes, frozenNode{})
+				fn = &f.nodes[i]
+				fn.numChildren++
+			}
+		}
+	}
+	return f
+}
+
+// nodeCount returns the number of nodes in the tree.
+func (t *RadixTree[T]) nodeCount() int {
+	var count func(n *node[T]) int
+	count = func(n *node[T]) int {
+		c := 1
+		for _, child := range n.children.nodes {
+			if child.count > 0 {
+				c += count(child)
+			}
+		}
+		return c
+	}
+	return count(t.root)
+}
+
+// Contains returns true if key is in the tree, false otherwise.
+func (f *Frozen[T]) Contains(key []byte) bool {
+	_, ok := f.Get(key)
+	return ok
+}
+
+// Find returns the values whose keys start with the given prefix in ascending
+// key order.
+func (f *Frozen[T]) Find(prefix []byte) []T {
+	var values []T
+	f.Walk(prefix, func(value T) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// Get returns the value associated with the given key and true, or the zero
+// value for type T and false if the key is not in the tree.
+func (f *Frozen[T]) Get(key []byte) (T, bool) {
+	n := &f.nodes[0]
+	for len(key) > 0 {
+		if n = f.child(n, key[0]); n == nil || !bytes.HasPrefix(key, f.prefix(n)) {
+			var zero T
+			return zero, false
+		}
+		key = key[n.end-n.prefix:]
+	}
+	return f.value(n)
+}
+
+// HasPrefix returns true if any key in the tree starts with the given prefix.
+func (f *Frozen[T]) HasPrefix(prefix []byte) bool {
+	n, _ := f.seek(prefix, nil)
+	return n != nil && (n.value != 0 || n.numChildren > 0)
+}
+
+// Insert always returns ErrFrozen since a Frozen tree cannot be modified.
+func (f *Frozen[T]) Insert(key []byte, value T) error {
+	return ErrFrozen
+}
+
+// InsertErr always returns ErrFrozen since a Frozen tree cannot be modified.
+func (f *Frozen[T]) InsertErr(key []byte, value T) (T, bool, error) {
+	var zero T
+	return zero, false, ErrFrozen
+}
+
+// InsertManyErr always returns ErrFrozen since a Frozen tree cannot be
+// modified.
+func (f *Frozen[T]) InsertManyErr(entries []Entry[T]) (int, error) {
+	return 0, ErrFrozen
+}
+
+// Len returns the number of values in the tree.
+func (f *Frozen[T]) Len() int {
+	return len(f.values)
+}
+
+// LongestPrefix returns the value associated with the longest key that is a
+// prefix of the given key and true, or the zero value for type T and false if
+// there is no such key.
+func (f *Frozen[T]) LongestPrefix(key []byte) (T, bool) {
+	n := &f.nodes[0]
+	last := n.value
+	for len(key) > 0 {
+		if n = f.child(n, key[0]); n == nil || !bytes.HasPrefix(key, f.prefix(n)) {
+			break
+		}
+		if n.value != 0 {
+			last = n.value
+		}
+		key = key[n.end-n.prefix:]
+	}
+	if last == 0 {
+		var zero T
+		return zero, false
+	}
+	return f.values[last-1], true
+}
+
+// Remove always returns ErrFrozen since a Frozen tree cannot be modified.
+func (f *Frozen[T]) Remove(key []byte) error {
+	return ErrFrozen
+}
+
+// RemoveErr always returns ErrFrozen since a Frozen tree cannot be modified.
+func (f *Frozen[T]) RemoveErr(key []byte) (T, error) {
+	var zero T
+	return zero, ErrFrozen
+}
+
+// RemoveManyErr always returns ErrFrozen since a Frozen tree cannot be
+// modified.
+func (f *Frozen[T]) RemoveManyErr(keys [][]byte) (int, error) {
+	return 0, ErrFrozen
+}
+
+// Max returns the value associated with the largest key in the tree. The
+// boolean return value will be false if the tree is empty.
+func (f *Frozen[T]) Max() (T, bool) {
+	n := &f.nodes[0]
+	for n.numChildren > 0 {
+		n = &f.nodes[n.children+n.numChildren-1]
+	}
+	return f.value(n)
+}
+
+// Min returns the value associated with the smallest key in the tree. The
+// boolean return value will be false if the tree is empty.
+func (f *Frozen[T]) Min() (T, bool) {
+	n := &f.nodes[0]
+	for n.value == 0 && n.numChildren > 0 {
+		n = &f.nodes[n.children]
+	}
+	return f.value(n)
+}
+
+// Walk executes function fn for each value whose key starts with the given
+// prefix in ascending key order. If fn returns true the traversal continues
+// otherwise the traversal stops.
+func (f *Frozen[T]) Walk(prefix []byte, fn func(value T) bool) {
+	if n, key := f.seek(prefix, nil); n != nil {
+		f.walk(n, key, -1, func(_ []byte, n *frozenNode) bool {
+			return fn(f.values[n.value-1])
+		})
+	}
+}
+
+// WalkDepth executes function fn for each key that starts with the given
+// prefix and is at most depth bytes longer than it, and its value, in
+// ascending key order. The key passed to fn is a copy that the caller may
+// retain. If fn returns true the traversal continues otherwise the traversal
+// stops.
+func (f *Frozen[T]) WalkDepth(prefix []byte, depth int, fn func(key []byte, value T) bool) {
+	if n, key := f.seek(prefix, nil); n != nil && depth >= 0 {
+		f.walk(n, key, len(prefix)+depth, func(key []byte, n *frozenNode) bool {
+			return fn(append([]byte(nil), key...), f.values[n.value-1])
+		})
+	}
+}
+
+// WalkKeys executes function fn for each key that starts with the given prefix
+// in ascending order. The key passed to fn is a copy that the caller may
+// retain. If fn returns true the traversal continues otherwise the traversal
+// stops.
+func (f *Frozen[T]) WalkKeys(prefix []byte, fn func(key []byte) bool) {
+	if n, key := f.seek(prefix, nil); n != nil {
+		f.walk(n, key, -1, func(key []byte, _ *frozenNode) bool {
+			return fn(append([]byte(nil), key...))
+		})
+	}
+}
+
+// walk is like walkNodes.
+func (f *Frozen[T]) walk(n *frozenNode, key []byte, limit int, fn func(key []byte, n *frozenNode) bool) ([]byte, bool) {
+	if limit >= 0 && len(key) > limit {
+		return key, true
+	}
+	if n.value != 0 && !fn(key, n) {
+		return key, false
+	}
+	for i := n.children; i < n.children+n.numChildren; i++ {
+		child := &f.nodes[i]
+		l := len(key)
+		if limit >= 0 && l+int(child.end-child.prefix) > limit {
+			continue
+		}
+		var ok bool
+		key, ok = f.walk(child, append(key, f.prefix(child)...), limit, fn)
+		key = key[:l]
+		if !ok {
+			return key, false
+		}
+	}
+	return key, true
+}
+
+// seek is like RadixTree.findPath.
+func (f *Frozen[T]) seek(prefix, buf []byte) (*frozenNode, []byte) {
+	n := &f.nodes[0]
+	for len(prefix) > 0 {
+		child := f.child(n, prefix[0])
+		if child == nil {
+			return nil, buf
+		}
+		p := f.prefix(child)
+		buf = append(buf, p...)
+		if len(prefix) <= len(p) {
+			if !bytes.HasPrefix(p, prefix) {
+				return nil, buf
+			}
+			return child, buf
+		}
+		if !bytes.HasPrefix(prefix, p) {
+			return nil, buf
+		}
+		n = child
+		prefix = prefix[len(p):]
+	}
+	return n, buf
+}
+
+// value returns the value of n and true, or the zero value for type T and false
+// if n has no value.
+func (f *Frozen[T]) value(n *frozenNode) (T, bool) {
+	if n.value == 0 {
+		var zero T
+		return zero, false
+	}
+	return f.values[n.value-1], true
+}
+
+func (f *Frozen[T]) prefix(n *frozenNode) []byte {
+	return f.prefixes[n.prefix:n.end:n.end]
+}
+
+// child returns the child of n whose prefix starts with b, or nil.
+func (f *Frozen[T]) child(n *frozenNode, b byte) *frozenNode {
+	children := f.nodes[n.children : n.children+n.numChildren]
+	i := sort.Search(len(children), func(i int) bool {
+		c := f.prefixes[children[i].prefix]
+		return c == b || !f.order.less(c, b)
+	})
+	if i < len(children) && f.prefixes[children[i].prefix] == b {
+		return &children[i]
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	tree := build(words)
+	tree.Insert(nil, "root")
+	f := tree.Freeze()
+
+	if got, want := f.Len(), tree.Len(); got != want {
+		t.Errorf("Len = %d, want %d", got, want)
+	}
+	for _, key := range append(words, "") {
+		want, _ := tree.Get([]byte(key))
+		if got, ok := f.Get([]byte(key)); !ok || got != want {
+			t.Errorf("Get(%q) = (%q, %t), want (%q, true)", key, got, ok, want)
+		}
+	}
+	for _, key := range []string{"aard", "toadstool", "x", "wi"} {
+		if f.Contains([]byte(key)) {
+			t.Errorf("Contains(%q) = true, want false", key)
+		}
+	}
+	for _, key := range []string{"toadstool", "winkles", "xyz", "macroanalysis"} {
+		want, _ := tree.LongestPrefix([]byte(key))
+		if got, ok := f.LongestPrefix([]byte(key)); !ok || got != want {
+			t.Errorf("LongestPrefix(%q) = (%q, %t), want (%q, true)", key, got, ok, want)
+		}
+	}
+	for _, prefix := range []string{"", "mac", "macroa", "toad", "wi", "x", "tob"} {
+		if got, want := f.Find([]byte(prefix)), tree.Find([]byte(prefix)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Find(%q)\n got: %v\nwant: %v", prefix, got, want)
+		}
+		if got, want := f.HasPrefix([]byte(prefix)), tree.HasPrefix([]byte(prefix)); got != want {
+			t.Errorf("HasPrefix(%q) = %t, want %t", prefix, got, want)
+		}
+	}
+
+	var keys []string
+	f.WalkDepth([]byte("to"), 3, func(key []byte, value string) bool {
+		if string(key) != value {
+			t.Errorf("WalkDepth passed key %q with value %q", key, value)
+		}
+		keys = append(keys, string(key))
+		return len(keys) < 3
+	})
+	if want := []string{"to", "toa", "toad"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("WalkDepth\n got: %v\nwant: %v", keys, want)
+	}
+	for _, depth := range []int{0, 1, 2, 5} {
+		var got, want []string
+		f.WalkDepth([]byte("toa"), depth, func(key []byte, _ string) bool {
+			got = append(got, string(key))
+			return true
+		})
+		tree.WalkDepth([]byte("toa"), depth, func(key []byte, _ string) bool {
+			want = append(want, string(key))
+			return true
+		})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("WalkDepth(%q, %d)\n got: %v\nwant: %v", "toa", depth, got, want)
+		}
+	}
+	keys = nil
+	f.WalkKeys([]byte("wi"), func(key []byte) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	if want := hasPrefix("wi", words); !reflect.DeepEqual(keys, want) {
+		t.Errorf("WalkKeys\n got: %v\nwant: %v", keys, want)
+	}
+	if got, ok := f.Min(); !ok || got != "root" {
+		t.Errorf("Min = (%q, %t), want (%q, true)", got, ok, "root")
+	}
+	want, _ := tree.Max()
+	if got, ok := f.Max(); !ok || got != want {
+		t.Errorf("Max = (%q, %t), want (%q, true)", got, ok, want)
+	}
+
+	if err := f.Insert([]byte("a"), "a"); err != ErrFrozen {
+		t.Errorf("Insert returned %v, want %v", err, ErrFrozen)
+	}
+	if err := f.Remove([]byte("toad")); err != ErrFrozen {
+		t.Errorf("Remove returned %v, want %v", err, ErrFrozen)
+	}
+
+	// The frozen tree does not change with the original.
+	tree.Remove([]byte("toad"))
+	if !f.Contains([]byte("toad")) {
+		t.Errorf("removing a key from the tree removed it from the frozen tree")
+	}
+
+	empty := New[int]().Freeze()
+	if _, ok := empty.Get(nil); ok || empty.Len() != 0 || empty.HasPrefix(nil) {
+		t.Errorf("empty frozen tree has entries")
+	}
+	if _, ok := empty.Min(); ok {
+		t.Errorf("empty frozen tree has a minimum")
+	}
+	if _, ok := empty.Max(); ok {
+		t.Errorf("empty frozen tree has entries")
+	}
+}
+
+func TestFreezeByteOrder(t *testing.T) {
+	f := buildCaseless(caselessKeys).Freeze()
+	if got := f.Find(nil); !reflect.DeepEqual(got, caselessKeys) {
+		t.Errorf("Find\n got: %v\nwant: %v", got, caselessKeys)
+	}
+	for _, key := range caselessKeys {
+		if !f.Contains([]byte(key)) {
+			t.Errorf("Contains(%q) = false", key)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package radixtree
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FS presents the keys of a tree as the paths of a read-only file system, so
+// that a tree can be served by http.FileServer through http.FS or read by
+// template loaders and anything else that accepts an fs.FS. A key separated by
+// slashes, such as "static/css/site.css", is a file whose contents come from
+// its value, and every prefix of a key ending before a slash is a directory.
+// Keys that are not valid paths by fs.ValidPath, such as those starting with a
+// slash or with empty elements, cannot be opened and are left out of their
+// directories, and a key that is also a directory of other keys is opened as a
+// file. The tree must not be modified while the FS is in use, so a Frozen tree
+// or a snapshot that is no longer written is best suited.
+type FS[T any] struct {
+	r    Reader[T]
+	data func(value T) []byte
+}
+
+var (
+	_ fs.ReadFileFS = (*FS[[]byte])(nil)
+	_ fs.ReadDirFS  = (*FS[[]byte])(nil)
+	_ fs.StatFS     = (*FS[[]byte])(nil)
+)
+
+// NewFS returns a file system over the keys of r in which the contents of a
+// file are data applied to the value of its key. The contents must not be
+// modified.
+func NewFS[T any](r Reader[T], data func(value T) []byte) *FS[T] {
+	return &FS[T]{r: r, data: data}
+}
+
+// BytesFS returns a file system over the keys of a tree whose values are the
+// contents of the files.
+func BytesFS(r Reader[[]byte]) *FS[[]byte] {
+	return NewFS(r, func(value []byte) []byte { return value })
+}
+
+// Open opens the named file or directory.
+func (f *FS[T]) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name != "." {
+		if v, ok := f.r.Get([]byte(name)); ok {
+			data := f.data(v)
+			return &fsFile{info: fileInfo{name: base(name), size: int64(len(data))}, Reader: bytes.NewReader(data)}, nil
+		}
+	}
+	entries, ok := f.list(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &fsDir{info: fileInfo{name: base(name), dir: true}, path: name, entries: entries}, nil
+}
+
+// ReadFile returns the contents of the named file.
+func (f *FS[T]) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+	if v, ok := f.r.Get([]byte(name)); ok && name != "." {
+		return bytes.Clone(f.data(v)), nil
+	}
+	if _, ok := f.list(name); ok {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: errIsDir}
+	}
+	return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir returns the entries of the named directory sorted by name.
+func (f *FS[T]) ReadDir(name string) ([]fs.DirEntry, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.Unwrap(err)}
+	}
+	d, ok := file.(*fsDir)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errNotDir}
+	}
+	return d.entries, nil
+}
+
+// Stat returns information about the named file or directory.
+func (f *FS[T]) Stat(name string) (fs.FileInfo, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: errors.Unwrap(err)}
+	}
+	return file.Stat()
+}
+
+// list returns the entries of the named directory sorted by name and true, or
+// false if it is not a directory. The keys below the directory are all walked
+// to find its entries, so the cost is proportional to their number.
+func (f *FS[T]) list(name string) ([]fs.DirEntry, bool) {
+	var prefix []byte
+	if name != "." {
+		prefix = []byte(name + "/")
+	}
+	entries := map[string]fileInfo{}
+	found := false
+	f.r.WalkKeys(prefix, func(key []byte) bool {
+		if !fs.ValidPath(string(key)) {
+			return true
+		}
+		found = true
+		elem, _, isDir := strings.Cut(string(key[len(prefix):]), "/")
+		if _, seen := entries[elem]; !seen || !isDir {
+			info := fileInfo{name: elem, dir: isDir}
+			if !isDir {
+				v, _ := f.r.Get(key)
+				info.size = int64(len(f.data(v)))
+			}
+			entries[elem] = info
+		}
+		return true
+	})
+	if !found && name != "." {
+		return nil, false
+	}
+	list := make([]fs.DirEntry, 0, len(entries))
+	for _, info := range entries {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list, true
+}
+
+// base returns the last element of a valid path.
+func base(name string) string {
+	return name[strings.LastIndexByte(name, '/')+1:]
+}
+
+var (
+	errIsDir  = errors.New("is a directory")
+	errNotDir = errors.New("not a directory")
+)
+
+// fileInfo describes a file or directory of an FS, as both an fs.FileInfo
+// and an fs.DirEntry.
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return fi.dir }
+func (fi fileInfo) Sys() any           { return nil }
+
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+func (fi fileInfo) Type() fs.FileMode          { return fi.Mode().Type() }
+func (fi fileInfo) Info() (fs.FileInfo, error) { return fi, nil }
+func (fi fileInfo) String() string             { return fs.FormatDirEntry(fi) }
+
+// fsFile is an open file of an FS. It can also seek and read at an offset, as
+// http.FileServer needs to serve ranges.
+type fsFile struct {
+	info fileInfo
+	*bytes.Reader
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *fsFile) Close() error               { return nil }
+
+// fsDir is an open directory of an FS.
+type fsDir struct {
+	info    fileInfo
+	path    string
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *fsDir) Close() error               { return nil }
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: errIsDir}
+}
+
+// ReadDir returns the next n entries of the directory, or all the remaining
+// ones if n is not positive, as specified by fs.ReadDirFile.
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
@@ -0,0 +1,41 @@
+package radixtree
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFS(t *testing.T) {
+	tree := New[[]byte]()
+	for _, key := range []string{"index.html", "static/css/site.css", "static/js/app.js", "static/logo.png", "docs", "docs/intro.md", "/absolute", "bad//path", "static/../up"} {
+		tree.Insert([]byte(key), []byte("contents of "+key))
+	}
+	fsys := BytesFS(tree.Freeze())
+	if err := fstest.TestFS(fsys, "index.html", "static/css/site.css", "static/js/app.js", "static/logo.png", "docs"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fs.ReadFile(fsys, "static/js/app.js")
+	if err != nil || string(data) != "contents of static/js/app.js" {
+		t.Errorf("ReadFile = %q, %v, want the value of the key", data, err)
+	}
+	var names []string
+	entries, _ := fs.ReadDir(fsys, ".")
+	for _, e := range entries {
+		names = append(names, fs.FormatDirEntry(e))
+	}
+	if want := []string{"- docs", "- index.html", "d static/"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ReadDir(.) = %q, want %q", names, want)
+	}
+	for _, name := range []string{"static/css/missing.css", "docs/intro.md/x", "/absolute", "bad", "static/../up", "stat"} {
+		if _, err := fsys.Open(name); err == nil {
+			t.Errorf("Open(%q) succeeded, want an error", name)
+		}
+	}
+	if _, err := fsys.Open("static/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open of a missing file = %v, want ErrNotExist", err)
+	}
+}
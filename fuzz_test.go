@@ -0,0 +1,58 @@
+package radixtree
+
+import (
+	"testing"
+
+	"github.com/jhm/go-radixtree/v2/testutil"
+)
+
+// FuzzTree applies random sequences of operations to trees with different
+// options and compares them with the reference model of testutil.
+func FuzzTree(f *testing.F) {
+	f.Add([]byte{0, 3, 1, 2, 3, 0, 2, 1, 2, 1, 3, 1, 2, 3, 3, 1, 1})
+	f.Add([]byte{0, 0, 0, 1, 0, 0, 2, 0, 1, 0, 3, 0})
+	f.Add([]byte{0, 7, 0, 0, 0, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0, 1, 7, 0, 0, 0, 0, 0, 0, 0, 3, 2, 0, 0})
+	configs := map[string]func(t *testing.T) []Option{
+		"default":  func(t *testing.T) []Option { return nil },
+		"lazy":     func(t *testing.T) []Option { return []Option{WithLazyDelete()} },
+		"deferred": func(t *testing.T) []Option { return []Option{WithDeferredMerge()} },
+		"cached":   func(t *testing.T) []Option { return []Option{WithLookupCache(4), WithBloomFilter(16, 0.01)} },
+		"tracked": func(t *testing.T) []Option {
+			return []Option{WithModificationTracking(), WithHistory(2), WithInsertionOrder()}
+		},
+		"aggregate": func(t *testing.T) []Option {
+			return []Option{WithAggregate(0, func(a, b int) int { return a + b }, func(v int) int { return v })}
+		},
+		"allocator": func(t *testing.T) []Option { return []Option{WithAllocator(newTrackingAllocator(t))} },
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := testutil.Decode(data)
+		for name, opts := range configs {
+			t.Run(name, func(t *testing.T) {
+				testutil.Run(t, New[int](opts(t)...), ops)
+			})
+		}
+
+		// A snapshot taken half way keeps the entries of the tree at
+		// that point while the tree goes on.
+		tree, m := New[int](), &testutil.Model[int]{}
+		var snap *RadixTree[int]
+		var at testutil.Model[int]
+		for i, op := range ops {
+			if i == len(ops)/2 {
+				snap = tree.Snapshot()
+				for k, v := range m.AllPrefix(nil) {
+					at.Insert(k, v)
+				}
+			}
+			if err := testutil.Apply(tree, m, op); err != nil {
+				t.Fatalf("operation %d after a snapshot: %v", i, err)
+			}
+		}
+		if snap != nil {
+			if err := testutil.Check(snap, &at); err != nil {
+				t.Fatalf("snapshot: %v", err)
+			}
+		}
+	})
+}
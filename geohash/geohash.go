@@ -0,0 +1,308 @@
+// Package geohash indexes points on the Earth by their geohashes and finds the
+// points within a distance of a location.
+//
+// A geohash divides the surface into cells by interleaving the bits of the
+// longitude and latitude and writing them five at a time in a base 32
+// alphabet, so every cell's hash is a prefix of the hashes of the cells within
+// it and of the points they contain. An Index stores its points in a radix
+// tree keyed by their hashes. A search covers the bounding box of its circle
+// with the cells of a precision at which a few cells suffice, including the
+// cells on the far side of a cell boundary, the antimeridian or a pole, walks
+// the points below each cell's hash and keeps those within the distance.
+package geohash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+// MaxPrecision is the greatest number of characters in a geohash, which
+// identifies a cell of a few centimetres.
+const MaxPrecision = 12
+
+// EarthRadius is the mean radius of the Earth in metres, used for distances.
+const EarthRadius = 6371008.8
+
+// maxCells is the greatest number of cells a search covers at a precision
+// finer than a single character.
+const maxCells = 16
+
+const alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+var (
+	// ErrInvalid is returned when a latitude or longitude is out of range.
+	ErrInvalid = errors.New("geohash: invalid location")
+	// ErrInvalidHash is returned when a geohash is empty, too long or has a
+	// character outside its alphabet.
+	ErrInvalidHash = errors.New("geohash: invalid geohash")
+)
+
+// valid returns an error wrapping ErrInvalid unless the latitude is in [-90,
+// 90] and the longitude in [-180, 180].
+func valid(lat, lng float64) error {
+	if !(lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180) {
+		return fmt.Errorf("%w: (%v, %v)", ErrInvalid, lat, lng)
+	}
+	return nil
+}
+
+// Encode returns the geohash of the location with the given number of
+// characters, between 1 and MaxPrecision. It returns an error wrapping
+// ErrInvalid if the location is out of range.
+func Encode(lat, lng float64, precision int) (string, error) {
+	if err := valid(lat, lng); err != nil {
+		return "", err
+	}
+	precision = min(max(precision, 1), MaxPrecision)
+	return encode(lat, lng, precision), nil
+}
+
+func encode(lat, lng float64, precision int) string {
+	lats, lngs := [2]float64{-90, 90}, [2]float64{-180, 180}
+	hash := make([]byte, 0, precision)
+	var c byte
+	for bit := 0; len(hash) < precision; bit++ {
+		// Even bits halve the longitude and odd bits the latitude.
+		r, x := &lngs, lng
+		if bit%2 == 1 {
+			r, x = &lats, lat
+		}
+		mid := (r[0] + r[1]) / 2
+		c <<= 1
+		if x >= mid {
+			c |= 1
+			r[0] = mid
+		} else {
+			r[1] = mid
+		}
+		if bit%5 == 4 {
+			hash = append(hash, alphabet[c])
+			c = 0
+		}
+	}
+	return string(hash)
+}
+
+// Box is a range of latitudes and longitudes.
+type Box struct {
+	MinLat, MinLng, MaxLat, MaxLng float64
+}
+
+// Center returns the location in the middle of the box.
+func (b Box) Center() (lat, lng float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLng + b.MaxLng) / 2
+}
+
+// Decode returns the cell identified by a geohash. It returns an error
+// wrapping ErrInvalidHash if the hash is not valid.
+func Decode(hash string) (Box, error) {
+	if hash == "" || len(hash) > MaxPrecision {
+		return Box{}, fmt.Errorf("%w: %q", ErrInvalidHash, hash)
+	}
+	lats, lngs := [2]float64{-90, 90}, [2]float64{-180, 180}
+	bit := 0
+	for i := 0; i < len(hash); i++ {
+		c := indexOf(hash[i])
+		if c < 0 {
+			return Box{}, fmt.Errorf("%w: %q", ErrInvalidHash, hash)
+		}
+		for j := 4; j >= 0; j, bit = j-1, bit+1 {
+			r := &lngs
+			if bit%2 == 1 {
+				r = &lats
+			}
+			mid := (r[0] + r[1]) / 2
+			if c>>j&1 == 1 {
+				r[0] = mid
+			} else {
+				r[1] = mid
+			}
+		}
+	}
+	return Box{MinLat: lats[0], MinLng: lngs[0], MaxLat: lats[1], MaxLng: lngs[1]}, nil
+}
+
+func indexOf(c byte) int {
+	for i := 0; i < len(alphabet); i++ {
+		if alphabet[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// Distance returns the great-circle distance in metres between two locations
+// by the haversine formula.
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	φ1, φ2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dφ, dλ := φ2-φ1, (lng2-lng1)*math.Pi/180
+	a := math.Sin(dφ/2)*math.Sin(dφ/2) + math.Cos(φ1)*math.Cos(φ2)*math.Sin(dλ/2)*math.Sin(dλ/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Point is a value stored at a location.
+type Point[T any] struct {
+	Lat, Lng float64
+	Value    T
+}
+
+// Result is a point found by a search and its distance in metres from the
+// location searched around.
+type Result[T any] struct {
+	Point[T]
+	Distance float64
+}
+
+// Index is a set of points. An Index is not safe for concurrent use.
+type Index[T any] struct {
+	tree *radixtree.RadixTree[[]Point[T]]
+	size int
+}
+
+// New returns an empty index.
+func New[T any]() *Index[T] {
+	return &Index[T]{tree: radixtree.New[[]Point[T]]()}
+}
+
+// Add adds a point with the value at the location. Several points may be added
+// at the same location. It returns an error wrapping ErrInvalid if the
+// location is out of range.
+func (x *Index[T]) Add(lat, lng float64, value T) error {
+	if err := valid(lat, lng); err != nil {
+		return err
+	}
+	key := []byte(encode(lat, lng, MaxPrecision))
+	points, _ := x.tree.Get(key)
+	x.tree.Insert(key, append(points, Point[T]{Lat: lat, Lng: lng, Value: value}))
+	x.size++
+	return nil
+}
+
+// Remove removes the points at exactly the location for which match returns
+// true, or all of them if match is nil, and returns the number removed.
+func (x *Index[T]) Remove(lat, lng float64, match func(value T) bool) int {
+	if valid(lat, lng) != nil {
+		return 0
+	}
+	key := []byte(encode(lat, lng, MaxPrecision))
+	points, _ := x.tree.Get(key)
+	kept := points[:0]
+	for _, p := range points {
+		if p.Lat != lat || p.Lng != lng || match != nil && !match(p.Value) {
+			kept = append(kept, p)
+		}
+	}
+	n := len(points) - len(kept)
+	x.size -= n
+	switch {
+	case len(kept) == 0 && len(points) > 0:
+		x.tree.Remove(key)
+	case n > 0:
+		x.tree.Insert(key, kept)
+	}
+	return n
+}
+
+// Len returns the number of points in the index.
+func (x *Index[T]) Len() int {
+	return x.size
+}
+
+// Within returns the points whose hashes start with the given geohash, that is
+// the points in its cell, in the order of their hashes.
+func (x *Index[T]) Within(hash string) []Point[T] {
+	var points []Point[T]
+	for _, ps := range x.tree.AllPrefix([]byte(hash)) {
+		points = append(points, ps...)
+	}
+	return points
+}
+
+// Near returns the points within radius metres of the location, nearest first.
+// It returns an error wrapping ErrInvalid if the location is out of range.
+func (x *Index[T]) Near(lat, lng, radius float64) ([]Result[T], error) {
+	if err := valid(lat, lng); err != nil {
+		return nil, err
+	}
+	var results []Result[T]
+	for _, cell := range cover(lat, lng, radius) {
+		for _, points := range x.tree.AllPrefix([]byte(cell)) {
+			for _, p := range points {
+				if d := Distance(lat, lng, p.Lat, p.Lng); d <= radius {
+					results = append(results, Result[T]{Point: p, Distance: d})
+				}
+			}
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+	return results, nil
+}
+
+// cover returns the hashes of distinct cells that together cover every
+// location within radius metres of the given one, using the finest precision
+// at which at most maxCells cells are needed, or the cells of a single
+// character if more are needed even then. A radius of a quarter of the
+// circumference of the Earth or more returns the empty hash, the prefix of
+// every point.
+func cover(lat, lng, radius float64) []string {
+	dlat := radius / EarthRadius * 180 / math.Pi
+	minLat, maxLat := lat-dlat, lat+dlat
+	dlng := 360.0
+	if minLat > -90 && maxLat < 90 {
+		// The circle spans the most longitude at its latitude farthest
+		// from the equator.
+		if c := math.Cos(math.Max(math.Abs(minLat), math.Abs(maxLat)) * math.Pi / 180); c > 0 {
+			dlng = dlat / c
+		}
+	}
+	minLat, maxLat = math.Max(minLat, -90), math.Min(maxLat, 90)
+	if dlat >= 90 {
+		return []string{""}
+	}
+
+	for precision := MaxPrecision; precision >= 1; precision-- {
+		lngBits := (5*precision + 1) / 2
+		latBits := 5 * precision / 2
+		w, h := 360/math.Exp2(float64(lngBits)), 180/math.Exp2(float64(latBits))
+		cols, rows := int(math.Exp2(float64(lngBits))), int(math.Exp2(float64(latBits)))
+		j0, j1 := cell((minLat+90)/h, rows), cell((maxLat+90)/h, rows)
+		i0, i1 := int(math.Floor((lng-dlng+180)/w)), int(math.Floor((lng+dlng+180)/w))
+		if dlng >= 180 {
+			// The circle reaches a pole, around which it covers every
+			// longitude.
+			i0, i1 = 0, cols-1
+		}
+		n := (j1 - j0 + 1) * min(i1-i0+1, cols)
+		if n > maxCells && precision > 1 {
+			continue
+		}
+		seen := make(map[string]bool, n)
+		var cells []string
+		for j := j0; j <= j1; j++ {
+			for i := i0; i <= i1 && i < i0+cols; i++ {
+				// Columns past the antimeridian wrap around to the
+				// other side.
+				col := (i%cols + cols) % cols
+				hash := encode(-90+(float64(j)+0.5)*h, -180+(float64(col)+0.5)*w, precision)
+				if !seen[hash] {
+					seen[hash] = true
+					cells = append(cells, hash)
+				}
+			}
+		}
+		return cells
+	}
+	return []string{""}
+}
+
+// cell returns the index of the cell at position x in a grid of n cells,
+// clamping the edges of the grid.
+func cell(x float64, n int) int {
+	return min(max(int(math.Floor(x)), 0), n-1)
+}
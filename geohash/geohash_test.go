@@ -0,0 +1,106 @@
+package geohash
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	for _, tc := range []struct {
+		lat, lng  float64
+		precision int
+		hash      string
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{48.8584, 2.2945, 9, "u09tunquc"},
+		{-33.8568, 151.2153, 7, "r3gx2ux"},
+		{0, 0, 1, "s"},
+	} {
+		hash, err := Encode(tc.lat, tc.lng, tc.precision)
+		if err != nil || hash != tc.hash {
+			t.Errorf("Encode(%v, %v, %d) = %q, %v, want %q", tc.lat, tc.lng, tc.precision, hash, err, tc.hash)
+		}
+		box, err := Decode(hash)
+		if err != nil || tc.lat < box.MinLat || tc.lat > box.MaxLat || tc.lng < box.MinLng || tc.lng > box.MaxLng {
+			t.Errorf("Decode(%q) = %+v, %v, which does not contain (%v, %v)", hash, box, err, tc.lat, tc.lng)
+		}
+	}
+	if _, err := Encode(91, 0, 5); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Encode(91, 0) returned %v, want %v", err, ErrInvalid)
+	}
+	for _, hash := range []string{"", "u4pa", "u4pruydqqvjxx"} {
+		if _, err := Decode(hash); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("Decode(%q) returned %v, want %v", hash, err, ErrInvalidHash)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// Paris to London is about 344 km.
+	if d := Distance(48.8566, 2.3522, 51.5074, -0.1278); math.Abs(d-343.5e3) > 1e3 {
+		t.Errorf("Distance(Paris, London) = %.0f m, want about 343500", d)
+	}
+}
+
+func TestNear(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	x := New[int]()
+	type point struct{ lat, lng float64 }
+	var points []point
+	centers := []point{{48.8566, 2.3522}, {0, 179.999}, {89.99, 10}, {-0.0001, -0.0001}, {51.5, 0}}
+	for i := 0; i < 5000; i++ {
+		c := centers[i%len(centers)]
+		p := point{
+			lat: math.Max(-90, math.Min(90, c.lat+rng.NormFloat64()*0.05)),
+			lng: c.lng + rng.NormFloat64()*0.05,
+		}
+		if p.lng > 180 {
+			p.lng -= 360
+		}
+		points = append(points, p)
+		if err := x.Add(p.lat, p.lng, i); err != nil {
+			t.Fatalf("Add(%v, %v) returned error %v", p.lat, p.lng, err)
+		}
+	}
+	if x.Len() != len(points) {
+		t.Errorf("Len() = %d, want %d", x.Len(), len(points))
+	}
+
+	for _, c := range centers {
+		for _, radius := range []float64{10, 500, 3000, 20000} {
+			var want []int
+			for i, p := range points {
+				if Distance(c.lat, c.lng, p.lat, p.lng) <= radius {
+					want = append(want, i)
+				}
+			}
+			results, err := x.Near(c.lat, c.lng, radius)
+			if err != nil {
+				t.Fatalf("Near returned error %v", err)
+			}
+			var got []int
+			for i, r := range results {
+				if i > 0 && r.Distance < results[i-1].Distance {
+					t.Errorf("Near(%v, %v, %v) is not ordered by distance", c.lat, c.lng, radius)
+				}
+				got = append(got, r.Value)
+			}
+			sort.Ints(got)
+			if !slices.Equal(got, want) {
+				t.Errorf("Near(%v, %v, %v) found %d points, want %d", c.lat, c.lng, radius, len(got), len(want))
+			}
+		}
+	}
+
+	p := points[0]
+	if n := x.Remove(p.lat, p.lng, func(v int) bool { return v == 0 }); n != 1 || x.Len() != len(points)-1 {
+		t.Errorf("Remove = %d with Len() %d, want 1 with %d", n, x.Len(), len(points)-1)
+	}
+	if len(x.Within("")) != len(points)-1 {
+		t.Errorf("Within(\"\") returned %d points, want %d", len(x.Within("")), len(points)-1)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/jhm/go-radixtree/v2
 
-go 1.18
+go 1.23
@@ -0,0 +1,146 @@
+package radixtree
+
+import "bytes"
+
+// Graft moves every entry of sub into the tree with the given prefix prepended
+// to its key. The nodes of sub are spliced into the tree at the mount point
+// rather than reinserted, splitting the existing node at the mount point if
+// necessary. If a grafted key is already in the tree its value is replaced.
+// The prefix is retained by the tree, unless it was created with WithKeyCopy
+// or WithAllocator, and sub is left empty. Like Insert, Graft panics if a
+// grafted key would exceed a limit of the tree, leaving both trees unchanged.
+// A tree cannot be grafted into itself, and Graft panics if sub is the tree.
+func (t *RadixTree[T]) Graft(prefix []byte, sub *RadixTree[T]) {
+	if sub == t {
+		panic("radixtree: Graft of a tree into itself")
+	}
+	if sub.size == 0 {
+		return
+	}
+	if err := t.checkSubtree(prefix, sub.root, false); err != nil {
+		panic(err)
+	}
+	t.unshareAll()
+	sub.unshareAll()
+	if t.filter != nil {
+		walkNodes(sub.root, append([]byte(nil), prefix...), -1, func(key []byte, _ *node[T]) bool {
+			t.filter.add(key)
+			return true
+		})
+	}
+
+	t.gen++
+	t.adopt(sub.root, sub)
+	t.sequence(sub.root)
+	t.touchAll(sub.root)
+	mount := sub.root
+	if len(prefix) > 0 {
+		mount = t.compact(&node[T]{prefix: t.ownKey(prefix), leaf: mount.leaf, children: mount.children, mod: mount.mod, agg: mount.agg, count: mount.count})
+		mount = &node[T]{children: childrenOf(mount), mod: mount.mod}
+	}
+	t.notifySubtree(prefix, sub.root)
+	replaced := t.union(t.root, mount)
+	t.size += sub.size - replaced
+	sub.reset()
+}
+
+// Detach removes every entry whose key starts with the given prefix and returns
+// them as a new tree with the same options. If relative is true the prefix is
+// removed from the keys in the returned tree, otherwise they keep their full
+// keys. The subtree is unlinked from the tree as a whole rather than removed
+// entry by entry.
+func (t *RadixTree[T]) Detach(prefix []byte, relative bool) *RadixTree[T] {
+	t.unshareAll()
+	var parent *node[T]
+	var i int
+	var path []byte
+	n := t.root
+
+	for rest := prefix; len(rest) > 0; {
+		if i = n.children.index(rest[0]); i < 0 {
+			return newTree[T](t.opts)
+		}
+		parent, n = n, n.children.nodes[i]
+		path = append(path, n.prefix...)
+		if len(rest) <= len(n.prefix) {
+			// The prefix ends within this node's prefix.
+			if !bytes.HasPrefix(n.prefix, rest) {
+				return newTree[T](t.opts)
+			}
+			break
+		}
+		if !bytes.HasPrefix(rest, n.prefix) {
+			return newTree[T](t.opts)
+		}
+		rest = rest[len(n.prefix):]
+	}
+
+	if parent == nil {
+		// The whole tree is detached.
+		d := t.derive(t.root)
+		d.size = t.size
+		t.reset()
+		return d
+	}
+
+	parent.children.remove(i)
+	if parent != t.root && !parent.hasValue() && parent.children.len() == 1 {
+		t.merge(parent)
+	}
+	t.updateKey(t.root, prefix)
+
+	key := path
+	if relative {
+		key = path[len(prefix):]
+	}
+	root := &node[T]{leaf: n.leaf, children: n.children, mod: n.mod, agg: n.agg, count: n.count}
+	if len(key) > 0 {
+		child := &node[T]{prefix: t.own(key), leaf: n.leaf, children: n.children, mod: n.mod, agg: n.agg, count: n.count}
+		root = &node[T]{children: childrenOf(child), mod: n.mod}
+		t.update(root)
+	}
+	t.free(n.prefix)
+
+	d := t.derive(root)
+	d.size = root.count
+	if relative && d.filter != nil {
+		d.rebuildFilter()
+	}
+	t.size -= d.size
+	t.gen++
+	t.detached++
+	t.logRemoval(backupPrefix, prefix, nil)
+	return d
+}
+
+// ReplaceAll replaces every entry of the tree by the entries of src in
+// constant time, apart from adopting the nodes of a tree with other options,
+// and leaves src empty. A tree rebuilt in full, for example from a database,
+// can thus be published by calling ReplaceAll while holding the lock that
+// readers of the tree take, so that they see either every old entry or every
+// new one. Snapshots of the tree keep its old entries and iterators over it
+// panic with ErrModified. Like Insert, ReplaceAll panics if the entries of src
+// would exceed a limit of the tree, leaving both trees unchanged.
+func (t *RadixTree[T]) ReplaceAll(src *RadixTree[T]) {
+	if src == t {
+		return
+	}
+	if err := t.checkSubtree(nil, src.root, true); err != nil {
+		panic(err)
+	}
+	src.unshareAll()
+	t.reset()
+	t.adopt(src.root, src)
+	t.sequence(src.root)
+	t.touchAll(src.root)
+	t.root = src.root
+	t.notifySubtree(nil, t.root)
+	t.size = src.size
+	t.removed = src.removed
+	// None of the new nodes are shared with the snapshots of the tree.
+	t.shared = false
+	if t.filter != nil {
+		t.rebuildFilter()
+	}
+	src.reset()
+}
@@ -0,0 +1,165 @@
+package radixtree
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGraft(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   []string
+		prefix string
+		sub    []string
+		want   []string
+	}{
+		{"empty tree", nil, "ab", []string{"c", "d"}, []string{"abc", "abd"}},
+		{"empty prefix", []string{"a", "c"}, "", []string{"b", "d"}, []string{"a", "b", "c", "d"}},
+		{"split mount point", []string{"abcd", "abce"}, "ab", []string{"x", "y"}, []string{"abcd", "abce", "abx", "aby"}},
+		{"mount inside node", []string{"abcdef"}, "abc", []string{"", "d", "x"}, []string{"abc", "abcd", "abcdef", "abcx"}},
+		{"mount below leaf", []string{"ab"}, "abc", []string{"d"}, []string{"ab", "abcd"}},
+		{"replace values", []string{"abc", "abd"}, "ab", []string{"c", "e"}, []string{"abc", "abd", "abe"}},
+		{"shared sub prefix", []string{"macro"}, "ma", []string{"cro", "croa", "t"}, []string{"macro", "macroa", "mat"}},
+	}
+	for _, test := range tests {
+		tree := build(test.base)
+		sub := New[string]()
+		for _, key := range test.sub {
+			sub.Insert([]byte(key), test.prefix+key)
+		}
+		tree.Graft([]byte(test.prefix), sub)
+
+		var got []string
+		tree.WalkKeys(nil, func(key []byte) bool {
+			got = append(got, string(key))
+			return true
+		})
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Graft %s\n got: %v\nwant: %v", test.name, got, test.want)
+		}
+		for _, key := range test.want {
+			if v, ok := tree.Get([]byte(key)); !ok || v != key {
+				t.Errorf("Get(%s) after Graft %s\n got: (%s, %t)\nwant: (%s, true)", key, test.name, v, ok, key)
+			}
+		}
+		verify(t, tree)
+		if sub.Len() != 0 {
+			t.Errorf("Graft %s did not empty the grafted tree", test.name)
+		}
+	}
+}
+
+func TestGraftSelf(t *testing.T) {
+	tree := build([]string{"a"})
+	defer func() {
+		if recover() == nil {
+			t.Error("Graft of a tree into itself did not panic")
+		}
+		if tree.Len() != 1 || !tree.Contains([]byte("a")) {
+			t.Errorf("Graft of a tree into itself changed it to %v", tree.ToMap())
+		}
+	}()
+	tree.Graft([]byte("x"), tree)
+}
+
+func TestGraftBloomFilter(t *testing.T) {
+	tree := New[string](WithBloomFilter(100, 0.01))
+	tree.Insert([]byte("a"), "a")
+	tree.Graft([]byte("sub/"), build(words))
+	for _, word := range words {
+		if !tree.Contains([]byte("sub/" + word)) {
+			t.Errorf("Contains(sub/%s) returned false after Graft", word)
+		}
+	}
+}
+
+func TestDetach(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"to", hasPrefix("to", words)},
+		{"macroan", hasPrefix("macroan", words)},
+		{"w", hasPrefix("w", words)},
+		{"wil", hasPrefix("wil", words)},
+		{"", words},
+		{"x", nil},
+		{"macz", nil},
+		{"toadyismx", nil},
+	}
+	for _, test := range tests {
+		for _, relative := range []bool{false, true} {
+			tree := build(words)
+			d := tree.Detach([]byte(test.prefix), relative)
+
+			var got []string
+			d.WalkKeys(nil, func(key []byte) bool {
+				got = append(got, string(key))
+				return true
+			})
+			var want []string
+			for _, key := range test.want {
+				if relative {
+					key = key[len(test.prefix):]
+				}
+				want = append(want, key)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Detach(%s, %t)\n got: %v\nwant: %v", test.prefix, relative, got, want)
+			}
+			if got := d.Find(nil); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Detach(%s, %t) values\n got: %v\nwant: %v", test.prefix, relative, got, test.want)
+			}
+
+			remaining := []string{}
+			for _, word := range words {
+				if !strings.HasPrefix(word, test.prefix) {
+					remaining = append(remaining, word)
+				}
+			}
+			if got := tree.Values(); !reflect.DeepEqual(got, remaining) {
+				t.Errorf("Values after Detach(%s, %t)\n got: %v\nwant: %v", test.prefix, relative, got, remaining)
+			}
+			verify(t, tree)
+			verify(t, d)
+		}
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	tree := New[string](WithBloomFilter(100, 0.01))
+	for _, key := range []string{"old", "toad", "zebra"} {
+		tree.Insert([]byte(key), "old "+key)
+	}
+	snap := tree.Snapshot()
+	src := build(words)
+	src.Snapshot()
+
+	tree.ReplaceAll(src)
+	verify(t, tree)
+	if got := keysOf(tree); !reflect.DeepEqual(got, keysOf(build(words))) {
+		t.Errorf("keys after ReplaceAll = %q, want the keys of src", got)
+	}
+	if v, ok := tree.Get([]byte("toad")); !ok || v != "toad" {
+		t.Errorf("Get(toad) = %q, %t, want the value from src", v, ok)
+	}
+	for _, key := range []string{"old", "zebra"} {
+		if tree.Contains([]byte(key)) {
+			t.Errorf("Contains(%q) = true after ReplaceAll", key)
+		}
+	}
+	if src.Len() != 0 || len(keysOf(src)) != 0 {
+		t.Errorf("src holds %d keys after ReplaceAll, want 0", src.Len())
+	}
+	if got := keysOf(snap); !reflect.DeepEqual(got, []string{"old", "toad", "zebra"}) {
+		t.Errorf("snapshot keys after ReplaceAll = %q, want the old keys", got)
+	}
+
+	tree.Insert([]byte("toad"), "changed")
+	tree.Remove([]byte("win"))
+	verify(t, tree)
+	if v, _ := snap.Get([]byte("toad")); v != "old toad" {
+		t.Errorf("snapshot Get(toad) = %q after modifying the tree, want %q", v, "old toad")
+	}
+}
@@ -0,0 +1,110 @@
+package radixtree
+
+// Handle refers to an entry of a tree so that its value can be read and set
+// repeatedly without searching for its key each time. A handle stays valid
+// until its key is removed from the tree, even if the key is later inserted
+// again, or moved to another tree by Split, Join or Detach. Checking a handle
+// costs a search for its key only after an operation that may have removed
+// entries from the tree, such as Remove or DeleteRange, so a set of handles to
+// keys that are updated far more often than entries are removed is checked in
+// constant time. After Snapshot the entry may be copied when the tree is next
+// modified, which the handle follows unless entries were also removed, in which
+// case it is no longer valid.
+type Handle[T any] struct {
+	tree     *RadixTree[T]
+	key      []byte
+	leaf     *leaf[T]
+	detached uint64
+	copies   uint64
+	owner    *token
+}
+
+// Handle returns a handle to the entry with the given key and true, or nil and
+// false if the key is not in the tree. The key is copied.
+func (t *RadixTree[T]) Handle(key []byte) (*Handle[T], bool) {
+	lf := t.lookup(key)
+	if lf == nil {
+		return nil, false
+	}
+	return &Handle[T]{tree: t, key: append([]byte(nil), key...), leaf: lf, detached: t.detached, copies: t.copies}, true
+}
+
+// valid returns true if the entry of the handle is still in the tree,
+// searching for its key if entries may have been removed or copied since it
+// was last checked.
+func (h *Handle[T]) valid() bool {
+	t := h.tree
+	if h.leaf != nil && (h.detached != t.detached || h.copies != t.copies) {
+		if lf := t.lookup(h.key); lf != h.leaf {
+			// A different leaf is a copy of the entry only if no
+			// entries were removed in the meantime.
+			h.leaf = nil
+			if lf != nil && h.detached == t.detached {
+				h.leaf = lf
+			}
+		}
+		h.detached = t.detached
+		h.copies = t.copies
+	}
+	return h.leaf != nil
+}
+
+// Delete removes the entry of the handle from the tree, as Remove does, and
+// invalidates the handle. It returns the value of the entry and true, or the
+// zero value for type T and false if the handle is no longer valid.
+func (h *Handle[T]) Delete() (T, bool) {
+	if !h.valid() {
+		var zero T
+		return zero, false
+	}
+	h.leaf = nil
+	return h.tree.Remove(h.key)
+}
+
+// Key returns the key of the entry, which the caller must not modify.
+func (h *Handle[T]) Key() []byte {
+	return h.key
+}
+
+// Set replaces the value of the entry and returns true, or returns false if
+// the handle is no longer valid. It is a modification of the tree like Insert
+// but in constant time, except in trees created with WithAggregate or
+// WithModificationTracking, whose bookkeeping along the path to the key is
+// updated by calling Insert, and in trees with watches made by WatchKey.
+func (h *Handle[T]) Set(value T) bool {
+	if !h.valid() {
+		return false
+	}
+	t := h.tree
+	if t.agg != nil || t.opts.trackMods || t.watches != nil {
+		t.Insert(h.key, value)
+		return true
+	}
+	if t.shared && h.owner != t.owner {
+		// The leaf may be shared with a snapshot.
+		t.unshare(h.key)
+		h.owner = t.owner
+		if !h.valid() {
+			return false
+		}
+	}
+	t.gen++
+	t.keep(h.leaf, h.leaf.value)
+	h.leaf.value = value
+	return true
+}
+
+// Valid returns true if the entry of the handle is still in the tree.
+func (h *Handle[T]) Valid() bool {
+	return h.valid()
+}
+
+// Value returns the value of the entry and true, or the zero value for type T
+// and false if the handle is no longer valid.
+func (h *Handle[T]) Value() (T, bool) {
+	if !h.valid() {
+		var zero T
+		return zero, false
+	}
+	return h.leaf.value, true
+}
@@ -0,0 +1,66 @@
+package radixtree
+
+import "testing"
+
+func TestHandle(t *testing.T) {
+	tree := build(words)
+	h, ok := tree.Handle([]byte("toad"))
+	if !ok || string(h.Key()) != "toad" {
+		t.Fatalf("Handle(%q) = (%v, %t)", "toad", h, ok)
+	}
+	if _, ok := tree.Handle([]byte("toa\x00")); ok {
+		t.Errorf("Handle of a missing key returned true")
+	}
+
+	if !h.Set("frog") {
+		t.Errorf("Set returned false")
+	}
+	if v, _ := tree.Get([]byte("toad")); v != "frog" {
+		t.Errorf("Get after Set = %q, want %q", v, "frog")
+	}
+
+	// Removing other keys and restructuring the tree keeps the handle valid.
+	tree.Remove([]byte("toa"))
+	tree.Remove([]byte("toady"))
+	tree.DeleteRange([]byte("w"), nil)
+	if v, ok := h.Value(); !ok || v != "frog" {
+		t.Errorf("Value after removing other keys = (%q, %t), want (%q, true)", v, ok, "frog")
+	}
+
+	// Removing the key invalidates the handle, even if it is reinserted.
+	tree.Remove([]byte("toad"))
+	tree.Insert([]byte("toad"), "toad")
+	if h.Valid() || h.Set("newt") {
+		t.Errorf("handle is valid after its key was removed")
+	}
+	if v, _ := tree.Get([]byte("toad")); v != "toad" {
+		t.Errorf("Set through an invalid handle changed the tree to %q", v)
+	}
+
+	h, _ = tree.Handle([]byte("toad"))
+	if v, ok := h.Delete(); !ok || v != "toad" || tree.Contains([]byte("toad")) {
+		t.Errorf("Delete = (%q, %t), want (%q, true)", v, ok, "toad")
+	}
+	if _, ok := h.Delete(); ok {
+		t.Errorf("second Delete returned true")
+	}
+	verify(t, tree)
+
+	// Keys moved to another tree by Split leave the handle invalid.
+	h, _ = tree.Handle([]byte("macro"))
+	left, _ := tree.Split([]byte("n"))
+	if _, ok := h.Value(); ok || !left.Contains([]byte("macro")) {
+		t.Errorf("handle is valid after Split")
+	}
+}
+
+func TestHandleAggregate(t *testing.T) {
+	tree := New[int](WithAggregate(0, func(a, b int) int { return a + b }, func(v int) int { return v }))
+	tree.Insert([]byte("a"), 1)
+	tree.Insert([]byte("ab"), 2)
+	h, _ := tree.Handle([]byte("ab"))
+	h.Set(10)
+	if got, _ := Aggregate[int](tree, []byte("a")); got != 11 {
+		t.Errorf("Aggregate after Set = %d, want 11", got)
+	}
+}
@@ -0,0 +1,34 @@
+package radixtree
+
+// keep records old as the most recent previous value of l if the tree keeps
+// history. The history is replaced rather than modified in place since the
+// leaf may have been copied from one shared with a snapshot.
+func (t *RadixTree[T]) keep(l *leaf[T], old T) {
+	n := t.opts.history
+	if n <= 0 {
+		return
+	}
+	prev := l.prev
+	if len(prev) >= n {
+		prev = prev[len(prev)-n+1:]
+	}
+	l.prev = append(append(make([]T, 0, len(prev)+1), prev...), old)
+}
+
+// History returns the values that key held before its current value, most
+// recent first, for a tree created with WithHistory, so that History(key)[1]
+// is the value the key had before its last two updates. At most as many
+// values as were given to WithHistory are kept. It returns nil if the key is
+// not in the tree, has not been updated since it was inserted or the tree does
+// not keep history.
+func (t *RadixTree[T]) History(key []byte) []T {
+	l := t.lookup(key)
+	if l == nil || len(l.prev) == 0 {
+		return nil
+	}
+	values := make([]T, len(l.prev))
+	for i, v := range l.prev {
+		values[len(values)-1-i] = v
+	}
+	return values
+}
@@ -0,0 +1,41 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	tree := New[int](WithHistory(3))
+	key := []byte("toad")
+	tree.Insert(key, 1)
+	if got := tree.History(key); got != nil {
+		t.Errorf("History of a new key = %v, want nil", got)
+	}
+	tree.Insert(key, 2)
+	tree.InsertMany([]Entry[int]{{Key: key, Value: 3}})
+	h, _ := tree.Handle(key)
+	h.Set(4)
+	if got, want := tree.History(key), []int{3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("History after three updates = %v, want %v", got, want)
+	}
+
+	// Only the last three values are kept and snapshots keep their own.
+	snap := tree.Snapshot()
+	tree.Insert(key, 5)
+	if got, want := tree.History(key), []int{4, 3, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("History after four updates = %v, want %v", got, want)
+	}
+	if got, want := snap.History(key), []int{3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("History of the snapshot = %v, want %v", got, want)
+	}
+
+	tree.Remove(key)
+	tree.Insert(key, 6)
+	if got := tree.History(key); got != nil {
+		t.Errorf("History of a reinserted key = %v, want nil", got)
+	}
+	if got := build(words).History([]byte("toad")); got != nil {
+		t.Errorf("History without WithHistory = %v, want nil", got)
+	}
+}
@@ -0,0 +1,109 @@
+package radixtree
+
+import (
+	"bytes"
+	"sort"
+)
+
+// PrefixCount is the traffic of the keys that start with a prefix, as reported
+// by HotPrefixes. The counts are estimates: the sampled operations times the
+// sampling interval.
+type PrefixCount struct {
+	Prefix   []byte
+	Accesses uint64
+	Inserts  uint64
+}
+
+// hotPrefixes counts the sampled operations on a tree by the first depth bytes
+// of their keys, for WithHotPrefixes.
+type hotPrefixes struct {
+	depth int
+	every int
+	// skip is the number of operations left before the next one is
+	// sampled.
+	skip   int
+	counts map[string]*PrefixCount
+}
+
+func newHotPrefixes(depth, every int) *hotPrefixes {
+	return &hotPrefixes{depth: depth, every: max(every, 1), counts: make(map[string]*PrefixCount)}
+}
+
+// record counts an access to key, or an insert of it if insert is true, if the
+// operation is sampled.
+func (h *hotPrefixes) record(key []byte, insert bool) {
+	if h.skip > 0 {
+		h.skip--
+		return
+	}
+	h.skip = h.every - 1
+	key = key[:min(len(key), h.depth)]
+	c := h.counts[string(key)]
+	if c == nil {
+		c = &PrefixCount{Prefix: append([]byte(nil), key...)}
+		h.counts[string(key)] = c
+	}
+	if insert {
+		c.Inserts += uint64(h.every)
+	} else {
+		c.Accesses += uint64(h.every)
+	}
+}
+
+// HotPrefixes returns the k prefixes with the most traffic in a tree created
+// with WithHotPrefixes, busiest first, where the traffic of a prefix is its
+// accesses plus its inserts. Prefixes with the same traffic are in ascending
+// key order. It returns nil if k is not positive or the tree does not count
+// its traffic.
+func (t *RadixTree[T]) HotPrefixes(k int) []PrefixCount {
+	if t == nil || t.hot == nil || k <= 0 {
+		return nil
+	}
+	counts := make([]PrefixCount, 0, len(t.hot.counts))
+	for _, c := range t.hot.counts {
+		counts = append(counts, *c)
+	}
+	return hottest(counts, k, t.opts.order)
+}
+
+// HotPrefixes is like the method of RadixTree for a map created with
+// WithHotPrefixes, combining the counts of every shard. Each shard samples its
+// own operations.
+func (m *SyncMap[T]) HotPrefixes(k int) []PrefixCount {
+	if k <= 0 {
+		return nil
+	}
+	var counts []PrefixCount
+	var order *byteOrder
+	for i := range m.shards {
+		s := m.lock(i)
+		if s.tree != nil && s.tree.hot != nil {
+			order = s.tree.opts.order
+			for _, c := range s.tree.hot.counts {
+				counts = append(counts, *c)
+			}
+		}
+		s.mu.Unlock()
+	}
+	if counts == nil {
+		return nil
+	}
+	return hottest(counts, k, order)
+}
+
+// hottest sorts counts by descending traffic and then by prefix and returns the
+// first k with copies of their prefixes. The prefixes must be distinct.
+func hottest(counts []PrefixCount, k int, order *byteOrder) []PrefixCount {
+	sort.Slice(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		if ta, tb := a.Accesses+a.Inserts, b.Accesses+b.Inserts; ta != tb {
+			return ta > tb
+		}
+		return order.compare(a.Prefix, b.Prefix) < 0
+	})
+	counts = counts[:min(k, len(counts))]
+	for i := range counts {
+		counts[i].Prefix = bytes.Clone(counts[i].Prefix)
+	}
+	return counts
+}
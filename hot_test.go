@@ -0,0 +1,56 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHotPrefixes(t *testing.T) {
+	tree := New[int](WithHotPrefixes(2, 1))
+	for i, k := range []string{"us/a", "us/b", "eu/a", "u"} {
+		tree.Insert([]byte(k), i)
+	}
+	for i := 0; i < 5; i++ {
+		tree.Get([]byte("eu/a"))
+		tree.Contains([]byte("eu/x"))
+	}
+	tree.LongestPrefix([]byte("us/a/b"))
+	tree.InsertMany([]Entry[int]{{Key: []byte("ap/a")}})
+
+	want := []PrefixCount{
+		{Prefix: []byte("eu"), Accesses: 10, Inserts: 1},
+		{Prefix: []byte("us"), Accesses: 1, Inserts: 2},
+	}
+	got := tree.HotPrefixes(2)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HotPrefixes(2)\n got: %+v\nwant: %+v", got, want)
+	}
+	got[0].Prefix[0] = 'x'
+	if got := tree.HotPrefixes(3); len(got) != 3 || string(got[0].Prefix) != "eu" || string(got[2].Prefix) != "u" {
+		t.Errorf("HotPrefixes(3) = %+v", got)
+	}
+	if tree.HotPrefixes(0) != nil || New[int]().HotPrefixes(1) != nil {
+		t.Errorf("HotPrefixes returned counts for k = 0 or a tree without counts")
+	}
+
+	// With sampling the counts are scaled by the interval.
+	sampled := New[int](WithHotPrefixes(1, 4))
+	for i := 0; i < 40; i++ {
+		sampled.Get([]byte("a"))
+	}
+	if got := sampled.HotPrefixes(1); len(got) != 1 || got[0].Accesses != 40 {
+		t.Errorf("sampled HotPrefixes = %+v, want 40 accesses", got)
+	}
+
+	m := NewSyncMap[int](WithHotPrefixes(1, 1))
+	m.Store("a", 1)
+	m.Store("b", 2)
+	m.Load("b")
+	want = []PrefixCount{
+		{Prefix: []byte("b"), Accesses: 1, Inserts: 1},
+		{Prefix: []byte("a"), Inserts: 1},
+	}
+	if got := m.HotPrefixes(5); !reflect.DeepEqual(got, want) {
+		t.Errorf("SyncMap HotPrefixes\n got: %+v\nwant: %+v", got, want)
+	}
+}
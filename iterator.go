@@ -0,0 +1,83 @@
+package radixtree
+
+// Iterator is a pull iterator over the entries of a tree in ascending key
+// order. Entries are found one at a time as Next is called so a caller that
+// stops early only pays for the entries it visits. An Iterator must not be used
+// after the tree is modified; Next panics with ErrModified if it is. To modify
+// the tree while iterating over it, iterate over a Snapshot instead, as
+// FindIterSnapshot does.
+type Iterator[T any] struct {
+	t     *RadixTree[T]
+	gen   uint64
+	stack []iteratorFrame[T]
+	key   []byte
+	cur   *node[T]
+}
+
+// iteratorFrame is a node on the path to the current entry, the length of its
+// key and the index of the next of its children to visit.
+type iteratorFrame[T any] struct {
+	n       *node[T]
+	end     int
+	next    int
+	visited bool
+}
+
+// FindIter returns an iterator over the keys and values whose keys start with
+// the given prefix, in ascending key order. Unlike Find nothing is collected
+// up front.
+func (t *RadixTree[T]) FindIter(prefix []byte) *Iterator[T] {
+	it := &Iterator[T]{t: t, gen: t.gen}
+	var n *node[T]
+	n, it.key = t.findPath(prefix, nil)
+	if n != nil {
+		it.stack = append(it.stack, iteratorFrame[T]{n: n, end: len(it.key)})
+	}
+	return it
+}
+
+// Next advances the iterator to the next entry and reports whether there is
+// one. It must be called before the first entry is read.
+func (it *Iterator[T]) Next() bool {
+	it.t.unmodified(it.gen)
+	it.cur = nil
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+		if !top.visited {
+			top.visited = true
+			if top.n.hasValue() {
+				it.key = it.key[:top.end]
+				it.cur = top.n
+				return true
+			}
+		}
+		if top.next < top.n.children.len() {
+			child := top.n.children.nodes[top.next]
+			top.next++
+			it.key = append(it.key[:top.end], child.prefix...)
+			it.stack = append(it.stack, iteratorFrame[T]{n: child, end: len(it.key)})
+			continue
+		}
+		it.stack = it.stack[:len(it.stack)-1]
+	}
+	return false
+}
+
+// Key returns the key of the current entry. The key is a copy that the caller
+// may retain unless the tree was created with WithSharedKeys, in which case it
+// is only valid until the next call to Next.
+func (it *Iterator[T]) Key() []byte {
+	if it.cur == nil {
+		return nil
+	}
+	return it.t.yield(it.key)
+}
+
+// Value returns the value of the current entry.
+func (it *Iterator[T]) Value() T {
+	if it.cur == nil {
+		var zero T
+		return zero
+	}
+	return it.cur.leaf.value
+}
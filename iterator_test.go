@@ -0,0 +1,45 @@
+package radixtree
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFindIter(t *testing.T) {
+	tree := build(words)
+	for _, prefix := range []string{"", "a", "mac", "macroa", "toad", "wi", "x", "tob"} {
+		var keys, values []string
+		for it := tree.FindIter([]byte(prefix)); it.Next(); {
+			keys = append(keys, string(it.Key()))
+			values = append(values, it.Value())
+		}
+		want := hasPrefix(prefix, words)
+		if !reflect.DeepEqual(keys, want) || !reflect.DeepEqual(values, want) {
+			t.Errorf("FindIter(%q)\n got: %v, %v\nwant: %v", prefix, keys, values, want)
+		}
+	}
+
+	it := tree.FindIter([]byte("to"))
+	if it.Next(); string(it.Key()) != "to" {
+		t.Errorf("Key = %q, want \"to\"", it.Key())
+	}
+	for it.Next() {
+	}
+	if it.Key() != nil || it.Value() != "" || it.Next() {
+		t.Errorf("exhausted iterator returned an entry")
+	}
+}
+
+func TestFindIterModified(t *testing.T) {
+	tree := build(words)
+	it := tree.FindIter(nil)
+	it.Next()
+	tree.Insert([]byte("zebra"), "zebra")
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrModified) {
+			t.Errorf("Next after Insert panicked with %v, want %v", err, ErrModified)
+		}
+	}()
+	it.Next()
+}
@@ -0,0 +1,57 @@
+package radixtree
+
+import "bytes"
+
+// List returns the entries whose keys start with the given prefix and have no
+// delimiter after it, and the distinct common prefixes of the other keys with
+// the prefix, each of which runs up to and including the first delimiter after
+// the prefix, like the contents and subdirectories of a directory in a listing
+// of an object store. Both are in ascending key order. The subtree below a
+// common prefix is not visited, so the cost depends on the number of entries
+// and common prefixes returned rather than the number of keys with the prefix.
+// With an empty delimiter every entry with the prefix is returned. The keys
+// and prefixes are copies that the caller may retain.
+func (t *RadixTree[T]) List(prefix, delimiter []byte) (entries []Entry[T], prefixes [][]byte) {
+	n, key := t.findPath(prefix, t.borrow())
+	if n != nil && n.count > 0 {
+		l := lister[T]{start: len(prefix), delimiter: delimiter}
+		key = l.list(n, key, len(prefix))
+		entries, prefixes = l.entries, l.prefixes
+	}
+	t.release(key)
+	return entries, prefixes
+}
+
+// lister collects the results of List.
+type lister[T any] struct {
+	start     int
+	delimiter []byte
+	entries   []Entry[T]
+	prefixes  [][]byte
+}
+
+// list lists the subtree rooted at n, whose full key is key. A delimiter that
+// starts before from would have been found in the key of an ancestor of n.
+// Nodes left without keys by WithLazyDelete are skipped.
+func (l *lister[T]) list(n *node[T], key []byte, from int) []byte {
+	if n.count == 0 {
+		return key
+	}
+	if len(l.delimiter) > 0 {
+		if i := bytes.Index(key[from:], l.delimiter); i >= 0 {
+			end := from + i + len(l.delimiter)
+			l.prefixes = append(l.prefixes, append([]byte(nil), key[:end]...))
+			return key
+		}
+	}
+	if n.hasValue() {
+		l.entries = append(l.entries, Entry[T]{Key: append([]byte(nil), key...), Value: n.leaf.value})
+	}
+	from = max(l.start, len(key)-len(l.delimiter)+1)
+	for _, child := range n.children.nodes {
+		k := len(key)
+		key = l.list(child, append(key, child.prefix...), from)
+		key = key[:k]
+	}
+	return key
+}
@@ -0,0 +1,57 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	tree := build([]string{"a", "a/", "a/b", "a/b/c", "a/bc/d", "a/c", "a//d", "ab", "b/x", "b/y/z"})
+	for _, tc := range []struct {
+		prefix, delimiter string
+		keys, prefixes    []string
+	}{
+		{"", "/", []string{"a", "ab"}, []string{"a/", "b/"}},
+		{"a/", "/", []string{"a/", "a/b", "a/c"}, []string{"a//", "a/b/", "a/bc/"}},
+		{"a/b", "/", []string{"a/b"}, []string{"a/b/", "a/bc/"}},
+		{"b/", "/", []string{"b/x"}, []string{"b/y/"}},
+		{"a", "", []string{"a", "a/", "a//d", "a/b", "a/b/c", "a/bc/d", "a/c", "ab"}, nil},
+		{"a/", "b/", []string{"a/", "a//d", "a/b", "a/bc/d", "a/c"}, []string{"a/b/"}},
+		{"c", "/", nil, nil},
+	} {
+		entries, prefixes := tree.List([]byte(tc.prefix), []byte(tc.delimiter))
+		var keys []string
+		for _, e := range entries {
+			if string(e.Key) != e.Value {
+				t.Errorf("List returned key %q with value %q", e.Key, e.Value)
+			}
+			keys = append(keys, string(e.Key))
+		}
+		var got []string
+		for _, p := range prefixes {
+			got = append(got, string(p))
+		}
+		if !reflect.DeepEqual(keys, tc.keys) || !reflect.DeepEqual(got, tc.prefixes) {
+			t.Errorf("List(%q, %q) = %v, %v, want %v, %v", tc.prefix, tc.delimiter, keys, got, tc.keys, tc.prefixes)
+		}
+	}
+}
+
+func TestListLazyDelete(t *testing.T) {
+	tree := New[string](WithLazyDelete())
+	for _, key := range []string{"acb/x", "acb/y", "b/z"} {
+		tree.Insert([]byte(key), key)
+	}
+	tree.Remove([]byte("acb/x"))
+	tree.Remove([]byte("acb/y"))
+	for _, prefix := range []string{"", "a", "acb/"} {
+		entries, prefixes := tree.List([]byte(prefix), []byte("/"))
+		var want [][]byte
+		if prefix == "" {
+			want = [][]byte{[]byte("b/")}
+		}
+		if len(entries) != 0 || !reflect.DeepEqual(prefixes, want) {
+			t.Errorf("List(%q) after lazy removals = %v, %q, want none, %q", prefix, entries, prefixes, want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package radixtree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+)
+
+// LoadSorted inserts the entries read from r, one per line, into the tree.
+// Each line, without its line ending, is passed to parse which returns the key
+// and value of the entry. The keys must be in strictly ascending order, which
+// lets the entries be appended along the right edge of a new subtree without
+// searching and lets the nodes and key bytes be allocated in large contiguous
+// blocks. The new subtree is then merged into the tree, so values loaded for
+// keys already in the tree replace the existing ones.
+//
+// If parse returns an error, a key is not greater than the previous one or a
+// key would exceed a limit of the tree, loading stops and the error is
+// returned annotated with the line number, with ErrUnsorted for keys out of
+// order and errors wrapping ErrKeyTooLong or ErrQuotaExceeded for limits as
+// from InsertErr. The entries read before the error remain in the tree. The
+// key returned by parse may refer to the line, which is only valid until parse
+// is called again.
+func (t *RadixTree[T]) LoadSorted(r io.Reader, parse func(line []byte) (key []byte, value T, err error)) error {
+	l := newLoader(t)
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, math.MaxInt)
+	var err error
+	for line := 1; s.Scan(); line++ {
+		key, value, perr := parse(s.Bytes())
+		if perr != nil {
+			err = fmt.Errorf("radixtree: line %d: %w", line, perr)
+			break
+		}
+		if l.size > 0 && t.opts.order.compare(key, l.prev) <= 0 {
+			err = fmt.Errorf("radixtree: line %d: %w", line, ErrUnsorted)
+			break
+		}
+		if qerr := l.check(key); qerr != nil {
+			err = fmt.Errorf("radixtree: line %d: %w", line, qerr)
+			break
+		}
+		l.add(key, value)
+	}
+	if err == nil {
+		err = s.Err()
+	}
+
+	l.merge()
+	return err
+}
+
+// loadBlock is the number of nodes and key bytes allocated at a time while
+// loading.
+const loadBlock = 4096
+
+// loader builds a subtree from keys in ascending order. Only the nodes on the
+// path to the last key, the right spine, can still gain children so the others
+// are complete and their counts and aggregates are computed as they leave the
+// spine.
+type loader[T any] struct {
+	t     *RadixTree[T]
+	root  *node[T]
+	spine []spineNode[T]
+	prev  []byte
+	size  int
+	nodes []node[T]
+	bytes []byte
+	quota *quotaBatch[T]
+}
+
+// spineNode is a node on the right spine and the length of its key.
+type spineNode[T any] struct {
+	n   *node[T]
+	end int
+}
+
+// newLoader returns a loader for keys to be merged into t and starts a new
+// generation of t.
+func newLoader[T any](t *RadixTree[T]) *loader[T] {
+	t.gen++
+	l := &loader[T]{t: t}
+	if t.limited() {
+		l.quota = &quotaBatch[T]{t: t}
+	}
+	l.root = l.node()
+	l.spine = append(l.spine, spineNode[T]{n: l.root})
+	return l
+}
+
+// add appends the given key, which is greater than every key added so far, and
+// its value and returns the leaf holding the value.
+func (l *loader[T]) add(key []byte, value T) *leaf[T] {
+	p := longestCommonPrefix(l.prev, key)
+	last := l.pop(p)
+	top := l.spine[len(l.spine)-1]
+	if last != nil && top.end < p {
+		// The key diverges from the previous one within the prefix of
+		// the last node that left the spine so it is split.
+		i := p - top.end
+		mid := l.node()
+		mid.prefix = l.t.own(last.prefix[:i:i])
+		mid.mod = last.mod
+		l.t.setPrefix(last, last.prefix[i:])
+		mid.children.append(last)
+		top.n.children.nodes[top.n.children.len()-1] = mid
+		top = spineNode[T]{n: mid, end: p}
+		l.spine = append(l.spine, top)
+	}
+
+	lf := l.t.newLeaf(value)
+	if p == len(key) {
+		// Only the first key can end at an existing node, the root.
+		top.n.leaf = lf
+	} else {
+		child := l.node()
+		child.prefix = l.copy(key[p:])
+		child.leaf = lf
+		top.n.children.append(child)
+		l.spine = append(l.spine, spineNode[T]{n: child, end: len(key)})
+	}
+	if l.t.filter != nil {
+		l.t.filter.add(key)
+	}
+	l.prev = append(l.prev[:0], key...)
+	l.size++
+	return lf
+}
+
+// check returns an error as checkQuota does if adding key, which is greater
+// than every key added so far, would exceed a limit of the tree, counting the
+// keys checked before it that are not in the tree.
+func (l *loader[T]) check(key []byte) error {
+	if l.quota == nil {
+		return nil
+	}
+	return l.quota.add(key)
+}
+
+// pop removes the nodes whose keys are longer than end from the spine, updating
+// each of them, and returns the last one removed.
+func (l *loader[T]) pop(end int) *node[T] {
+	var last *node[T]
+	for i := len(l.spine) - 1; i >= 0 && l.spine[i].end > end; i-- {
+		last = l.spine[i].n
+		l.t.update(last)
+		l.spine = l.spine[:i]
+	}
+	return last
+}
+
+// merge completes the subtree by updating the nodes remaining on the spine and
+// merges it into the tree. It returns the number of keys that were already in
+// the tree.
+func (l *loader[T]) merge() int {
+	l.t.unshareAll()
+	for i := len(l.spine) - 1; i >= 0; i-- {
+		l.t.update(l.spine[i].n)
+	}
+	l.spine = l.spine[:0]
+
+	t := l.t
+	if l.quota != nil {
+		// Checking the keys may have totalled the key bytes at this
+		// generation, before the keys were added.
+		t.addKeyBytes(t.gen, l.quota.bytes)
+	}
+	t.notifySubtree(nil, l.root)
+	if t.size == 0 {
+		t.root = l.root
+		t.size = l.size
+		return 0
+	}
+	replaced := t.union(t.root, l.root)
+	t.size += l.size - replaced
+	return replaced
+}
+
+// node returns a new node, touched at the current generation, from the current
+// block of nodes.
+func (l *loader[T]) node() *node[T] {
+	if len(l.nodes) == cap(l.nodes) {
+		l.nodes = make([]node[T], 0, loadBlock)
+	}
+	l.nodes = l.nodes[:len(l.nodes)+1]
+	return l.t.touch(&l.nodes[len(l.nodes)-1])
+}
+
+// copy returns a copy of b from the current block of key bytes, or from the
+// allocator of the tree if it has one.
+func (l *loader[T]) copy(b []byte) []byte {
+	if l.t.opts.alloc != nil {
+		return l.t.own(b)
+	}
+	if cap(l.bytes)-len(l.bytes) < len(b) {
+		size := loadBlock
+		if len(b) > size {
+			size = len(b)
+		}
+		l.bytes = make([]byte, 0, size)
+	}
+	i := len(l.bytes)
+	l.bytes = append(l.bytes, b...)
+	return l.bytes[i:len(l.bytes):len(l.bytes)]
+}
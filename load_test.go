@@ -0,0 +1,91 @@
+package radixtree
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseEntry parses a line of the form "key=value".
+func parseEntry(line []byte) ([]byte, int, error) {
+	i := bytes.IndexByte(line, '=')
+	if i < 0 {
+		return nil, 0, errors.New("missing =")
+	}
+	v, err := strconv.Atoi(string(line[i+1:]))
+	return line[:i], v, err
+}
+
+func TestLoadSorted(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("=100\n")
+	for i, key := range words {
+		input.WriteString(key + "=" + strconv.Itoa(i) + "\n")
+	}
+
+	tree := New[int](WithAggregate(0, func(a, b int) int { return a + b }, func(v int) int { return 1 }))
+	if err := tree.LoadSorted(strings.NewReader(input.String()), parseEntry); err != nil {
+		t.Fatalf("LoadSorted returned error %v", err)
+	}
+	verify(t, tree)
+	if got, want := tree.Len(), len(words)+1; got != want {
+		t.Errorf("Len = %d, want %d", got, want)
+	}
+	if v, ok := tree.Get(nil); !ok || v != 100 {
+		t.Errorf("Get of the empty key = (%d, %t), want (100, true)", v, ok)
+	}
+	for i, key := range words {
+		if v, ok := tree.Get([]byte(key)); !ok || v != i {
+			t.Errorf("Get(%q) = (%d, %t), want (%d, true)", key, v, ok, i)
+		}
+	}
+	if got, _ := Aggregate[int](tree, []byte("mac")); got != 5 {
+		t.Errorf("Aggregate(mac) = %d, want 5", got)
+	}
+
+	// Loading into a non-empty tree merges the entries.
+	tree.Remove([]byte("toad"))
+	if err := tree.LoadSorted(strings.NewReader("a=1\ntoad=2\nzoo=3\n"), parseEntry); err != nil {
+		t.Fatalf("LoadSorted returned error %v", err)
+	}
+	verify(t, tree)
+	if got, want := tree.Len(), len(words)+3; got != want {
+		t.Errorf("Len after second load = %d, want %d", got, want)
+	}
+	if v, _ := tree.Get([]byte("toad")); v != 2 {
+		t.Errorf("Get(toad) = %d, want 2", v)
+	}
+	if got, _ := Aggregate[int](tree, nil); got != len(words)+3 {
+		t.Errorf("Aggregate = %d, want %d", got, len(words)+3)
+	}
+}
+
+func TestLoadSortedErrors(t *testing.T) {
+	tree := New[int](WithBloomFilter(100, 0.01))
+	err := tree.LoadSorted(strings.NewReader("a=1\nab=2\naa=3\nb=4\n"), parseEntry)
+	if !errors.Is(err, ErrUnsorted) || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("LoadSorted with unsorted keys returned %v", err)
+	}
+	verify(t, tree)
+	var keys []string
+	tree.WalkKeys(nil, func(key []byte) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	if want := []string{"a", "ab"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys after error\n got: %v\nwant: %v", keys, want)
+	}
+	if !tree.Contains([]byte("ab")) {
+		t.Errorf("Contains(ab) = false after load")
+	}
+
+	if err := tree.LoadSorted(strings.NewReader("c=1\nc=2\n"), parseEntry); !errors.Is(err, ErrUnsorted) {
+		t.Errorf("LoadSorted with duplicate keys returned %v", err)
+	}
+	if err := tree.LoadSorted(strings.NewReader("d\n"), parseEntry); err == nil || !strings.Contains(err.Error(), "line 1: missing =") {
+		t.Errorf("LoadSorted with a parse error returned %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package radixtree
+
+// Stamp identifies a write to an LWW tree. Stamps are ordered by Time and then
+// by Actor, so that concurrent writes with the same time are resolved the same
+// way on every replica. Every write must have a distinct stamp, which holds if
+// each actor uses its own name and never reuses a time.
+type Stamp struct {
+	Time  int64
+	Actor string
+}
+
+// Less reports whether s is ordered before u.
+func (s Stamp) Less(u Stamp) bool {
+	if s.Time != u.Time {
+		return s.Time < u.Time
+	}
+	return s.Actor < u.Actor
+}
+
+// version is the latest write of a key in an LWW tree.
+type version[T any] struct {
+	value   T
+	stamp   Stamp
+	deleted bool
+}
+
+// supersedes reports whether v wins over the write w of the same key. A delete
+// wins over a set with the same stamp so that the outcome does not depend on
+// the order in which the writes are applied.
+func (v version[T]) supersedes(w version[T]) bool {
+	if v.stamp != w.stamp {
+		return w.stamp.Less(v.stamp)
+	}
+	return v.deleted && !w.deleted
+}
+
+// LWW is a radix tree of last-writer-wins registers. Every write carries a
+// Stamp and only takes effect if its stamp is greater than that of the latest
+// write of the same key, so replicas that apply the same writes in any order,
+// directly or through Merge, end up with the same entries. Deletes are kept as
+// tombstones carrying their stamp so that an older write received later does
+// not bring the key back, until they are removed by PurgeTombstones.
+type LWW[T any] struct {
+	tree *RadixTree[version[T]]
+	live int
+}
+
+// NewLWW creates and returns an empty LWW tree whose underlying tree is
+// configured with the given options. Options that depend on the value type,
+// such as WithAggregate, cannot be used.
+func NewLWW[T any](opts ...Option) *LWW[T] {
+	return &LWW[T]{tree: New[version[T]](opts...)}
+}
+
+// Set writes the value of key with the given stamp. It returns true if the
+// write took effect and false if the key has a write with a greater stamp.
+func (l *LWW[T]) Set(key []byte, value T, stamp Stamp) bool {
+	return l.apply(key, version[T]{value: value, stamp: stamp})
+}
+
+// Delete removes key with the given stamp, leaving a tombstone in its place.
+// It returns true if the delete took effect and false if the key has a write
+// with a greater stamp.
+func (l *LWW[T]) Delete(key []byte, stamp Stamp) bool {
+	return l.apply(key, version[T]{stamp: stamp, deleted: true})
+}
+
+func (l *LWW[T]) apply(key []byte, v version[T]) bool {
+	cur, ok := l.tree.Get(key)
+	if ok && !v.supersedes(cur) {
+		return false
+	}
+	l.tree.Insert(key, v)
+	if !ok || cur.deleted {
+		l.live++
+	}
+	if v.deleted {
+		l.live--
+	}
+	return true
+}
+
+// Get returns the value of key and true if key is in the tree and has not
+// been deleted, or the zero value and false otherwise.
+func (l *LWW[T]) Get(key []byte) (T, bool) {
+	v, ok := l.tree.Get(key)
+	if !ok || v.deleted {
+		var zero T
+		return zero, false
+	}
+	return v.value, true
+}
+
+// Stamp returns the stamp of the latest write of key, which may be a delete,
+// and true, or the zero stamp and false if key has never been written.
+func (l *LWW[T]) Stamp(key []byte) (Stamp, bool) {
+	v, ok := l.tree.Get(key)
+	return v.stamp, ok
+}
+
+// Len returns the number of keys that have not been deleted.
+func (l *LWW[T]) Len() int {
+	return l.live
+}
+
+// Tombstones returns the number of deleted keys whose tombstones are retained.
+func (l *LWW[T]) Tombstones() int {
+	return l.tree.Len() - l.live
+}
+
+// Walk traverses the keys that start with the given prefix and have not been
+// deleted in ascending order and executes function f for each of them. If f
+// returns true the traversal continues otherwise the traversal stops. If f
+// modifies the tree and returns true Walk panics with ErrModified.
+func (l *LWW[T]) Walk(prefix []byte, f func(key []byte, value T) bool) {
+	t := l.tree
+	n, key := t.findPath(prefix, t.borrow())
+	if n != nil {
+		gen := t.gen
+		key, _ = walkNodes(n, key, -1, func(key []byte, n *node[version[T]]) bool {
+			v := n.leaf.value
+			return v.deleted || f(t.yield(key), v.value) && t.unmodified(gen)
+		})
+	}
+	t.release(key)
+}
+
+// Merge applies every write held by other, including its tombstones, to the
+// tree as Set and Delete would and returns the number that took effect. Merge
+// is commutative, associative and idempotent, so replicas that exchange their
+// state in any order converge. The keys of other are copied and other is not
+// changed.
+func (l *LWW[T]) Merge(other *LWW[T]) int {
+	if l == other {
+		return 0
+	}
+	applied := 0
+	walkNodes(other.tree.root, nil, -1, func(key []byte, n *node[version[T]]) bool {
+		if l.apply(append([]byte(nil), key...), n.leaf.value) {
+			applied++
+		}
+		return true
+	})
+	return applied
+}
+
+// PurgeTombstones removes the tombstones of deletes whose stamp has a Time
+// before the given time and returns the number removed. A tombstone must only
+// be purged once every replica has seen the delete, otherwise a replica that
+// still holds an older write of the key resurrects it on the next Merge.
+func (l *LWW[T]) PurgeTombstones(before int64) int {
+	var keys [][]byte
+	walkNodes(l.tree.root, nil, -1, func(key []byte, n *node[version[T]]) bool {
+		if v := n.leaf.value; v.deleted && v.stamp.Time < before {
+			keys = append(keys, append([]byte(nil), key...))
+		}
+		return true
+	})
+	return l.tree.RemoveMany(keys)
+}
@@ -0,0 +1,163 @@
+package radixtree
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func lwwEntries(l *LWW[int]) map[string]int {
+	m := map[string]int{}
+	l.Walk(nil, func(key []byte, value int) bool {
+		m[string(key)] = value
+		return true
+	})
+	return m
+}
+
+func TestLWWSetDelete(t *testing.T) {
+	l := NewLWW[int]()
+	if !l.Set([]byte("apple"), 1, Stamp{Time: 2, Actor: "a"}) {
+		t.Fatal("first Set did not take effect")
+	}
+	if l.Set([]byte("apple"), 2, Stamp{Time: 1, Actor: "b"}) {
+		t.Error("older Set took effect")
+	}
+	if !l.Set([]byte("apple"), 3, Stamp{Time: 2, Actor: "b"}) {
+		t.Error("Set with the same time and a greater actor did not take effect")
+	}
+	if v, ok := l.Get([]byte("apple")); !ok || v != 3 {
+		t.Errorf("Get = %d, %v, want 3, true", v, ok)
+	}
+
+	if !l.Delete([]byte("apple"), Stamp{Time: 3, Actor: "a"}) {
+		t.Fatal("Delete did not take effect")
+	}
+	if _, ok := l.Get([]byte("apple")); ok {
+		t.Error("Get found a deleted key")
+	}
+	if l.Set([]byte("apple"), 4, Stamp{Time: 2, Actor: "c"}) {
+		t.Error("Set older than the tombstone took effect")
+	}
+	if s, ok := l.Stamp([]byte("apple")); !ok || s != (Stamp{Time: 3, Actor: "a"}) {
+		t.Errorf("Stamp = %v, %v, want the stamp of the delete", s, ok)
+	}
+	if l.Len() != 0 || l.Tombstones() != 1 {
+		t.Errorf("Len, Tombstones = %d, %d, want 0, 1", l.Len(), l.Tombstones())
+	}
+
+	// A delete wins over a set with the same stamp in either order.
+	s := Stamp{Time: 5, Actor: "a"}
+	l.Set([]byte("apple"), 5, s)
+	if !l.Delete([]byte("apple"), s) {
+		t.Error("Delete with the stamp of the Set did not take effect")
+	}
+	if l.Set([]byte("apple"), 5, s) {
+		t.Error("Set with the stamp of the Delete took effect")
+	}
+}
+
+func TestLWWMerge(t *testing.T) {
+	writes := []struct {
+		key     string
+		value   int
+		stamp   Stamp
+		deleted bool
+	}{
+		{"apple", 1, Stamp{1, "a"}, false},
+		{"apple", 2, Stamp{1, "b"}, false},
+		{"applesauce", 3, Stamp{2, "a"}, false},
+		{"applesauce", 0, Stamp{3, "b"}, true},
+		{"banana", 4, Stamp{2, "b"}, false},
+		{"banana", 0, Stamp{1, "a"}, true},
+		{"cherry", 5, Stamp{4, "c"}, false},
+	}
+	replica := func(actors ...string) *LWW[int] {
+		l := NewLWW[int]()
+		for _, w := range writes {
+			for _, a := range actors {
+				if w.stamp.Actor != a {
+					continue
+				}
+				if w.deleted {
+					l.Delete([]byte(w.key), w.stamp)
+				} else {
+					l.Set([]byte(w.key), w.value, w.stamp)
+				}
+			}
+		}
+		return l
+	}
+
+	want := map[string]int{"apple": 2, "banana": 4, "cherry": 5}
+	a, b, c := replica("a"), replica("b"), replica("c")
+	ab := replica("a")
+	ab.Merge(b)
+	ab.Merge(c)
+	cb := replica("c")
+	cb.Merge(b)
+	cb.Merge(a)
+	for _, l := range []*LWW[int]{ab, cb} {
+		if got := lwwEntries(l); !reflect.DeepEqual(got, want) {
+			t.Errorf("merged entries = %v, want %v", got, want)
+		}
+		if l.Len() != 3 || l.Tombstones() != 1 {
+			t.Errorf("Len, Tombstones = %d, %d, want 3, 1", l.Len(), l.Tombstones())
+		}
+	}
+
+	if n := ab.Merge(cb); n != 0 {
+		t.Errorf("Merge of a converged replica applied %d writes, want 0", n)
+	}
+	if n := ab.Merge(ab); n != 0 {
+		t.Errorf("Merge with itself applied %d writes, want 0", n)
+	}
+	if got := lwwEntries(a); !reflect.DeepEqual(got, map[string]int{"apple": 1, "applesauce": 3}) {
+		t.Errorf("Merge changed its argument: %v", got)
+	}
+}
+
+func TestLWWWalk(t *testing.T) {
+	l := NewLWW[int]()
+	for i, w := range words {
+		l.Set([]byte(w), i, Stamp{Time: 1})
+	}
+	for _, w := range words {
+		if strings.HasPrefix(w, "wi") {
+			l.Delete([]byte(w), Stamp{Time: 2})
+		}
+	}
+	var got []string
+	l.Walk([]byte("w"), func(key []byte, _ int) bool {
+		got = append(got, string(key))
+		return true
+	})
+	for _, w := range got {
+		if strings.HasPrefix(w, "wi") {
+			t.Errorf("Walk visited the deleted key %q", w)
+		}
+	}
+	if len(got) == 0 {
+		t.Error("Walk visited no keys")
+	}
+}
+
+func TestLWWPurgeTombstones(t *testing.T) {
+	l := NewLWW[int]()
+	l.Set([]byte("apple"), 1, Stamp{Time: 1})
+	l.Set([]byte("banana"), 2, Stamp{Time: 1})
+	l.Delete([]byte("apple"), Stamp{Time: 2})
+	l.Delete([]byte("banana"), Stamp{Time: 4})
+	if n := l.PurgeTombstones(3); n != 1 {
+		t.Errorf("PurgeTombstones = %d, want 1", n)
+	}
+	if _, ok := l.Stamp([]byte("apple")); ok {
+		t.Error("tombstone of apple was not purged")
+	}
+	if _, ok := l.Stamp([]byte("banana")); !ok {
+		t.Error("tombstone of banana was purged")
+	}
+	if l.Len() != 0 || l.Tombstones() != 1 {
+		t.Errorf("Len, Tombstones = %d, %d, want 0, 1", l.Len(), l.Tombstones())
+	}
+}
@@ -0,0 +1,29 @@
+package radixtree
+
+// FromMap creates and returns a radix tree configured with the given options
+// holding the entries of m. The keys are sorted and built into the tree in a
+// single pass as by InsertMany rather than inserted one at a time. With
+// WithInsertionOrder the entries are inserted in an unspecified order. Like
+// InsertMany, FromMap panics if the entries would exceed a limit set by the
+// options.
+func FromMap[T any](m map[string]T, opts ...Option) *RadixTree[T] {
+	t := New[T](opts...)
+	entries := make([]Entry[T], 0, len(m))
+	for k, v := range m {
+		entries = append(entries, Entry[T]{Key: []byte(k), Value: v})
+	}
+	t.InsertMany(entries)
+	return t
+}
+
+// ToMap returns a map holding the entries of the tree.
+func (t *RadixTree[T]) ToMap() map[string]T {
+	m := make(map[string]T, t.size)
+	key := t.borrow()
+	key, _ = walkNodes(t.root, key, -1, func(key []byte, n *node[T]) bool {
+		m[string(key)] = n.leaf.value
+		return true
+	})
+	t.release(key)
+	return m
+}
@@ -0,0 +1,42 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFromMap(t *testing.T) {
+	m := map[string]string{}
+	for _, w := range words {
+		m[w] = w
+	}
+	m[""] = "root"
+	tree := FromMap(m)
+	verify(t, tree)
+	if got, want := tree.Len(), len(m); got != want {
+		t.Errorf("Len = %d, want %d", got, want)
+	}
+	for k, want := range m {
+		if got, ok := tree.Get([]byte(k)); !ok || got != want {
+			t.Errorf("Get(%q) = (%q, %t), want (%q, true)", k, got, ok, want)
+		}
+	}
+	if FromMap[int](nil).Len() != 0 {
+		t.Errorf("FromMap(nil) is not empty")
+	}
+}
+
+func TestToMap(t *testing.T) {
+	tree := build(words)
+	tree.Insert(nil, "root")
+	m := tree.ToMap()
+	if got, want := len(m), tree.Len(); got != want {
+		t.Errorf("len(ToMap()) = %d, want %d", got, want)
+	}
+	if !reflect.DeepEqual(FromMap(m).ToMap(), m) {
+		t.Errorf("FromMap(ToMap()) differs from the tree")
+	}
+	if m := New[int]().ToMap(); m == nil || len(m) != 0 {
+		t.Errorf("ToMap of an empty tree = %v, want an empty map", m)
+	}
+}
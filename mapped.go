@@ -0,0 +1,430 @@
+package radixtree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"iter"
+	"math"
+	"os"
+)
+
+// Mapped is an immutable radix tree stored in a file that is mapped into
+// memory, for trees larger than the memory of the process: the operating
+// system reads the pages of the file that lookups touch and may evict them
+// again, so only the parts of the tree in use take memory. Values are stored
+// encoded and decoded each time they are read. A Mapped tree is created by
+// WriteMapped or by MappedTiered.Compact and opened by OpenMapped. It is safe
+// for concurrent use until it is closed.
+//
+// The nodes are written in post-order, so that each is written after its
+// children and can refer to them by their offsets in the file, and the offset
+// of the root is stored at the end of the file. This lets the file be written
+// from a sorted stream of keys while holding only the nodes on the path of the
+// last key. Each node is stored as its prefix, its value, if any, the first
+// bytes of its children and their offsets. The file is read lazily, so a
+// corrupt file is only detected when the corrupt part is read, at which point
+// the method reading it returns ErrCorrupt.
+type Mapped[T any] struct {
+	data   []byte
+	root   uint64
+	size   int
+	decode func(b []byte) (T, error)
+}
+
+// mappedMagic starts every file written by WriteMapped, followed by the format
+// version.
+var mappedMagic = []byte("RDXM\x01")
+
+// mappedTrailer is the length of the end of the file, which holds the offset
+// of the root and the number of keys.
+const mappedTrailer = 16
+
+// mappedNode is a node read from the file of a Mapped tree. The offsets of the
+// children are children[8*i:8*i+8] in little endian order.
+type mappedNode struct {
+	off      uint64
+	prefix   []byte
+	value    []byte
+	hasValue bool
+	firsts   []byte
+	children []byte
+}
+
+// WriteMapped writes the tree to w in the format read by OpenMapped, encoding
+// the values with encode. The byte order of the tree is not recorded, so a
+// tiered tree using the file must be created with the same WithByteOrder
+// option as the tree.
+func (t *RadixTree[T]) WriteMapped(w io.Writer, encode func(value T) ([]byte, error)) error {
+	mw, err := newMappedWriter(w)
+	if err != nil {
+		return err
+	}
+	key := t.borrow()
+	key, _ = walkNodes(t.root, key, -1, func(key []byte, n *node[T]) bool {
+		var b []byte
+		if b, err = encode(n.leaf.value); err == nil {
+			err = mw.add(key, b)
+		}
+		return err == nil
+	})
+	t.release(key)
+	if err != nil {
+		return err
+	}
+	return mw.finish()
+}
+
+// OpenMapped maps the file at path, written by WriteMapped or
+// MappedTiered.Compact, and returns the tree it holds, whose values are
+// decoded with decode. Only the header and trailer of the file are checked.
+// The file must not be modified while it is mapped, but it may be removed or
+// replaced by renaming another file over it. On platforms without mmap the
+// file is read into memory.
+func OpenMapped[T any](path string, decode func(b []byte) (T, error)) (*Mapped[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+	if size < int64(len(mappedMagic)+mappedTrailer) || size > math.MaxInt {
+		return nil, ErrCorrupt
+	}
+	data, err := mmapFile(f, int(size))
+	if err != nil {
+		return nil, err
+	}
+	m := &Mapped[T]{data: data, decode: decode}
+	trailer := data[len(data)-mappedTrailer:]
+	m.root = binary.LittleEndian.Uint64(trailer)
+	count := binary.LittleEndian.Uint64(trailer[8:])
+	// Every key takes at least one byte for its node.
+	if !bytes.HasPrefix(data, mappedMagic) || count > uint64(size) ||
+		m.root < uint64(len(mappedMagic)) || m.root >= uint64(size-mappedTrailer) {
+		munmapFile(data)
+		return nil, ErrCorrupt
+	}
+	m.size = int(count)
+	return m, nil
+}
+
+// Close unmaps the file. The tree must not be used after Close.
+func (m *Mapped[T]) Close() error {
+	if m.data == nil {
+		return os.ErrClosed
+	}
+	err := munmapFile(m.data)
+	m.data = nil
+	return err
+}
+
+// Len returns the number of keys in the tree.
+func (m *Mapped[T]) Len() int {
+	return m.size
+}
+
+// Get returns the decoded value of key and true, or the zero value for type T
+// and false if the key is not in the tree. The error is that of reading or
+// decoding the value.
+func (m *Mapped[T]) Get(key []byte) (T, bool, error) {
+	var zero T
+	n, ok, err := m.lookup(key)
+	if !ok || err != nil {
+		return zero, false, err
+	}
+	v, err := m.decode(n.value)
+	if err != nil {
+		return zero, false, err
+	}
+	return v, true, nil
+}
+
+// Walk executes f for each key that starts with the given prefix and its
+// decoded value, in the order the keys were written, which is ascending order.
+// The key passed to f is a copy that f may retain. If f returns false Walk
+// stops. If the file is corrupt or a value cannot be decoded Walk stops and
+// returns the error.
+func (m *Mapped[T]) Walk(prefix []byte, f func(key []byte, value T) bool) error {
+	var err error
+	for key, b := range m.all(prefix, &err) {
+		var v T
+		if v, err = m.decode(b); err != nil || !f(key, v) {
+			break
+		}
+	}
+	return err
+}
+
+// contains returns true if key is in the tree, without decoding its value.
+func (m *Mapped[T]) contains(key []byte) (bool, error) {
+	_, ok, err := m.lookup(key)
+	return ok, err
+}
+
+// lookup returns the node holding the value of key and true, or false if the
+// key is not in the tree.
+func (m *Mapped[T]) lookup(key []byte) (mappedNode, bool, error) {
+	n, err := m.node(m.root)
+	for err == nil && bytes.HasPrefix(key, n.prefix) {
+		if key = key[len(n.prefix):]; len(key) == 0 {
+			return n, n.hasValue, nil
+		}
+		i := bytes.IndexByte(n.firsts, key[0])
+		if i < 0 {
+			break
+		}
+		n, err = m.child(n, i, 0)
+	}
+	return mappedNode{}, false, err
+}
+
+// all returns an iterator over the keys that start with prefix and their
+// encoded values in the order they were written. The keys are copies and the
+// values point into the file. If the file is corrupt the iteration stops and
+// sets *errp.
+func (m *Mapped[T]) all(prefix []byte, errp *error) iter.Seq2[[]byte, []byte] {
+	return func(yield func(key, value []byte) bool) {
+		n, key, err := m.seek(prefix)
+		if n.off == 0 || err != nil {
+			*errp = err
+			return
+		}
+		// A node is pushed with the length of the key of its parent and
+		// the bound below which the offsets of its descendants must be.
+		// Each child's subtree lies between its offset and the offset of
+		// the previous child, so no node of a corrupt file is visited
+		// twice.
+		type item struct {
+			off, low uint64
+			keyLen   int
+		}
+		stack := []item{{off: n.off, keyLen: len(key) - len(n.prefix)}}
+		for len(stack) > 0 {
+			it := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if n, err = m.node(it.off); err != nil {
+				*errp = err
+				return
+			}
+			key = append(key[:it.keyLen], n.prefix...)
+			if n.hasValue && !yield(append([]byte(nil), key...), n.value) {
+				return
+			}
+			top := len(stack)
+			low := it.low
+			for i := range n.firsts {
+				c, err := m.childOffset(n, i, low)
+				if err != nil {
+					*errp = err
+					return
+				}
+				stack = append(stack, item{off: c, low: low, keyLen: len(key)})
+				low = c
+			}
+			// The children are visited in order.
+			for i, j := top, len(stack)-1; i < j; i, j = i+1, j-1 {
+				stack[i], stack[j] = stack[j], stack[i]
+			}
+		}
+	}
+}
+
+// seek returns the node below which the keys with the given prefix are and its
+// key, or a node with a zero offset if there are no such keys.
+func (m *Mapped[T]) seek(prefix []byte) (mappedNode, []byte, error) {
+	var key []byte
+	n, err := m.node(m.root)
+	for err == nil {
+		if len(prefix) <= len(n.prefix) {
+			if !bytes.HasPrefix(n.prefix, prefix) {
+				break
+			}
+			return n, append(key, n.prefix...), nil
+		}
+		if !bytes.HasPrefix(prefix, n.prefix) {
+			break
+		}
+		key = append(key, n.prefix...)
+		prefix = prefix[len(n.prefix):]
+		i := bytes.IndexByte(n.firsts, prefix[0])
+		if i < 0 {
+			break
+		}
+		n, err = m.child(n, i, 0)
+	}
+	return mappedNode{}, nil, err
+}
+
+// child reads the i-th child of n, whose offset must be above low.
+func (m *Mapped[T]) child(n mappedNode, i int, low uint64) (mappedNode, error) {
+	c, err := m.childOffset(n, i, low)
+	if err != nil {
+		return mappedNode{}, err
+	}
+	return m.node(c)
+}
+
+// childOffset returns the offset of the i-th child of n. Since the nodes are
+// written in post-order the offset must be below that of n, which also keeps
+// a lookup in a corrupt file from looping, and above low.
+func (m *Mapped[T]) childOffset(n mappedNode, i int, low uint64) (uint64, error) {
+	c := binary.LittleEndian.Uint64(n.children[8*i:])
+	if c >= n.off || c <= low {
+		return 0, ErrCorrupt
+	}
+	return c, nil
+}
+
+// node reads the node at offset off.
+func (m *Mapped[T]) node(off uint64) (mappedNode, error) {
+	if m.data == nil {
+		return mappedNode{}, os.ErrClosed
+	}
+	end := uint64(len(m.data) - mappedTrailer)
+	if off < uint64(len(mappedMagic)) || off >= end {
+		return mappedNode{}, ErrCorrupt
+	}
+	n := mappedNode{off: off}
+	b := m.data[off:end]
+	l, k := binary.Uvarint(b)
+	if k <= 0 || l > uint64(len(b)-k) {
+		return n, ErrCorrupt
+	}
+	n.prefix, b = b[k:k+int(l)], b[k+int(l):]
+	// The length of the value is stored plus one, or as zero if the node
+	// has no value.
+	if l, k = binary.Uvarint(b); k <= 0 || l > uint64(len(b)-k)+1 {
+		return n, ErrCorrupt
+	}
+	if n.hasValue = l > 0; n.hasValue {
+		n.value = b[k : k+int(l-1)]
+	}
+	b = b[k+len(n.value):]
+	if l, k = binary.Uvarint(b); k <= 0 || l > 256 || l*9 > uint64(len(b)-k) {
+		return n, ErrCorrupt
+	}
+	b = b[k:]
+	n.firsts, n.children = b[:l], b[l:9*l]
+	return n, nil
+}
+
+// mappedWriter writes the nodes of a tree in the format of Mapped from its keys
+// in ascending order.
+type mappedWriter struct {
+	w     *bufio.Writer
+	off   uint64
+	count uint64
+	last  []byte // the last key added
+	// stack holds the nodes on the path of the last key that are not
+	// written yet, from the root down. Their children are all written.
+	stack []mappedFrame
+	buf   []byte
+}
+
+// mappedFrame is a node that is not written yet, whose key is the first depth
+// bytes of the last key.
+type mappedFrame struct {
+	depth    int
+	value    []byte
+	hasValue bool
+	firsts   []byte
+	children []uint64
+}
+
+func newMappedWriter(w io.Writer) (*mappedWriter, error) {
+	mw := &mappedWriter{
+		w:     bufio.NewWriter(w),
+		off:   uint64(len(mappedMagic)),
+		stack: []mappedFrame{{}},
+	}
+	_, err := mw.w.Write(mappedMagic)
+	return mw, err
+}
+
+// add adds a key, which must be greater than the last one, and its encoded
+// value, writing the nodes that no later key can be below.
+func (w *mappedWriter) add(key, value []byte) error {
+	l := 0
+	if w.count > 0 {
+		l = longestCommonPrefix(w.last, key)
+	}
+	if err := w.pop(l); err != nil {
+		return err
+	}
+	value = append([]byte(nil), value...)
+	if top := &w.stack[len(w.stack)-1]; top.depth == len(key) {
+		// Only the empty key can be at an existing node.
+		top.value, top.hasValue = value, true
+	} else {
+		w.stack = append(w.stack, mappedFrame{depth: len(key), value: value, hasValue: true})
+	}
+	w.last = append(w.last[:0], key...)
+	w.count++
+	return nil
+}
+
+// pop writes the nodes deeper than depth, adding a node at depth where the
+// next key branches off the last one below an existing node.
+func (w *mappedWriter) pop(depth int) error {
+	for len(w.stack) > 1 && w.stack[len(w.stack)-1].depth > depth {
+		f := w.stack[len(w.stack)-1]
+		w.stack = w.stack[:len(w.stack)-1]
+		if w.stack[len(w.stack)-1].depth < depth {
+			w.stack = append(w.stack, mappedFrame{depth: depth})
+		}
+		parent := &w.stack[len(w.stack)-1]
+		off, err := w.write(&f, w.last[parent.depth:f.depth])
+		if err != nil {
+			return err
+		}
+		parent.firsts = append(parent.firsts, w.last[parent.depth])
+		parent.children = append(parent.children, off)
+	}
+	return nil
+}
+
+// write writes a node with the given prefix and returns its offset.
+func (w *mappedWriter) write(f *mappedFrame, prefix []byte) (uint64, error) {
+	b := binary.AppendUvarint(w.buf[:0], uint64(len(prefix)))
+	b = append(b, prefix...)
+	if f.hasValue {
+		b = binary.AppendUvarint(b, uint64(len(f.value))+1)
+		b = append(b, f.value...)
+	} else {
+		b = append(b, 0)
+	}
+	b = binary.AppendUvarint(b, uint64(len(f.firsts)))
+	b = append(b, f.firsts...)
+	for _, c := range f.children {
+		b = binary.LittleEndian.AppendUint64(b, c)
+	}
+	w.buf = b
+	off := w.off
+	w.off += uint64(len(b))
+	_, err := w.w.Write(b)
+	return off, err
+}
+
+// finish writes the remaining nodes and the trailer.
+func (w *mappedWriter) finish() error {
+	if err := w.pop(0); err != nil {
+		return err
+	}
+	root, err := w.write(&w.stack[0], nil)
+	if err != nil {
+		return err
+	}
+	var trailer [mappedTrailer]byte
+	binary.LittleEndian.PutUint64(trailer[:], root)
+	binary.LittleEndian.PutUint64(trailer[8:], w.count)
+	if _, err := w.w.Write(trailer[:]); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
@@ -0,0 +1,149 @@
+package radixtree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeMapped writes tree to a file in a temporary directory and opens it.
+func writeMapped(t *testing.T, tree *RadixTree[string]) *Mapped[string] {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := tree.WriteMapped(&buf, encodeString); err != nil {
+		t.Fatalf("WriteMapped: %v", err)
+	}
+	m, err := openMappedBytes(t, buf.Bytes())
+	if err != nil {
+		t.Fatalf("OpenMapped: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func openMappedBytes(t *testing.T, data []byte) (*Mapped[string], error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tree")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return OpenMapped(path, decodeString)
+}
+
+func mappedKeys(t *testing.T, m *Mapped[string], prefix string) []string {
+	t.Helper()
+	var keys []string
+	err := m.Walk([]byte(prefix), func(key []byte, value string) bool {
+		if string(key) != value {
+			t.Errorf("Walk passed %q with value %q", key, value)
+		}
+		keys = append(keys, string(key))
+		return true
+	})
+	if err != nil {
+		t.Errorf("Walk(%q): %v", prefix, err)
+	}
+	return keys
+}
+
+func TestMapped(t *testing.T) {
+	tree := build(append([]string{""}, words...))
+	m := writeMapped(t, tree)
+	if m.Len() != tree.Len() {
+		t.Errorf("Len = %d, want %d", m.Len(), tree.Len())
+	}
+	for _, key := range append([]string{"", "toadstool", "ant", "zzz"}, words...) {
+		v, ok, err := m.Get([]byte(key))
+		want, wok := tree.Get([]byte(key))
+		if v != want || ok != wok || err != nil {
+			t.Errorf("Get(%q) = %q, %t, %v, want %q, %t", key, v, ok, err, want, wok)
+		}
+	}
+	if got, want := mappedKeys(t, m, ""), keysOf(tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk = %q, want %q", got, want)
+	}
+	for _, prefix := range []string{"toad", "to", "aardvark", "zzz"} {
+		var want []string
+		tree.WalkKeys([]byte(prefix), func(key []byte) bool {
+			want = append(want, string(key))
+			return true
+		})
+		if got := mappedKeys(t, m, prefix); !reflect.DeepEqual(got, want) {
+			t.Errorf("Walk(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+
+	empty := writeMapped(t, New[string]())
+	if _, ok, err := empty.Get(nil); ok || err != nil || empty.Len() != 0 {
+		t.Errorf("empty tree: Get = %t, %v, Len = %d", ok, err, empty.Len())
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if _, _, err := m.Get([]byte("toad")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Get after Close = %v, want os.ErrClosed", err)
+	}
+}
+
+func TestMappedCorrupt(t *testing.T) {
+	var buf bytes.Buffer
+	build(words).WriteMapped(&buf, encodeString)
+	valid := buf.Bytes()
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("RDXF"), valid[4:]...),
+		"truncated": valid[:len(valid)-1],
+	} {
+		if _, err := openMappedBytes(t, data); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%s: OpenMapped = %v, want ErrCorrupt", name, err)
+		}
+	}
+
+	// node returns a node with the given children, which are pairs of a
+	// first byte and an offset.
+	node := func(children ...uint64) []byte {
+		b := []byte{0, 0, byte(len(children) / 2)}
+		for i := 0; i < len(children); i += 2 {
+			b = append(b, byte(children[i]))
+		}
+		for i := 1; i < len(children); i += 2 {
+			b = binary.LittleEndian.AppendUint64(b, children[i])
+		}
+		return b
+	}
+	file := func(root uint64, nodes ...[]byte) []byte {
+		b := append([]byte(nil), mappedMagic...)
+		for _, n := range nodes {
+			b = append(b, n...)
+		}
+		b = binary.LittleEndian.AppendUint64(b, root)
+		return binary.LittleEndian.AppendUint64(b, 1)
+	}
+	leaf := []byte{1, 'a', 2, 'v', 0}
+	off := uint64(len(mappedMagic))
+	for name, data := range map[string][]byte{
+		// A node that is its own child would loop.
+		"cycle": file(off, node('a', off)),
+		// Two children sharing a subtree would be walked twice.
+		"shared": file(off+5, leaf, node('a', off, 'b', off)),
+		// A length running past the end of the file.
+		"length": file(off, []byte{200, 0, 0}),
+	} {
+		m, err := openMappedBytes(t, data)
+		if err != nil {
+			t.Fatalf("%s: OpenMapped: %v", name, err)
+		}
+		if _, _, err := m.Get([]byte(strings.Repeat("a", 10))); name != "shared" && !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%s: Get = %v, want ErrCorrupt", name, err)
+		}
+		if err := m.Walk(nil, func([]byte, string) bool { return true }); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%s: Walk = %v, want ErrCorrupt", name, err)
+		}
+		m.Close()
+	}
+}
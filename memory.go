@@ -0,0 +1,75 @@
+package radixtree
+
+import "unsafe"
+
+// MemUsage is an estimate of the memory used by a tree, in bytes. The sizes
+// cover the structure of the tree itself and the values stored in it but not
+// memory that the values refer to, such as the contents of strings or the
+// targets of pointers, nor boxed subtree aggregates.
+type MemUsage struct {
+	// Nodes is the memory used by the nodes of the tree.
+	Nodes int
+	// Prefixes is the memory used by the compressed key prefixes of the
+	// nodes. Prefixes that share a backing array are counted separately.
+	Prefixes int
+	// Children is the memory used by the slices of child nodes, including
+	// unused capacity.
+	Children int
+	// Values is the memory used by the leaves holding the stored values.
+	Values int
+	// Other is the memory used by the bloom filter, the lookup caches and
+	// the reusable buffers of the tree.
+	Other int
+}
+
+// Total returns the total memory usage in bytes.
+func (m MemUsage) Total() int {
+	return m.Nodes + m.Prefixes + m.Children + m.Values + m.Other
+}
+
+// MemUsage walks the tree and returns an estimate of the memory it uses.
+func (t *RadixTree[T]) MemUsage() MemUsage {
+	var m MemUsage
+	memUsage(t.root, &m)
+	m.Other += int(unsafe.Sizeof(*t)) + cap(t.scratch) + cap(t.path)*int(unsafe.Sizeof(t.root))
+	if t.filter != nil {
+		m.Other += int(unsafe.Sizeof(*t.filter)) + cap(t.filter.bits)*8
+	}
+	for _, c := range []*cache[T]{t.cache, t.lpc} {
+		if c == nil {
+			continue
+		}
+		m.Other += int(unsafe.Sizeof(*c)) + cap(c.sets)*int(unsafe.Sizeof(cacheSet[T]{}))
+		for i := range c.sets {
+			for _, e := range c.sets[i].ways {
+				m.Other += cap(e.key)
+			}
+		}
+	}
+	return m
+}
+
+// MemUsagePrefix returns an estimate of the memory used by the subtree holding
+// the keys that start with the given prefix. The node that the prefix ends in
+// is counted in full even if part of its prefix lies before the given prefix.
+// Other is always zero.
+func (t *RadixTree[T]) MemUsagePrefix(prefix []byte) MemUsage {
+	var m MemUsage
+	if n := t.find(prefix); n != nil {
+		memUsage(n, &m)
+	}
+	return m
+}
+
+// memUsage adds the memory used by the subtree rooted at n to m.
+func memUsage[T any](n *node[T], m *MemUsage) {
+	m.Nodes += int(unsafe.Sizeof(*n))
+	m.Prefixes += len(n.prefix)
+	m.Children += cap(n.children.nodes)*int(unsafe.Sizeof(n)) + cap(n.children.keys)
+	if n.hasValue() {
+		m.Values += int(unsafe.Sizeof(*n.leaf))
+	}
+	for _, child := range n.children.nodes {
+		memUsage(child, m)
+	}
+}
@@ -0,0 +1,61 @@
+package radixtree
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestMemUsage(t *testing.T) {
+	tree := New[int64]()
+	empty := tree.MemUsage()
+	if empty.Nodes != int(unsafe.Sizeof(node[int64]{})) || empty.Values != 0 || empty.Prefixes != 0 {
+		t.Errorf("MemUsage of an empty tree = %+v", empty)
+	}
+
+	// The keys form a root with children "a" and "b", where "a" has
+	// children "b" and "c".
+	for i, key := range []string{"ab", "ac", "b"} {
+		tree.Insert([]byte(key), int64(i))
+	}
+	m := tree.MemUsage()
+	if want := 5 * int(unsafe.Sizeof(node[int64]{})); m.Nodes != want {
+		t.Errorf("Nodes = %d, want %d", m.Nodes, want)
+	}
+	if m.Prefixes != 4 {
+		t.Errorf("Prefixes = %d, want 4", m.Prefixes)
+	}
+	if want := 3 * int(unsafe.Sizeof(leaf[int64]{})); m.Values != want {
+		t.Errorf("Values = %d, want %d", m.Values, want)
+	}
+	if m.Children < 4*int(unsafe.Sizeof(&node[int64]{})) {
+		t.Errorf("Children = %d is less than the size of 4 pointers", m.Children)
+	}
+	if m.Total() != m.Nodes+m.Prefixes+m.Children+m.Values+m.Other {
+		t.Errorf("Total = %d does not match the sum of %+v", m.Total(), m)
+	}
+
+	withFilter := New[int64](WithBloomFilter(1000, 0.01), WithLookupCache(64))
+	if got, want := withFilter.MemUsage().Other, empty.Other+1000; got < want {
+		t.Errorf("Other with a bloom filter and cache = %d, want at least %d", got, want)
+	}
+}
+
+func TestMemUsagePrefix(t *testing.T) {
+	tree := New[int64]()
+	for i, key := range []string{"ab", "ac", "b"} {
+		tree.Insert([]byte(key), int64(i))
+	}
+	m := tree.MemUsagePrefix([]byte("a"))
+	if want := 3 * int(unsafe.Sizeof(node[int64]{})); m.Nodes != want {
+		t.Errorf("Nodes = %d, want %d", m.Nodes, want)
+	}
+	if want := 2 * int(unsafe.Sizeof(leaf[int64]{})); m.Values != want {
+		t.Errorf("Values = %d, want %d", m.Values, want)
+	}
+	if m.Other != 0 {
+		t.Errorf("Other = %d, want 0", m.Other)
+	}
+	if got := tree.MemUsagePrefix([]byte("x")); got != (MemUsage{}) {
+		t.Errorf("MemUsagePrefix of a missing prefix = %+v", got)
+	}
+}
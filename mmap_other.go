@@ -0,0 +1,23 @@
+//go:build !unix || aix
+
+package radixtree
+
+import (
+	"io"
+	"os"
+)
+
+// mmapFile reads the first size bytes of f, on platforms where they cannot be
+// mapped.
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// munmapFile releases memory returned by mmapFile.
+func munmapFile([]byte) error {
+	return nil
+}
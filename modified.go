@@ -0,0 +1,65 @@
+package radixtree
+
+// Generation returns the current generation of the tree. The generation is
+// incremented by every modification so entries inserted or updated after a
+// call to Generation are visited by WalkModifiedSince with the returned value.
+func (t *RadixTree[T]) Generation() uint64 {
+	return t.gen
+}
+
+// WalkModifiedSince executes function f for each key and value that has been
+// inserted or updated after the given generation, in ascending key order.
+// Subtrees without such entries are skipped so the cost is proportional to the
+// number of modified entries rather than the size of the tree. If the tree was
+// not created with WithModificationTracking every entry is visited. The key
+// passed to f is a copy that the caller may retain unless the tree was created
+// with WithSharedKeys. If f returns true the traversal continues otherwise the
+// traversal stops. If f modifies the tree and returns true WalkModifiedSince
+// panics with ErrModified.
+func (t *RadixTree[T]) WalkModifiedSince(gen uint64, f func(key []byte, value T) bool) {
+	if !t.opts.trackMods {
+		gen = 0
+	}
+	current := t.gen
+	key := t.borrow()
+	key, _ = walkModified(t.root, key, gen, !t.opts.trackMods, func(key []byte, n *node[T]) bool {
+		return f(t.yield(key), n.leaf.value) && t.unmodified(current)
+	})
+	t.release(key)
+}
+
+// walkModified is like walkNodes but only visits the nodes whose leaves were
+// modified after generation gen, or every node with a value if all is true.
+func walkModified[T any](n *node[T], key []byte, gen uint64, all bool, f func(key []byte, n *node[T]) bool) ([]byte, bool) {
+	if !all && n.mod <= gen {
+		return key, true
+	}
+	if n.hasValue() && (all || n.leaf.mod > gen) && !f(key, n) {
+		return key, false
+	}
+	for _, child := range n.children.nodes {
+		l := len(key)
+		var ok bool
+		key, ok = walkModified(child, append(key, child.prefix...), gen, all, f)
+		key = key[:l]
+		if !ok {
+			return key, false
+		}
+	}
+	return key, true
+}
+
+// touchAll marks every node and leaf in the subtree rooted at n as modified in
+// the current generation if modifications are tracked.
+func (t *RadixTree[T]) touchAll(n *node[T]) {
+	if !t.opts.trackMods {
+		return
+	}
+	t.touch(n)
+	if n.hasValue() {
+		n.leaf.mod = t.gen
+	}
+	for _, child := range n.children.nodes {
+		t.touchAll(child)
+	}
+}
@@ -0,0 +1,63 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func modifiedSince[T any](tree *RadixTree[T], gen uint64) []string {
+	var keys []string
+	tree.WalkModifiedSince(gen, func(key []byte, _ T) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	return keys
+}
+
+func TestWalkModifiedSince(t *testing.T) {
+	tree := New[string](WithModificationTracking())
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	if got := modifiedSince(tree, 0); !reflect.DeepEqual(got, words) {
+		t.Errorf("WalkModifiedSince(0)\n got: %v\nwant: %v", got, words)
+	}
+
+	gen := tree.Generation()
+	if got := modifiedSince(tree, gen); len(got) != 0 {
+		t.Errorf("WalkModifiedSince(Generation())\n got: %v\nwant: []", got)
+	}
+
+	tree.Insert([]byte("toad"), "TOAD")
+	tree.Insert([]byte("macroa"), "macroa")
+	tree.Insert([]byte("abacus"), "abacus")
+	tree.Remove([]byte("winkle"))
+	want := []string{"abacus", "macroa", "toad"}
+	if got := modifiedSince(tree, gen); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkModifiedSince after updates\n got: %v\nwant: %v", got, want)
+	}
+
+	// Splitting and joining keeps the generations of the entries.
+	joined, err := Join(tree.Split([]byte("m")))
+	if err != nil {
+		t.Fatalf("Join returned error %v", err)
+	}
+	if got := modifiedSince(joined, gen); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkModifiedSince after Split and Join\n got: %v\nwant: %v", got, want)
+	}
+
+	// Grafted entries count as modified.
+	gen = joined.Generation()
+	joined.Graft([]byte("x"), build([]string{"a", "b"}))
+	want = []string{"xa", "xb"}
+	if got := modifiedSince(joined, gen); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkModifiedSince after Graft\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestWalkModifiedSinceUntracked(t *testing.T) {
+	tree := build(words)
+	if got := modifiedSince(tree, tree.Generation()); !reflect.DeepEqual(got, words) {
+		t.Errorf("WalkModifiedSince without tracking\n got: %v\nwant: %v", got, words)
+	}
+}
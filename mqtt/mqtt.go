@@ -0,0 +1,195 @@
+// Package mqtt matches MQTT topic names against subscriptions to topic filters,
+// for message brokers and bridges that need to find the subscribers of each
+// published message.
+//
+// Topics are divided into levels by slashes. A filter matches the topics with
+// the same levels, except that the level "+" matches any single level and a
+// final level "#" matches any number of levels, including none, so that
+// "sport/#" matches "sport", "sport/tennis" and "sport/tennis/player1". As the
+// MQTT specification requires, filters that start with a wildcard do not
+// match topics starting with "$", which are reserved for the broker.
+//
+// The filters are stored in a radix tree keyed by their levels, each followed
+// by a slash, so a topic is matched by following its levels down the tree and
+// only the branches for its own levels and for wildcards are visited.
+package mqtt
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+// MaxLen is the greatest length in bytes of a topic name or filter.
+const MaxLen = 65535
+
+var (
+	// ErrInvalidTopic is returned when a topic name is not valid.
+	ErrInvalidTopic = errors.New("mqtt: invalid topic name")
+	// ErrInvalidFilter is returned when a topic filter is not valid.
+	ErrInvalidFilter = errors.New("mqtt: invalid topic filter")
+)
+
+// ValidTopic returns an error wrapping ErrInvalidTopic if topic is empty,
+// longer than MaxLen, or has a wildcard or a NUL byte.
+func ValidTopic(topic string) error {
+	if topic == "" || len(topic) > MaxLen || strings.ContainsAny(topic, "+#\x00") {
+		return fmt.Errorf("%w: %q", ErrInvalidTopic, topic)
+	}
+	return nil
+}
+
+// ValidFilter returns an error wrapping ErrInvalidFilter if filter is empty,
+// longer than MaxLen or has a NUL byte, if a wildcard does not occupy a whole
+// level or if "#" is not the last level.
+func ValidFilter(filter string) error {
+	if filter == "" || len(filter) > MaxLen || strings.ContainsRune(filter, 0) {
+		return fmt.Errorf("%w: %q", ErrInvalidFilter, filter)
+	}
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if level != "+" && level != "#" && strings.ContainsAny(level, "+#") || level == "#" && i != len(levels)-1 {
+			return fmt.Errorf("%w: %q has wildcard level %q", ErrInvalidFilter, filter, level)
+		}
+	}
+	return nil
+}
+
+// Subscription is a subscriber to a topic filter.
+type Subscription[S comparable] struct {
+	Filter     string
+	Subscriber S
+}
+
+// Tree holds subscriptions to topic filters. A Tree is not safe for concurrent
+// use.
+type Tree[S comparable] struct {
+	tree *radixtree.RadixTree[[]S]
+	size int
+}
+
+// New returns a tree without subscriptions.
+func New[S comparable]() *Tree[S] {
+	return &Tree[S]{tree: radixtree.New[[]S]()}
+}
+
+// key returns the key of a filter, its levels each followed by a slash.
+func key(filter string) []byte {
+	return []byte(filter + "/")
+}
+
+// Subscribe subscribes s to the filter and returns true, or false if s was
+// already subscribed to it. It returns an error wrapping ErrInvalidFilter if
+// the filter is not valid.
+func (t *Tree[S]) Subscribe(filter string, s S) (bool, error) {
+	if err := ValidFilter(filter); err != nil {
+		return false, err
+	}
+	k := key(filter)
+	subs, _ := t.tree.Get(k)
+	for _, sub := range subs {
+		if sub == s {
+			return false, nil
+		}
+	}
+	t.tree.Insert(k, append(subs, s))
+	t.size++
+	return true, nil
+}
+
+// Unsubscribe removes the subscription of s to the filter and returns true, or
+// false if s is not subscribed to it.
+func (t *Tree[S]) Unsubscribe(filter string, s S) bool {
+	k := key(filter)
+	subs, _ := t.tree.Get(k)
+	for i, sub := range subs {
+		if sub != s {
+			continue
+		}
+		t.size--
+		if len(subs) == 1 {
+			t.tree.Remove(k)
+			return true
+		}
+		t.tree.Insert(k, append(subs[:i], subs[i+1:]...))
+		return true
+	}
+	return false
+}
+
+// Len returns the number of subscriptions.
+func (t *Tree[S]) Len() int {
+	return t.size
+}
+
+// Match returns the subscriptions whose filters match the topic in ascending
+// order of their filters and, for each filter, in the order they were made. A
+// subscriber appears once for each of its filters that match. It returns an
+// error wrapping ErrInvalidTopic if the topic is not valid.
+func (t *Tree[S]) Match(topic string) ([]Subscription[S], error) {
+	if err := ValidTopic(topic); err != nil {
+		return nil, err
+	}
+	var matches []Subscription[S]
+	t.match(nil, strings.Split(topic, "/"), strings.HasPrefix(topic, "$"), func(k []byte, subs []S) {
+		filter := string(k[:len(k)-1])
+		for _, s := range subs {
+			matches = append(matches, Subscription[S]{Filter: filter, Subscriber: s})
+		}
+	})
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Filter < matches[j].Filter
+	})
+	return matches, nil
+}
+
+// Subscribers returns the distinct subscribers with a filter that matches the
+// topic, in the order of their first subscription in Match.
+func (t *Tree[S]) Subscribers(topic string) ([]S, error) {
+	matches, err := t.Match(topic)
+	if err != nil {
+		return nil, err
+	}
+	var subs []S
+	seen := make(map[S]bool, len(matches))
+	for _, m := range matches {
+		if !seen[m.Subscriber] {
+			seen[m.Subscriber] = true
+			subs = append(subs, m.Subscriber)
+		}
+	}
+	return subs, nil
+}
+
+// match calls f for the filters below the key prefix that match the remaining
+// levels of a topic. If reserved is true the topic starts with "$" and the
+// first level is not matched by wildcards.
+func (t *Tree[S]) match(prefix []byte, levels []string, reserved bool, f func(key []byte, subs []S)) {
+	get := func(k []byte) {
+		if subs, ok := t.tree.Get(k); ok {
+			f(k, subs)
+		}
+	}
+	if !reserved {
+		// "#" matches the remaining levels, or none of them after a
+		// level of the filter.
+		get(append(prefix[:len(prefix):len(prefix)], "#/"...))
+	}
+	if len(levels) == 0 {
+		get(prefix)
+		return
+	}
+	candidates := []string{levels[0]}
+	if !reserved {
+		candidates = append(candidates, "+")
+	}
+	for _, level := range candidates {
+		k := append(append(prefix[:len(prefix):len(prefix)], level...), '/')
+		if t.tree.HasPrefix(k) {
+			t.match(k, levels[1:], false, f)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package mqtt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tree := New[string]()
+	for _, sub := range []Subscription[string]{
+		{"sport/tennis/player1", "a"},
+		{"sport/tennis/+", "b"},
+		{"sport/#", "c"},
+		{"sport/+", "d"},
+		{"#", "e"},
+		{"+/+", "f"},
+		{"$SYS/#", "g"},
+		{"/finance", "h"},
+		{"+/finance", "i"},
+		{"sport/#", "a"},
+		{"a//b", "j"},
+	} {
+		if ok, err := tree.Subscribe(sub.Filter, sub.Subscriber); !ok || err != nil {
+			t.Fatalf("Subscribe(%q, %q) = %v, %v", sub.Filter, sub.Subscriber, ok, err)
+		}
+	}
+	if ok, _ := tree.Subscribe("sport/#", "c"); ok {
+		t.Error("Subscribe of an existing subscription returned true")
+	}
+	if tree.Len() != 11 {
+		t.Errorf("Len() = %d, want 11", tree.Len())
+	}
+
+	for topic, want := range map[string][]string{
+		"sport":                 {"#e", "sport/#c", "sport/#a"},
+		"sport/tennis":          {"#e", "+/+f", "sport/#c", "sport/#a", "sport/+d"},
+		"sport/tennis/player1":  {"#e", "sport/#c", "sport/#a", "sport/tennis/+b", "sport/tennis/player1a"},
+		"sport/tennis/player1/": {"#e", "sport/#c", "sport/#a"},
+		"/finance":              {"#e", "+/+f", "+/financei", "/financeh"},
+		"$SYS/broker":           {"$SYS/#g"},
+		"$SYS":                  {"$SYS/#g"},
+		"a//b":                  {"#e", "a//bj"},
+		"news":                  {"#e"},
+	} {
+		matches, err := tree.Match(topic)
+		if err != nil {
+			t.Fatalf("Match(%q) returned error %v", topic, err)
+		}
+		var got []string
+		for _, m := range matches {
+			got = append(got, m.Filter+m.Subscriber)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Match(%q)\n got: %v\nwant: %v", topic, got, want)
+		}
+	}
+
+	subs, _ := tree.Subscribers("sport/tennis/player1")
+	if want := []string{"e", "c", "a", "b"}; !reflect.DeepEqual(subs, want) {
+		t.Errorf("Subscribers(sport/tennis/player1) = %v, want %v", subs, want)
+	}
+
+	if !tree.Unsubscribe("sport/#", "c") || tree.Unsubscribe("sport/#", "c") || !tree.Unsubscribe("#", "e") {
+		t.Error("Unsubscribe did not remove each subscription once")
+	}
+	subs, _ = tree.Subscribers("sport")
+	if want := []string{"a"}; !reflect.DeepEqual(subs, want) || tree.Len() != 9 {
+		t.Errorf("Subscribers(sport) after Unsubscribe = %v with Len() %d, want %v with 9", subs, tree.Len(), want)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	tree := New[int]()
+	for _, filter := range []string{"", "sport/tennis#", "sport/#/ranking", "sport+", "a/\x00"} {
+		if _, err := tree.Subscribe(filter, 1); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("Subscribe(%q) returned %v, want %v", filter, err, ErrInvalidFilter)
+		}
+	}
+	for _, topic := range []string{"", "sport/+", "sport/#", "a\x00"} {
+		if _, err := tree.Match(topic); !errors.Is(err, ErrInvalidTopic) {
+			t.Errorf("Match(%q) returned %v, want %v", topic, err, ErrInvalidTopic)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package radixtree
+
+// Nested is a two-level tree: an outer tree keyed by namespace holding an inner
+// tree of keys and values for each namespace. Keys in different namespaces are
+// independent, a namespace is created by the first insertion into it and
+// removed with its last key, and a whole namespace is dropped by unlinking its
+// inner tree rather than removing its keys one at a time.
+type Nested[T any] struct {
+	outer *RadixTree[*RadixTree[T]]
+	opts  []Option
+	size  int
+}
+
+// NewNested returns an empty Nested tree whose inner trees are created with
+// the given options.
+func NewNested[T any](opts ...Option) *Nested[T] {
+	return &Nested[T]{outer: New[*RadixTree[T]](), opts: opts}
+}
+
+// Drop removes the namespace ns and every key in it and returns the number of
+// keys removed.
+func (t *Nested[T]) Drop(ns []byte) int {
+	inner, ok := t.outer.Remove(ns)
+	if !ok {
+		return 0
+	}
+	t.size -= inner.Len()
+	return inner.Len()
+}
+
+// Get returns the value associated with key in the namespace ns and true, or
+// the zero value for type T and false if the key is not in the namespace.
+func (t *Nested[T]) Get(ns, key []byte) (T, bool) {
+	if inner, ok := t.outer.Get(ns); ok {
+		return inner.Get(key)
+	}
+	var zero T
+	return zero, false
+}
+
+// Insert inserts the value under key in the namespace ns, creating the
+// namespace if needed. It returns the value previously associated with the key
+// and true, or the zero value for type T and false if there was none. As with
+// RadixTree.Insert the inner tree retains the key, while the namespace is
+// copied.
+func (t *Nested[T]) Insert(ns, key []byte, value T) (T, bool) {
+	inner, ok := t.outer.Get(ns)
+	if !ok {
+		inner = New[T](t.opts...)
+		t.outer.Insert(append([]byte(nil), ns...), inner)
+	}
+	old, replaced := inner.Insert(key, value)
+	if !replaced {
+		t.size++
+	}
+	return old, replaced
+}
+
+// Len returns the number of keys in all the namespaces.
+func (t *Nested[T]) Len() int {
+	return t.size
+}
+
+// Namespace returns the inner tree of the namespace ns, or nil if there is no
+// such namespace. Changes made to the inner tree directly are not reflected in
+// Len and a namespace emptied through it is not removed.
+func (t *Nested[T]) Namespace(ns []byte) *RadixTree[T] {
+	inner, _ := t.outer.Get(ns)
+	return inner
+}
+
+// Namespaces returns the number of namespaces.
+func (t *Nested[T]) Namespaces() int {
+	return t.outer.Len()
+}
+
+// Remove removes key from the namespace ns, and the namespace if it is left
+// empty, and returns the value of the key and true, or the zero value for type
+// T and false if the key is not in the namespace.
+func (t *Nested[T]) Remove(ns, key []byte) (T, bool) {
+	inner, ok := t.outer.Get(ns)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	value, ok := inner.Remove(key)
+	if ok {
+		t.size--
+		if inner.IsEmpty() {
+			t.outer.Remove(ns)
+		}
+	}
+	return value, ok
+}
+
+// WalkAll executes function f for each namespace, key and value in ascending
+// order of namespace and then of key within each namespace. The namespace and
+// key passed to f are copies that the caller may retain unless the inner trees
+// were created with WithSharedKeys. If f returns true the traversal continues
+// otherwise the traversal stops.
+func (t *Nested[T]) WalkAll(f func(ns, key []byte, value T) bool) {
+	for ns, inner := range t.outer.All() {
+		for key, value := range inner.All() {
+			if !f(ns, key, value) {
+				return
+			}
+		}
+	}
+}
+
+// WalkNamespace executes function f for each key in the namespace ns that
+// starts with the given prefix, and its value, in ascending key order. The key
+// passed to f is a copy that the caller may retain unless the inner trees were
+// created with WithSharedKeys. If f returns true the traversal continues
+// otherwise the traversal stops.
+func (t *Nested[T]) WalkNamespace(ns, prefix []byte, f func(key []byte, value T) bool) {
+	if inner, ok := t.outer.Get(ns); ok {
+		for key, value := range inner.AllPrefix(prefix) {
+			if !f(key, value) {
+				return
+			}
+		}
+	}
+}
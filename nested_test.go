@@ -0,0 +1,67 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNested(t *testing.T) {
+	n := NewNested[int]()
+	for i, e := range []struct{ ns, key string }{
+		{"b", "x"}, {"a", "y"}, {"a", "x"}, {"ab", "x"}, {"b", ""},
+	} {
+		if _, replaced := n.Insert([]byte(e.ns), []byte(e.key), i); replaced {
+			t.Errorf("Insert(%q, %q) replaced a value", e.ns, e.key)
+		}
+	}
+	if old, replaced := n.Insert([]byte("a"), []byte("x"), 9); !replaced || old != 2 {
+		t.Errorf("Insert(%q, %q) = (%d, %t), want (2, true)", "a", "x", old, replaced)
+	}
+	if n.Len() != 5 || n.Namespaces() != 3 {
+		t.Errorf("Len, Namespaces = %d, %d, want 5, 3", n.Len(), n.Namespaces())
+	}
+	if v, ok := n.Get([]byte("a"), []byte("x")); !ok || v != 9 {
+		t.Errorf("Get(%q, %q) = (%d, %t), want (9, true)", "a", "x", v, ok)
+	}
+	if _, ok := n.Get([]byte("c"), []byte("x")); ok {
+		t.Errorf("Get found a key in a missing namespace")
+	}
+
+	var got []string
+	n.WalkAll(func(ns, key []byte, value int) bool {
+		got = append(got, string(ns)+"/"+string(key))
+		return true
+	})
+	if want := []string{"a/x", "a/y", "ab/x", "b/", "b/x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkAll\n got: %v\nwant: %v", got, want)
+	}
+	got = nil
+	n.WalkNamespace([]byte("a"), nil, func(key []byte, _ int) bool {
+		got = append(got, string(key))
+		return false
+	})
+	if want := []string{"x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkNamespace\n got: %v\nwant: %v", got, want)
+	}
+
+	// Removing the last key of a namespace removes the namespace.
+	if v, ok := n.Remove([]byte("ab"), []byte("x")); !ok || v != 3 {
+		t.Errorf("Remove(%q, %q) = (%d, %t), want (3, true)", "ab", "x", v, ok)
+	}
+	if n.Namespace([]byte("ab")) != nil || n.Namespaces() != 2 {
+		t.Errorf("Remove of the last key left the namespace")
+	}
+	if _, ok := n.Remove([]byte("ab"), []byte("x")); ok {
+		t.Errorf("Remove of a missing key returned true")
+	}
+
+	if got := n.Drop([]byte("a")); got != 2 {
+		t.Errorf("Drop(%q) = %d, want 2", "a", got)
+	}
+	if got := n.Drop([]byte("a")); got != 0 {
+		t.Errorf("Drop of a missing namespace = %d, want 0", got)
+	}
+	if n.Len() != 2 || n.Namespaces() != 1 || n.Namespace([]byte("b")).Len() != 2 {
+		t.Errorf("Len, Namespaces = %d, %d after Drop, want 2, 1", n.Len(), n.Namespaces())
+	}
+}
@@ -0,0 +1,52 @@
+package radixtree
+
+// NodeView is a read-only view of a node of a tree, for serializers and
+// visualizers that need the structure of the tree. A NodeView is only valid
+// until the tree is modified; its methods panic with ErrModified afterwards.
+type NodeView[T any] struct {
+	t   *RadixTree[T]
+	n   *node[T]
+	gen uint64
+}
+
+// Root returns a view of the root node of the tree, whose prefix is empty.
+func (t *RadixTree[T]) Root() NodeView[T] {
+	return NodeView[T]{t: t, n: t.root, gen: t.gen}
+}
+
+// Prefix returns the part of the key that the node adds to the key of its
+// parent. The prefix must not be modified.
+func (v NodeView[T]) Prefix() []byte {
+	v.t.unmodified(v.gen)
+	return v.n.prefix
+}
+
+// HasValue returns true if the node holds the value of a key.
+func (v NodeView[T]) HasValue() bool {
+	v.t.unmodified(v.gen)
+	return v.n.hasValue()
+}
+
+// Value returns the value held by the node and true, or the zero value for
+// type T and false if the node holds no value.
+func (v NodeView[T]) Value() (T, bool) {
+	v.t.unmodified(v.gen)
+	if !v.n.hasValue() {
+		var zero T
+		return zero, false
+	}
+	return v.n.leaf.value, true
+}
+
+// Children returns views of the children of the node in ascending key order.
+// Nodes left without any values by WithLazyDelete are skipped, as by Accept.
+func (v NodeView[T]) Children() []NodeView[T] {
+	v.t.unmodified(v.gen)
+	var children []NodeView[T]
+	for _, child := range v.n.children.nodes {
+		if child.count > 0 {
+			children = append(children, NodeView[T]{t: v.t, n: child, gen: v.gen})
+		}
+	}
+	return children
+}
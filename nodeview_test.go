@@ -0,0 +1,42 @@
+package radixtree
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// viewKeys returns the keys and values below a node view by concatenating
+// the prefixes of the nodes.
+func viewKeys(v NodeView[string], key string, keys []string) []string {
+	key += string(v.Prefix())
+	if value, ok := v.Value(); ok {
+		if value != key || !v.HasValue() {
+			panic("NodeView value " + value + " for key " + key)
+		}
+		keys = append(keys, key)
+	}
+	for _, child := range v.Children() {
+		keys = viewKeys(child, key, keys)
+	}
+	return keys
+}
+
+func TestNodeView(t *testing.T) {
+	tree := build(words)
+	root := tree.Root()
+	if len(root.Prefix()) != 0 || root.HasValue() {
+		t.Errorf("Root() has prefix %q and value %v", root.Prefix(), root.HasValue())
+	}
+	if got := viewKeys(root, "", nil); !reflect.DeepEqual(got, words) {
+		t.Errorf("keys of the node views\n got: %v\nwant: %v", got, words)
+	}
+
+	tree.Insert([]byte("toadstool"), "toadstool")
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrModified) {
+			t.Errorf("NodeView of a modified tree panicked with %v, want %v", err, ErrModified)
+		}
+	}()
+	root.Children()
+}
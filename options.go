@@ -0,0 +1,255 @@
+package radixtree
+
+import "cmp"
+
+// Option configures optional behaviour of a radix tree created by New.
+type Option func(*options)
+
+// options holds the configuration shared by a tree and any trees derived from
+// it.
+type options struct {
+	bloomSize  int
+	bloomRate  float64
+	cacheSize  int
+	sharedKeys bool
+	copyKeys   bool
+
+	insertionOrder bool
+	trackMods      bool
+	deferMerge     bool
+	lazyDelete     bool
+
+	aggregate any
+	alloc     *allocator
+	order     *byteOrder
+
+	history int
+
+	hotDepth int
+	hotEvery int
+
+	maxKeyLen    int
+	maxEntries   int
+	maxKeyBytes  int
+	prefixLimits []prefixLimit
+}
+
+// WithBloomFilter enables a bloom filter that is consulted by Get and Contains
+// to reject keys that are definitely not in the tree without traversing it.
+// The filter is sized for n keys with the given false positive rate and is
+// updated on every insert. Removing a key does not clear it from the filter so
+// a tree with heavy churn will see its false positive rate rise over time.
+func WithBloomFilter(n int, falsePositiveRate float64) Option {
+	return func(o *options) {
+		o.bloomSize = n
+		o.bloomRate = falsePositiveRate
+	}
+}
+
+// WithLookupCache enables a small cache of recent Get and LongestPrefix results
+// holding up to size entries for each method. It suits workloads where a
+// handful of keys dominate the lookups. Any mutation of the tree invalidates
+// the cache. Get, Contains and LongestPrefix update the cache, so with this
+// option they write to the tree and need the same exclusive access as Insert:
+// unlike the reads of other trees they must not run concurrently with any
+// other method.
+func WithLookupCache(size int) Option {
+	return func(o *options) {
+		o.cacheSize = size
+	}
+}
+
+// WithSharedKeys makes the methods that pass keys to a callback, such as
+// WalkKeys and WalkDepth, reuse a single scratch buffer instead of copying each
+// key. This avoids an allocation per key but the key is only valid until the
+// callback returns and must be copied if it is retained. Since the buffer
+// belongs to the tree, traversals of such a tree write to it and must not run
+// concurrently: each tree created with the option, and each snapshot of one,
+// is for a single reader at a time.
+func WithSharedKeys() Option {
+	return func(o *options) {
+		o.sharedKeys = true
+	}
+}
+
+// WithKeyCopy makes Insert and Graft copy the bytes of the keys they are
+// passed. By default the tree is zero-copy: it retains the caller's slice as
+// the prefix of the nodes it adds, saving an allocation per insertion, so the
+// caller must not modify or reuse the slice afterwards. With WithKeyCopy the
+// caller may reuse its buffer for the next key. Trees created with
+// WithAllocator always copy keys into memory from the allocator.
+func WithKeyCopy() Option {
+	return func(o *options) {
+		o.copyKeys = true
+	}
+}
+
+// WithInsertionOrder makes the tree record the order in which keys are first
+// inserted so that they can be visited in that order by WalkByInsertion.
+func WithInsertionOrder() Option {
+	return func(o *options) {
+		o.insertionOrder = true
+	}
+}
+
+// WithModificationTracking makes the tree record the generation at which each
+// entry was last inserted or updated so that WalkModifiedSince can visit only
+// the entries changed after a given generation.
+func WithModificationTracking() Option {
+	return func(o *options) {
+		o.trackMods = true
+	}
+}
+
+// WithDeferredMerge makes Remove and RemoveMany leave a node that no longer
+// holds a value in place when it has a single child, rather than merging it
+// with the child, until Compact is called. Reinserting a removed key then
+// reuses its nodes instead of splitting a merged node again, which suits bursts
+// of removals followed by reinsertions of the same keys. Nodes left with
+// neither a value nor children are still removed.
+func WithDeferredMerge() Option {
+	return func(o *options) {
+		o.deferMerge = true
+	}
+}
+
+// WithLazyDelete makes Remove and RemoveMany only clear the value of a key and
+// the counts on the path to it, in time proportional to the length of the key,
+// leaving every node in place until Compact prunes the empty subtrees and
+// merges the nodes left without a value. This keeps removals free of
+// structural changes on hot paths; the cost is paid when compacting, which can
+// be done periodically with CompactEvery. Lookups skip the empty subtrees so
+// they only see the entries in the tree.
+func WithLazyDelete() Option {
+	return func(o *options) {
+		o.lazyDelete = true
+	}
+}
+
+// WithAggregate makes the tree maintain an aggregate of the values in every
+// subtree, which Aggregate returns for any prefix in time proportional to the
+// length of the prefix. The aggregate is defined by a monoid: zero is the
+// aggregate of no values, combine merges two aggregates and fromValue gives the
+// aggregate of a single value. combine must be associative and zero must be its
+// identity. Aggregates are combined in ascending key order so combine need not
+// be commutative. The value type T must match the type of the tree.
+func WithAggregate[T, A any](zero A, combine func(a, b A) A, fromValue func(value T) A) Option {
+	return func(o *options) {
+		o.aggregate = &monoid[T, A]{zero: zero, combine: combine, fromValue: fromValue}
+	}
+}
+
+// WithAllocator makes the tree store the bytes of the keys it holds in memory
+// obtained from a, copying them from the keys passed to Insert, and return the
+// memory to a once it is no longer referenced. Nodes are still allocated by
+// Go. Trees that are derived from the tree, such as those returned by Split,
+// share the allocator.
+func WithAllocator(a Allocator) Option {
+	return func(o *options) {
+		o.alloc = &allocator{a}
+	}
+}
+
+// WithByteOrder makes the tree order keys by comparing table[b] rather than b
+// for each of their bytes, so that methods such as Min, Successor, Walk and
+// Split follow a custom collation like a case-insensitive order. Bytes that
+// table maps to the same value are ordered by their own value, one byte at a
+// time, so with a table that folds case "bAnd" is ordered before "banana". Keys
+// are stored and matched unchanged.
+func WithByteOrder(table [256]byte) Option {
+	return func(o *options) {
+		o.order = newByteOrder(table)
+	}
+}
+
+// prefixLimit is the greatest number of keys with a prefix, for
+// WithPrefixLimit.
+type prefixLimit struct {
+	prefix []byte
+	n      int
+}
+
+// WithMaxKeyLen limits the length of the keys in the tree to n bytes. Inserting
+// a longer key fails with ErrKeyTooLong before the tree is searched for it.
+func WithMaxKeyLen(n int) Option {
+	return func(o *options) {
+		o.maxKeyLen = n
+	}
+}
+
+// WithMaxEntries limits the tree to n keys. Inserting a key that is not in the
+// tree when it already holds n keys fails with ErrQuotaExceeded.
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
+	}
+}
+
+// WithMaxKeyBytes limits the total length of the keys in the tree to n bytes.
+// Inserting a key that is not in the tree fails with ErrQuotaExceeded if the
+// total would then exceed n. The total is kept up to date by Insert, Remove,
+// InsertMany and LoadSorted; after other changes, such as RemoveMany or Graft,
+// the next insert walks the tree to total the keys again.
+func WithMaxKeyBytes(n int) Option {
+	return func(o *options) {
+		o.maxKeyBytes = n
+	}
+}
+
+// WithPrefixLimit limits the number of keys in the tree that start with prefix
+// to n, so that a tree shared by tenants keyed by their names can bound each
+// of them. Inserting a key with the prefix that is not in the tree when n keys
+// already have it fails with ErrQuotaExceeded. The option may be given several
+// times, for different prefixes, and a key is checked against every limit on
+// a prefix of it. The prefix is copied.
+func WithPrefixLimit(prefix []byte, n int) Option {
+	prefix = append([]byte(nil), prefix...)
+	return func(o *options) {
+		o.prefixLimits = append(o.prefixLimits, prefixLimit{prefix: prefix, n: n})
+	}
+}
+
+// WithValueIndex makes the tree maintain the least and greatest value in every
+// subtree, ordered by the result of calling value for each of them, so that
+// MinByValue, MaxByValue and WalkByValue find the entries with the least or
+// greatest values without visiting every entry while the tree stays ordered by
+// key. For an ordered value type value may return its argument. The index is
+// the aggregate of the tree, so WithValueIndex cannot be combined with
+// WithAggregate and whichever is given last takes effect. The value type T
+// must match the type of the tree.
+func WithValueIndex[T any, V cmp.Ordered](value func(v T) V) Option {
+	return func(o *options) {
+		o.aggregate = &valueIndex[T, V]{extract: value}
+	}
+}
+
+// WithHistory makes the tree keep the last n values of every key before its
+// current one, so that History can return what a key held before its recent
+// updates. The history of a key is discarded when the key is removed. Every
+// update of a key copies its history, so the cost of an update grows with n.
+func WithHistory(n int) Option {
+	return func(o *options) {
+		o.history = n
+	}
+}
+
+// WithHotPrefixes makes the tree count its lookups and inserts by the first
+// depth bytes of their keys, so that HotPrefixes can report which parts of the
+// key space receive the most traffic, for instance to plan how to shard it.
+// One in every n operations is sampled to keep the cost low, and the counts are
+// scaled up by n; an n below 1 samples every operation. Get, Contains and
+// LongestPrefix count as accesses and Insert and InsertErr as inserts, while
+// bulk operations such as InsertMany are not counted. Keys shorter than depth
+// are counted under the whole key. A prefix is kept for every distinct start
+// of the sampled keys, so depth should be small enough to bound their number.
+// Snapshots and forks of the tree start with no counts. The counters are not
+// synchronized: since the lookups update them, a tree counting its traffic
+// cannot be read by several goroutines at once, and HotPrefixes must not run
+// concurrently with any other method. A SyncMap counts under the mutexes of
+// its shards, so it remains safe for concurrent use.
+func WithHotPrefixes(depth, n int) Option {
+	return func(o *options) {
+		o.hotDepth = depth
+		o.hotEvery = n
+	}
+}
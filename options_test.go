@@ -0,0 +1,69 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithSharedKeys(t *testing.T) {
+	tree := New[string](WithSharedKeys())
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+
+	var got []string
+	tree.WalkKeys([]byte("to"), func(key []byte) bool {
+		got = append(got, string(key))
+		return true
+	})
+	if want := hasPrefix("to", words); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkKeys(to)\n got: %v\nwant: %v", got, want)
+	}
+
+	// The keys are not copied so walking the whole tree should not allocate
+	// once per key.
+	allocs := testing.AllocsPerRun(10, func() {
+		tree.WalkKeys(nil, func(key []byte) bool { return true })
+	})
+	if allocs >= float64(len(words)) {
+		t.Errorf("WalkKeys with shared keys\n got: %.0f allocations\nwant: < %d", allocs, len(words))
+	}
+
+	// Nested traversals must not clobber the keys of the enclosing one.
+	tree.WalkKeys([]byte("wi"), func(outer []byte) bool {
+		want := string(outer)
+		tree.WalkKeys([]byte("a"), func(key []byte) bool { return true })
+		if string(outer) != want {
+			t.Errorf("nested WalkKeys changed the outer key\n got: %s\nwant: %s", outer, want)
+		}
+		return true
+	})
+
+	// AppendKeys always copies.
+	appended := tree.AppendKeys(nil, []byte("to"))
+	if &appended[0][0] == &appended[1][0] {
+		t.Errorf("AppendKeys with shared keys did not copy the keys")
+	}
+}
+
+func TestWithKeyCopy(t *testing.T) {
+	tree := New[string](WithKeyCopy())
+	buf := make([]byte, 0, 16)
+	for _, key := range words {
+		// The buffer is reused for every key.
+		buf = append(buf[:0], key...)
+		tree.Insert(buf, key)
+	}
+	buf = append(buf[:0], "mount"...)
+	sub := New[string]()
+	sub.Insert([]byte("ed"), "mounted")
+	tree.Graft(buf, sub)
+	copy(buf, "xxxxx")
+
+	verify(t, tree)
+	for _, key := range append(words, "mounted") {
+		if got, ok := tree.Get([]byte(key)); !ok || got != key {
+			t.Errorf("Get(%q) = (%q, %t), want (%q, true)", key, got, ok, key)
+		}
+	}
+}
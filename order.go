@@ -0,0 +1,56 @@
+package radixtree
+
+import "sort"
+
+// WalkByInsertion executes function f for every key and value in the tree in
+// the order the keys were first inserted. Updating the value of an existing key
+// does not change its position. If the tree was not created with
+// WithInsertionOrder the keys are visited in ascending key order. The key passed
+// to f is a copy that the caller may retain. If f returns true the traversal
+// continues otherwise the traversal stops. If f modifies the tree and returns
+// true WalkByInsertion panics with ErrModified.
+//
+// The entries are collected and sorted before f is first called so a walk
+// takes O(n log n) time and O(n) space regardless of when it is stopped.
+func (t *RadixTree[T]) WalkByInsertion(f func(key []byte, value T) bool) {
+	type entry struct {
+		key  []byte
+		leaf *leaf[T]
+	}
+	entries := make([]entry, 0, t.size)
+	walkNodes(t.root, nil, -1, func(key []byte, n *node[T]) bool {
+		entries = append(entries, entry{append([]byte(nil), key...), n.leaf})
+		return true
+	})
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].leaf.seq < entries[j].leaf.seq
+	})
+
+	gen := t.gen
+	for _, e := range entries {
+		if !f(e.key, e.leaf.value) || !t.unmodified(gen) {
+			return
+		}
+	}
+}
+
+// sequence renumbers the entries of the subtree rooted at n, which have been
+// moved into t from another tree, so that they follow every entry already in t
+// while keeping their relative insertion order.
+func (t *RadixTree[T]) sequence(n *node[T]) {
+	if !t.opts.insertionOrder {
+		return
+	}
+	var leaves []*leaf[T]
+	walkNodes(n, nil, -1, func(_ []byte, n *node[T]) bool {
+		leaves = append(leaves, n.leaf)
+		return true
+	})
+	sort.SliceStable(leaves, func(i, j int) bool {
+		return leaves[i].seq < leaves[j].seq
+	})
+	for _, l := range leaves {
+		t.seq++
+		l.seq = t.seq
+	}
+}
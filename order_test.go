@@ -0,0 +1,70 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func walkByInsertion[T any](tree *RadixTree[T]) []string {
+	var keys []string
+	tree.WalkByInsertion(func(key []byte, _ T) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	return keys
+}
+
+func TestWalkByInsertion(t *testing.T) {
+	order := []string{"toad", "abacus", "to", "winkle", "toa", "aardvark", "win"}
+	tree := New[int](WithInsertionOrder())
+	for i, key := range order {
+		tree.Insert([]byte(key), i)
+	}
+
+	if got := walkByInsertion(tree); !reflect.DeepEqual(got, order) {
+		t.Errorf("WalkByInsertion\n got: %v\nwant: %v", got, order)
+	}
+
+	// Updates keep their position and removed keys are skipped. Removing
+	// "to" merges its node with its only child.
+	tree.Insert([]byte("abacus"), 100)
+	tree.Remove([]byte("to"))
+	tree.Remove([]byte("toa"))
+	want := []string{"toad", "abacus", "winkle", "aardvark", "win"}
+	if got := walkByInsertion(tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkByInsertion after update and remove\n got: %v\nwant: %v", got, want)
+	}
+
+	tree.Insert([]byte("to"), 7)
+	want = append(want, "to")
+	if got := walkByInsertion(tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkByInsertion after reinsert\n got: %v\nwant: %v", got, want)
+	}
+
+	// Splitting and joining keeps the original order.
+	joined, err := Join(tree.Split([]byte("t")))
+	if err != nil {
+		t.Fatalf("Join returned error %v", err)
+	}
+	if got := walkByInsertion(joined); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkByInsertion after Split and Join\n got: %v\nwant: %v", got, want)
+	}
+
+	// Grafted entries follow the existing ones in their own order.
+	sub := New[int](WithInsertionOrder())
+	sub.Insert([]byte("b"), 0)
+	sub.Insert([]byte("a"), 1)
+	joined.Graft([]byte("x"), sub)
+	want = append(want, "xb", "xa")
+	if got := walkByInsertion(joined); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkByInsertion after Graft\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestWalkByInsertionUntracked(t *testing.T) {
+	tree := build([]string{"c", "a", "b"})
+	want := []string{"a", "b", "c"}
+	if got := walkByInsertion(tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkByInsertion without insertion order\n got: %v\nwant: %v", got, want)
+	}
+}
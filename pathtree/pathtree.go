@@ -0,0 +1,273 @@
+// Package pathtree indexes slash-separated file paths, such as the contents of
+// an fs.FS or a listing of an artifact store, in a radix tree so that
+// directories can be listed, everything under a path found and paths matched
+// against glob patterns without walking the whole index.
+//
+// Paths follow the rules of io/fs: they are unrooted, slash-separated and
+// contain no "." or ".." elements, with "." naming the root. Adding a path adds
+// the directories above it, so every directory in the index has an entry.
+package pathtree
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"path"
+	"strings"
+	"time"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+)
+
+// ErrInvalidPath is returned when a path is not valid according to
+// fs.ValidPath.
+var ErrInvalidPath = errors.New("pathtree: invalid path")
+
+// Entry describes a file or directory in the index. Paths added without
+// metadata have a zero Size and ModTime.
+type Entry struct {
+	Path    string
+	Mode    fs.FileMode
+	Size    int64
+	ModTime time.Time
+}
+
+// IsDir reports whether the entry is a directory.
+func (e Entry) IsDir() bool {
+	return e.Mode.IsDir()
+}
+
+// Name returns the last element of the path of the entry.
+func (e Entry) Name() string {
+	return path.Base(e.Path)
+}
+
+// Tree is an index of paths. A Tree is not safe for concurrent use.
+type Tree struct {
+	tree *radixtree.RadixTree[Entry]
+}
+
+// New returns an empty index.
+func New() *Tree {
+	return &Tree{tree: radixtree.New[Entry]()}
+}
+
+// FromFS returns an index of the files and directories in fsys below root,
+// which is included, as found by fs.WalkDir. The paths in the index are those
+// of fsys rather than relative to root.
+func FromFS(fsys fs.FS, root string) (*Tree, error) {
+	t := New()
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return t.Add(Entry{Path: p, Mode: info.Mode(), Size: info.Size(), ModTime: info.ModTime()})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// FromPaths returns an index of the paths produced by seq, as added by
+// AddPath. It stops at the first path that is not valid and returns the
+// error.
+func FromPaths(seq iter.Seq[string]) (*Tree, error) {
+	t := New()
+	for p := range seq {
+		if err := t.AddPath(p); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// key returns the key of a path, which is the path itself except that the root
+// "." has the empty key.
+func key(p string) []byte {
+	if p == "." {
+		return nil
+	}
+	return []byte(p)
+}
+
+// Add adds the entry to the index, replacing any entry with the same path, and
+// adds an entry for each directory above it that is not in the index. It
+// returns an error wrapping ErrInvalidPath if the path is not valid.
+func (t *Tree) Add(e Entry) error {
+	if !fs.ValidPath(e.Path) {
+		return fmt.Errorf("%w: %q", ErrInvalidPath, e.Path)
+	}
+	t.tree.Insert(key(e.Path), e)
+	for dir := path.Dir(e.Path); dir != "." && !t.tree.Contains(key(dir)); dir = path.Dir(dir) {
+		t.tree.Insert(key(dir), Entry{Path: dir, Mode: fs.ModeDir})
+	}
+	return nil
+}
+
+// AddPath adds a path without metadata, as a directory if it ends with a slash
+// and as a regular file otherwise.
+func (t *Tree) AddPath(p string) error {
+	if dir, ok := strings.CutSuffix(p, "/"); ok {
+		return t.Add(Entry{Path: dir, Mode: fs.ModeDir})
+	}
+	return t.Add(Entry{Path: p})
+}
+
+// Len returns the number of entries in the index.
+func (t *Tree) Len() int {
+	return t.tree.Len()
+}
+
+// Stat returns the entry for the path and true, or false if the path is not in
+// the index.
+func (t *Tree) Stat(p string) (Entry, bool) {
+	if !fs.ValidPath(p) {
+		return Entry{}, false
+	}
+	return t.tree.Get(key(p))
+}
+
+// under returns the prefix of the keys below the directory dir.
+func under(dir string) []byte {
+	if dir == "." {
+		return nil
+	}
+	return []byte(dir + "/")
+}
+
+// List returns the entries directly in the directory dir, in ascending order of
+// their names. The entries below each subdirectory are skipped over rather than
+// visited, so the cost depends on the number of entries listed rather than the
+// number below dir.
+func (t *Tree) List(dir string) []Entry {
+	if !fs.ValidPath(dir) {
+		return nil
+	}
+	prefix := under(dir)
+	var entries []Entry
+	for i := t.tree.Rank(prefix); ; {
+		k, e, ok := t.tree.Select(i)
+		if !ok || !strings.HasPrefix(string(k), string(prefix)) {
+			break
+		}
+		if len(k) == 0 {
+			// The root itself.
+			i++
+			continue
+		}
+		if j := strings.IndexByte(string(k[len(prefix):]), '/'); j >= 0 {
+			// The key is below a subdirectory, which has been listed
+			// already, so skip past its entries. No byte of a valid
+			// path is 0xff.
+			skip := append(k[:len(prefix)+j+1:len(prefix)+j+1], 0xff)
+			i = t.tree.Rank(skip)
+			continue
+		}
+		entries = append(entries, e)
+		i++
+	}
+	return entries
+}
+
+// Find returns every entry below the directory dir, excluding dir itself, in
+// ascending order of their paths.
+func (t *Tree) Find(dir string) []Entry {
+	if !fs.ValidPath(dir) {
+		return nil
+	}
+	entries := t.tree.Find(under(dir))
+	if len(entries) > 0 && entries[0].Path == "." {
+		entries = entries[1:]
+	}
+	return entries
+}
+
+// Glob returns the entries whose paths match pattern, in ascending order of
+// their paths. The pattern is matched one element at a time with path.Match,
+// except that an element "**" matches any number of elements, including none.
+// Only the entries below the elements of the pattern that have no special
+// characters are visited. The only possible error is path.ErrBadPattern.
+func (t *Tree) Glob(pattern string) ([]Entry, error) {
+	elems := strings.Split(pattern, "/")
+	for _, elem := range elems {
+		if _, err := path.Match(elem, ""); err != nil {
+			return nil, err
+		}
+	}
+	literal := 0
+	for literal < len(elems) && !hasMeta(elems[literal]) {
+		literal++
+	}
+	if literal == len(elems) {
+		if e, ok := t.Stat(pattern); ok {
+			return []Entry{e}, nil
+		}
+		return nil, nil
+	}
+
+	var prefix []byte
+	if literal > 0 {
+		prefix = []byte(strings.Join(elems[:literal], "/") + "/")
+	}
+	var entries []Entry
+	for _, e := range t.tree.AllPrefix(prefix) {
+		if e.Path != "." && match(elems[literal:], strings.Split(e.Path[len(prefix):], "/")) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// hasMeta reports whether a pattern element has characters special to
+// path.Match.
+func hasMeta(elem string) bool {
+	return strings.ContainsAny(elem, `*?[\`)
+}
+
+// match reports whether the elements of a path match those of a pattern.
+func match(pattern, elems []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if match(pattern[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], elems[0]); !ok {
+			return false
+		}
+		pattern, elems = pattern[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
+
+// Remove removes the path and, if it is a directory, every entry below it, and
+// returns the number of entries removed. Removing "." empties the index.
+func (t *Tree) Remove(p string) int {
+	if !fs.ValidPath(p) {
+		return 0
+	}
+	if p == "." {
+		return t.tree.DeleteRange(nil, nil)
+	}
+	// The keys below a directory start with its path and a slash, so they
+	// are less than its path followed by the next byte.
+	start := under(p)
+	end := append([]byte(p), '/'+1)
+	n := t.tree.DeleteRange(start, end)
+	if _, ok := t.tree.Remove(key(p)); ok {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,120 @@
+package pathtree
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"reflect"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func paths(entries []Entry) []string {
+	var ps []string
+	for _, e := range entries {
+		ps = append(ps, e.Path)
+	}
+	return ps
+}
+
+func TestTree(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":                 {Data: []byte("module x")},
+		"cmd/tool/main.go":       {Data: []byte("package main")},
+		"cmd/tool-extra/main.go": {},
+		"pkg/a/a.go":             {},
+		"pkg/a/a_test.go":        {},
+		"pkg/a/b/b.go":           {},
+		"pkg/a.txt":              {},
+		"pkg/a-b/c.go":           {},
+	}
+	tree, err := FromFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("FromFS: %v", err)
+	}
+	// The files, their directories and the root.
+	if tree.Len() != 8+7+1 {
+		t.Errorf("Len = %d, want %d", tree.Len(), 16)
+	}
+	if e, ok := tree.Stat("go.mod"); !ok || e.Size != 8 || e.IsDir() {
+		t.Errorf("Stat(%q) = (%v, %t)", "go.mod", e, ok)
+	}
+	if e, ok := tree.Stat("pkg/a"); !ok || !e.IsDir() || e.Name() != "a" {
+		t.Errorf("Stat(%q) = (%v, %t)", "pkg/a", e, ok)
+	}
+
+	for dir, want := range map[string][]string{
+		".":       {"cmd", "go.mod", "pkg"},
+		"pkg":     {"pkg/a", "pkg/a-b", "pkg/a.txt"},
+		"pkg/a-b": {"pkg/a-b/c.go"},
+		"pkg/a":   {"pkg/a/a.go", "pkg/a/a_test.go", "pkg/a/b"},
+		"go.mod":  nil,
+		"nothing": nil,
+	} {
+		got := paths(tree.List(dir))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("List(%q) = %q, want %q", dir, got, want)
+		}
+		// List agrees with fs.ReadDir.
+		if des, err := fs.ReadDir(fsys, dir); err == nil && len(des) != len(got) {
+			t.Errorf("List(%q) has %d entries, fs.ReadDir has %d", dir, len(got), len(des))
+		}
+	}
+
+	if got, want := paths(tree.Find("pkg/a")), []string{"pkg/a/a.go", "pkg/a/a_test.go", "pkg/a/b", "pkg/a/b/b.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Find = %q, want %q", got, want)
+	}
+	if got := len(tree.Find(".")); got != 15 {
+		t.Errorf("Find(%q) found %d entries, want 15", ".", got)
+	}
+
+	for pattern, want := range map[string][]string{
+		"pkg/*/*.go":   {"pkg/a-b/c.go", "pkg/a/a.go", "pkg/a/a_test.go"},
+		"**/main.go":   {"cmd/tool-extra/main.go", "cmd/tool/main.go"},
+		"pkg/**/b*.go": {"pkg/a/b/b.go"},
+		"pkg/**":       {"pkg/a", "pkg/a-b", "pkg/a-b/c.go", "pkg/a.txt", "pkg/a/a.go", "pkg/a/a_test.go", "pkg/a/b", "pkg/a/b/b.go"},
+		"go.mod":       {"go.mod"},
+		"*.sum":        nil,
+		"cmd/tool?":    nil,
+		"cmd/tool-*":   {"cmd/tool-extra"},
+	} {
+		got, err := tree.Glob(pattern)
+		slices.Sort(want)
+		if err != nil || !reflect.DeepEqual(paths(got), want) {
+			t.Errorf("Glob(%q) = (%q, %v), want %q", pattern, paths(got), err, want)
+		}
+	}
+	if _, err := tree.Glob("pkg/[a"); !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("Glob of a bad pattern = %v", err)
+	}
+
+	if n := tree.Remove("pkg/a"); n != 5 || tree.Len() != 11 {
+		t.Errorf("Remove = %d leaving %d entries", n, tree.Len())
+	}
+	if _, ok := tree.Stat("pkg/a-b/c.go"); !ok {
+		t.Errorf("Remove removed a sibling with a longer name")
+	}
+	if err := tree.Add(Entry{Path: "/abs"}); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Add of an invalid path = %v", err)
+	}
+	if n := tree.Remove("."); n != 11 || tree.Len() != 0 {
+		t.Errorf("Remove(%q) = %d leaving %d entries", ".", n, tree.Len())
+	}
+}
+
+func TestFromPaths(t *testing.T) {
+	tree, err := FromPaths(slices.Values([]string{"a/b/c.txt", "a/d/", "e"}))
+	if err != nil {
+		t.Fatalf("FromPaths: %v", err)
+	}
+	if got, want := paths(tree.List("a")), []string{"a/b", "a/d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List = %q, want %q", got, want)
+	}
+	if e, _ := tree.Stat("a/d"); !e.IsDir() {
+		t.Errorf("a path ending with a slash is not a directory")
+	}
+	if _, err := FromPaths(slices.Values([]string{"a/../b"})); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("FromPaths of an invalid path = %v", err)
+	}
+}
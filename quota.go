@@ -0,0 +1,126 @@
+package radixtree
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// limited reports whether the tree has any limits to check when keys are
+// added to it.
+func (t *RadixTree[T]) limited() bool {
+	return t.opts.maxKeyLen > 0 || t.opts.maxEntries > 0 || t.opts.maxKeyBytes > 0 || len(t.opts.prefixLimits) > 0
+}
+
+// checkQuota returns an error wrapping ErrKeyTooLong if key is longer than
+// allowed, or wrapping ErrQuotaExceeded if inserting key, if it is not in the
+// tree, would exceed a limit.
+func (t *RadixTree[T]) checkQuota(key []byte) error {
+	b := quotaBatch[T]{t: t}
+	return b.add(key)
+}
+
+// checkSubtree returns an error as checkQuota does if adding the keys of the
+// subtree rooted at n, with prefix prepended to them, to the tree would exceed
+// a limit, or if replace is true if the keys would exceed a limit on their own.
+func (t *RadixTree[T]) checkSubtree(prefix []byte, n *node[T], replace bool) error {
+	if !t.limited() {
+		return nil
+	}
+	b := quotaBatch[T]{t: t, replace: replace}
+	var err error
+	walkNodes(n, append([]byte(nil), prefix...), -1, func(key []byte, _ *node[T]) bool {
+		err = b.add(key)
+		return err == nil
+	})
+	return err
+}
+
+// quotaBatch counts the keys that a bulk operation adds to a tree so that the
+// limits can be checked for each of them before any is in the tree. The keys
+// must be distinct. If replace is true the keys replace every key of the tree,
+// so only they count towards the limits.
+type quotaBatch[T any] struct {
+	t        *RadixTree[T]
+	replace  bool
+	keys     int
+	bytes    int
+	prefixes []int // keys counted with the prefix of each limit
+}
+
+// add returns an error as checkQuota does if adding key to the tree, along
+// with the keys counted so far, would exceed a limit, and otherwise counts key
+// if it is not in the tree.
+func (b *quotaBatch[T]) add(key []byte) error {
+	t := b.t
+	if max := t.opts.maxKeyLen; max > 0 && len(key) > max {
+		return fmt.Errorf("%w: key of %d bytes is longer than %d", ErrKeyTooLong, len(key), max)
+	}
+	size, keyBytes := b.keys, b.bytes
+	if !b.replace {
+		if t.lookup(key) != nil {
+			return nil
+		}
+		size += t.size
+		if t.opts.maxKeyBytes > 0 {
+			keyBytes += t.totalKeyBytes()
+		}
+	}
+	if max := t.opts.maxEntries; max > 0 && size >= max {
+		return fmt.Errorf("%w: tree has %d keys", ErrQuotaExceeded, max)
+	}
+	if max := t.opts.maxKeyBytes; max > 0 && keyBytes+len(key) > max {
+		return fmt.Errorf("%w: keys would exceed %d bytes", ErrQuotaExceeded, max)
+	}
+	for i, l := range t.opts.prefixLimits {
+		if !bytes.HasPrefix(key, l.prefix) {
+			continue
+		}
+		count := 0
+		if b.prefixes != nil {
+			count = b.prefixes[i]
+		}
+		if n := t.find(l.prefix); n != nil && !b.replace {
+			count += n.count
+		}
+		if count >= l.n {
+			return fmt.Errorf("%w: prefix %q has %d keys", ErrQuotaExceeded, l.prefix, l.n)
+		}
+	}
+
+	b.keys++
+	b.bytes += len(key)
+	for i, l := range t.opts.prefixLimits {
+		if bytes.HasPrefix(key, l.prefix) {
+			if b.prefixes == nil {
+				b.prefixes = make([]int, len(t.opts.prefixLimits))
+			}
+			b.prefixes[i]++
+		}
+	}
+	return nil
+}
+
+// totalKeyBytes returns the total length of the keys in the tree, walking the
+// tree to total them if the tree has changed since they were last totalled.
+func (t *RadixTree[T]) totalKeyBytes() int {
+	if t.keyBytesGen != t.gen+1 {
+		t.keyBytes = 0
+		key, _ := walkNodes(t.root, t.borrow(), -1, func(key []byte, _ *node[T]) bool {
+			t.keyBytes += len(key)
+			return true
+		})
+		t.release(key)
+		t.keyBytesGen = t.gen + 1
+	}
+	return t.keyBytes
+}
+
+// addKeyBytes adds delta to the total length of the keys after a change that
+// took the tree from generation gen to the current one, if the total was up
+// to date before the change.
+func (t *RadixTree[T]) addKeyBytes(gen uint64, delta int) {
+	if t.opts.maxKeyBytes > 0 && t.keyBytesGen == gen+1 {
+		t.keyBytes += delta
+		t.keyBytesGen = t.gen + 1
+	}
+}
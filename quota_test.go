@@ -0,0 +1,223 @@
+package radixtree
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestQuota(t *testing.T) {
+	tree := New[int](WithMaxEntries(3), WithMaxKeyBytes(13), WithPrefixLimit([]byte("a/"), 2))
+	insert := func(key string) error {
+		_, _, err := tree.InsertErr([]byte(key), len(key))
+		return err
+	}
+
+	for _, key := range []string{"a/1", "a/22"} {
+		if err := insert(key); err != nil {
+			t.Fatalf("InsertErr(%q) returned error %v", key, err)
+		}
+	}
+	if err := insert("a/3"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("InsertErr over the prefix limit returned %v, want %v", err, ErrQuotaExceeded)
+	}
+	if err := insert("a/1"); err != nil {
+		t.Errorf("InsertErr updating a key at the prefix limit returned error %v", err)
+	}
+	if err := insert("b/12345"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("InsertErr over the key bytes limit returned %v, want %v", err, ErrQuotaExceeded)
+	}
+	if err := insert("b/1234"); err != nil {
+		t.Errorf("InsertErr up to the key bytes limit returned error %v", err)
+	}
+	if err := insert("c"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("InsertErr over the entries limit returned %v, want %v", err, ErrQuotaExceeded)
+	}
+	if tree.Len() != 3 || tree.Contains([]byte("c")) {
+		t.Errorf("tree changed by failed inserts: %v", tree.ToMap())
+	}
+
+	// Removing keys makes room again, however they are removed.
+	tree.Remove([]byte("b/1234"))
+	if err := insert("b/1"); err != nil {
+		t.Errorf("InsertErr after Remove returned error %v", err)
+	}
+	tree.DeleteRange([]byte("a/"), []byte("a0"))
+	for _, key := range []string{"a/3", "a/4"} {
+		if err := insert(key); err != nil {
+			t.Errorf("InsertErr(%q) after DeleteRange returned error %v", key, err)
+		}
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("Insert over a limit panicked with %v, want %v", err, ErrQuotaExceeded)
+		}
+	}()
+	tree.Insert([]byte("d"), 1)
+}
+
+func TestMaxKeyLen(t *testing.T) {
+	tree := New[int](WithMaxKeyLen(4))
+	if _, _, err := tree.InsertErr([]byte("toad"), 1); err != nil {
+		t.Errorf("InsertErr of a key at the limit returned error %v", err)
+	}
+	if _, _, err := tree.InsertErr([]byte("toady"), 1); !errors.Is(err, ErrKeyTooLong) {
+		t.Errorf("InsertErr of a key over the limit returned %v, want %v", err, ErrKeyTooLong)
+	}
+	if tree.Len() != 1 {
+		t.Errorf("Len() = %d after inserting a key over the limit, want 1", tree.Len())
+	}
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrKeyTooLong) {
+			t.Errorf("Insert of a key over the limit panicked with %v, want %v", err, ErrKeyTooLong)
+		}
+	}()
+	tree.Insert(make([]byte, 5), 1)
+}
+
+// recoverError calls f and returns the error it panics with, if any.
+func recoverError(f func()) (err error) {
+	defer func() {
+		err, _ = recover().(error)
+	}()
+	f()
+	return nil
+}
+
+func TestBulkLimits(t *testing.T) {
+	newTree := func(keys ...string) *RadixTree[int] {
+		tree := New[int](WithMaxKeyLen(4), WithMaxEntries(3))
+		for _, key := range keys {
+			tree.Insert([]byte(key), len(key))
+		}
+		return tree
+	}
+	entries := func(keys ...string) []Entry[int] {
+		var entries []Entry[int]
+		for _, key := range keys {
+			entries = append(entries, Entry[int]{Key: []byte(key), Value: len(key)})
+		}
+		return entries
+	}
+	check := func(name string, err, want error, tree *RadixTree[int], size int) {
+		t.Helper()
+		if !errors.Is(err, want) {
+			t.Errorf("%s returned %v, want %v", name, err, want)
+		}
+		if tree.Len() != size {
+			t.Errorf("Len() = %d after %s, want %d", tree.Len(), name, size)
+		}
+	}
+
+	tree := newTree("a")
+	_, err := tree.InsertManyErr(entries("b", "ccccc"))
+	check("InsertManyErr with a long key", err, ErrKeyTooLong, tree, 1)
+	_, err = tree.InsertManyErr(entries("b", "c", "d"))
+	check("InsertManyErr over the entries limit", err, ErrQuotaExceeded, tree, 1)
+	if n, err := tree.InsertManyErr(entries("a", "b", "b")); n != 1 || err != nil {
+		t.Errorf("InsertManyErr up to the limit = %d, %v, want 1, nil", n, err)
+	}
+	err = recoverError(func() { tree.InsertMany(entries("c", "d")) })
+	check("InsertMany over the entries limit", err, ErrQuotaExceeded, tree, 2)
+
+	tree = newTree()
+	err = tree.LoadSorted(strings.NewReader("a=1\nb=2\nccccc=3\n"), parseEntry)
+	check("LoadSorted with a long key", err, ErrKeyTooLong, tree, 2)
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("LoadSorted returned %v, want an error for line 3", err)
+	}
+
+	var buf bytes.Buffer
+	if err := newTree("x", "y").ExportCompact(&buf, func(int) ([]byte, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	tree = New[int](WithMaxEntries(3))
+	tree.Insert([]byte("a"), 1)
+	tree.Insert([]byte("b"), 2)
+	err = tree.ImportCompact(&buf, func([]byte) (int, error) { return 0, nil })
+	check("ImportCompact over the entries limit", err, ErrQuotaExceeded, tree, 2)
+
+	tree = newTree("a", "b")
+	sub := newTree("c", "d")
+	err = recoverError(func() { tree.Graft(nil, sub) })
+	check("Graft over the entries limit", err, ErrQuotaExceeded, tree, 2)
+	if sub.Len() != 2 {
+		t.Errorf("Graft over a limit changed the grafted tree")
+	}
+	err = recoverError(func() { tree.Graft([]byte("xyz"), newTree("ab")) })
+	check("Graft with a long key", err, ErrKeyTooLong, tree, 2)
+	tree.Graft([]byte("b"), newTree("", "b"))
+	if tree.Len() != 3 || !tree.Contains([]byte("bb")) {
+		t.Errorf("Graft up to the limit gave %v", tree.ToMap())
+	}
+
+	tree = newTree()
+	err = recoverError(func() { tree.ReplaceAll(FromMap(map[string]int{"a": 1, "b": 2, "c": 3, "d": 4})) })
+	check("ReplaceAll over the entries limit", err, ErrQuotaExceeded, tree, 0)
+	tree = newTree("a", "b", "c")
+	tree.ReplaceAll(FromMap(map[string]int{"x": 1, "y": 2, "z": 3}))
+	if tree.Len() != 3 {
+		t.Errorf("Len() = %d after ReplaceAll up to the limit, want 3", tree.Len())
+	}
+
+	tree = newTree("a", "b")
+	src := FromMap(map[string]int{"c": 1, "d": 2, "eeeee": 3})
+	err = recoverError(func() { src.CopyRange(tree, []byte("e"), nil, nil) })
+	check("CopyRange with a long key", err, ErrKeyTooLong, tree, 2)
+	err = recoverError(func() { src.CopyRange(tree, []byte("c"), []byte("e"), nil) })
+	check("CopyRange over the entries limit", err, ErrQuotaExceeded, tree, 2)
+
+	left, right := newTree("a", "b"), newTree("c", "d")
+	_, err = Join(left, right)
+	check("Join over the entries limit", err, ErrQuotaExceeded, left, 2)
+	if right.Len() != 2 {
+		t.Errorf("Join over a limit changed the right tree")
+	}
+
+	err = recoverError(func() { FromMap(map[string]int{"a": 1, "b": 2}, WithMaxEntries(1)) })
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("FromMap over the entries limit panicked with %v, want %v", err, ErrQuotaExceeded)
+	}
+}
+
+func TestBulkKeyBytes(t *testing.T) {
+	tree := New[int](WithMaxKeyBytes(10))
+	entries := []Entry[int]{{Key: []byte("aaaa")}, {Key: []byte("bbbb")}, {Key: []byte("cccc")}}
+	if _, err := tree.InsertManyErr(entries); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("InsertManyErr over the key bytes limit returned %v, want %v", err, ErrQuotaExceeded)
+	}
+	err := recoverError(func() { tree.InsertMany(entries) })
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("InsertMany over the key bytes limit panicked with %v, want %v", err, ErrQuotaExceeded)
+	}
+	if tree.Len() != 0 {
+		t.Fatalf("Len() = %d after failed InsertMany, want 0", tree.Len())
+	}
+	if n := tree.InsertMany(entries[:2]); n != 2 {
+		t.Errorf("InsertMany up to the key bytes limit = %d, want 2", n)
+	}
+	// The total includes the keys inserted by InsertMany.
+	if _, _, err := tree.InsertErr([]byte("ccc"), 0); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("InsertErr after InsertMany returned %v, want %v", err, ErrQuotaExceeded)
+	}
+
+	tree = New[int](WithMaxKeyBytes(10))
+	err = tree.LoadSorted(strings.NewReader("aaaa=1\nbbbb=2\ncccc=3\n"), parseEntry)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("LoadSorted over the key bytes limit returned %v, want %v", err, ErrQuotaExceeded)
+	}
+	if tree.Len() != 2 {
+		t.Errorf("Len() = %d after LoadSorted over the limit, want 2", tree.Len())
+	}
+	if err := tree.LoadSorted(strings.NewReader("aaaa=5\nbb=6\n"), parseEntry); err != nil {
+		t.Errorf("LoadSorted up to the key bytes limit returned error %v", err)
+	}
+	if _, _, err := tree.InsertErr([]byte("c"), 0); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("InsertErr after LoadSorted returned %v, want %v", err, ErrQuotaExceeded)
+	}
+	if v, _ := tree.Get([]byte("aaaa")); v != 5 || tree.Len() != 3 {
+		t.Errorf("tree after LoadSorted = %v", tree.ToMap())
+	}
+}
@@ -7,37 +7,122 @@ package radixtree
 import (
 	"bytes"
 	"sort"
+	"sync"
 )
 
-// children encapsulates a slice of nodes sorted in ascending order by the first
-// byte of their prefix.
-type children[T any] []*node[T]
+// children encapsulates the child nodes of a node sorted in ascending order by
+// the first byte of their prefix, in the byte order of the tree. The first
+// bytes are also kept in a slice of their own so that finding a child scans
+// contiguous bytes rather than dereferencing every child. The nodes themselves
+// are kept by pointer rather than by value: snapshots and forks share
+// subtrees by pointer, Graft, Detach, Split and Join move subtrees between
+// trees without copying them, and mutations hold the nodes on the path to a
+// key, all of which would break if adding a sibling could move a node.
+type children[T any] struct {
+	keys  []byte
+	nodes []*node[T]
+}
+
+// childrenOf returns children holding the given nodes, which must already be
+// in order.
+func childrenOf[T any](nodes ...*node[T]) children[T] {
+	c := children[T]{
+		keys:  make([]byte, len(nodes)),
+		nodes: nodes,
+	}
+	for i, n := range nodes {
+		c.keys[i] = n.prefix[0]
+	}
+	return c
+}
+
+func (c *children[T]) add(node *node[T], o *byteOrder) {
+	i := c.search(node.prefix[0], o)
+	c.keys = append(c.keys, 0)
+	copy(c.keys[i+1:], c.keys[i:])
+	c.keys[i] = node.prefix[0]
+	c.nodes = append(c.nodes, nil)
+	copy(c.nodes[i+1:], c.nodes[i:])
+	c.nodes[i] = node
+}
+
+// append adds node after the existing children, which it must follow in the
+// byte order of the tree.
+func (c *children[T]) append(node *node[T]) {
+	c.keys = append(c.keys, node.prefix[0])
+	c.nodes = append(c.nodes, node)
+}
 
-func (c *children[T]) add(node *node[T]) {
-	i := c.search(node.prefix[0])
-	*c = append(*c, nil)
-	copy((*c)[i+1:], (*c)[i:])
-	(*c)[i] = node
+// remove removes the child at index i.
+func (c *children[T]) remove(i int) {
+	c.keys = append(c.keys[:i], c.keys[i+1:]...)
+	copy(c.nodes[i:], c.nodes[i+1:])
+	c.nodes[len(c.nodes)-1] = nil
+	c.nodes = c.nodes[:len(c.nodes)-1]
 }
 
 func (c children[T]) get(b byte) *node[T] {
 	if i := c.index(b); i >= 0 {
-		return c[i]
+		return c.nodes[i]
 	}
 	return nil
 }
 
+// index returns the index of the child whose prefix starts with b or -1 if
+// there is none. The first bytes of the children are distinct so the search
+// does not depend on their order.
 func (c children[T]) index(b byte) int {
-	if i := c.search(b); i < len(c) && c[i].prefix[0] == b {
-		return i
+	return bytes.IndexByte(c.keys, b)
+}
+
+// search returns the index of the first child whose prefix starts with a byte
+// that is not less than b.
+func (c children[T]) search(b byte, o *byteOrder) int {
+	if o == nil {
+		return sort.Search(len(c.keys), func(i int) bool {
+			return c.keys[i] >= b
+		})
+	}
+	r := o[b]
+	return sort.Search(len(c.keys), func(i int) bool {
+		return o[c.keys[i]] >= r
+	})
+}
+
+func (c children[T]) len() int {
+	return len(c.nodes)
+}
+
+// firstFrom returns the index of the first child from index i onwards whose
+// subtree holds a value, or -1 if there is none. Only trees with lazy deletion
+// have children without values.
+func (c children[T]) firstFrom(i int) int {
+	for ; i < len(c.nodes); i++ {
+		if c.nodes[i].count > 0 {
+			return i
+		}
 	}
 	return -1
 }
 
-func (c children[T]) search(b byte) int {
-	return sort.Search(len(c), func(i int) bool {
-		return c[i].prefix[0] >= b
-	})
+// lastBefore returns the index of the last child before index i whose subtree
+// holds a value, or -1 if there is none.
+func (c children[T]) lastBefore(i int) int {
+	for i--; i >= 0; i-- {
+		if c.nodes[i].count > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// leaf holds a value stored in the tree along with the bookkeeping that has to
+// move with it when nodes are split or merged.
+type leaf[T any] struct {
+	value T
+	seq   uint64 // insertion sequence number when insertion order is tracked
+	mod   uint64 // generation of the last modification when tracked
+	prev  []T    // previous values, oldest first, when history is kept
 }
 
 // node encapsulates a prefix, with a possible associated value, and a set of
@@ -45,44 +130,139 @@ func (c children[T]) search(b byte) int {
 type node[T any] struct {
 	prefix   []byte
 	children children[T]
-	value    *T
+	leaf     *leaf[T]
+	mod      uint64 // latest generation of any modification in the subtree
+	agg      any    // aggregate of the subtree when aggregation is enabled
+	count    int    // number of values in the subtree
+	owner    *token // tree that may modify the node when nodes are shared
 }
 
 func (n *node[T]) hasValue() bool {
-	return n.value != nil
+	return n.leaf != nil
 }
 
 func (n *node[T]) max() (T, bool) {
-	for len(n.children) > 0 {
-		n = n.children[len(n.children)-1]
+	for i := n.children.lastBefore(n.children.len()); i >= 0; i = n.children.lastBefore(n.children.len()) {
+		n = n.children.nodes[i]
 	}
 	if n.hasValue() {
-		return *n.value, true
+		return n.leaf.value, true
 	}
 	var zero T
 	return zero, false
 }
 
 func (n *node[T]) min() (T, bool) {
-	for !n.hasValue() && len(n.children) > 0 {
-		n = n.children[0]
+	for !n.hasValue() {
+		i := n.children.firstFrom(0)
+		if i < 0 {
+			break
+		}
+		n = n.children.nodes[i]
 	}
 	if n.hasValue() {
-		return *n.value, true
+		return n.leaf.value, true
 	}
 	var zero T
 	return zero, false
 }
 
 // RadixTree implements a mutable radix tree.
+//
+// Like a nil map, a nil *RadixTree reads as an empty tree, so that an optional
+// tree need not be checked for nil before it is read: Get, Contains,
+// LongestPrefix, HasPrefix, Len, IsEmpty, Min, Max, MinPrefix, MaxPrefix,
+// Find, AppendFind, FindN, AppendKeys, Values, Walk, WalkDepth,
+// WalkSegments, WalkKeys, All and AllPrefix return empty results. Modifying a
+// nil tree panics.
+//
+// A tree created with WithMaxKeyLen, WithMaxEntries, WithMaxKeyBytes or
+// WithPrefixLimit checks its limits on every operation that adds keys to it.
+// Those that return an error, such as InsertErr, InsertManyErr, LoadSorted,
+// ImportCompact and Join, report a key over a limit that way, but the others,
+// Insert, InsertMany, Graft, ReplaceAll and CopyRange among them, panic. Use
+// the former for keys that come from outside the program.
 type RadixTree[T any] struct {
-	root *node[T]
-	size int
+	root   *node[T]
+	size   int
+	opts   options
+	gen    uint64 // incremented by every mutation
+	seq    uint64 // last insertion sequence number
+	filter *bloom
+	cache  *cache[T]
+	lpc    *cache[T]
+
+	// removed is the number of removals since the tree was last compacted
+	// when merges are deferred or deletion is lazy.
+	removed int
+	// detached is incremented by every operation that may take entries out
+	// of the tree, so that handles know when to check they are still valid.
+	detached uint64
+
+	// shared is true if the tree may share nodes with a snapshot, in which
+	// case only the nodes whose owner is owner may be modified in place and
+	// the others are copied first. copies counts the nodes copied.
+	shared bool
+	owner  *token
+	copies uint64
+
+	// removals records the keys removed, in the order of their
+	// generations, for BackupSince once it has been called and set
+	// logRemovals. The record is complete from generation removalsFrom.
+	removals     []removal
+	logRemovals  bool
+	removalsFrom uint64
+
+	// keyBytes is the total length of the keys, for WithMaxKeyBytes. It is
+	// only up to date if keyBytesGen is one more than gen.
+	keyBytes    int
+	keyBytesGen uint64
+
+	// watches holds the watches of keys made by WatchKey, if any.
+	watches *watches[T]
+	// hot counts the traffic by prefix for WithHotPrefixes.
+	hot *hotPrefixes
+
+	agg aggregator[T]
+
+	// scratch is the buffer reused for building keys during traversals
+	// when keys are shared.
+	scratch []byte
+	// path is the buffer reused for the nodes on the path to a key during
+	// mutations.
+	path []*node[T]
 }
 
-// New creates and returns an empty radix tree.
-func New[T any]() *RadixTree[T] {
-	return &RadixTree[T]{root: &node[T]{}}
+// New creates and returns an empty radix tree configured with the given
+// options.
+func New[T any](opts ...Option) *RadixTree[T] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return newTree[T](o)
+}
+
+func newTree[T any](o options) *RadixTree[T] {
+	t := &RadixTree[T]{root: &node[T]{}, opts: o}
+	if o.bloomSize > 0 {
+		t.filter = newBloom(o.bloomSize, o.bloomRate)
+	}
+	if o.cacheSize > 0 {
+		t.cache = newCache[T](o.cacheSize)
+		t.lpc = newCache[T](o.cacheSize)
+	}
+	if o.hotDepth > 0 {
+		t.hot = newHotPrefixes(o.hotDepth, o.hotEvery)
+	}
+	if o.aggregate != nil {
+		agg, ok := o.aggregate.(aggregator[T])
+		if !ok {
+			panic("radixtree: WithAggregate value type does not match the tree")
+		}
+		t.agg = agg
+	}
+	return t
 }
 
 // Contains returns true if key is in the tree, false otherwise.
@@ -95,11 +275,70 @@ func (t *RadixTree[T]) Contains(key []byte) bool {
 // starts with the given prefix. The slice will be ordered in ascending key
 // order.
 func (t *RadixTree[T]) Find(prefix []byte) []T {
-	var results []T
+	return t.AppendFind(nil, prefix)
+}
+
+// AppendFind appends the values that have a key that starts with the given
+// prefix to dst in ascending key order and returns the extended slice.
+func (t *RadixTree[T]) AppendFind(dst []T, prefix []byte) []T {
 	t.Walk(prefix, func(value T) bool {
-		results = append(results, value)
+		dst = append(dst, value)
 		return true
 	})
+	return dst
+}
+
+// AppendKeys appends the keys that start with the given prefix to dst in
+// ascending order and returns the extended slice. Each appended key is a copy
+// that the caller may retain.
+func (t *RadixTree[T]) AppendKeys(dst [][]byte, prefix []byte) [][]byte {
+	n, key := t.findPath(prefix, t.borrow())
+	if n != nil {
+		key, _ = walkNodes(n, key, -1, func(key []byte, _ *node[T]) bool {
+			dst = append(dst, append([]byte(nil), key...))
+			return true
+		})
+	}
+	t.release(key)
+	return dst
+}
+
+// FindN is like Find but returns at most n values. The returned slice holds
+// the values of the first n keys, in ascending key order, that start with the
+// given prefix.
+func (t *RadixTree[T]) FindN(prefix []byte, n int) []T {
+	if n <= 0 {
+		return nil
+	}
+	if n > t.Len() {
+		n = t.Len()
+	}
+	results := make([]T, 0, n)
+	t.Walk(prefix, func(value T) bool {
+		results = append(results, value)
+		return len(results) < n
+	})
+	return results
+}
+
+// FindFunc is like Find but returns only the values for which pred returns
+// true. The values are filtered during the traversal so those rejected are
+// never collected. The key passed to pred is only valid until pred returns and
+// must not be modified, so that filtering does not allocate. If pred modifies
+// the tree FindFunc panics with ErrModified.
+func (t *RadixTree[T]) FindFunc(prefix []byte, pred func(key []byte, value T) bool) []T {
+	var results []T
+	n, key := t.findPath(prefix, t.borrow())
+	if n != nil {
+		gen := t.gen
+		key, _ = walkNodes(n, key, -1, func(key []byte, n *node[T]) bool {
+			if pred(key, n.leaf.value) {
+				results = append(results, n.leaf.value)
+			}
+			return t.unmodified(gen)
+		})
+	}
+	t.release(key)
 	return results
 }
 
@@ -108,92 +347,286 @@ func (t *RadixTree[T]) Find(prefix []byte) []T {
 // indicating that a value was found. If the key is not in the tree it returns
 // the zero value for type T and a false boolean value.
 func (t *RadixTree[T]) Get(key []byte) (T, bool) {
+	if t != nil && t.hot != nil {
+		t.hot.record(key, false)
+	}
+	if t == nil || t.filter != nil && !t.filter.mayContain(key) {
+		var zero T
+		return zero, false
+	}
+	if t.cache == nil {
+		return t.get(key)
+	}
+	if v, ok, hit := t.cache.get(key, t.gen); hit {
+		return v, ok
+	}
+	v, ok := t.get(key)
+	t.cache.put(key, t.gen, v, ok)
+	return v, ok
+}
+
+func (t *RadixTree[T]) get(key []byte) (T, bool) {
+	if lf := t.lookup(key); lf != nil {
+		return lf.value, true
+	}
+	var zero T
+	return zero, false
+}
+
+// lookup returns the leaf of the given key or nil if the key is not in the
+// tree.
+func (t *RadixTree[T]) lookup(key []byte) *leaf[T] {
 	n := t.root
 
 	for len(key) > 0 {
 		n = n.children.get(key[0])
 		if n == nil || !bytes.HasPrefix(key, n.prefix) {
-			var zero T
-			return zero, false
+			return nil
 		}
 		key = key[len(n.prefix):]
 	}
+	return n.leaf
+}
 
-	if n.hasValue() {
-		return *n.value, true
+// GetRef returns a pointer to the value stored for the given key and true, or
+// nil and false if the key is not in the tree, so that a large value can be
+// read and modified in place. The pointer stays valid, and refers to the value
+// of the key, until the key is removed: updating the key with Insert stores
+// the new value through it and the value moves with the key when the tree is
+// split, joined or grafted. Once the key is removed the pointer no longer
+// refers to the tree. A pointer obtained before Snapshot must not be used
+// afterwards, since it may refer to the value held by the snapshot; call GetRef
+// again instead. Changes made through the pointer are not seen by the
+// tree, so they do not update the aggregates of WithAggregate, are not
+// recorded by WithModificationTracking and are not seen by Get with
+// WithLookupCache until the tree is next modified; use Insert for trees with
+// those options.
+func (t *RadixTree[T]) GetRef(key []byte) (*T, bool) {
+	if t.filter != nil && !t.filter.mayContain(key) {
+		return nil, false
 	}
-	var zero T
-	return zero, false
+	lf := t.lookup(key)
+	if lf == nil {
+		return nil, false
+	}
+	if t.shared {
+		// The value may be written through the pointer.
+		t.unshare(key)
+		lf = t.lookup(key)
+	}
+	return &lf.value, true
+}
+
+// HasPrefix returns true if any key in the tree starts with the given prefix.
+// It stops at the node where the prefix ends without visiting any values.
+func (t *RadixTree[T]) HasPrefix(prefix []byte) bool {
+	n := t.find(prefix)
+	return n != nil && n.count > 0
 }
 
 // Insert adds the value to the radix tree with the given key. If the exact key
 // already exists in the radix tree it updates the value and returns the old
 // value and a boolean value of true indicating that an old value was found. If
 // the key was not in the tree it returns the zero value for type T and a false
-// boolean value.
+// boolean value. The empty key, nil or zero length, is a valid key that is
+// stored at the root, is less than every other key and is a prefix of them.
+// Unless the tree was created with WithKeyCopy or WithAllocator it retains the
+// key, which the caller must not modify afterwards.
+//
+// Insert panics if the tree has limits and the key breaks one: if the key is
+// longer than the limit set by WithMaxKeyLen it panics with an error wrapping
+// ErrKeyTooLong, and if inserting it would exceed a limit set by
+// WithMaxEntries, WithMaxKeyBytes or WithPrefixLimit with an error wrapping
+// ErrQuotaExceeded. Use InsertErr to have the error returned instead.
 func (t *RadixTree[T]) Insert(key []byte, value T) (T, bool) {
+	old, ok, err := t.InsertErr(key, value)
+	if err != nil {
+		panic(err)
+	}
+	return old, ok
+}
+
+// InsertErr is like Insert but returns an error, leaving the tree unchanged,
+// if the key is longer than the limit set by WithMaxKeyLen, wrapping
+// ErrKeyTooLong, or if inserting the key would exceed a limit set by
+// WithMaxEntries, WithMaxKeyBytes or WithPrefixLimit, wrapping
+// ErrQuotaExceeded. Updating the value of a key that is already in the tree
+// never exceeds a quota.
+func (t *RadixTree[T]) InsertErr(key []byte, value T) (T, bool, error) {
+	if t.limited() {
+		if err := t.checkQuota(key); err != nil {
+			var zero T
+			return zero, false, err
+		}
+	}
+	if t.hot != nil {
+		t.hot.record(key, true)
+	}
+	if t.filter != nil {
+		t.filter.add(key)
+	}
+	t.gen++
+	t.unshare(key)
+	old, ok := t.insert(key, value)
+	t.notify(key, value, true)
+	if !ok {
+		for _, n := range t.path {
+			n.count++
+		}
+		t.addKeyBytes(t.gen-1, len(key))
+	}
+	t.refresh(t.path)
+	return old, ok, nil
+}
+
+// insert implements Insert, leaving the nodes on the path to the key, from the
+// root down, in t.path.
+func (t *RadixTree[T]) insert(key []byte, value T) (T, bool) {
+	var zero T
 	n := t.root
+	t.path = t.path[:0]
 
 	for len(key) > 0 {
+		t.path = append(t.path, t.touch(n))
 		i := n.children.index(key[0])
 		if i < 0 {
 			// There is no child starting with the first byte of the
 			// key so we can simply add a new child node to n.
-			n.children.add(&node[T]{value: &value, prefix: key})
+			child := t.touch(&node[T]{leaf: t.newLeaf(value), prefix: t.ownKey(key), owner: t.owner})
+			n.children.add(child, t.opts.order)
+			t.path = append(t.path, child)
 			t.size++
-			var zero T
 			return zero, false
 		}
 
-		child := n.children[i]
+		child := n.children.nodes[i]
 		lcm := longestCommonPrefix(key, child.prefix)
 		if lcm < len(child.prefix) {
 			// The child needs to be split.
-			newChild := &node[T]{prefix: key[:lcm]}
-			n.children[i] = newChild
-			child.prefix = child.prefix[lcm:]
-			newChild.children.add(child)
+			newChild := t.touch(&node[T]{prefix: t.ownKey(key[:lcm]), count: child.count, owner: t.owner})
+			n.children.nodes[i] = newChild
+			t.setPrefix(child, child.prefix[lcm:])
+			newChild.children.add(child, t.opts.order)
+			t.path = append(t.path, newChild)
 			key = key[lcm:]
 			if len(key) == 0 {
-				newChild.value = &value
+				newChild.leaf = t.newLeaf(value)
 				t.size++
-				var zero T
 				return zero, false
 			}
-			newChild.children.add(&node[T]{value: &value, prefix: key})
+			child = t.touch(&node[T]{leaf: t.newLeaf(value), prefix: t.ownKey(key), owner: t.owner})
+			newChild.children.add(child, t.opts.order)
+			t.path = append(t.path, child)
 			t.size++
-			var zero T
 			return zero, false
 		}
 		n = child
 		key = key[lcm:]
 	}
 
+	t.path = append(t.path, t.touch(n))
 	if n.hasValue() {
 		// This insert is actually an update to an existing value.
-		old := *n.value
-		n.value = &value
+		old := n.leaf.value
+		t.keep(n.leaf, old)
+		n.leaf.value = value
+		if t.opts.trackMods {
+			n.leaf.mod = t.gen
+		}
 		return old, true
 	}
 	// The node exists but doesn't contain a value.
-	n.value = &value
+	n.leaf = t.newLeaf(value)
 	t.size++
-	var zero T
 	return zero, false
 }
 
+// newLeaf returns a leaf holding value for a key that is new to the tree.
+func (t *RadixTree[T]) newLeaf(value T) *leaf[T] {
+	l := &leaf[T]{value: value}
+	if t.opts.insertionOrder {
+		t.seq++
+		l.seq = t.seq
+	}
+	if t.opts.trackMods {
+		l.mod = t.gen
+	}
+	return l
+}
+
+// touch records that the subtree rooted at n has been modified in the current
+// generation if modifications are tracked. It returns n.
+func (t *RadixTree[T]) touch(n *node[T]) *node[T] {
+	if t.opts.trackMods {
+		n.mod = t.gen
+	}
+	return n
+}
+
+// IsEmpty returns true if the tree holds no values. Use Contains to check for a
+// value at a particular key.
+func (t *RadixTree[T]) IsEmpty() bool {
+	return t.Len() == 0
+}
+
 // Len returns the number of values in the tree.
 func (t *RadixTree[T]) Len() int {
+	if t == nil {
+		return 0
+	}
 	return t.size
 }
 
+// LongestKey returns the longest key in the tree and its value. If several
+// keys have the greatest length the smallest of them is returned. The boolean
+// return value will be false if the tree is empty. The returned key is a copy
+// that the caller may retain.
+func (t *RadixTree[T]) LongestKey() ([]byte, T, bool) {
+	var longest *node[T]
+	var longestKey []byte
+	key := t.borrow()
+	key, _ = walkNodes(t.root, key, -1, func(key []byte, n *node[T]) bool {
+		if longest == nil || len(key) > len(longestKey) {
+			longest = n
+			longestKey = append(longestKey[:0], key...)
+		}
+		return true
+	})
+	t.release(key)
+	if longest == nil {
+		var zero T
+		return nil, zero, false
+	}
+	return longestKey, longest.leaf.value, true
+}
+
 // LongestPrefix returns the value associated with the key that has the longest
 // prefix of the given key. If a value is found it returns the value and a
 // boolean value of true. If no value is found it returns the zero value for
 // type T and a boolean value of false.
 func (t *RadixTree[T]) LongestPrefix(key []byte) (T, bool) {
+	if t == nil {
+		var zero T
+		return zero, false
+	}
+	if t.hot != nil {
+		t.hot.record(key, false)
+	}
+	if t.lpc == nil {
+		return t.longestPrefix(key)
+	}
+	if v, ok, hit := t.lpc.get(key, t.gen); hit {
+		return v, ok
+	}
+	v, ok := t.longestPrefix(key)
+	t.lpc.put(key, t.gen, v, ok)
+	return v, ok
+}
+
+func (t *RadixTree[T]) longestPrefix(key []byte) (T, bool) {
 	n := t.root
-	var last *T
+	// The empty key, stored at the root, is a prefix of every key.
+	last := n.leaf
 
 	for len(key) > 0 {
 		n = n.children.get(key[0])
@@ -201,12 +634,12 @@ func (t *RadixTree[T]) LongestPrefix(key []byte) (T, bool) {
 			break
 		}
 		if n.hasValue() {
-			last = n.value
+			last = n.leaf
 		}
 		key = key[len(n.prefix):]
 	}
 	if last != nil {
-		return *last, true
+		return last.value, true
 	}
 	var zero T
 	return zero, false
@@ -216,14 +649,36 @@ func (t *RadixTree[T]) LongestPrefix(key []byte) (T, bool) {
 // boolean return value will be true if a maximum value was found and false if
 // the tree is empty and therefore has no maximum value.
 func (t *RadixTree[T]) Max() (T, bool) {
-	return t.root.max()
+	return t.MaxPrefix(nil)
+}
+
+// MaxPrefix returns the value associated with the largest key that starts with
+// the given prefix. The boolean return value will be false if no key starts
+// with the prefix.
+func (t *RadixTree[T]) MaxPrefix(prefix []byte) (T, bool) {
+	if n := t.find(prefix); n != nil {
+		return n.max()
+	}
+	var zero T
+	return zero, false
 }
 
 // Min returns the value associated with the smallest key in the tree. The
 // boolean return value will be true if a maximum value was found and false if
 // the tree is empty and therefore has no minimum value.
 func (t *RadixTree[T]) Min() (T, bool) {
-	return t.root.min()
+	return t.MinPrefix(nil)
+}
+
+// MinPrefix returns the value associated with the smallest key that starts with
+// the given prefix. The boolean return value will be false if no key starts
+// with the prefix.
+func (t *RadixTree[T]) MinPrefix(prefix []byte) (T, bool) {
+	if n := t.find(prefix); n != nil {
+		return n.min()
+	}
+	var zero T
+	return zero, false
 }
 
 // Predecessor returns the value that is associated with the key that
@@ -232,31 +687,61 @@ func (t *RadixTree[T]) Min() (T, bool) {
 // given key does not exist in the tree, the zero value for type T and a boolean
 // value of false will be returned.
 func (t *RadixTree[T]) Predecessor(key []byte) (T, bool) {
-	n := t.root
+	return t.predecessor(t.root, key)
+}
+
+// PredecessorPrefix is like Predecessor but only considers keys that start
+// with the given prefix. If the given key does not start with the prefix, or
+// its predecessor does not, the zero value for type T and a boolean value of
+// false will be returned.
+func (t *RadixTree[T]) PredecessorPrefix(prefix, key []byte) (T, bool) {
+	if n, path := t.within(prefix, key); n != nil {
+		return t.predecessor(n, key[len(path):])
+	}
+	var zero T
+	return zero, false
+}
+
+// within returns the node whose subtree holds the keys that start with prefix,
+// along with the full key of that node, provided that key is in the subtree.
+func (t *RadixTree[T]) within(prefix, key []byte) (*node[T], []byte) {
+	if !bytes.HasPrefix(key, prefix) {
+		return nil, nil
+	}
+	n, path := t.findPath(prefix, nil)
+	if n == nil || !bytes.HasPrefix(key, path) {
+		return nil, nil
+	}
+	return n, path
+}
+
+// predecessor returns the value of the key that precedes key in the subtree
+// rooted at n, where key is relative to n.
+func (t *RadixTree[T]) predecessor(n *node[T], key []byte) (T, bool) {
 	ancestor := false
 	var min *node[T]
 
 	for len(key) > 0 {
 		i := n.children.index(key[0])
-		if i < 0 || !bytes.HasPrefix(key, n.children[i].prefix) {
+		if i < 0 || !bytes.HasPrefix(key, n.children.nodes[i].prefix) {
 			var zero T
 			return zero, false
 		}
 
-		if i > 0 {
-			min = n.children[i-1]
+		if j := n.children.lastBefore(i); j >= 0 {
+			min = n.children.nodes[j]
 			ancestor = false
 		} else if n.hasValue() {
 			min = n
 			ancestor = true
 		}
-		n = n.children[i]
+		n = n.children.nodes[i]
 		key = key[len(n.prefix):]
 	}
 
 	if min != nil {
 		if ancestor {
-			return *min.value, true
+			return min.leaf.value, true
 		}
 		return min.max()
 	}
@@ -269,10 +754,34 @@ func (t *RadixTree[T]) Predecessor(key []byte) (T, bool) {
 // found. If the key was not present in the tree it will return the zero value
 // for type T and a boolean value of false.
 func (t *RadixTree[T]) Remove(key []byte) (T, bool) {
+	return t.remove(key, nil)
+}
+
+// RemoveExpected is like Remove but only removes the key if pred returns true
+// for its current value, all in a single traversal. If the key is in the tree
+// but pred returns false the current value and false are returned and the tree
+// is unchanged. pred must not modify the tree; RemoveExpected panics with
+// ErrModified if it does.
+func (t *RadixTree[T]) RemoveExpected(key []byte, pred func(value T) bool) (T, bool) {
+	return t.remove(key, pred)
+}
+
+// remove removes the key if it holds a value for which pred, if not nil,
+// returns true.
+func (t *RadixTree[T]) remove(key []byte, pred func(T) bool) (T, bool) {
+	if t.shared {
+		if t.lookup(key) == nil {
+			var zero T
+			return zero, false
+		}
+		t.unshare(key)
+	}
 	var parent *node[T]
 	var i int
+	full := key
 	n := t.root
 	root := n
+	t.path = append(t.path[:0], n)
 
 	for len(key) > 0 {
 		if i = n.children.index(key[0]); i < 0 {
@@ -280,47 +789,109 @@ func (t *RadixTree[T]) Remove(key []byte) (T, bool) {
 			return zero, false
 		}
 		parent = n
-		n = n.children[i]
+		n = n.children.nodes[i]
 		if !bytes.HasPrefix(key, n.prefix) {
 			var zero T
 			return zero, false
 		}
+		t.path = append(t.path, n)
 		key = key[len(n.prefix):]
 	}
 
-	if n.hasValue() {
-		v := *n.value
-		n.value = nil
-
-		// If the node to be deleted has no children it can be removed
-		// from the parent node's list of children.
-		if parent != nil && len(n.children) == 0 {
-			parent.children = append(parent.children[:i], parent.children[i+1:]...)
+	if !n.hasValue() {
+		var zero T
+		return zero, false
+	}
+	if pred != nil {
+		gen := t.gen
+		ok := pred(n.leaf.value)
+		t.unmodified(gen)
+		if !ok {
+			return n.leaf.value, false
 		}
+	}
 
-		// If the node to be deleted only has a single child that child
-		// can be merged into node n.
-		if n != root && len(n.children) == 1 {
-			merge(n)
-		}
+	t.gen++
+	t.detached++
+	t.logRemoval(backupKey, full, nil)
+	t.addKeyBytes(t.gen-1, -len(full))
+	v := n.leaf.value
+	n.leaf = nil
+	for _, n := range t.path {
+		n.count--
+	}
 
-		// If the parent node exists, has no value, and only has a
-		// single child it can be merged with that child.
-		if parent != nil && parent != root && len(parent.children) == 1 && !parent.hasValue() {
-			merge(parent)
+	if t.opts.lazyDelete || t.opts.deferMerge {
+		if !t.opts.lazyDelete {
+			t.removeEmpty(t.path)
 		}
+		t.removed++
 		t.size--
+		t.refresh(t.path)
 		return v, true
 	}
-	var zero T
-	return zero, false
+
+	// If the node to be deleted has no children it can be removed from the
+	// parent node's list of children.
+	if parent != nil && n.children.len() == 0 {
+		parent.children.remove(i)
+		t.free(n.prefix)
+	}
+
+	// If the node to be deleted only has a single child that child can be
+	// merged into node n.
+	if n != root && n.children.len() == 1 {
+		t.merge(n)
+	}
+
+	// If the parent node exists, has no value, and only has a single child
+	// it can be merged with that child.
+	if parent != nil && parent != root && parent.children.len() == 1 && !parent.hasValue() {
+		t.merge(parent)
+	}
+	t.size--
+	t.refresh(t.path)
+	return v, true
 }
 
-func merge[T any](n *node[T]) {
-	child := n.children[0]
-	n.prefix = append(n.prefix, child.prefix...)
-	n.value = child.value
+// merge merges n, which must have a single child and no value, with its child.
+func (t *RadixTree[T]) merge(n *node[T]) {
+	// The child's leaf and children are taken over by n so they must not be
+	// shared.
+	child := t.mutable(n.children.nodes[0])
+	if t.opts.alloc != nil {
+		prefix := t.opts.alloc.Alloc(len(n.prefix) + len(child.prefix))
+		copy(prefix[copy(prefix, n.prefix):], child.prefix)
+		t.free(n.prefix)
+		t.free(child.prefix)
+		n.prefix = prefix
+	} else {
+		// The prefix may share its backing array with other nodes so it
+		// must be copied rather than appended to in place.
+		n.prefix = append(n.prefix[:len(n.prefix):len(n.prefix)], child.prefix...)
+	}
+	n.leaf = child.leaf
 	n.children = child.children
+	n.agg = child.agg
+	n.count = child.count
+}
+
+// ShortestKey returns the shortest key in the tree and its value. If several
+// keys have the least length the smallest of them is returned. The boolean
+// return value will be false if the tree is empty. Subtrees that can only hold
+// longer keys than the shortest one found so far are skipped. The returned key
+// is a copy that the caller may retain.
+func (t *RadixTree[T]) ShortestKey() ([]byte, T, bool) {
+	var shortest *node[T]
+	var shortestKey []byte
+	key := t.borrow()
+	key = walkShortest(t.root, key, &shortest, &shortestKey)
+	t.release(key)
+	if shortest == nil {
+		var zero T
+		return nil, zero, false
+	}
+	return shortestKey, shortest.leaf.value, true
 }
 
 // Successor returns the value that is associated with the key that immediately
@@ -329,24 +900,41 @@ func merge[T any](n *node[T]) {
 // exist in the tree, the zero value for type T and a boolean value of false
 // will be returned.
 func (t *RadixTree[T]) Successor(key []byte) (T, bool) {
-	n := t.root
+	return t.successor(t.root, key)
+}
+
+// SuccessorPrefix is like Successor but only considers keys that start with
+// the given prefix. If the given key does not start with the prefix, or its
+// successor does not, the zero value for type T and a boolean value of false
+// will be returned.
+func (t *RadixTree[T]) SuccessorPrefix(prefix, key []byte) (T, bool) {
+	if n, path := t.within(prefix, key); n != nil {
+		return t.successor(n, key[len(path):])
+	}
+	var zero T
+	return zero, false
+}
+
+// successor returns the value of the key that follows key in the subtree rooted
+// at n, where key is relative to n.
+func (t *RadixTree[T]) successor(n *node[T], key []byte) (T, bool) {
 	var min *node[T]
 
 	for len(key) > 0 {
 		i := n.children.index(key[0])
-		if i < 0 || !bytes.HasPrefix(key, n.children[i].prefix) {
+		if i < 0 || !bytes.HasPrefix(key, n.children.nodes[i].prefix) {
 			var zero T
 			return zero, false
 		}
-		if r := i + 1; r < len(n.children) {
-			min = n.children[r]
+		if r := n.children.firstFrom(i + 1); r >= 0 {
+			min = n.children.nodes[r]
 		}
-		n = n.children[i]
+		n = n.children.nodes[i]
 		key = key[len(n.prefix):]
 	}
 
-	if len(n.children) != 0 {
-		min = n.children[0]
+	if r := n.children.firstFrom(0); r >= 0 {
+		min = n.children.nodes[r]
 	}
 
 	if min != nil {
@@ -369,28 +957,219 @@ func (t *RadixTree[T]) Values() []T {
 
 // Walk traverses the tree rooted at the given prefix and executes function f
 // for each value. If f returns true the traversal continues otherwise the
-// traversal stops.
+// traversal stops. If f modifies the tree and returns true Walk panics with
+// ErrModified.
 func (t *RadixTree[T]) Walk(prefix []byte, f func(value T) bool) {
-	n := t.root
+	if n := t.find(prefix); n != nil {
+		gen := t.gen
+		walk(n, func(value T) bool {
+			return f(value) && t.unmodified(gen)
+		})
+	}
+}
 
-	for len(prefix) > 0 {
-		n = n.children.get(prefix[0])
-		if n == nil || !bytes.HasPrefix(prefix, n.prefix) {
-			break
+// WalkDepth traverses the keys that start with the given prefix and are at most
+// depth bytes longer than it, executing function f for each key and its value
+// in ascending key order. Subtrees below the depth limit are not visited. The
+// key passed to f is a copy that the caller may retain unless the tree was
+// created with WithSharedKeys. If f returns true the traversal continues
+// otherwise the traversal stops. If f modifies the tree and returns true
+// WalkDepth panics with ErrModified.
+func (t *RadixTree[T]) WalkDepth(prefix []byte, depth int, f func(key []byte, value T) bool) {
+	n, key := t.findPath(prefix, t.borrow())
+	if n != nil && depth >= 0 {
+		gen := t.gen
+		key, _ = walkNodes(n, key, len(prefix)+depth, func(key []byte, n *node[T]) bool {
+			return f(t.yield(key), n.leaf.value) && t.unmodified(gen)
+		})
+	}
+	t.release(key)
+}
+
+// WalkSegments is like WalkDepth with the depth counted in segments separated
+// by delimiter rather than in bytes: it traverses the keys that start with the
+// given prefix and have fewer than depth delimiters after it, not counting one
+// at the end of the key. With a depth of 1 and a delimiter of "/", the keys
+// below "a/" that are visited are those like "a/b" and "a/b/", the immediate
+// children in a listing of a directory, and a depth of 0 visits only the key
+// equal to the prefix. Subtrees whose keys all have too many delimiters are not
+// visited. With an empty delimiter every key with the prefix is visited. The
+// key passed to f is a copy that the caller may retain unless the tree was
+// created with WithSharedKeys. If f returns true the traversal continues
+// otherwise the traversal stops. If f modifies the tree and returns true
+// WalkSegments panics with ErrModified.
+func (t *RadixTree[T]) WalkSegments(prefix, delimiter []byte, depth int, f func(key []byte, value T) bool) {
+	n, key := t.findPath(prefix, t.borrow())
+	if n != nil && depth >= 0 {
+		gen := t.gen
+		s := segmentWalker[T]{start: len(prefix), delimiter: delimiter, depth: depth}
+		s.f = func(key []byte, n *node[T]) bool {
+			return f(t.yield(key), n.leaf.value) && t.unmodified(gen)
+		}
+		key, _ = s.walk(n, key)
+	}
+	t.release(key)
+}
+
+// segmentWalker holds the state of WalkSegments.
+type segmentWalker[T any] struct {
+	start     int
+	delimiter []byte
+	depth     int
+	f         func(key []byte, n *node[T]) bool
+}
+
+// walk calls f for the keys within the depth in the subtree rooted at n, whose
+// full key is key, as walkNodes does.
+func (s *segmentWalker[T]) walk(n *node[T], key []byte) ([]byte, bool) {
+	if len(s.delimiter) == 0 {
+		return walkNodes(n, key, -1, s.f)
+	}
+	rest := key[s.start:]
+	if n.hasValue() && (len(rest) == 0 || bytes.Count(bytes.TrimSuffix(rest, s.delimiter), s.delimiter) < s.depth) {
+		if !s.f(key, n) {
+			return key, false
+		}
+	}
+	// A longer key has at least the delimiters of this one, except for those
+	// in the last bytes that a trailing delimiter of the longer key could
+	// overlap, so once the others reach the depth no key below n is visited.
+	if bytes.Count(rest[:max(0, len(rest)-len(s.delimiter)+1)], s.delimiter) >= s.depth {
+		return key, true
+	}
+	for _, child := range n.children.nodes {
+		l := len(key)
+		var ok bool
+		key, ok = s.walk(child, append(key, child.prefix...))
+		key = key[:l]
+		if !ok {
+			return key, false
 		}
-		prefix = prefix[len(n.prefix):]
 	}
+	return key, true
+}
 
+// WalkKeys traverses the keys that start with the given prefix in ascending
+// order and executes function f for each of them without reading their values.
+// The key passed to f is a copy that the caller may retain unless the tree was
+// created with WithSharedKeys. If f returns true the traversal continues
+// otherwise the traversal stops. If f modifies the tree and returns true
+// WalkKeys panics with ErrModified.
+func (t *RadixTree[T]) WalkKeys(prefix []byte, f func(key []byte) bool) {
+	n, key := t.findPath(prefix, t.borrow())
 	if n != nil {
-		walk(n, f)
+		gen := t.gen
+		key, _ = walkNodes(n, key, -1, func(key []byte, _ *node[T]) bool {
+			return f(t.yield(key)) && t.unmodified(gen)
+		})
+	}
+	t.release(key)
+}
+
+// unmodified panics with ErrModified if the tree has been modified since
+// generation gen. It is used by traversals to fail fast rather than continue
+// over nodes that may have been restructured.
+func (t *RadixTree[T]) unmodified(gen uint64) bool {
+	if t.gen != gen {
+		panic(ErrModified)
+	}
+	return true
+}
+
+// keyBuffers holds the buffers for building keys during the traversals of
+// trees that do not share keys, which must not write to the tree so that
+// they can run concurrently.
+var keyBuffers = sync.Pool{New: func() any { return new([]byte) }}
+
+// borrow returns an empty buffer for building keys. A tree created with
+// WithSharedKeys reuses its scratch buffer if it is not already in use by an
+// enclosing traversal, and other trees take one from keyBuffers.
+func (t *RadixTree[T]) borrow() []byte {
+	if t == nil {
+		return nil
+	}
+	if !t.opts.sharedKeys {
+		return (*keyBuffers.Get().(*[]byte))[:0]
+	}
+	buf := t.scratch
+	t.scratch = nil
+	return buf[:0]
+}
+
+// release returns a buffer obtained from borrow so it can be reused.
+func (t *RadixTree[T]) release(buf []byte) {
+	if t == nil {
+		return
+	}
+	if !t.opts.sharedKeys {
+		if cap(buf) > 0 {
+			buf = buf[:0]
+			keyBuffers.Put(&buf)
+		}
+		return
+	}
+	t.scratch = buf[:0]
+}
+
+// yield returns the key to pass to a callback, copying it unless keys are
+// shared.
+func (t *RadixTree[T]) yield(key []byte) []byte {
+	if t.opts.sharedKeys {
+		return key
 	}
+	return append([]byte(nil), key...)
+}
+
+// find returns the node whose subtree holds every key that starts with the
+// given prefix, or nil if no key starts with it.
+func (t *RadixTree[T]) find(prefix []byte) *node[T] {
+	n, _ := t.seek(prefix, nil, false)
+	return n
+}
+
+// findPath is like find but also appends the full key of the returned node to
+// buf and returns the extended buffer.
+func (t *RadixTree[T]) findPath(prefix, buf []byte) (*node[T], []byte) {
+	return t.seek(prefix, buf, true)
+}
+
+func (t *RadixTree[T]) seek(prefix, buf []byte, path bool) (*node[T], []byte) {
+	if t == nil {
+		// A nil tree is empty.
+		return nil, buf
+	}
+	n := t.root
+
+	for len(prefix) > 0 {
+		child := n.children.get(prefix[0])
+		if child == nil {
+			return nil, buf
+		}
+		if path {
+			buf = append(buf, child.prefix...)
+		}
+		if len(prefix) <= len(child.prefix) {
+			// The prefix ends within this child's prefix so every key
+			// below the child starts with it if the bytes agree.
+			if !bytes.HasPrefix(child.prefix, prefix) {
+				return nil, buf
+			}
+			return child, buf
+		}
+		if !bytes.HasPrefix(prefix, child.prefix) {
+			return nil, buf
+		}
+		n = child
+		prefix = prefix[len(child.prefix):]
+	}
+	return n, buf
 }
 
 func walk[T any](n *node[T], f func(value T) bool) bool {
-	if n.hasValue() && !f(*n.value) {
+	if n.hasValue() && !f(n.leaf.value) {
 		return false
 	}
-	for _, node := range n.children {
+	for _, node := range n.children.nodes {
 		if !walk(node, f) {
 			return false
 		}
@@ -398,6 +1177,56 @@ func walk[T any](n *node[T], f func(value T) bool) bool {
 	return true
 }
 
+// walkNodes executes f for each node with a value in the subtree rooted at n in
+// ascending key order. The key of n must be in key, which is used as scratch
+// space for the keys passed to f. Keys longer than limit are skipped unless
+// limit is negative. It returns the scratch buffer so it can be reused and
+// false if f stopped the traversal.
+func walkNodes[T any](n *node[T], key []byte, limit int, f func(key []byte, n *node[T]) bool) ([]byte, bool) {
+	if limit >= 0 && len(key) > limit {
+		// A prefix that ends inside the edge to n can leave its key past
+		// the limit already.
+		return key, true
+	}
+	if n.hasValue() && !f(key, n) {
+		return key, false
+	}
+	for _, child := range n.children.nodes {
+		l := len(key)
+		if limit >= 0 && l+len(child.prefix) > limit {
+			continue
+		}
+		var ok bool
+		key, ok = walkNodes(child, append(key, child.prefix...), limit, f)
+		key = key[:l]
+		if !ok {
+			return key, false
+		}
+	}
+	return key, true
+}
+
+// walkShortest records in shortest and shortestKey the node holding the
+// shortest key in the subtree rooted at n unless a key found earlier is no
+// longer than it. The keys below a node with a value are longer than its own
+// key so they are never visited.
+func walkShortest[T any](n *node[T], key []byte, shortest **node[T], shortestKey *[]byte) []byte {
+	if *shortest != nil && len(key) >= len(*shortestKey) {
+		return key
+	}
+	if n.hasValue() {
+		*shortest = n
+		*shortestKey = append((*shortestKey)[:0], key...)
+		return key
+	}
+	for _, child := range n.children.nodes {
+		l := len(key)
+		key = walkShortest(child, append(key, child.prefix...), shortest, shortestKey)
+		key = key[:l]
+	}
+	return key
+}
+
 func longestCommonPrefix(a, b []byte) int {
 	limit := len(a)
 	if l := len(b); l < limit {
@@ -1,9 +1,11 @@
 package radixtree
 
 import (
+	"errors"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -19,6 +21,60 @@ func build(keys []string) *RadixTree[string] {
 	return tree
 }
 
+// verify checks the structural invariants of the tree: children are sorted by
+// their first byte, which their parent records, only the root may have an
+// empty prefix, nodes other than the root without a value have at least two
+// children, or one if merges are deferred and any number if deletion is lazy,
+// and the size matches the number of values.
+func verify[T any](t *testing.T, tree *RadixTree[T]) {
+	t.Helper()
+	var check func(n *node[T], root bool) int
+	check = func(n *node[T], root bool) int {
+		c := 0
+		if n.hasValue() {
+			c++
+		}
+		if !root {
+			if len(n.prefix) == 0 {
+				t.Errorf("non-root node with an empty prefix")
+			}
+			if !n.hasValue() && n.children.len() < 2 && !tree.opts.lazyDelete && (!tree.opts.deferMerge || n.children.len() == 0) {
+				t.Errorf("node %q without a value has %d children", n.prefix, n.children.len())
+			}
+		}
+		if len(n.children.keys) != n.children.len() {
+			t.Errorf("node %q has %d first bytes for %d children", n.prefix, len(n.children.keys), n.children.len())
+		}
+		for i, child := range n.children.nodes {
+			if i > 0 && !tree.opts.order.less(n.children.nodes[i-1].prefix[0], child.prefix[0]) {
+				t.Errorf("children of node %q are not sorted", n.prefix)
+			}
+			if i < len(n.children.keys) && n.children.keys[i] != child.prefix[0] {
+				t.Errorf("node %q records first byte %q for child %q", n.prefix, n.children.keys[i], child.prefix)
+			}
+			c += check(child, false)
+		}
+		if n.count != c {
+			t.Errorf("node %q counts %d values but has %d", n.prefix, n.count, c)
+		}
+		return c
+	}
+	if got := check(tree.root, true); got != tree.Len() {
+		t.Errorf("Len does not match the number of values\n got: %d\nwant: %d", tree.Len(), got)
+	}
+}
+
+// less returns the elements of xs that are less than s.
+func less(s string, xs []string) []string {
+	var ys []string
+	for _, x := range xs {
+		if x < s {
+			ys = append(ys, x)
+		}
+	}
+	return ys
+}
+
 func hasPrefix(prefix string, xs []string) []string {
 	var ys []string
 	for _, s := range xs {
@@ -45,6 +101,70 @@ func TestContains(t *testing.T) {
 	if tree.Contains([]byte{}) {
 		t.Errorf("Contains returned true for empty byte slice key")
 	}
+
+	tree.Insert(nil, "")
+	if !tree.Contains([]byte{}) || !tree.Contains(nil) {
+		t.Errorf("Contains returned false for the empty key after inserting it")
+	}
+}
+
+func TestEmptyKey(t *testing.T) {
+	tree := build(words)
+	want := "default"
+	if _, ok := tree.Insert(nil, want); ok {
+		t.Errorf("Insert of the empty key found an existing value")
+	}
+	verify(t, tree)
+
+	if got, ok := tree.Get([]byte{}); !ok || got != want {
+		t.Errorf("Get of the empty key\n got: (%s, %t)\nwant: (%s, true)", got, ok, want)
+	}
+	if got, ok := tree.LongestPrefix([]byte("xyz")); !ok || got != want {
+		t.Errorf("LongestPrefix without a longer match\n got: (%s, %t)\nwant: (%s, true)", got, ok, want)
+	}
+	if got, ok := tree.LongestPrefix([]byte("toadstool")); !ok || got != "toad" {
+		t.Errorf("LongestPrefix with a longer match\n got: (%s, %t)\nwant: (toad, true)", got, ok)
+	}
+	if got, ok := tree.Min(); !ok || got != want {
+		t.Errorf("Min\n got: (%s, %t)\nwant: (%s, true)", got, ok, want)
+	}
+	if got, ok := tree.Predecessor([]byte(words[0])); !ok || got != want {
+		t.Errorf("Predecessor(%s)\n got: (%s, %t)\nwant: (%s, true)", words[0], got, ok, want)
+	}
+	if got, ok := tree.Successor(nil); !ok || got != words[0] {
+		t.Errorf("Successor of the empty key\n got: (%s, %t)\nwant: (%s, true)", got, ok, words[0])
+	}
+	if got := tree.Find(nil); len(got) != len(words)+1 || got[0] != want {
+		t.Errorf("Find(nil) returned %d values starting with %q", len(got), got[0])
+	}
+	var keys []string
+	tree.WalkKeys(nil, func(key []byte) bool {
+		keys = append(keys, string(key))
+		return len(keys) < 2
+	})
+	if !reflect.DeepEqual(keys, []string{"", words[0]}) {
+		t.Errorf("WalkKeys\n got: %q\nwant: %q", keys, []string{"", words[0]})
+	}
+	if got := tree.Rank([]byte(words[0])); got != 1 {
+		t.Errorf("Rank(%s) = %d, want 1", words[0], got)
+	}
+
+	left, right := tree.Split([]byte("a"))
+	if got, ok := left.Get(nil); !ok || got != want || left.Len() != 1 {
+		t.Errorf("Split left half\n got: (%s, %t) with %d keys\nwant: (%s, true) with 1 key", got, ok, left.Len(), want)
+	}
+	tree, _ = Join(left, right)
+
+	if got, ok := tree.Remove(nil); !ok || got != want {
+		t.Errorf("Remove of the empty key\n got: (%s, %t)\nwant: (%s, true)", got, ok, want)
+	}
+	if tree.Contains(nil) || tree.Len() != len(words) {
+		t.Errorf("the empty key is still in the tree after Remove")
+	}
+	if _, ok := tree.LongestPrefix([]byte("xyz")); ok {
+		t.Errorf("LongestPrefix found the removed empty key")
+	}
+	verify(t, tree)
 }
 
 func TestFind(t *testing.T) {
@@ -66,6 +186,88 @@ func TestFind(t *testing.T) {
 	if got := tree.Find([]byte{0}); len(got) != 0 {
 		t.Errorf("Find with a non-existent prefix\n got: %v\nwant: %v", got, want)
 	}
+
+	// The prefix shares its first byte with a node but then diverges.
+	if got := tree.Find([]byte("mx")); len(got) != 0 {
+		t.Errorf("Find with a diverging prefix\n got: %v\nwant: %v", got, want)
+	}
+
+	// The prefix ends part way through a node's prefix.
+	prefix = "macroan"
+	want = hasPrefix(prefix, words)
+	if got := tree.Find([]byte(prefix)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Find(%s)\n got: %v\nwant: %v", prefix, got, want)
+	}
+}
+
+func TestAppendFind(t *testing.T) {
+	tree := build(words)
+
+	dst := []string{"first"}
+	want := append([]string{"first"}, hasPrefix("wi", words)...)
+	if got := tree.AppendFind(dst, []byte("wi")); !reflect.DeepEqual(got, want) {
+		t.Errorf("AppendFind(wi)\n got: %v\nwant: %v", got, want)
+	}
+
+	if got := tree.AppendFind(dst[:0], []byte{0}); len(got) != 0 {
+		t.Errorf("AppendFind with a non-existent prefix\n got: %v\nwant: []", got)
+	}
+}
+
+func TestAppendKeys(t *testing.T) {
+	tree := build(words)
+
+	got := tree.AppendKeys([][]byte{[]byte("first")}, []byte("wi"))
+	want := append([]string{"first"}, hasPrefix("wi", words)...)
+	if len(got) != len(want) {
+		t.Fatalf("AppendKeys(wi)\n got: %q\nwant: %q", got, want)
+	}
+	for i := range got {
+		if string(got[i]) != want[i] {
+			t.Errorf("AppendKeys(wi)\n got: %q\nwant: %q", got, want)
+			break
+		}
+	}
+}
+
+func TestFindN(t *testing.T) {
+	tree := build(words)
+
+	prefix := "to"
+	want := hasPrefix(prefix, words)[:3]
+	if got := tree.FindN([]byte(prefix), 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindN(%s, 3)\n got: %v\nwant: %v", prefix, got, want)
+	}
+
+	want = hasPrefix(prefix, words)
+	if got := tree.FindN([]byte(prefix), 100); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindN(%s, 100)\n got: %v\nwant: %v", prefix, got, want)
+	}
+
+	if got := tree.FindN([]byte(prefix), 0); len(got) != 0 {
+		t.Errorf("FindN(%s, 0)\n got: %v\nwant: []", prefix, got)
+	}
+
+	if got := tree.FindN([]byte{0}, 3); len(got) != 0 {
+		t.Errorf("FindN with a non-existent prefix\n got: %v\nwant: []", got)
+	}
+}
+
+func TestFindFunc(t *testing.T) {
+	tree := build(words)
+
+	got := tree.FindFunc([]byte("to"), func(key []byte, value string) bool {
+		if string(key) != value {
+			t.Errorf("FindFunc passed key %q with value %q", key, value)
+		}
+		return len(key)%2 == 0
+	})
+	if want := []string{"to", "toad", "toadyism"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindFunc\n got: %v\nwant: %v", got, want)
+	}
+	if got := tree.FindFunc([]byte{0}, func([]byte, string) bool { return true }); len(got) != 0 {
+		t.Errorf("FindFunc with a non-existent prefix\n got: %v\nwant: []", got)
+	}
 }
 
 func TestGet(t *testing.T) {
@@ -104,6 +306,65 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGetRef(t *testing.T) {
+	type big struct {
+		n    int
+		name [64]byte
+	}
+	tree := New[big]()
+	for i, key := range words {
+		tree.Insert([]byte(key), big{n: i})
+	}
+
+	p, ok := tree.GetRef([]byte("toad"))
+	if !ok || p.n != 16 {
+		t.Fatalf("GetRef(%q) = (%v, %t), want a pointer to 16", "toad", p, ok)
+	}
+	p.n = 100
+	if v, _ := tree.Get([]byte("toad")); v.n != 100 {
+		t.Errorf("Get after writing through GetRef = %d, want 100", v.n)
+	}
+
+	// The pointer survives updates and changes to the nodes around the key.
+	tree.Insert([]byte("toad"), big{n: 200})
+	tree.Insert([]byte("toadst"), big{})
+	tree.Remove([]byte("toa"))
+	left, right := tree.Split([]byte("toadx"))
+	tree, _ = Join(left, right)
+	if p.n != 200 {
+		t.Errorf("GetRef pointer after Insert, Split and Join = %d, want 200", p.n)
+	}
+	p.n = 300
+	if v, _ := tree.Get([]byte("toad")); v.n != 300 {
+		t.Errorf("Get after Split and Join = %d, want 300", v.n)
+	}
+
+	if p, ok := tree.GetRef([]byte("toa")); ok || p != nil {
+		t.Errorf("GetRef(%q) = (%v, %t), want (nil, false)", "toa", p, ok)
+	}
+}
+
+func TestHasPrefix(t *testing.T) {
+	if New[int]().HasPrefix(nil) {
+		t.Errorf("HasPrefix on empty tree = true")
+	}
+
+	tree := build(words)
+	for _, prefix := range []string{"", "a", "aard", "macroanalysis", "toady", "wi"} {
+		if !tree.HasPrefix([]byte(prefix)) {
+			t.Errorf("HasPrefix(%q) = false, want true", prefix)
+		}
+	}
+	for _, prefix := range []string{"ac", "macroanalysiss", "toadz", "x"} {
+		if tree.HasPrefix([]byte(prefix)) {
+			t.Errorf("HasPrefix(%q) = true, want false", prefix)
+		}
+	}
+	if got := testing.AllocsPerRun(100, func() { tree.HasPrefix([]byte("mac")) }); got != 0 {
+		t.Errorf("HasPrefix allocated %v times", got)
+	}
+}
+
 func TestInsert(t *testing.T) {
 	tree := build(words)
 	want := "wink"
@@ -118,6 +379,21 @@ func TestInsert(t *testing.T) {
 	}
 }
 
+func TestIsEmpty(t *testing.T) {
+	tree := New[int]()
+	if !tree.IsEmpty() {
+		t.Errorf("IsEmpty on new tree = false")
+	}
+	tree.Insert([]byte("a"), 1)
+	if tree.IsEmpty() {
+		t.Errorf("IsEmpty after insert = true")
+	}
+	tree.Remove([]byte("a"))
+	if !tree.IsEmpty() {
+		t.Errorf("IsEmpty after remove = false")
+	}
+}
+
 func TestLen(t *testing.T) {
 	tree := New[int]()
 	if got := tree.Len(); got != 0 {
@@ -145,6 +421,26 @@ func TestLen(t *testing.T) {
 	}
 }
 
+func TestLongestKey(t *testing.T) {
+	if key, got, ok := New[int]().LongestKey(); ok || key != nil || got != 0 {
+		t.Errorf("LongestKey on empty tree\n got: (%q, %v, %t)\nwant: (\"\", 0, false)", key, got, ok)
+	}
+
+	tree := build(words)
+	want := "macroanalysis"
+	if key, got, ok := tree.LongestKey(); !ok || string(key) != want || got != want {
+		t.Errorf("LongestKey\n got: (%q, %s, %t)\nwant: (%q, %s, true)", key, got, ok, want, want)
+	}
+
+	// Ties are broken by taking the smallest key.
+	tree.Insert([]byte("zzzzzzzzzzzzz"), "zzzzzzzzzzzzz")
+	tree.Insert([]byte("aaaaaaaaaaaaa"), "aaaaaaaaaaaaa")
+	want = "aaaaaaaaaaaaa"
+	if key, got, ok := tree.LongestKey(); !ok || string(key) != want || got != want {
+		t.Errorf("LongestKey with ties\n got: (%q, %s, %t)\nwant: (%q, %s, true)", key, got, ok, want, want)
+	}
+}
+
 func TestLongestPrefix(t *testing.T) {
 	if got, ok := New[int]().LongestPrefix([]byte("a")); ok || got != 0 {
 		t.Errorf("LongestPrefix on empty tree\n got: (%v, %t)\nwant: (0, false)", got, ok)
@@ -182,6 +478,29 @@ func TestMax(t *testing.T) {
 	}
 }
 
+func TestMaxPrefix(t *testing.T) {
+	tree := build(words)
+
+	tests := []struct {
+		prefix string
+		want   string
+		ok     bool
+	}{
+		{"", words[len(words)-1], true},
+		{"mac", "mactroid", true},
+		{"macroan", "macroanalyst", true},
+		{"to", "toadyism", true},
+		{"wink", "winkleman", true},
+		{"x", "", false},
+		{"macz", "", false},
+	}
+	for _, test := range tests {
+		if got, ok := tree.MaxPrefix([]byte(test.prefix)); ok != test.ok || got != test.want {
+			t.Errorf("MaxPrefix(%s)\n got: (%s, %t)\nwant: (%s, %t)", test.prefix, got, ok, test.want, test.ok)
+		}
+	}
+}
+
 func TestMin(t *testing.T) {
 	if got, ok := New[int]().Min(); ok || got != 0 {
 		t.Errorf("Min on empty tree\ngot: (%v, %t)\nwant: (0, false)", got, ok)
@@ -200,6 +519,29 @@ func TestMin(t *testing.T) {
 	}
 }
 
+func TestMinPrefix(t *testing.T) {
+	tree := build(words)
+
+	tests := []struct {
+		prefix string
+		want   string
+		ok     bool
+	}{
+		{"", words[0], true},
+		{"mac", "macro", true},
+		{"macroan", "macroanalysis", true},
+		{"to", "to", true},
+		{"wil", "will", true},
+		{"x", "", false},
+		{"macz", "", false},
+	}
+	for _, test := range tests {
+		if got, ok := tree.MinPrefix([]byte(test.prefix)); ok != test.ok || got != test.want {
+			t.Errorf("MinPrefix(%s)\n got: (%s, %t)\nwant: (%s, %t)", test.prefix, got, ok, test.want, test.ok)
+		}
+	}
+}
+
 func TestPredecessor(t *testing.T) {
 	if got, ok := New[int]().Predecessor([]byte("key")); ok || got != 0 {
 		t.Errorf("Predecessor on empty tree\n got: (%v, %t)\nwant: (0, false)", got, ok)
@@ -225,6 +567,32 @@ func TestPredecessor(t *testing.T) {
 	}
 }
 
+func TestPredecessorPrefix(t *testing.T) {
+	tree := build(words)
+
+	tests := []struct {
+		prefix, key string
+		want        string
+		ok          bool
+	}{
+		{"to", "toad", "toa", true},
+		{"to", "to", "", false},
+		{"toa", "toa", "", false},
+		{"mac", "macroanalysis", "macro", true},
+		{"macroan", "macroanalyst", "macroanalysis", true},
+		{"macroan", "macroanalysis", "", false},
+		{"wi", "win", "wilting", true},
+		{"to", "wink", "", false},
+		{"to", "tob", "", false},
+		{"", "to", "sequence", true},
+	}
+	for _, test := range tests {
+		if got, ok := tree.PredecessorPrefix([]byte(test.prefix), []byte(test.key)); ok != test.ok || got != test.want {
+			t.Errorf("PredecessorPrefix(%s, %s)\n got: (%s, %t)\nwant: (%s, %t)", test.prefix, test.key, got, ok, test.want, test.ok)
+		}
+	}
+}
+
 func TestRemove(t *testing.T) {
 	tree := build(words)
 
@@ -263,6 +631,59 @@ func TestRemove(t *testing.T) {
 	}
 }
 
+func TestRemoveExpected(t *testing.T) {
+	tree := New[int]()
+	tree.Insert([]byte("a"), 1)
+	tree.Insert([]byte("ab"), 2)
+	is := func(want int) func(int) bool {
+		return func(v int) bool { return v == want }
+	}
+
+	if got, ok := tree.RemoveExpected([]byte("ab"), is(3)); ok || got != 2 {
+		t.Errorf("RemoveExpected with a failing predicate\n got: (%d, %t)\nwant: (2, false)", got, ok)
+	}
+	if !tree.Contains([]byte("ab")) {
+		t.Errorf("RemoveExpected with a failing predicate removed the key")
+	}
+	if got, ok := tree.RemoveExpected([]byte("ab"), is(2)); !ok || got != 2 {
+		t.Errorf("RemoveExpected\n got: (%d, %t)\nwant: (2, true)", got, ok)
+	}
+	if got, ok := tree.RemoveExpected([]byte("ab"), is(2)); ok || got != 0 {
+		t.Errorf("RemoveExpected with a missing key\n got: (%d, %t)\nwant: (0, false)", got, ok)
+	}
+	verify(t, tree)
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrModified) {
+			t.Errorf("RemoveExpected with a modifying predicate panicked with %v, want %v", err, ErrModified)
+		}
+	}()
+	tree.RemoveExpected([]byte("a"), func(int) bool {
+		tree.Insert([]byte("b"), 3)
+		return true
+	})
+}
+
+func TestShortestKey(t *testing.T) {
+	if key, got, ok := New[int]().ShortestKey(); ok || key != nil || got != 0 {
+		t.Errorf("ShortestKey on empty tree\n got: (%q, %v, %t)\nwant: (\"\", 0, false)", key, got, ok)
+	}
+
+	tree := build(words)
+	want := "to"
+	if key, got, ok := tree.ShortestKey(); !ok || string(key) != want || got != want {
+		t.Errorf("ShortestKey\n got: (%q, %s, %t)\nwant: (%q, %s, true)", key, got, ok, want, want)
+	}
+
+	// Ties are broken by taking the smallest key.
+	tree.Insert([]byte("wi"), "wi")
+	tree.Insert([]byte("ma"), "ma")
+	want = "ma"
+	if key, got, ok := tree.ShortestKey(); !ok || string(key) != want || got != want {
+		t.Errorf("ShortestKey with ties\n got: (%q, %s, %t)\nwant: (%q, %s, true)", key, got, ok, want, want)
+	}
+}
+
 func TestSuccessor(t *testing.T) {
 	if got, ok := New[int]().Successor([]byte("key")); ok || got != 0 {
 		t.Errorf("Successor on empty tree\n got: (%v, %t)\nwant: (0, false)", got, ok)
@@ -288,6 +709,32 @@ func TestSuccessor(t *testing.T) {
 	}
 }
 
+func TestSuccessorPrefix(t *testing.T) {
+	tree := build(words)
+
+	tests := []struct {
+		prefix, key string
+		want        string
+		ok          bool
+	}{
+		{"to", "toad", "toady", true},
+		{"to", "toadyism", "", false},
+		{"to", "to", "toa", true},
+		{"mac", "macrochelys", "mactroid", true},
+		{"macro", "macrochelys", "", false},
+		{"macroan", "macroanalysis", "macroanalyst", true},
+		{"wil", "will", "wilting", true},
+		{"wil", "wilting", "", false},
+		{"to", "wink", "", false},
+		{"", "sequence", "to", true},
+	}
+	for _, test := range tests {
+		if got, ok := tree.SuccessorPrefix([]byte(test.prefix), []byte(test.key)); ok != test.ok || got != test.want {
+			t.Errorf("SuccessorPrefix(%s, %s)\n got: (%s, %t)\nwant: (%s, %t)", test.prefix, test.key, got, ok, test.want, test.ok)
+		}
+	}
+}
+
 func TestValues(t *testing.T) {
 	want := make([]string, 0, len(words))
 	if got := New[int]().Values(); len(got) != 0 {
@@ -331,6 +778,206 @@ func TestWalk(t *testing.T) {
 	}
 }
 
+func TestWalkModified(t *testing.T) {
+	mustPanic := func(name string, f func()) {
+		t.Helper()
+		defer func() {
+			if r := recover(); r != ErrModified {
+				t.Errorf("%s modifying the tree\n got: %v\nwant: %v", name, r, ErrModified)
+			}
+		}()
+		f()
+	}
+
+	tree := build(words)
+	mustPanic("Walk", func() {
+		tree.Walk(nil, func(value string) bool {
+			tree.Remove([]byte(value))
+			return true
+		})
+	})
+
+	tree = build(words)
+	mustPanic("WalkKeys", func() {
+		tree.WalkKeys([]byte("to"), func(key []byte) bool {
+			tree.Insert([]byte("tox"), "tox")
+			return true
+		})
+	})
+
+	tree = build(words)
+	mustPanic("WalkDepth", func() {
+		tree.WalkDepth(nil, 3, func(key []byte, value string) bool {
+			tree.Insert(key, value)
+			return true
+		})
+	})
+
+	// Modifying the tree is allowed when the traversal stops.
+	tree = build(words)
+	tree.Walk([]byte("to"), func(value string) bool {
+		tree.Remove([]byte(value))
+		return false
+	})
+	if tree.Contains([]byte("to")) {
+		t.Errorf("Contains(to) after removal in Walk returned true")
+	}
+}
+
+func TestWalkDepth(t *testing.T) {
+	tree := build(words)
+
+	tests := []struct {
+		prefix string
+		depth  int
+		want   []string
+	}{
+		{"to", 0, []string{"to"}},
+		{"to", 1, []string{"to", "toa"}},
+		{"to", 2, []string{"to", "toa", "toad"}},
+		{"t", 10, hasPrefix("t", words)},
+		{"mac", 2, []string{"macro"}},
+		{"w", 2, []string{"win", "wit"}},
+		{"zz", 5, nil},
+	}
+	for _, test := range tests {
+		var got []string
+		tree.WalkDepth([]byte(test.prefix), test.depth, func(key []byte, value string) bool {
+			if string(key) != value {
+				t.Errorf("WalkDepth(%s, %d) passed key %s for value %s", test.prefix, test.depth, key, value)
+			}
+			got = append(got, value)
+			return true
+		})
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("WalkDepth(%s, %d)\n got: %v\nwant: %v", test.prefix, test.depth, got, test.want)
+		}
+	}
+
+	// Stop the traversal after the first value.
+	var got []string
+	tree.WalkDepth([]byte("to"), 3, func(key []byte, value string) bool {
+		got = append(got, value)
+		return false
+	})
+	if want := []string{"to"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkDepth stopped early\n got: %v\nwant: %v", got, want)
+	}
+
+	// A prefix that ends inside an edge is within the depth only up to the
+	// end of the edge.
+	edge := build([]string{"cabba"})
+	for depth, want := range [][]string{3: nil, 4: {"cabba"}} {
+		got = nil
+		edge.WalkDepth([]byte("c"), depth, func(key []byte, value string) bool {
+			got = append(got, value)
+			return true
+		})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("WalkDepth(%q, %d) inside an edge\n got: %v\nwant: %v", "c", depth, got, want)
+		}
+	}
+}
+
+func TestWalkSegments(t *testing.T) {
+	tree := build([]string{"a", "a/", "a/b", "a/b/", "a/b/c", "a/b/c/d", "a/bc", "a/d/", "a/d/e", "ab/x", "b::c::d", "b::c::"})
+
+	tests := []struct {
+		prefix, delimiter string
+		depth             int
+		want              []string
+	}{
+		{"a/", "/", 0, []string{"a/"}},
+		{"a/", "/", 1, []string{"a/", "a/b", "a/b/", "a/bc", "a/d/"}},
+		{"a/", "/", 2, []string{"a/", "a/b", "a/b/", "a/b/c", "a/bc", "a/d/", "a/d/e"}},
+		{"a/b", "/", 1, []string{"a/b", "a/b/", "a/bc"}},
+		{"", "/", 1, []string{"a", "a/", "b::c::", "b::c::d"}},
+		{"b", "::", 2, []string{"b::c::"}},
+		{"b::", "::", 1, []string{"b::c::"}},
+		{"a/", "", 1, []string{"a/", "a/b", "a/b/", "a/b/c", "a/b/c/d", "a/bc", "a/d/", "a/d/e"}},
+		{"a/", "/", -1, nil},
+		{"z", "/", 3, nil},
+	}
+	for _, test := range tests {
+		var got []string
+		tree.WalkSegments([]byte(test.prefix), []byte(test.delimiter), test.depth, func(key []byte, value string) bool {
+			if string(key) != value {
+				t.Errorf("WalkSegments(%q, %q, %d) passed key %q for value %q", test.prefix, test.delimiter, test.depth, key, value)
+			}
+			got = append(got, value)
+			return true
+		})
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("WalkSegments(%q, %q, %d)\n got: %q\nwant: %q", test.prefix, test.delimiter, test.depth, got, test.want)
+		}
+	}
+}
+
+func TestWalkKeys(t *testing.T) {
+	tree := New[int]()
+	for i, key := range words {
+		tree.Insert([]byte(key), i)
+	}
+
+	var got []string
+	tree.WalkKeys([]byte("mac"), func(key []byte) bool {
+		got = append(got, string(key))
+		return true
+	})
+	if want := hasPrefix("mac", words); !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkKeys(mac)\n got: %v\nwant: %v", got, want)
+	}
+
+	got = got[:0]
+	tree.WalkKeys(nil, func(key []byte) bool {
+		got = append(got, string(key))
+		return len(got) < 2
+	})
+	if want := words[:2]; !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkKeys stopped early\n got: %v\nwant: %v", got, want)
+	}
+
+	called := false
+	tree.WalkKeys([]byte("macz"), func(key []byte) bool {
+		called = true
+		return true
+	})
+	if called {
+		t.Errorf("WalkKeys with a non-existent prefix called f")
+	}
+}
+
+func TestConcurrentReads(t *testing.T) {
+	tree := build(words)
+	snap := tree.Snapshot()
+	want := tree.AppendKeys(nil, nil)
+
+	// Run with -race: traversals of a tree that does not share keys must
+	// not write to it.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				for _, r := range []*RadixTree[string]{tree, snap} {
+					if got := r.AppendKeys(nil, nil); !reflect.DeepEqual(got, want) {
+						t.Errorf("AppendKeys\n got: %q\nwant: %q", got, want)
+						return
+					}
+					r.WalkKeys([]byte("to"), func(key []byte) bool {
+						if !strings.HasPrefix(string(key), "to") {
+							t.Errorf("WalkKeys(%q) passed key %q", "to", key)
+						}
+						return true
+					})
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 var words = []string{
 	"aardvark",
 	"aardwolf",
@@ -360,3 +1007,45 @@ var words = []string{
 	"wilting",
 	"wit",
 }
+
+func TestNilTree(t *testing.T) {
+	var tree *RadixTree[string]
+	if v, ok := tree.Get([]byte("a")); ok || v != "" {
+		t.Errorf("Get = %q, %t, want no value", v, ok)
+	}
+	if tree.Contains(nil) || tree.HasPrefix(nil) || !tree.IsEmpty() || tree.Len() != 0 {
+		t.Error("nil tree is not empty")
+	}
+	if _, ok := tree.LongestPrefix([]byte("abc")); ok {
+		t.Error("LongestPrefix found a value")
+	}
+	for _, f := range []func() (string, bool){tree.Min, tree.Max} {
+		if _, ok := f(); ok {
+			t.Error("Min or Max found a value")
+		}
+	}
+	if _, ok := tree.MinPrefix([]byte("a")); ok {
+		t.Error("MinPrefix found a value")
+	}
+	if len(tree.Find(nil)) != 0 || len(tree.Values()) != 0 || len(tree.AppendKeys(nil, nil)) != 0 {
+		t.Error("Find, Values or AppendKeys returned values")
+	}
+	if len(tree.FindN(nil, 3)) != 0 {
+		t.Error("FindN returned values")
+	}
+	tree.Walk(nil, func(string) bool {
+		t.Error("Walk visited a value")
+		return true
+	})
+	tree.WalkKeys(nil, func([]byte) bool {
+		t.Error("WalkKeys visited a key")
+		return true
+	})
+	tree.WalkDepth(nil, 3, func([]byte, string) bool {
+		t.Error("WalkDepth visited a key")
+		return true
+	})
+	for range tree.All() {
+		t.Error("All yielded a key")
+	}
+}
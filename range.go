@@ -0,0 +1,117 @@
+package radixtree
+
+// DeleteRange removes every key that is greater than or equal to start and less
+// than end and returns the number of keys removed. The tree is split along the
+// paths to start and end so subtrees that lie entirely within the range are
+// unlinked as a whole rather than visited entry by entry. A nil end removes
+// every key from start onwards. The bloom filter, if any, keeps the removed
+// keys.
+func (t *RadixTree[T]) DeleteRange(start, end []byte) int {
+	if end != nil && t.opts.order.compare(start, end) >= 0 {
+		return 0
+	}
+
+	// splitNode consumes the node it splits when the tree has an allocator,
+	// so an empty range is detected beforehand rather than by splitting and
+	// finding nothing to remove.
+	count := t.Len() - t.Rank(start)
+	if end != nil {
+		count = t.Rank(end) - t.Rank(start)
+	}
+	if count == 0 {
+		return 0
+	}
+
+	t.unshareAll()
+	left, rest := t.splitNode(t.root, start)
+	var right *node[T]
+	if end != nil {
+		rest, right = t.splitNode(rest, end)
+	}
+
+	if left == nil {
+		left = &node[T]{}
+	}
+	if right != nil {
+		t.union(left, right)
+	}
+	t.root = left
+	t.freeAll(rest)
+	t.size -= rest.count
+	t.gen++
+	t.detached++
+	t.logRemoval(backupRange, start, end)
+	return rest.count
+}
+
+// CopyRange inserts every entry of the tree whose key is greater than or equal
+// to start and less than end into dst and returns the number of entries copied.
+// A nil end copies every key from start onwards. The nodes of the range are
+// copied as a whole and merged into dst rather than inserted one at a time, and
+// the copies share their key bytes with the tree unless either tree has an
+// allocator. If copyValue is not nil it is
+// used to copy each value, otherwise values are copied by assignment. Entries
+// replace the values of the same keys in dst and the tree is not changed. Like
+// Insert, CopyRange panics if a copied key would exceed a limit of dst,
+// leaving dst unchanged.
+func (t *RadixTree[T]) CopyRange(dst *RadixTree[T], start, end []byte, copyValue func(T) T) int {
+	if end != nil && t.opts.order.compare(start, end) >= 0 {
+		return 0
+	}
+	// Splitting a copy of the tree without an allocator leaves the prefixes
+	// owned by the nodes of the tree.
+	v := *t
+	v.opts.alloc = nil
+	_, n := v.splitNode(t.root, start)
+	if n != nil && end != nil {
+		n, _ = v.splitNode(n, end)
+	}
+	if n == nil {
+		return 0
+	}
+	if err := dst.checkSubtree(nil, n, false); err != nil {
+		panic(err)
+	}
+
+	dst.gen++
+	dst.unshareAll()
+	n = dst.clone(n, t, copyValue)
+	if dst.filter != nil {
+		walkNodes(n, nil, -1, func(key []byte, _ *node[T]) bool {
+			dst.filter.add(key)
+			return true
+		})
+	}
+	dst.notifySubtree(nil, n)
+	dst.size += n.count - dst.union(dst.root, n)
+	return n.count
+}
+
+// clone returns a copy of the subtree rooted at n, which belongs to the tree
+// from, with leaves, byte order and bookkeeping for t. The prefixes are shared
+// with n unless either tree has an allocator.
+func (t *RadixTree[T]) clone(n *node[T], from *RadixTree[T], copyValue func(T) T) *node[T] {
+	c := t.touch(&node[T]{prefix: t.own(n.prefix)})
+	if from.opts.alloc != nil && t.opts.alloc == nil {
+		c.prefix = append([]byte(nil), n.prefix...)
+	}
+	if n.hasValue() {
+		v := n.leaf.value
+		if copyValue != nil {
+			v = copyValue(v)
+		}
+		c.leaf = t.newLeaf(v)
+	}
+	if n.children.len() > 0 {
+		nodes := make([]*node[T], n.children.len())
+		for i, child := range n.children.nodes {
+			nodes[i] = t.clone(child, from, copyValue)
+		}
+		c.children = childrenOf(nodes...)
+		if from.opts.order != t.opts.order {
+			t.sortChildren(c)
+		}
+	}
+	t.update(c)
+	return c
+}
@@ -0,0 +1,105 @@
+package radixtree
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeleteRange(t *testing.T) {
+	tests := []struct {
+		start, end string
+		nilEnd     bool
+	}{
+		{start: "a", end: "b"},
+		{start: "", end: "mac"},
+		{start: "macro", end: "macroanalyst"},
+		{start: "macroa", end: "macrob"},
+		{start: "to", end: "toady"},
+		{start: "toad", end: "toad"},
+		{start: "z", end: "a"},
+		{start: "wil", nilEnd: true},
+		{start: "", nilEnd: true},
+		{start: "x", end: "y"},
+	}
+	for _, test := range tests {
+		tree := New[string](WithAggregate("", concat, identity))
+		for _, key := range words {
+			tree.Insert([]byte(key), key)
+		}
+		var end []byte
+		if !test.nilEnd {
+			end = []byte(test.end)
+		}
+
+		want := []string{}
+		removed := 0
+		for _, key := range words {
+			if key >= test.start && (test.nilEnd || key < test.end) {
+				removed++
+			} else {
+				want = append(want, key)
+			}
+		}
+
+		if got := tree.DeleteRange([]byte(test.start), end); got != removed {
+			t.Errorf("DeleteRange(%q, %q) = %d, want %d", test.start, end, got, removed)
+		}
+		verify(t, tree)
+		checkAggregates(t, "DeleteRange", tree, words)
+		if got := append([]string{}, tree.Values()...); !reflect.DeepEqual(got, want) {
+			t.Errorf("DeleteRange(%q, %q)\n got: %v\nwant: %v", test.start, end, got, want)
+		}
+
+		tree.Insert([]byte("macro"), "macro")
+		verify(t, tree)
+	}
+}
+
+func TestCopyRange(t *testing.T) {
+	src := build(words)
+	dst := New[string](WithAggregate("", concat, identity), WithBloomFilter(100, 0.01))
+	dst.Insert([]byte("macro"), "old")
+	dst.Insert([]byte("zebra"), "zebra")
+
+	if got := src.CopyRange(dst, []byte("mac"), []byte("toad"), nil); got != 9 {
+		t.Errorf("CopyRange = %d, want 9", got)
+	}
+	verify(t, src)
+	verify(t, dst)
+	checkAggregates(t, "CopyRange", dst, words)
+	if got, want := src.Values(), words; !reflect.DeepEqual(got, want) {
+		t.Errorf("CopyRange changed the source\n got: %v\nwant: %v", got, want)
+	}
+	want := []string{"macro", "macroanalysis", "macroanalyst", "macrochelys", "mactroid", "obsequious", "sequence", "to", "toa", "zebra"}
+	if got := dst.Values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Values after CopyRange\n got: %v\nwant: %v", got, want)
+	}
+	if got := dst.Len(); got != len(want) {
+		t.Errorf("Len after CopyRange = %d, want %d", got, len(want))
+	}
+	if !dst.Contains([]byte("obsequious")) {
+		t.Errorf("Contains(obsequious) = false after CopyRange")
+	}
+
+	// Changing the copies does not affect the source.
+	dst.Insert([]byte("macroa"), "macroa")
+	dst.Remove([]byte("to"))
+	verify(t, src)
+	if got, want := src.Values(), words; !reflect.DeepEqual(got, want) {
+		t.Errorf("changing the copies changed the source\n got: %v\nwant: %v", got, want)
+	}
+
+	other := New[string]()
+	upper := func(s string) string { return strings.ToUpper(s) }
+	if got := src.CopyRange(other, []byte("wil"), nil, upper); got != 7 {
+		t.Errorf("CopyRange to the end = %d, want 7", got)
+	}
+	want = []string{"WILL", "WILTING", "WIN", "WINK", "WINKLE", "WINKLEMAN", "WIT"}
+	if got := other.Values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Values after CopyRange with copyValue\n got: %v\nwant: %v", got, want)
+	}
+	if got := src.CopyRange(other, []byte("x"), []byte("y"), nil); got != 0 {
+		t.Errorf("CopyRange of an empty range = %d, want 0", got)
+	}
+}
@@ -0,0 +1,74 @@
+package radixtree
+
+// CountPrefix returns the number of keys in the tree that start with the given
+// prefix. Every node keeps the number of values below it so the cost is
+// proportional to the length of the prefix rather than the number of keys.
+func (t *RadixTree[T]) CountPrefix(prefix []byte) int {
+	n := t.find(prefix)
+	if n == nil {
+		return 0
+	}
+	return n.count
+}
+
+// Rank returns the number of keys in the tree that are less than the given
+// key, which need not be in the tree. If the key is in the tree its rank is its
+// zero-based position in ascending key order.
+func (t *RadixTree[T]) Rank(key []byte) int {
+	rank := 0
+	n := t.root
+	for len(key) > 0 {
+		if n.hasValue() {
+			// The key of n is a proper prefix of key.
+			rank++
+		}
+		i := n.children.search(key[0], t.opts.order)
+		for _, child := range n.children.nodes[:i] {
+			rank += child.count
+		}
+		if i == n.children.len() || n.children.nodes[i].prefix[0] != key[0] {
+			return rank
+		}
+
+		child := n.children.nodes[i]
+		l := longestCommonPrefix(key, child.prefix)
+		if l < len(child.prefix) {
+			// Every key below the child is either greater than key or,
+			// if it diverges with a smaller byte, less than it.
+			if l < len(key) && t.opts.order.less(child.prefix[l], key[l]) {
+				rank += child.count
+			}
+			return rank
+		}
+		n = child
+		key = key[l:]
+	}
+	return rank
+}
+
+// Select returns the key and value at the given zero-based position in
+// ascending key order, so that Rank of the returned key is i. The returned ok
+// is false if i is negative or not less than Len. Like Rank the cost is
+// proportional to the length of the key rather than the number of keys.
+func (t *RadixTree[T]) Select(i int) (key []byte, value T, ok bool) {
+	if i < 0 || i >= t.root.count {
+		return nil, value, false
+	}
+	n := t.root
+	for {
+		if n.hasValue() {
+			if i == 0 {
+				return key, n.leaf.value, true
+			}
+			i--
+		}
+		for _, child := range n.children.nodes {
+			if i < child.count {
+				n = child
+				break
+			}
+			i -= child.count
+		}
+		key = append(key, n.prefix...)
+	}
+}
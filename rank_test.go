@@ -0,0 +1,91 @@
+package radixtree
+
+import (
+	"sort"
+	"testing"
+)
+
+var rankKeys = []string{"", "a", "ab", "abc", "abd", "b", "ba", "bab", "c", "toad", "toast", "to"}
+
+func TestCountPrefix(t *testing.T) {
+	tree := New[int]()
+	for i, key := range rankKeys {
+		tree.Insert([]byte(key), i)
+	}
+	tree.Remove([]byte("ab"))
+	keys := append([]string{}, rankKeys...)
+	keys = append(keys[:2], keys[3:]...)
+
+	for _, prefix := range []string{"", "a", "ab", "abc", "abx", "b", "t", "to", "toa", "toad", "x"} {
+		if got, want := tree.CountPrefix([]byte(prefix)), len(hasPrefix(prefix, keys)); got != want {
+			t.Errorf("CountPrefix(%q) = %d, want %d", prefix, got, want)
+		}
+	}
+	verify(t, tree)
+}
+
+func TestRank(t *testing.T) {
+	tree := New[int]()
+	for i, key := range rankKeys {
+		tree.Insert([]byte(key), i)
+	}
+
+	for _, key := range append(rankKeys, "aa", "abb", "abz", "bb", "d", "t", "toa", "toadstool", "z") {
+		if got, want := tree.Rank([]byte(key)), len(less(key, rankKeys)); got != want {
+			t.Errorf("Rank(%q) = %d, want %d", key, got, want)
+		}
+	}
+
+	if got := New[int]().Rank([]byte("a")); got != 0 {
+		t.Errorf("Rank on an empty tree = %d, want 0", got)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tree := New[int]()
+	for i, key := range rankKeys {
+		tree.Insert([]byte(key), i)
+	}
+	sorted := append([]string{}, rankKeys...)
+	sort.Strings(sorted)
+
+	for i, want := range sorted {
+		key, value, ok := tree.Select(i)
+		if !ok || string(key) != want || rankKeys[value] != want {
+			t.Errorf("Select(%d) = %q, %d, %v, want %q", i, key, value, ok, want)
+		}
+		if r := tree.Rank(key); r != i {
+			t.Errorf("Rank(Select(%d)) = %d", i, r)
+		}
+	}
+	for _, i := range []int{-1, len(sorted)} {
+		if _, _, ok := tree.Select(i); ok {
+			t.Errorf("Select(%d) found an entry", i)
+		}
+	}
+
+	// Counts are kept up to date by structural changes.
+	left, right := tree.Split([]byte("b"))
+	verify(t, left)
+	verify(t, right)
+	if key, _, _ := right.Select(0); string(key) != "b" {
+		t.Errorf("Select(0) after Split = %q, want \"b\"", key)
+	}
+	d := right.Detach([]byte("to"), false)
+	verify(t, right)
+	verify(t, d)
+	if got := d.CountPrefix([]byte("toa")); got != 2 {
+		t.Errorf("CountPrefix after Detach = %d, want 2", got)
+	}
+	joined, err := Join(left, right)
+	if err != nil {
+		t.Fatalf("Join returned error %v", err)
+	}
+	joined.Graft([]byte("to"), d.Detach([]byte("to"), true))
+	verify(t, joined)
+	for i, want := range sorted {
+		if key, _, _ := joined.Select(i); string(key) != want {
+			t.Errorf("Select(%d) after Graft = %q, want %q", i, key, want)
+		}
+	}
+}
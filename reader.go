@@ -0,0 +1,47 @@
+package radixtree
+
+// Reader is the read-only interface shared by RadixTree, Frozen and Scope, so
+// that code which only reads a tree can accept a live tree, a frozen snapshot
+// of one or a part of one. The methods behave as documented on RadixTree except
+// that a Frozen tree never panics with ErrModified.
+type Reader[T any] interface {
+	// Contains returns true if key is in the tree, false otherwise.
+	Contains(key []byte) bool
+	// Find returns the values whose keys start with the given prefix in
+	// ascending key order.
+	Find(prefix []byte) []T
+	// Get returns the value associated with the given key and true, or the
+	// zero value for type T and false if the key is not in the tree.
+	Get(key []byte) (T, bool)
+	// HasPrefix returns true if any key in the tree starts with the given
+	// prefix.
+	HasPrefix(prefix []byte) bool
+	// Len returns the number of values in the tree.
+	Len() int
+	// LongestPrefix returns the value associated with the longest key that
+	// is a prefix of the given key and true, or the zero value for type T
+	// and false if there is no such key.
+	LongestPrefix(key []byte) (T, bool)
+	// Max returns the value associated with the largest key in the tree and
+	// true, or false if the tree is empty.
+	Max() (T, bool)
+	// Min returns the value associated with the smallest key in the tree and
+	// true, or false if the tree is empty.
+	Min() (T, bool)
+	// Walk executes f for each value whose key starts with the given prefix
+	// in ascending key order until f returns false.
+	Walk(prefix []byte, f func(value T) bool)
+	// WalkDepth executes f for each key that starts with the given prefix
+	// and is at most depth bytes longer than it, and its value, in
+	// ascending key order until f returns false.
+	WalkDepth(prefix []byte, depth int, f func(key []byte, value T) bool)
+	// WalkKeys executes f for each key that starts with the given prefix in
+	// ascending order until f returns false.
+	WalkKeys(prefix []byte, f func(key []byte) bool)
+}
+
+var (
+	_ Reader[int] = (*RadixTree[int])(nil)
+	_ Reader[int] = (*Frozen[int])(nil)
+	_ Reader[int] = (*Scope[int])(nil)
+)
@@ -0,0 +1,60 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	tree := build(words)
+	m := NewSyncMap[string]()
+	m.ReplaceAll(build(words))
+	for name, r := range map[string]Reader[string]{
+		"RadixTree": tree,
+		"Frozen":    tree.Freeze(),
+		"SyncMap":   m,
+	} {
+		if got, want := r.Len(), len(words); got != want {
+			t.Errorf("%s: Len = %d, want %d", name, got, want)
+		}
+		if got, ok := r.Get([]byte("toad")); !ok || got != "toad" {
+			t.Errorf("%s: Get(%q) = (%q, %t), want (%q, true)", name, "toad", got, ok, "toad")
+		}
+		if got, ok := r.LongestPrefix([]byte("toadstool")); !ok || got != "toad" {
+			t.Errorf("%s: LongestPrefix(%q) = (%q, %t), want (%q, true)", name, "toadstool", got, ok, "toad")
+		}
+		if got, ok := r.Min(); !ok || got != words[0] {
+			t.Errorf("%s: Min = (%q, %t), want (%q, true)", name, got, ok, words[0])
+		}
+		if got, ok := r.Max(); !ok || got != words[len(words)-1] {
+			t.Errorf("%s: Max = (%q, %t), want (%q, true)", name, got, ok, words[len(words)-1])
+		}
+		if !r.Contains([]byte("wink")) || r.Contains([]byte("wi")) || !r.HasPrefix([]byte("wi")) {
+			t.Errorf("%s: Contains or HasPrefix disagree with the keys", name)
+		}
+
+		want := hasPrefix("to", words)
+		if got := r.Find([]byte("to")); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: Find\n got: %v\nwant: %v", name, got, want)
+		}
+		var values, keys, depth []string
+		r.Walk([]byte("to"), func(value string) bool {
+			values = append(values, value)
+			return true
+		})
+		r.WalkKeys([]byte("to"), func(key []byte) bool {
+			keys = append(keys, string(key))
+			return true
+		})
+		r.WalkDepth([]byte("to"), 2, func(key []byte, _ string) bool {
+			depth = append(depth, string(key))
+			return true
+		})
+		if !reflect.DeepEqual(values, want) || !reflect.DeepEqual(keys, want) {
+			t.Errorf("%s: Walk and WalkKeys\n got: %v and %v\nwant: %v", name, values, keys, want)
+		}
+		if got, want := depth, []string{"to", "toa", "toad"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: WalkDepth\n got: %v\nwant: %v", name, got, want)
+		}
+	}
+}
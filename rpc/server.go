@@ -0,0 +1,210 @@
+// Package rpc provides a gRPC service that exposes a radix tree to other
+// processes, such as sidecars and debugging tools. It is a separate module so
+// that the radixtree package itself does not depend on gRPC.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative radixtree.proto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// watchBuffer is the number of events buffered for each watcher. A watcher
+// that falls further behind than this is disconnected rather than allowed to
+// hold up the mutations of other clients.
+const watchBuffer = 256
+
+// Server implements the RadixTree service for a radix tree with values of type
+// T, which are converted to and from the bytes sent over the wire by the
+// functions given to NewServer. The server serializes its own access to the
+// tree so other code must not use the tree while it is being served. Watch
+// only reports the changes made through the server.
+type Server[T any] struct {
+	UnimplementedRadixTreeServer
+
+	encode func(T) ([]byte, error)
+	decode func([]byte) (T, error)
+
+	mu       sync.Mutex
+	tree     *radixtree.RadixTree[T]
+	watchers map[*watcher]struct{}
+}
+
+// watcher is a client of Watch.
+type watcher struct {
+	prefix []byte
+	events chan *Event
+	// lagged is closed when the watcher is disconnected for falling behind.
+	lagged chan struct{}
+}
+
+// NewServer returns a server for the given tree. The keys passed to Insert are
+// retained by the tree.
+func NewServer[T any](t *radixtree.RadixTree[T], encode func(T) ([]byte, error), decode func([]byte) (T, error)) *Server[T] {
+	return &Server[T]{
+		encode:   encode,
+		decode:   decode,
+		tree:     t,
+		watchers: make(map[*watcher]struct{}),
+	}
+}
+
+// Get implements RadixTreeServer.
+func (s *Server[T]) Get(_ context.Context, req *GetRequest) (*GetResponse, error) {
+	s.mu.Lock()
+	v, ok := s.tree.Get(req.Key)
+	s.mu.Unlock()
+	if !ok {
+		return &GetResponse{}, nil
+	}
+	b, err := s.encodeValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return &GetResponse{Value: b, Found: true}, nil
+}
+
+// Insert implements RadixTreeServer. A key over a limit of the tree fails with
+// codes.InvalidArgument if it is too long and codes.ResourceExhausted if the
+// tree has no room for it.
+func (s *Server[T]) Insert(_ context.Context, req *InsertRequest) (*InsertResponse, error) {
+	v, err := s.decode(req.Value)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "decoding value: %v", err)
+	}
+	s.mu.Lock()
+	old, replaced, err := s.tree.InsertErr(req.Key, v)
+	if err != nil {
+		s.mu.Unlock()
+		if errors.Is(err, radixtree.ErrKeyTooLong) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	s.notify(Event_TYPE_INSERT, req.Key, req.Value)
+	s.mu.Unlock()
+	if !replaced {
+		return &InsertResponse{}, nil
+	}
+	b, err := s.encodeValue(old)
+	if err != nil {
+		return nil, err
+	}
+	return &InsertResponse{Previous: b, Replaced: true}, nil
+}
+
+// Remove implements RadixTreeServer.
+func (s *Server[T]) Remove(_ context.Context, req *RemoveRequest) (*RemoveResponse, error) {
+	s.mu.Lock()
+	v, ok := s.tree.Remove(req.Key)
+	if !ok {
+		s.mu.Unlock()
+		return &RemoveResponse{}, nil
+	}
+	b, err := s.encodeValue(v)
+	s.notify(Event_TYPE_REMOVE, req.Key, b)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return &RemoveResponse{Value: b, Found: true}, nil
+}
+
+// Find implements RadixTreeServer. The matching entries are collected before
+// they are sent so the tree is not locked while waiting on the client.
+func (s *Server[T]) Find(req *FindRequest, stream RadixTree_FindServer) error {
+	var entries []radixtree.Entry[T]
+	s.mu.Lock()
+	for it := s.tree.FindIter(req.Prefix); it.Next(); {
+		if req.Limit > 0 && int64(len(entries)) == req.Limit {
+			break
+		}
+		// The key is copied in case the tree was created with
+		// WithSharedKeys.
+		key := append([]byte(nil), it.Key()...)
+		entries = append(entries, radixtree.Entry[T]{Key: key, Value: it.Value()})
+	}
+	s.mu.Unlock()
+
+	for _, e := range entries {
+		b, err := s.encodeValue(e.Value)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&Entry{Key: e.Key, Value: b}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Watch implements RadixTreeServer. It sends the response headers once the
+// watcher is registered, so a client can wait for them before making changes
+// it expects to see, and returns when the client goes away or, with
+// codes.ResourceExhausted, if the client falls behind.
+func (s *Server[T]) Watch(req *WatchRequest, stream RadixTree_WatchServer) error {
+	w := &watcher{
+		prefix: append([]byte(nil), req.Prefix...),
+		events: make(chan *Event, watchBuffer),
+		lagged: make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.watchers[w] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers, w)
+		s.mu.Unlock()
+	}()
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case e := <-w.events:
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+		case <-w.lagged:
+			return status.Error(codes.ResourceExhausted, "watcher fell behind")
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// notify sends an event to every watcher whose prefix matches key. It must be
+// called with s.mu held.
+func (s *Server[T]) notify(typ Event_Type, key, value []byte) {
+	var e *Event
+	for w := range s.watchers {
+		if !bytes.HasPrefix(key, w.prefix) {
+			continue
+		}
+		if e == nil {
+			e = &Event{Type: typ, Key: append([]byte(nil), key...), Value: value}
+		}
+		select {
+		case w.events <- e:
+		default:
+			delete(s.watchers, w)
+			close(w.lagged)
+		}
+	}
+}
+
+func (s *Server[T]) encodeValue(v T) ([]byte, error) {
+	b, err := s.encode(v)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encoding value: %v", err)
+	}
+	return b, nil
+}
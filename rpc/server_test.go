@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func identity(b []byte) ([]byte, error) {
+	return b, nil
+}
+
+// dial serves tree over an in-memory connection and returns a client for it.
+func dial(t *testing.T, tree *radixtree.RadixTree[[]byte]) RadixTreeClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	RegisterRadixTreeServer(s, NewServer(tree, identity, identity))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewRadixTreeClient(conn)
+}
+
+func TestGetInsertRemove(t *testing.T) {
+	tree := radixtree.New[[]byte]()
+	c := dial(t, tree)
+	ctx := context.Background()
+
+	ins, err := c.Insert(ctx, &InsertRequest{Key: []byte("apple"), Value: []byte("1")})
+	if err != nil || ins.Replaced {
+		t.Fatalf("Insert = %v, %v, want no replacement", ins, err)
+	}
+	ins, err = c.Insert(ctx, &InsertRequest{Key: []byte("apple"), Value: []byte("2")})
+	if err != nil || !ins.Replaced || string(ins.Previous) != "1" {
+		t.Fatalf("Insert = %v, %v, want replacement of 1", ins, err)
+	}
+	if v, ok := tree.Get([]byte("apple")); !ok || string(v) != "2" {
+		t.Errorf("tree has %q, %v, want 2", v, ok)
+	}
+
+	get, err := c.Get(ctx, &GetRequest{Key: []byte("apple")})
+	if err != nil || !get.Found || string(get.Value) != "2" {
+		t.Errorf("Get = %v, %v, want 2", get, err)
+	}
+	get, err = c.Get(ctx, &GetRequest{Key: []byte("banana")})
+	if err != nil || get.Found {
+		t.Errorf("Get of missing key = %v, %v", get, err)
+	}
+
+	rem, err := c.Remove(ctx, &RemoveRequest{Key: []byte("apple")})
+	if err != nil || !rem.Found || string(rem.Value) != "2" {
+		t.Errorf("Remove = %v, %v, want 2", rem, err)
+	}
+	rem, err = c.Remove(ctx, &RemoveRequest{Key: []byte("apple")})
+	if err != nil || rem.Found {
+		t.Errorf("Remove of missing key = %v, %v", rem, err)
+	}
+	if tree.Len() != 0 {
+		t.Errorf("tree has %d entries, want 0", tree.Len())
+	}
+}
+
+func TestInsertLimits(t *testing.T) {
+	tree := radixtree.New[[]byte](radixtree.WithMaxEntries(1), radixtree.WithMaxKeyLen(4))
+	c := dial(t, tree)
+	ctx := context.Background()
+
+	if _, err := c.Insert(ctx, &InsertRequest{Key: []byte("a"), Value: []byte("1")}); err != nil {
+		t.Fatalf("Insert = %v", err)
+	}
+	for _, tc := range []struct {
+		key  string
+		code codes.Code
+	}{
+		{"b", codes.ResourceExhausted},
+		{"toolong", codes.InvalidArgument},
+	} {
+		_, err := c.Insert(ctx, &InsertRequest{Key: []byte(tc.key), Value: []byte("2")})
+		if status.Code(err) != tc.code {
+			t.Errorf("Insert(%q) returned %v, want code %v", tc.key, err, tc.code)
+		}
+	}
+	// The server is still usable after a failed insert.
+	if ins, err := c.Insert(ctx, &InsertRequest{Key: []byte("a"), Value: []byte("3")}); err != nil || !ins.Replaced {
+		t.Errorf("Insert after failures = %v, %v, want replacement", ins, err)
+	}
+	if tree.Len() != 1 {
+		t.Errorf("tree has %d entries, want 1", tree.Len())
+	}
+}
+
+func TestFind(t *testing.T) {
+	tree := radixtree.New[[]byte]()
+	for _, k := range []string{"apple", "applesauce", "apricot", "banana"} {
+		tree.Insert([]byte(k), []byte(k))
+	}
+	c := dial(t, tree)
+
+	tests := []struct {
+		prefix string
+		limit  int64
+		want   []string
+	}{
+		{"ap", 0, []string{"apple", "applesauce", "apricot"}},
+		{"ap", 2, []string{"apple", "applesauce"}},
+		{"", 0, []string{"apple", "applesauce", "apricot", "banana"}},
+		{"cherry", 0, nil},
+	}
+	for _, tt := range tests {
+		stream, err := c.Find(context.Background(), &FindRequest{Prefix: []byte(tt.prefix), Limit: tt.limit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for {
+			e, err := stream.Recv()
+			if err != nil {
+				break
+			}
+			if string(e.Key) != string(e.Value) {
+				t.Errorf("entry %q has value %q", e.Key, e.Value)
+			}
+			got = append(got, string(e.Key))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Find(%q, %d) = %q, want %q", tt.prefix, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	c := dial(t, radixtree.New[[]byte]())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.Watch(ctx, &WatchRequest{Prefix: []byte("ap")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Header(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Insert(ctx, &InsertRequest{Key: []byte("banana"), Value: []byte("1")})
+	c.Insert(ctx, &InsertRequest{Key: []byte("apple"), Value: []byte("2")})
+	c.Remove(ctx, &RemoveRequest{Key: []byte("banana")})
+	c.Remove(ctx, &RemoveRequest{Key: []byte("apple")})
+
+	want := []*Event{
+		{Type: Event_TYPE_INSERT, Key: []byte("apple"), Value: []byte("2")},
+		{Type: Event_TYPE_REMOVE, Key: []byte("apple"), Value: []byte("2")},
+	}
+	for _, w := range want {
+		e, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Type != w.Type || string(e.Key) != string(w.Key) || string(e.Value) != string(w.Value) {
+			t.Errorf("event = %v, want %v", e, w)
+		}
+	}
+}
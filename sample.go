@@ -0,0 +1,92 @@
+package radixtree
+
+import "math/rand"
+
+// SampleWeighted picks an entry of the tree at random with probability
+// proportional to its weight and returns its key and value. Entries with a
+// weight that is not positive are never picked. The tree is traversed once,
+// calling weight for every entry, so the cost is proportional to the number of
+// entries; SampleAggregate picks in time proportional to the length of the key
+// for a tree that maintains its weights. The returned ok is false if no entry
+// has a positive weight. The key passed to weight is only valid until weight
+// returns.
+func (t *RadixTree[T]) SampleWeighted(rng *rand.Rand, weight func(key []byte, value T) float64) (key []byte, value T, ok bool) {
+	// A single pass of weighted reservoir sampling: each entry replaces the
+	// pick with probability its weight over the total weight seen so far.
+	var (
+		total  float64
+		picked *node[T]
+	)
+	buf := t.borrow()
+	buf, _ = walkNodes(t.root, buf, -1, func(k []byte, n *node[T]) bool {
+		w := weight(k, n.leaf.value)
+		if w <= 0 {
+			return true
+		}
+		total += w
+		if rng.Float64()*total < w {
+			picked = n
+			key = append(key[:0], k...)
+		}
+		return true
+	})
+	t.release(buf)
+	if picked == nil {
+		return nil, value, false
+	}
+	return key, picked.leaf.value, true
+}
+
+// SampleAggregate picks an entry of a tree created with WithAggregate at random
+// with probability proportional to its weight and returns its key and value.
+// The aggregate of the tree must be of type float64 and the sum of the weights,
+// which must not be negative, as with
+//
+//	WithAggregate(0.0, func(a, b float64) float64 { return a + b }, weight)
+//
+// so that a subtree is chosen by its aggregate at every node on the way down
+// and the cost is proportional to the length of the key. The returned ok is
+// false if the tree does not maintain aggregates or the total weight is not
+// positive. SampleAggregate panics if the aggregate type is not float64.
+func SampleAggregate[T any](t *RadixTree[T], rng *rand.Rand) (key []byte, value T, ok bool) {
+	if t.agg == nil || t.size == 0 {
+		return nil, value, false
+	}
+	n := t.root
+	total := n.agg.(float64)
+	if !(total > 0) {
+		return nil, value, false
+	}
+	r := rng.Float64() * total
+	for {
+		if n.hasValue() {
+			w := t.agg.value(n.leaf.value).(float64)
+			if r < w {
+				return key, n.leaf.value, true
+			}
+			r -= w
+		}
+		// Rounding may leave r beyond the last child with a positive
+		// weight, which is then taken.
+		next := -1
+		for i, child := range n.children.nodes {
+			w := child.agg.(float64)
+			if w <= 0 {
+				continue
+			}
+			next = i
+			if r < w {
+				break
+			}
+			r -= w
+		}
+		if next < 0 {
+			if n.hasValue() {
+				return key, n.leaf.value, true
+			}
+			return nil, value, false
+		}
+		n = n.children.nodes[next]
+		key = append(key, n.prefix...)
+	}
+}
@@ -0,0 +1,81 @@
+package radixtree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checkSamples draws samples with pick and checks that each key is picked about
+// as often as its weight implies.
+func checkSamples(t *testing.T, name string, weights map[string]float64, pick func() (string, bool)) {
+	t.Helper()
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	const n = 20000
+	counts := map[string]int{}
+	for i := 0; i < n; i++ {
+		key, ok := pick()
+		if !ok {
+			t.Fatalf("%s: no entry picked", name)
+		}
+		counts[key]++
+	}
+	for key := range counts {
+		if weights[key] <= 0 {
+			t.Errorf("%s: picked %q with weight %g", name, key, weights[key])
+		}
+	}
+	for key, w := range weights {
+		want := n * w / total
+		if got := float64(counts[key]); math.Abs(got-want) > 5*math.Sqrt(want)+1 {
+			t.Errorf("%s: picked %q %.0f times, want about %.0f", name, key, got, want)
+		}
+	}
+}
+
+var sampleWeights = map[string]float64{
+	"": 1, "a": 0, "ab": 4, "abc": 2, "b": 8, "ba": 0.5, "c": 0,
+}
+
+func TestSampleWeighted(t *testing.T) {
+	tree := New[float64]()
+	for k, w := range sampleWeights {
+		tree.Insert([]byte(k), w)
+	}
+	rng := rand.New(rand.NewSource(1))
+	checkSamples(t, "SampleWeighted", sampleWeights, func() (string, bool) {
+		key, value, ok := tree.SampleWeighted(rng, func(key []byte, value float64) float64 {
+			return value
+		})
+		if ok && value != sampleWeights[string(key)] {
+			t.Fatalf("SampleWeighted returned %q with value %g", key, value)
+		}
+		return string(key), ok
+	})
+	if _, _, ok := New[float64]().SampleWeighted(rng, func([]byte, float64) float64 { return 1 }); ok {
+		t.Errorf("SampleWeighted picked an entry of an empty tree")
+	}
+}
+
+func TestSampleAggregate(t *testing.T) {
+	tree := New[float64](WithAggregate(0.0, func(a, b float64) float64 { return a + b }, func(v float64) float64 { return v }))
+	for k, w := range sampleWeights {
+		tree.Insert([]byte(k), w)
+	}
+	tree.Insert([]byte("abcd"), 3)
+	tree.Remove([]byte("abcd"))
+	rng := rand.New(rand.NewSource(1))
+	checkSamples(t, "SampleAggregate", sampleWeights, func() (string, bool) {
+		key, value, ok := SampleAggregate(tree, rng)
+		if ok && value != sampleWeights[string(key)] {
+			t.Fatalf("SampleAggregate returned %q with value %g", key, value)
+		}
+		return string(key), ok
+	})
+	if _, _, ok := SampleAggregate(New[float64](), rng); ok {
+		t.Errorf("SampleAggregate picked an entry of a tree without aggregates")
+	}
+}
@@ -0,0 +1,142 @@
+package radixtree
+
+import "bytes"
+
+// Scope is a read-write view of the keys of a tree that start with a prefix.
+// Its methods take and pass keys relative to the prefix, which is prepended to
+// them transparently, and only see the keys that start with it, so that a part
+// of a tree can be handed to code that needs not know where it lies. A Scope
+// holds no entries of its own: changes made through it are made to the tree
+// and changes made to the tree are seen through it.
+type Scope[T any] struct {
+	tree   *RadixTree[T]
+	prefix []byte
+}
+
+// Scope returns a view of the keys of the tree that start with the given
+// prefix. The prefix is copied.
+func (t *RadixTree[T]) Scope(prefix []byte) *Scope[T] {
+	return &Scope[T]{tree: t, prefix: append([]byte(nil), prefix...)}
+}
+
+// key returns a new slice holding the prefix of the scope followed by key.
+func (s *Scope[T]) key(key []byte) []byte {
+	return append(s.prefix[:len(s.prefix):len(s.prefix)], key...)
+}
+
+// Contains returns true if key is in the scope, false otherwise.
+func (s *Scope[T]) Contains(key []byte) bool {
+	return s.tree.Contains(s.key(key))
+}
+
+// Find returns the values whose keys start with the given prefix within the
+// scope in ascending key order.
+func (s *Scope[T]) Find(prefix []byte) []T {
+	return s.tree.Find(s.key(prefix))
+}
+
+// Get returns the value associated with the given key and true, or the zero
+// value for type T and false if the key is not in the scope.
+func (s *Scope[T]) Get(key []byte) (T, bool) {
+	return s.tree.Get(s.key(key))
+}
+
+// HasPrefix returns true if any key in the scope starts with the given prefix.
+func (s *Scope[T]) HasPrefix(prefix []byte) bool {
+	return s.tree.HasPrefix(s.key(prefix))
+}
+
+// Insert inserts the value into the tree under the prefix of the scope
+// followed by key. It returns the value previously associated with the key and
+// true, or the zero value for type T and false if there was none.
+func (s *Scope[T]) Insert(key []byte, value T) (T, bool) {
+	return s.tree.Insert(s.key(key), value)
+}
+
+// Len returns the number of keys in the scope.
+func (s *Scope[T]) Len() int {
+	return s.tree.CountPrefix(s.prefix)
+}
+
+// LongestPrefix returns the value associated with the longest key in the scope
+// that is a prefix of the given key and true, or the zero value for type T and
+// false if there is no such key. Keys of the tree that are shorter than the
+// prefix of the scope are not considered.
+func (s *Scope[T]) LongestPrefix(key []byte) (T, bool) {
+	full := s.key(key)
+	n := s.tree.root
+	var last *leaf[T]
+	if len(s.prefix) == 0 {
+		last = n.leaf
+	}
+	for depth := 0; depth < len(full); {
+		n = n.children.get(full[depth])
+		if n == nil || !bytes.HasPrefix(full[depth:], n.prefix) {
+			break
+		}
+		if depth += len(n.prefix); n.hasValue() && depth >= len(s.prefix) {
+			last = n.leaf
+		}
+	}
+	if last != nil {
+		return last.value, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Max returns the value associated with the largest key in the scope and true,
+// or the zero value for type T and false if the scope is empty.
+func (s *Scope[T]) Max() (T, bool) {
+	return s.tree.MaxPrefix(s.prefix)
+}
+
+// Min returns the value associated with the smallest key in the scope and true,
+// or the zero value for type T and false if the scope is empty.
+func (s *Scope[T]) Min() (T, bool) {
+	return s.tree.MinPrefix(s.prefix)
+}
+
+// Prefix returns the prefix of the scope, which the caller must not modify.
+func (s *Scope[T]) Prefix() []byte {
+	return s.prefix
+}
+
+// Remove removes the key from the scope and returns its value and true, or the
+// zero value for type T and false if the key is not in the scope.
+func (s *Scope[T]) Remove(key []byte) (T, bool) {
+	return s.tree.Remove(s.key(key))
+}
+
+// Scope returns a view of the keys of the scope that start with the given
+// prefix, which is relative to the prefix of the scope.
+func (s *Scope[T]) Scope(prefix []byte) *Scope[T] {
+	return &Scope[T]{tree: s.tree, prefix: s.key(prefix)}
+}
+
+// Tree returns the tree that the scope is a view of.
+func (s *Scope[T]) Tree() *RadixTree[T] {
+	return s.tree
+}
+
+// Walk is like RadixTree.Walk for the keys of the scope that start with the
+// given prefix.
+func (s *Scope[T]) Walk(prefix []byte, f func(value T) bool) {
+	s.tree.Walk(s.key(prefix), f)
+}
+
+// WalkDepth is like RadixTree.WalkDepth for the keys of the scope that start
+// with the given prefix. The keys passed to f are relative to the scope.
+func (s *Scope[T]) WalkDepth(prefix []byte, depth int, f func(key []byte, value T) bool) {
+	s.tree.WalkDepth(s.key(prefix), depth, func(key []byte, value T) bool {
+		return f(key[len(s.prefix):], value)
+	})
+}
+
+// WalkKeys is like RadixTree.WalkKeys for the keys of the scope that start with
+// the given prefix. The keys passed to f are relative to the scope.
+func (s *Scope[T]) WalkKeys(prefix []byte, f func(key []byte) bool) {
+	s.tree.WalkKeys(s.key(prefix), func(key []byte) bool {
+		return f(key[len(s.prefix):])
+	})
+}
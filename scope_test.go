@@ -0,0 +1,88 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScope(t *testing.T) {
+	tree := build(words)
+	tree.Insert([]byte("t"), "t")
+	s := tree.Scope([]byte("to"))
+
+	if got, want := s.Len(), len(hasPrefix("to", words)); got != want {
+		t.Errorf("Len = %d, want %d", got, want)
+	}
+	if got, ok := s.Get([]byte("ad")); !ok || got != "toad" {
+		t.Errorf("Get(%q) = (%q, %t), want (%q, true)", "ad", got, ok, "toad")
+	}
+	if s.Contains([]byte("toad")) || !s.Contains(nil) || !s.HasPrefix([]byte("ady")) {
+		t.Errorf("Contains or HasPrefix see keys outside the scope")
+	}
+	if got, want := s.Find([]byte("ady")), []string{"toady", "toadyism"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Find\n got: %v\nwant: %v", got, want)
+	}
+	if got, ok := s.Min(); !ok || got != "to" {
+		t.Errorf("Min = (%q, %t), want (%q, true)", got, ok, "to")
+	}
+	if got, ok := s.Max(); !ok || got != "toadyism" {
+		t.Errorf("Max = (%q, %t), want (%q, true)", got, ok, "toadyism")
+	}
+
+	// Keys of the tree shorter than the prefix are not in the scope.
+	for key, want := range map[string]string{"adstool": "toad", "x": "to", "": "to"} {
+		if got, ok := s.LongestPrefix([]byte(key)); !ok || got != want {
+			t.Errorf("LongestPrefix(%q) = (%q, %t), want (%q, true)", key, got, ok, want)
+		}
+	}
+	if _, ok := tree.Scope([]byte("toe")).LongestPrefix(nil); ok {
+		t.Errorf("LongestPrefix found a key outside the scope")
+	}
+	if got, ok := tree.Scope(nil).LongestPrefix([]byte("tx")); !ok || got != "t" {
+		t.Errorf("LongestPrefix(%q) in the root scope = (%q, %t), want (%q, true)", "tx", got, ok, "t")
+	}
+
+	var keys []string
+	s.WalkKeys([]byte("a"), func(key []byte) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	if want := []string{"a", "ad", "ady", "adyism"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("WalkKeys\n got: %v\nwant: %v", keys, want)
+	}
+	keys = nil
+	s.WalkDepth(nil, 2, func(key []byte, value string) bool {
+		if "to"+string(key) != value {
+			t.Errorf("WalkDepth passed key %q with value %q", key, value)
+		}
+		keys = append(keys, string(key))
+		return true
+	})
+	if want := []string{"", "a", "ad"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("WalkDepth\n got: %v\nwant: %v", keys, want)
+	}
+
+	// Changes through the scope are made to the tree and the other way round.
+	key := []byte("e")
+	s.Insert(key, "toe")
+	key[0] = 'x'
+	if got, _ := tree.Get([]byte("toe")); got != "toe" {
+		t.Errorf("Insert through the scope did not insert %q into the tree", "toe")
+	}
+	if _, ok := s.Remove([]byte("ad")); !ok || tree.Contains([]byte("toad")) {
+		t.Errorf("Remove through the scope did not remove %q from the tree", "toad")
+	}
+	tree.Insert([]byte("tot"), "tot")
+	if got, _ := s.Get([]byte("t")); got != "tot" {
+		t.Errorf("the scope does not see %q inserted into the tree", "tot")
+	}
+
+	inner := s.Scope([]byte("ad"))
+	if got, want := string(inner.Prefix()), "toad"; got != want {
+		t.Errorf("Prefix = %q, want %q", got, want)
+	}
+	if got, want := inner.Find(nil), []string{"toady", "toadyism"}; !reflect.DeepEqual(got, want) || inner.Tree() != tree {
+		t.Errorf("nested scope Find\n got: %v\nwant: %v", got, want)
+	}
+	verify(t, tree)
+}
@@ -0,0 +1,54 @@
+package radixtree
+
+import "iter"
+
+// All returns an iterator over the keys and values of the tree in ascending key
+// order, for use with range-over-func and the iter, maps and slices packages.
+// The keys are copies that the caller may retain unless the tree was created
+// with WithSharedKeys. If the tree is modified while the iteration continues
+// it panics with ErrModified.
+func (t *RadixTree[T]) All() iter.Seq2[[]byte, T] {
+	return t.AllPrefix(nil)
+}
+
+// AllPrefix is like All for the keys that start with the given prefix.
+func (t *RadixTree[T]) AllPrefix(prefix []byte) iter.Seq2[[]byte, T] {
+	return func(yield func(key []byte, value T) bool) {
+		n, key := t.findPath(prefix, t.borrow())
+		if n != nil {
+			gen := t.gen
+			key, _ = walkNodes(n, key, -1, func(key []byte, n *node[T]) bool {
+				return yield(t.yield(key), n.leaf.value) && t.unmodified(gen)
+			})
+		}
+		t.release(key)
+	}
+}
+
+// InsertSeq inserts every key and value produced by seq into the tree and
+// returns the number of keys that were not already in the tree. The keys are
+// copied, so seq may reuse its buffers, and built into the tree in a single
+// pass as by InsertMany. If seq produces a key more than once the last value is
+// kept.
+func (t *RadixTree[T]) InsertSeq(seq iter.Seq2[[]byte, T]) int {
+	return t.InsertMany(collect(seq))
+}
+
+// Collect creates and returns a radix tree configured with the given options
+// holding the keys and values produced by seq, as by InsertSeq. The keys may be
+// strings so that, for example, maps.All of a map with string keys can be
+// collected.
+func Collect[K ~string | ~[]byte, T any](seq iter.Seq2[K, T], opts ...Option) *RadixTree[T] {
+	t := New[T](opts...)
+	t.InsertMany(collect(seq))
+	return t
+}
+
+// collect returns the keys and values produced by seq with copies of the keys.
+func collect[K ~string | ~[]byte, T any](seq iter.Seq2[K, T]) []Entry[T] {
+	var entries []Entry[T]
+	for key, value := range seq {
+		entries = append(entries, Entry[T]{Key: append([]byte(nil), key...), Value: value})
+	}
+	return entries
+}
@@ -0,0 +1,79 @@
+package radixtree
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	tree := build(words)
+	var keys []string
+	for key, value := range tree.All() {
+		if string(key) != value {
+			t.Errorf("All produced key %q with value %q", key, value)
+		}
+		keys = append(keys, string(key))
+	}
+	if want := tree.Find(nil); !reflect.DeepEqual(keys, want) {
+		t.Errorf("All\n got: %v\nwant: %v", keys, want)
+	}
+
+	keys = nil
+	for key := range tree.AllPrefix([]byte("toad")) {
+		keys = append(keys, string(key))
+		if len(keys) == 2 {
+			break
+		}
+	}
+	if want := []string{"toad", "toady"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("AllPrefix\n got: %v\nwant: %v", keys, want)
+	}
+	for range tree.AllPrefix([]byte("x")) {
+		t.Errorf("AllPrefix produced a key for a missing prefix")
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrModified) {
+			t.Errorf("All after Insert panicked with %v, want %v", err, ErrModified)
+		}
+	}()
+	for key := range tree.All() {
+		tree.Insert(append(key, 'x'), "x")
+	}
+}
+
+func TestInsertSeq(t *testing.T) {
+	tree := build([]string{"to"})
+	buf := make([]byte, 0, 16)
+	seq := func(yield func([]byte, string) bool) {
+		for _, w := range words {
+			// The buffer is reused for every key.
+			buf = append(buf[:0], w...)
+			if !yield(buf, w) {
+				return
+			}
+		}
+	}
+	if got, want := tree.InsertSeq(seq), len(words)-1; got != want {
+		t.Errorf("InsertSeq = %d, want %d", got, want)
+	}
+	verify(t, tree)
+	for _, w := range words {
+		if got, ok := tree.Get([]byte(w)); !ok || got != w {
+			t.Errorf("Get(%q) = (%q, %t), want (%q, true)", w, got, ok, w)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	m := map[string]int{"a": 1, "ab": 2, "b": 3}
+	tree := Collect(maps.All(m))
+	if got := tree.ToMap(); !reflect.DeepEqual(got, m) {
+		t.Errorf("Collect(maps.All)\n got: %v\nwant: %v", got, m)
+	}
+	if got := Collect(tree.All()).ToMap(); !reflect.DeepEqual(got, m) {
+		t.Errorf("Collect(All)\n got: %v\nwant: %v", got, m)
+	}
+}
@@ -0,0 +1,176 @@
+package radixtree
+
+import "bytes"
+
+// token identifies the tree that may modify a node in place while trees share
+// nodes. It has a non-zero size so that every token has a distinct address.
+type token struct {
+	_ byte
+}
+
+// Snapshot returns an independent copy of the tree in constant time. The copy
+// shares the nodes of the tree rather than copying them, and both trees copy
+// the nodes on the path to a key before modifying them, so each sees none of
+// the changes made to the other afterwards and a change copies only the nodes
+// it affects. Operations that restructure many nodes at once, such as
+// InsertMany, RemoveMany, DeleteRange, Graft, Detach, Join and Compact, first
+// copy every node the tree still shares. Iterating over a snapshot, for example
+// with FindIter, is therefore unaffected by changes to the tree made during the
+// iteration, and once Snapshot has returned the snapshot may be read by another
+// goroutine while the tree is modified. Several goroutines may read the
+// snapshot at once unless the tree was created with WithSharedKeys,
+// WithLookupCache or WithHotPrefixes, whose reads write to the tree they read.
+// Handles and pointers returned by GetRef refer to values that may now be
+// shared so they are checked again or, for pointers, must be obtained again. A
+// tree created with WithAllocator cannot share the memory of its keys, so its
+// snapshot is a deep copy made in time proportional to the size of the tree.
+func (t *RadixTree[T]) Snapshot() *RadixTree[T] {
+	if t.opts.alloc != nil {
+		s := t.derive(t.copyAll(t.root))
+		s.size = t.size
+		s.removed = t.removed
+		return s
+	}
+	s := t.derive(t.root)
+	s.size = t.size
+	s.removed = t.removed
+	t.share()
+	s.share()
+	return s
+}
+
+// FindIterSnapshot is like FindIter but iterates over a Snapshot of the tree,
+// so that the tree may be modified during the iteration without affecting it.
+func (t *RadixTree[T]) FindIterSnapshot(prefix []byte) *Iterator[T] {
+	return t.Snapshot().FindIter(prefix)
+}
+
+// Fork returns a new tree holding the entries whose keys start with the given
+// prefix, with their full keys, and the same options as the tree. Like
+// Snapshot the new tree shares the nodes of the subtree in constant time after
+// finding the prefix, and each tree copies the nodes it shares before
+// modifying them, so the trees are independent and a change to either copies
+// only the nodes on the path to the keys it affects. A tree created with
+// WithAllocator is forked by copying the subtree.
+func (t *RadixTree[T]) Fork(prefix []byte) *RadixTree[T] {
+	if len(prefix) == 0 {
+		return t.Snapshot()
+	}
+	n, key := t.findPath(prefix, nil)
+	if n == nil || n.count == 0 {
+		return newTree[T](t.opts)
+	}
+
+	var child *node[T]
+	if t.opts.alloc != nil {
+		child = t.copyAll(n)
+		t.free(child.prefix)
+		child.prefix = t.own(key)
+	} else {
+		// The new node is owned by neither tree so the fork copies it
+		// before modifying it.
+		child = &node[T]{prefix: key, leaf: n.leaf, children: n.children, mod: n.mod, agg: n.agg, count: n.count}
+	}
+	root := &node[T]{children: childrenOf(child), mod: n.mod}
+	t.update(root)
+
+	f := t.derive(root)
+	f.size = root.count
+	if t.opts.alloc == nil {
+		t.share()
+		f.share()
+	}
+	return f
+}
+
+// share marks the nodes of the tree as possibly shared with another tree by
+// giving the tree a new owner that none of them has.
+func (t *RadixTree[T]) share() {
+	t.shared = true
+	t.owner = &token{}
+}
+
+// writable returns true if the tree may modify n in place.
+func (t *RadixTree[T]) writable(n *node[T]) bool {
+	return !t.shared || n.owner == t.owner
+}
+
+// mutable returns n if the tree may modify it in place, otherwise a copy of n
+// owned by the tree with its own leaf and children, which must replace n in
+// its parent.
+func (t *RadixTree[T]) mutable(n *node[T]) *node[T] {
+	if t.writable(n) {
+		return n
+	}
+	c := *n
+	c.owner = t.owner
+	c.children = children[T]{
+		keys:  append([]byte(nil), n.children.keys...),
+		nodes: append([]*node[T](nil), n.children.nodes...),
+	}
+	if n.leaf != nil {
+		lf := *n.leaf
+		c.leaf = &lf
+	}
+	t.copies++
+	return &c
+}
+
+// unshare replaces the nodes on the path to key that the tree may not modify,
+// including a node whose prefix the key diverges from, by copies so that key
+// can be inserted or removed.
+func (t *RadixTree[T]) unshare(key []byte) {
+	if !t.shared {
+		return
+	}
+	t.root = t.mutable(t.root)
+	n := t.root
+	for len(key) > 0 {
+		i := n.children.index(key[0])
+		if i < 0 {
+			return
+		}
+		child := t.mutable(n.children.nodes[i])
+		n.children.nodes[i] = child
+		if !bytes.HasPrefix(key, child.prefix) {
+			return
+		}
+		n = child
+		key = key[len(child.prefix):]
+	}
+}
+
+// unshareAll replaces every node of the tree that the tree may not modify by a
+// copy so that it no longer shares any nodes.
+func (t *RadixTree[T]) unshareAll() {
+	if !t.shared {
+		return
+	}
+	var unshare func(n *node[T]) *node[T]
+	unshare = func(n *node[T]) *node[T] {
+		n = t.mutable(n)
+		for i, child := range n.children.nodes {
+			n.children.nodes[i] = unshare(child)
+		}
+		return n
+	}
+	t.root = unshare(t.root)
+	t.shared = false
+}
+
+// copyAll returns a deep copy of the subtree rooted at n with prefixes from
+// the allocator of the tree.
+func (t *RadixTree[T]) copyAll(n *node[T]) *node[T] {
+	c := *n
+	c.prefix = t.own(n.prefix)
+	if n.leaf != nil {
+		lf := *n.leaf
+		c.leaf = &lf
+	}
+	nodes := make([]*node[T], n.children.len())
+	for i, child := range n.children.nodes {
+		nodes[i] = t.copyAll(child)
+	}
+	c.children = children[T]{keys: append([]byte(nil), n.children.keys...), nodes: nodes}
+	return &c
+}
@@ -0,0 +1,223 @@
+package radixtree
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// keysOf returns the keys of the tree in ascending order.
+func keysOf[T any](tree *RadixTree[T]) []string {
+	var keys []string
+	tree.WalkKeys(nil, func(key []byte) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	return keys
+}
+
+func TestSnapshot(t *testing.T) {
+	tree := build(words)
+	snap := tree.Snapshot()
+	want := append([]string(nil), words...)
+	sort.Strings(want)
+
+	tree.Insert([]byte("toad"), "frog")
+	tree.Insert([]byte("toadstool"), "toadstool")
+	tree.Remove([]byte("aardvark"))
+	tree.Remove([]byte("winkle"))
+	if tree.copies == 0 || tree.copies >= uint64(tree.nodeCount()) {
+		t.Errorf("modifying two keys copied %d of %d nodes", tree.copies, tree.nodeCount())
+	}
+	snap.Insert([]byte("zebra"), "zebra")
+	snap.Remove([]byte("macro"))
+
+	if got := keysOf(snap); len(got) != len(want) || got[len(got)-1] != "zebra" {
+		t.Errorf("snapshot keys = %q", got)
+	}
+	if v, _ := snap.Get([]byte("toad")); v != "toad" {
+		t.Errorf("snapshot Get(%q) = %q, want %q", "toad", v, "toad")
+	}
+	for _, key := range []string{"aardvark", "winkle"} {
+		if !snap.Contains([]byte(key)) || tree.Contains([]byte(key)) {
+			t.Errorf("removing %q from the tree changed the snapshot", key)
+		}
+	}
+	if v, _ := tree.Get([]byte("toad")); v != "frog" || tree.Contains([]byte("zebra")) || !tree.Contains([]byte("macro")) {
+		t.Errorf("changes to the snapshot are seen by the tree")
+	}
+	if tree.Len() != len(words)-1 || snap.Len() != len(words) {
+		t.Errorf("Len = %d and %d, want %d and %d", tree.Len(), snap.Len(), len(words)-1, len(words))
+	}
+	verify(t, tree)
+	verify(t, snap)
+
+	// Bulk operations copy every shared node first.
+	snap = tree.Snapshot()
+	before := keysOf(snap)
+	tree.RemoveMany([][]byte{[]byte("toad"), []byte("abacus")})
+	tree.DeleteRange([]byte("m"), []byte("n"))
+	tree.InsertMany([]Entry[string]{{Key: []byte("mango"), Value: "mango"}})
+	detached := tree.Detach([]byte("b"), false)
+	if got := keysOf(snap); !reflect.DeepEqual(got, before) {
+		t.Errorf("snapshot keys after bulk operations = %q, want %q", got, before)
+	}
+	tree.Graft(nil, detached)
+	verify(t, tree)
+	verify(t, snap)
+
+	// Values set through a pointer or handle stay out of the snapshot.
+	h, _ := tree.Handle([]byte("toa"))
+	snap = tree.Snapshot()
+	p, _ := tree.GetRef([]byte("babble"))
+	*p = "rattle"
+	if !h.Set("frog") {
+		t.Errorf("Set after Snapshot returned false")
+	}
+	if v, _ := snap.Get([]byte("babble")); v != "babble" {
+		t.Errorf("snapshot Get(%q) after GetRef = %q", "babble", v)
+	}
+	if v, _ := snap.Get([]byte("toa")); v != "toa" {
+		t.Errorf("snapshot Get(%q) after Handle.Set = %q", "toa", v)
+	}
+	if v, _ := tree.Get([]byte("toa")); v != "frog" {
+		t.Errorf("Get(%q) after Handle.Set = %q, want %q", "toa", v, "frog")
+	}
+}
+
+func TestSnapshotLazyDelete(t *testing.T) {
+	tree := New[string](WithLazyDelete(), WithAggregate("", concat, identity))
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	snap := tree.Snapshot()
+	for _, key := range words[:10] {
+		tree.Remove([]byte(key))
+	}
+	tree.Compact()
+	if snap.Len() != len(words) || len(keysOf(snap)) != len(words) {
+		t.Errorf("snapshot has %d keys after removals from the tree, want %d", len(keysOf(snap)), len(words))
+	}
+	checkAggregates(t, "snapshot", snap, words)
+	checkAggregates(t, "tree", tree, words[10:])
+	verify(t, tree)
+	verify(t, snap)
+}
+
+func TestSnapshotAllocator(t *testing.T) {
+	a := newTrackingAllocator(t)
+	tree := New[string](WithAllocator(a))
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	snap := tree.Snapshot()
+	for _, key := range words {
+		tree.Remove([]byte(key))
+	}
+	if got := keysOf(snap); len(got) != len(words) {
+		t.Errorf("snapshot has %d keys, want %d", len(got), len(words))
+	}
+	for _, key := range words {
+		snap.Remove([]byte(key))
+	}
+	if len(a.live) != 0 {
+		t.Errorf("%d allocations are still live", len(a.live))
+	}
+}
+
+func TestFindIterSnapshot(t *testing.T) {
+	tree := build(words)
+	var want []string
+	for _, key := range tree.AppendKeys(nil, []byte("to")) {
+		want = append(want, string(key))
+	}
+	var got []string
+	for it := tree.FindIterSnapshot([]byte("to")); it.Next(); {
+		got = append(got, string(it.Key()))
+		tree.Remove(it.Key())
+		tree.Insert(append([]byte("tz"), it.Key()...), "")
+	}
+	if len(want) == 0 || !reflect.DeepEqual(got, want) {
+		t.Errorf("FindIterSnapshot visited %q, want %q", got, want)
+	}
+	if tree.CountPrefix([]byte("to")) != 0 || tree.CountPrefix([]byte("tzto")) != len(got) {
+		t.Errorf("the tree has %d keys under %q after removing them", tree.CountPrefix([]byte("to")), "to")
+	}
+	verify(t, tree)
+}
+
+func TestSnapshotConcurrent(t *testing.T) {
+	tree := build(words)
+	snap := tree.Snapshot()
+	done := make(chan []string)
+	go func() {
+		done <- keysOf(snap)
+	}()
+	for _, key := range words {
+		tree.Remove([]byte(key))
+		tree.Insert(append([]byte(key), 's'), key)
+	}
+	if got := <-done; len(got) != len(words) {
+		t.Errorf("snapshot read concurrently with changes to the tree has %d keys, want %d", len(got), len(words))
+	}
+}
+
+func TestFork(t *testing.T) {
+	for _, prefix := range []string{"to", "macroan", "w", "", "x", "toadyismx"} {
+		tree := New[string](WithAggregate("", concat, identity))
+		for _, key := range words {
+			tree.Insert([]byte(key), key)
+		}
+		f := tree.Fork([]byte(prefix))
+		want := hasPrefix(prefix, words)
+		sort.Strings(want)
+		if got := keysOf(f); !reflect.DeepEqual(got, want) {
+			t.Errorf("Fork(%q) keys = %q, want %q", prefix, got, want)
+		}
+		if f.Len() != len(want) {
+			t.Errorf("Fork(%q).Len() = %d, want %d", prefix, f.Len(), len(want))
+		}
+		checkAggregates(t, "fork", f, want)
+
+		// Changes to either tree are not seen by the other.
+		for _, key := range want {
+			f.Insert([]byte(key), "x")
+		}
+		f.Insert([]byte(prefix+"zz"), "zz")
+		for _, key := range want {
+			tree.Remove([]byte(key))
+		}
+		if tree.Len() != len(words)-len(want) || f.Len() != len(want)+1 {
+			t.Errorf("Fork(%q): Len = %d and %d after changes", prefix, tree.Len(), f.Len())
+		}
+		for _, key := range want {
+			if v, _ := f.Get([]byte(key)); v != "x" {
+				t.Errorf("Fork(%q).Get(%q) = %q, want %q", prefix, key, v, "x")
+			}
+		}
+		var rest []string
+		for _, key := range words {
+			if !strings.HasPrefix(key, prefix) {
+				rest = append(rest, key)
+			}
+		}
+		checkAggregates(t, "tree", tree, rest)
+		verify(t, tree)
+		verify(t, f)
+	}
+
+	a := newTrackingAllocator(t)
+	tree := New[string](WithAllocator(a))
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	f := tree.Fork([]byte("to"))
+	for _, key := range words {
+		tree.Remove([]byte(key))
+		f.Remove([]byte(key))
+	}
+	if len(a.live) != 0 {
+		t.Errorf("%d allocations are still live after forking with an allocator", len(a.live))
+	}
+}
@@ -0,0 +1,334 @@
+package radixtree
+
+// Split partitions the entries of the tree into two trees, the first holding
+// the keys that are less than the given key and the second holding the keys
+// that are greater than or equal to it. The trees are formed by dividing the
+// existing nodes along the path to key rather than by reinserting entries so
+// the original tree is left empty. Both trees share the options of the
+// original.
+func (t *RadixTree[T]) Split(key []byte) (*RadixTree[T], *RadixTree[T]) {
+	l, r := t.splitNode(t.root, key)
+	left, right := t.derive(l), t.derive(r)
+	left.size = left.root.count
+	right.size = right.root.count
+	t.reset()
+	return left, right
+}
+
+// Partition divides the entries of the tree into n trees of nearly equal size,
+// for handing parts of a large tree to separate goroutines or machines, and
+// returns them in key order along with the n-1 boundary keys between them:
+// the i-th tree holds the keys less than bounds[i] and greater than or equal
+// to bounds[i-1]. The boundaries are found from the counts kept in the nodes
+// and the trees formed as by Split, so the original tree is left empty and the
+// trees share its options. If n is greater than Len some of the trees are
+// empty, and an n less than 1 is treated as 1.
+func (t *RadixTree[T]) Partition(n int) (trees []*RadixTree[T], bounds [][]byte) {
+	n = max(n, 1)
+	size := t.root.count
+	for i := 1; i < n; i++ {
+		key, _, ok := t.Select(i * size / n)
+		if !ok {
+			// The remaining trees are empty, bounded by a key
+			// greater than every key in the tree.
+			key = append(lastKey(t.root, nil), 0)
+		}
+		bounds = append(bounds, key)
+	}
+	rest := t
+	for _, key := range bounds {
+		var left *RadixTree[T]
+		left, rest = rest.Split(key)
+		trees = append(trees, left)
+	}
+	return append(trees, rest), bounds
+}
+
+// Join merges two trees whose key ranges do not overlap into a single tree. Every
+// key in left must be less than every key in right, otherwise ErrOverlap is
+// returned and neither tree is changed. The nodes of both trees are spliced
+// together along the boundary between them rather than reinserted so, like
+// Split, both trees are left empty. The joined tree has the options of left.
+// Entries keep their insertion sequence numbers so trees split from the same
+// tree rejoin in their original insertion order. If the keys of right would
+// exceed a limit of left the error is returned as by InsertErr and neither
+// tree is changed. The watches of both trees move to the joined tree.
+//
+// Join ends the chains of incremental backups of both trees, since the joined
+// tree does not record the removals made from them and holds entries of right
+// that are older than the generations of left. BackupSince on the joined tree
+// returns ErrRemovalsDiscarded for a generation from before the join, so its
+// first backup must be since generation 0.
+func Join[T any](left, right *RadixTree[T]) (*RadixTree[T], error) {
+	if left.size > 0 && right.size > 0 {
+		first := firstKey(right.root, nil)
+		if right.opts.order != left.opts.order {
+			first = minKey(right.root, nil, left.opts.order)
+		}
+		if left.opts.order.compare(lastKey(left.root, nil), first) >= 0 {
+			return nil, ErrOverlap
+		}
+	}
+	if err := left.checkSubtree(nil, right.root, false); err != nil {
+		return nil, err
+	}
+
+	left.unshareAll()
+	right.unshareAll()
+	t := left.derive(left.root)
+	t.adopt(right.root, right)
+	t.union(t.root, right.root)
+	t.size = left.size + right.size
+	if right.seq > t.seq {
+		t.seq = right.seq
+	}
+	if right.gen > t.gen {
+		t.gen = right.gen
+	}
+	t.gen++
+	if t.filter != nil && (right.filter == nil || !t.filter.union(right.filter)) {
+		t.rebuildFilter()
+	}
+	// The record of removals starts at the join, which is after every
+	// generation of either tree.
+	t.logRemovals = left.logRemovals || right.logRemovals
+	t.removalsFrom = t.gen
+	t.moveWatches(left)
+	t.moveWatches(right)
+	left.reset()
+	right.reset()
+	return t, nil
+}
+
+// adopt prepares the subtree rooted at n, taken from the tree from, to be linked
+// into t. Its prefixes are moved to the allocator of t and, if the trees differ
+// in byte order or aggregation, its children are sorted and its bookkeeping
+// recomputed.
+func (t *RadixTree[T]) adopt(n *node[T], from *RadixTree[T]) {
+	t.moveKeys(n, from.opts.alloc)
+	reordered := from.opts.order != t.opts.order
+	if reordered {
+		t.reorder(n)
+	}
+	if reordered || from.agg != t.agg {
+		t.updateAll(n)
+	}
+}
+
+// rebuildFilter replaces the bloom filter with one holding exactly the keys in
+// the tree.
+func (t *RadixTree[T]) rebuildFilter() {
+	t.filter = newBloom(t.opts.bloomSize, t.opts.bloomRate)
+	walkNodes(t.root, nil, -1, func(key []byte, _ *node[T]) bool {
+		t.filter.add(key)
+		return true
+	})
+}
+
+// derive returns a tree with the same options as t rooted at root, which may be
+// nil for an empty tree. If t has a bloom filter the derived tree starts with a
+// copy of it since it holds a subset of the keys of t.
+func (t *RadixTree[T]) derive(root *node[T]) *RadixTree[T] {
+	d := newTree[T](t.opts)
+	if root != nil {
+		d.root = root
+	}
+	d.seq = t.seq
+	d.gen = t.gen
+	d.shared = t.shared
+	d.owner = t.owner
+	if t.filter != nil {
+		d.filter = t.filter.clone()
+	}
+	return d
+}
+
+// reset removes every entry from the tree.
+func (t *RadixTree[T]) reset() {
+	t.root = &node[T]{}
+	t.size = 0
+	t.gen++
+	t.detached++
+	t.logRemoval(backupPrefix, nil, nil)
+	if t.filter != nil {
+		t.filter = newBloom(t.opts.bloomSize, t.opts.bloomRate)
+	}
+}
+
+// splitNode divides the subtree rooted at n into the keys that are less than
+// key and the keys that are greater than or equal to it, where key is relative
+// to n. Both halves keep the prefix of n and either half is nil if it would be
+// empty. Nodes other than the two returned ones are shared with n rather than
+// copied. If the tree has an allocator the left half takes over the prefix of n
+// and the right half gets a copy so n must be discarded.
+func (t *RadixTree[T]) splitNode(n *node[T], key []byte) (*node[T], *node[T]) {
+	if len(key) == 0 {
+		// Every key in the subtree is greater than or equal to key.
+		return nil, n
+	}
+
+	// The children before i belong to the left half and the children from j
+	// onwards belong to the right half. Only a child that starts with every
+	// byte of its prefix in common with key can straddle the two.
+	i := n.children.search(key[0], t.opts.order)
+	j := i
+	var lc, rc *node[T]
+	if i < n.children.len() && n.children.nodes[i].prefix[0] == key[0] {
+		child := n.children.nodes[i]
+		l := longestCommonPrefix(key, child.prefix)
+		switch {
+		case l == len(child.prefix):
+			lc, rc = t.splitNode(child, key[l:])
+			lc, rc = t.compact(lc), t.compact(rc)
+			j++
+		case l < len(key) && t.opts.order.less(child.prefix[l], key[l]):
+			i++
+			j++
+		}
+	}
+
+	left := &node[T]{prefix: n.prefix, leaf: n.leaf, mod: n.mod}
+	nodes := make([]*node[T], 0, i+1)
+	nodes = append(nodes, n.children.nodes[:i]...)
+	if lc != nil {
+		nodes = append(nodes, lc)
+	}
+	left.children = childrenOf(nodes...)
+
+	right := &node[T]{prefix: t.own(n.prefix), mod: n.mod}
+	nodes = make([]*node[T], 0, n.children.len()-j+1)
+	if rc != nil {
+		nodes = append(nodes, rc)
+	}
+	nodes = append(nodes, n.children.nodes[j:]...)
+	right.children = childrenOf(nodes...)
+
+	t.update(left)
+	t.update(right)
+	if prune(left) == nil {
+		t.free(left.prefix)
+		left = nil
+	}
+	if prune(right) == nil {
+		t.free(right.prefix)
+		right = nil
+	}
+	return left, right
+}
+
+// union merges the subtree rooted at b into the subtree rooted at a. Both nodes
+// must be at the same position in their trees and a keeps its own prefix. The
+// value of a key in b replaces the value of the same key in a. The nodes of b
+// are reused so b must not be used afterwards. It returns the number of values
+// in a that were replaced.
+func (t *RadixTree[T]) union(a, b *node[T]) int {
+	replaced := 0
+	if b.hasValue() {
+		if a.hasValue() {
+			// The leaf of a is kept so that, as with Insert, an
+			// updated entry keeps its insertion sequence number.
+			replaced++
+			t.keep(a.leaf, a.leaf.value)
+			a.leaf.value = b.leaf.value
+			if b.leaf.mod > a.leaf.mod {
+				a.leaf.mod = b.leaf.mod
+			}
+		} else {
+			a.leaf = b.leaf
+		}
+	}
+	if b.mod > a.mod {
+		a.mod = b.mod
+	}
+
+	for _, bc := range b.children.nodes {
+		i := a.children.index(bc.prefix[0])
+		if i < 0 {
+			a.children.add(bc, t.opts.order)
+			continue
+		}
+
+		ac := a.children.nodes[i]
+		l := longestCommonPrefix(ac.prefix, bc.prefix)
+		if l < len(ac.prefix) {
+			// Split the child of a so that both children start at the
+			// same position.
+			mid := &node[T]{prefix: t.own(ac.prefix[:l:l]), mod: ac.mod}
+			t.setPrefix(ac, ac.prefix[l:])
+			mid.children = childrenOf(ac)
+			a.children.nodes[i] = mid
+			ac = mid
+		}
+		if l < len(bc.prefix) {
+			// The child of b continues below ac so it is merged with
+			// the children of ac.
+			t.setPrefix(bc, bc.prefix[l:])
+			bc = &node[T]{children: childrenOf(bc), mod: bc.mod}
+		}
+		replaced += t.union(ac, bc)
+	}
+	t.update(a)
+	t.free(b.prefix)
+	return replaced
+}
+
+// firstKey appends the smallest key in the subtree rooted at n, relative to n,
+// to buf and returns the extended buffer.
+func firstKey[T any](n *node[T], buf []byte) []byte {
+	for !n.hasValue() {
+		i := n.children.firstFrom(0)
+		if i < 0 {
+			break
+		}
+		n = n.children.nodes[i]
+		buf = append(buf, n.prefix...)
+	}
+	return buf
+}
+
+// minKey is like firstKey but finds the smallest key in the byte order o rather
+// than the order of the children.
+func minKey[T any](n *node[T], buf []byte, o *byteOrder) []byte {
+	for !n.hasValue() {
+		var min *node[T]
+		for _, child := range n.children.nodes {
+			if child.count > 0 && (min == nil || o.less(child.prefix[0], min.prefix[0])) {
+				min = child
+			}
+		}
+		if min == nil {
+			break
+		}
+		n = min
+		buf = append(buf, n.prefix...)
+	}
+	return buf
+}
+
+// lastKey appends the largest key in the subtree rooted at n, relative to n, to
+// buf and returns the extended buffer.
+func lastKey[T any](n *node[T], buf []byte) []byte {
+	for i := n.children.lastBefore(n.children.len()); i >= 0; i = n.children.lastBefore(n.children.len()) {
+		n = n.children.nodes[i]
+		buf = append(buf, n.prefix...)
+	}
+	return buf
+}
+
+// prune returns nil if n has neither a value nor any children.
+func prune[T any](n *node[T]) *node[T] {
+	if n == nil || (!n.hasValue() && n.children.len() == 0) {
+		return nil
+	}
+	return n
+}
+
+// compact returns n after merging it with its only child if it has no value of
+// its own, or nil if n is empty. It must not be used on the root.
+func (t *RadixTree[T]) compact(n *node[T]) *node[T] {
+	n = prune(n)
+	if n != nil && !n.hasValue() && n.children.len() == 1 {
+		t.merge(n)
+	}
+	return n
+}
@@ -0,0 +1,211 @@
+package radixtree
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	keys := append([]string{"", "a", "aard", "macroa", "macroanalysiz", "to", "tob", "zzz"}, words...)
+	for _, key := range keys {
+		tree := build(words)
+		left, right := tree.Split([]byte(key))
+
+		want := append([]string{}, less(key, words)...)
+		if got := left.Values(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Split(%s) left\n got: %v\nwant: %v", key, got, want)
+		}
+		want = append([]string{}, words[len(want):]...)
+		if got := right.Values(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Split(%s) right\n got: %v\nwant: %v", key, got, want)
+		}
+		verify(t, left)
+		verify(t, right)
+
+		for _, word := range words {
+			side, other := right, left
+			if word < key {
+				side, other = left, right
+			}
+			if got, ok := side.Get([]byte(word)); !ok || got != word {
+				t.Errorf("Get(%s) after Split(%s)\n got: (%s, %t)\nwant: (%s, true)", word, key, got, ok, word)
+			}
+			if other.Contains([]byte(word)) {
+				t.Errorf("Contains(%s) after Split(%s) returned true for both halves", word, key)
+			}
+		}
+
+		if tree.Len() != 0 || len(tree.Values()) != 0 {
+			t.Errorf("Split(%s) did not leave the original tree empty", key)
+		}
+	}
+}
+
+func TestSplitBloomFilter(t *testing.T) {
+	tree := New[string](WithBloomFilter(len(words), 0.01))
+	for _, key := range words {
+		tree.Insert([]byte(key), key)
+	}
+	left, right := tree.Split([]byte("m"))
+	for _, word := range words {
+		side := right
+		if word < "m" {
+			side = left
+		}
+		if !side.Contains([]byte(word)) {
+			t.Errorf("Contains(%s) returned false after Split(m)", word)
+		}
+	}
+
+	tree.Insert([]byte("toad"), "toad")
+	if !tree.Contains([]byte("toad")) || tree.Len() != 1 {
+		t.Errorf("original tree is not usable after Split")
+	}
+}
+
+func TestJoin(t *testing.T) {
+	keys := append([]string{"", "a", "aard", "macroa", "to", "tob", "zzz"}, words...)
+	for _, key := range keys {
+		left, right := build(words).Split([]byte(key))
+		tree, err := Join(left, right)
+		if err != nil {
+			t.Fatalf("Join after Split(%s) returned error %v", key, err)
+		}
+		if got := tree.Values(); !reflect.DeepEqual(got, words) {
+			t.Errorf("Join after Split(%s)\n got: %v\nwant: %v", key, got, words)
+		}
+		verify(t, tree)
+		if left.Len() != 0 || right.Len() != 0 {
+			t.Errorf("Join after Split(%s) did not empty the joined trees", key)
+		}
+	}
+
+	// Join trees built separately, where the boundary falls inside shared
+	// prefixes.
+	left := build([]string{"abc", "abd", "to"})
+	right := build([]string{"toa", "toad", "x"})
+	tree, err := Join(left, right)
+	if err != nil {
+		t.Fatalf("Join returned error %v", err)
+	}
+	want := []string{"abc", "abd", "to", "toa", "toad", "x"}
+	if got := tree.Values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Join\n got: %v\nwant: %v", got, want)
+	}
+	verify(t, tree)
+
+	// Joining with an empty tree.
+	tree, err = Join(New[string](), build(words))
+	if err != nil || !reflect.DeepEqual(tree.Values(), words) {
+		t.Errorf("Join with an empty left tree\n got: (%v, %v)\nwant: (%v, <nil>)", tree.Values(), err, words)
+	}
+}
+
+func TestJoinTracking(t *testing.T) {
+	left := New[string](WithModificationTracking())
+	left.Insert([]byte("a"), "a")
+	left.Insert([]byte("b"), "b")
+	gen, err := left.BackupSince(0, io.Discard, encodeString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	left.Remove([]byte("b"))
+	right := New[string](WithModificationTracking())
+	right.Insert([]byte("x"), "x")
+
+	a, cancelA := left.WatchKey([]byte("a"))
+	x, cancelX := left.WatchKey([]byte("x"))
+	defer cancelX()
+	rx, cancelRX := right.WatchKey([]byte("x"))
+	defer cancelRX()
+	tree, err := Join(left, right)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The removal of b is not in the joined tree, so the chain of backups
+	// since gen cannot continue.
+	if _, err := tree.BackupSince(gen, io.Discard, encodeString); !errors.Is(err, ErrRemovalsDiscarded) {
+		t.Errorf("BackupSince(%d) after Join = %v, want ErrRemovalsDiscarded", gen, err)
+	}
+	if _, err := tree.BackupSince(0, io.Discard, encodeString); err != nil {
+		t.Errorf("BackupSince(0) after Join = %v", err)
+	}
+
+	// The watches follow the keys into the joined tree, and only the watch
+	// of a key that was absent from its tree sees the join.
+	event := func(name string, ch <-chan KeyEvent[string], want *KeyEvent[string]) {
+		t.Helper()
+		select {
+		case e := <-ch:
+			if want == nil || e != *want {
+				t.Errorf("%s: event %+v, want %+v", name, e, want)
+			}
+		default:
+			if want != nil {
+				t.Errorf("%s: no event, want %+v", name, *want)
+			}
+		}
+	}
+	event("a after Join", a, nil)
+	event("x of left after Join", x, &KeyEvent[string]{Value: "x"})
+	event("x of right after Join", rx, nil)
+	tree.Insert([]byte("a"), "a2")
+	event("Insert(a)", a, &KeyEvent[string]{Value: "a2"})
+	tree.Remove([]byte("x"))
+	event("Remove(x)", rx, &KeyEvent[string]{Removed: true})
+
+	cancelA()
+	if _, ok := <-a; ok {
+		t.Error("cancel after Join did not close the channel")
+	}
+	tree.Insert([]byte("a"), "a3")
+}
+
+func TestJoinOverlap(t *testing.T) {
+	left := build([]string{"b", "d"})
+	right := build([]string{"c", "e"})
+	if _, err := Join(left, right); err != ErrOverlap {
+		t.Errorf("Join with overlapping ranges\n got: %v\nwant: %v", err, ErrOverlap)
+	}
+	if left.Len() != 2 || right.Len() != 2 {
+		t.Errorf("Join with overlapping ranges changed the trees")
+	}
+
+	if _, err := Join(build([]string{"a"}), build([]string{"a"})); err != ErrOverlap {
+		t.Errorf("Join with a shared key\n got: %v\nwant: %v", err, ErrOverlap)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	sorted := slices.Sorted(slices.Values(words))
+	for _, n := range []int{0, 1, 3, 4, len(words), len(words) + 2} {
+		trees, bounds := build(words).Partition(n)
+		if want := max(n, 1); len(trees) != want || len(bounds) != want-1 {
+			t.Errorf("Partition(%d) returned %d trees and %d bounds, want %d and %d", n, len(trees), len(bounds), want, want-1)
+			continue
+		}
+		var keys []string
+		smallest, largest := len(words), 0
+		for i, tree := range trees {
+			verify(t, tree)
+			smallest, largest = min(smallest, tree.Len()), max(largest, tree.Len())
+			for key := range tree.All() {
+				if i > 0 && bytes.Compare(key, bounds[i-1]) < 0 || i < len(bounds) && bytes.Compare(key, bounds[i]) >= 0 {
+					t.Errorf("Partition(%d) tree %d holds %q outside its bounds", n, i, key)
+				}
+				keys = append(keys, string(key))
+			}
+		}
+		if largest-smallest > 1 {
+			t.Errorf("Partition(%d) trees hold between %d and %d keys", n, smallest, largest)
+		}
+		if !reflect.DeepEqual(keys, sorted) {
+			t.Errorf("Partition(%d) trees hold %q, want %q", n, keys, sorted)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package radixtree
+
+import "bytes"
+
+// Height returns the height of the tree, which is the number of edges on the
+// longest path from the root to a node. A tree holding at most the empty key
+// has a height of zero. The height is computed by visiting every node.
+func (t *RadixTree[T]) Height() int {
+	return height(t.root)
+}
+
+// Depth returns the depth of the node holding the given key, which is the
+// number of nodes other than the root that are visited to find it. The boolean
+// return value will be false if the key is not in the tree.
+func (t *RadixTree[T]) Depth(key []byte) (int, bool) {
+	depth := 0
+	n := t.root
+	for len(key) > 0 {
+		child := n.children.get(key[0])
+		if child == nil || !bytes.HasPrefix(key, child.prefix) {
+			return 0, false
+		}
+		depth++
+		n = child
+		key = key[len(child.prefix):]
+	}
+	if !n.hasValue() {
+		return 0, false
+	}
+	return depth, true
+}
+
+func height[T any](n *node[T]) int {
+	h := 0
+	for _, child := range n.children.nodes {
+		if c := height(child) + 1; c > h {
+			h = c
+		}
+	}
+	return h
+}
+
+// Stats describes the shape of a tree.
+type Stats struct {
+	// Nodes is the number of nodes in the tree, including the root.
+	Nodes int
+	// Values is the number of nodes holding a value.
+	Values int
+	// Height is the height of the tree as returned by Height.
+	Height int
+	// FanOut is a histogram of the number of children of each node, where
+	// FanOut[i] is the number of nodes with i children.
+	FanOut []int
+	// PrefixLen is a histogram of the lengths of the compressed prefixes of
+	// the nodes other than the root, where PrefixLen[i] is the number of
+	// nodes with a prefix of i bytes.
+	PrefixLen []int
+}
+
+// StatsDetail visits every node of the tree and returns the statistics of its
+// shape. Many nodes with a single child and long prefixes indicate long chains
+// of keys sharing a prefix while a high fan-out indicates wide nodes.
+func (t *RadixTree[T]) StatsDetail() Stats {
+	var s Stats
+	s.Height = stats(t.root, 0, &s)
+	return s
+}
+
+// stats adds the nodes of the subtree rooted at n, at the given depth, to s
+// and returns the height of the subtree.
+func stats[T any](n *node[T], depth int, s *Stats) int {
+	s.Nodes++
+	if n.hasValue() {
+		s.Values++
+	}
+	s.FanOut = inc(s.FanOut, n.children.len())
+	if depth > 0 {
+		s.PrefixLen = inc(s.PrefixLen, len(n.prefix))
+	}
+	h := 0
+	for _, child := range n.children.nodes {
+		if c := stats(child, depth+1, s) + 1; c > h {
+			h = c
+		}
+	}
+	return h
+}
+
+// inc increments bucket i of the histogram h, growing it if necessary.
+func inc(h []int, i int) []int {
+	for len(h) <= i {
+		h = append(h, 0)
+	}
+	h[i]++
+	return h
+}
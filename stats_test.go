@@ -0,0 +1,73 @@
+package radixtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHeight(t *testing.T) {
+	if got := New[int]().Height(); got != 0 {
+		t.Errorf("Height on empty tree = %d, want 0", got)
+	}
+
+	// "winkleman" is below nodes holding "w", "i", "n", "k" and "le".
+	tree := build(words)
+	if got := tree.Height(); got != 6 {
+		t.Errorf("Height = %d, want 6", got)
+	}
+
+	tree.Remove([]byte("winkleman"))
+	if got := tree.Height(); got != 5 {
+		t.Errorf("Height after remove = %d, want 5", got)
+	}
+}
+
+func TestDepth(t *testing.T) {
+	tree := build(words)
+
+	tests := []struct {
+		key   string
+		depth int
+		ok    bool
+	}{
+		{"to", 1, true},
+		{"toad", 3, true},
+		{"toadyism", 5, true},
+		// "aardvark" is below a node holding "a" and one holding "ardw".
+		{"aardvark", 3, true},
+		{"will", 4, true},
+		{"a", 0, false},
+		{"toadstool", 0, false},
+		{"wi", 0, false},
+	}
+	for _, test := range tests {
+		if got, ok := tree.Depth([]byte(test.key)); got != test.depth || ok != test.ok {
+			t.Errorf("Depth(%q) = (%d, %t), want (%d, %t)", test.key, got, ok, test.depth, test.ok)
+		}
+	}
+}
+
+func TestStatsDetail(t *testing.T) {
+	// The keys form a root with children "a" and "b", where "a" has
+	// children "b" and "cde".
+	tree := New[int]()
+	for i, key := range []string{"ab", "acde", "b"} {
+		tree.Insert([]byte(key), i)
+	}
+
+	want := Stats{
+		Nodes:     5,
+		Values:    3,
+		Height:    2,
+		FanOut:    []int{3, 0, 2},
+		PrefixLen: []int{0, 3, 0, 1},
+	}
+	if got := tree.StatsDetail(); !reflect.DeepEqual(got, want) {
+		t.Errorf("StatsDetail\n got: %+v\nwant: %+v", got, want)
+	}
+
+	want = Stats{Nodes: 1, FanOut: []int{1}}
+	if got := New[int]().StatsDetail(); !reflect.DeepEqual(got, want) {
+		t.Errorf("StatsDetail on empty tree\n got: %+v\nwant: %+v", got, want)
+	}
+}
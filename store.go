@@ -0,0 +1,120 @@
+package radixtree
+
+import "errors"
+
+// ValueStore holds values outside a StoreTree, such as in a file, a database or
+// an object store, and identifies each of them by a reference for the tree to
+// keep in their place. A reference is only passed to the store's methods after
+// Put has returned it and until Delete is called with it.
+type ValueStore[T any] interface {
+	// Put stores a value and returns its reference.
+	Put(value T) (ref uint64, err error)
+	// Get returns the value with the given reference.
+	Get(ref uint64) (T, error)
+	// Delete removes the value with the given reference from the store.
+	Delete(ref uint64) error
+}
+
+// StoreTree is a radix tree whose values live in a ValueStore while the tree
+// itself only holds their references, so that the index of the keys stays small
+// enough to be kept in memory however large the values are. Looking up a value
+// finds its reference in the tree and then reads it from the store. A
+// StoreTree is no more safe for concurrent use than a RadixTree, whatever the
+// store.
+type StoreTree[T any] struct {
+	tree  *RadixTree[uint64]
+	store ValueStore[T]
+}
+
+// NewStoreTree creates and returns an empty StoreTree keeping its values in
+// store, whose underlying tree is configured with the given options. Options
+// that depend on the value type, such as WithAggregate, cannot be used.
+func NewStoreTree[T any](store ValueStore[T], opts ...Option) *StoreTree[T] {
+	return &StoreTree[T]{tree: New[uint64](opts...), store: store}
+}
+
+// Contains returns true if the key is in the tree, without reading the store.
+func (s *StoreTree[T]) Contains(key []byte) bool {
+	return s.tree.Contains(key)
+}
+
+// Get returns the value of key read from the store and true, or the zero value
+// for type T and false if the key is not in the tree. The error is that of the
+// store.
+func (s *StoreTree[T]) Get(key []byte) (T, bool, error) {
+	ref, ok := s.tree.Get(key)
+	if !ok {
+		var zero T
+		return zero, false, nil
+	}
+	v, err := s.store.Get(ref)
+	return v, true, err
+}
+
+// Insert puts the value in the store and sets key to refer to it, deleting the
+// value the key referred to before from the store. If the store fails to put
+// the value, or the key exceeds a limit of the tree as for InsertErr, the tree
+// is not changed and the value is not kept in the store; if it fails to delete
+// the previous value the key refers to the new one and the error is returned.
+func (s *StoreTree[T]) Insert(key []byte, value T) error {
+	ref, err := s.store.Put(value)
+	if err != nil {
+		return err
+	}
+	old, ok, err := s.tree.InsertErr(key, ref)
+	if err != nil {
+		return errors.Join(err, s.store.Delete(ref))
+	}
+	if ok {
+		return s.store.Delete(old)
+	}
+	return nil
+}
+
+// Len returns the number of keys in the tree.
+func (s *StoreTree[T]) Len() int {
+	return s.tree.Len()
+}
+
+// Ref returns the reference held for key and true, or 0 and false if the key
+// is not in the tree.
+func (s *StoreTree[T]) Ref(key []byte) (uint64, bool) {
+	return s.tree.Get(key)
+}
+
+// Remove removes the key from the tree and deletes its value from the store.
+// It returns true if the key was in the tree, and the error of the store, in
+// which case the key is removed regardless.
+func (s *StoreTree[T]) Remove(key []byte) (bool, error) {
+	ref, ok := s.tree.Remove(key)
+	if !ok {
+		return false, nil
+	}
+	return true, s.store.Delete(ref)
+}
+
+// Walk executes f for each key that starts with the given prefix and its value
+// read from the store, in ascending key order. If f returns false Walk stops.
+// If the store fails to read a value Walk stops and returns the error.
+func (s *StoreTree[T]) Walk(prefix []byte, f func(key []byte, value T) bool) error {
+	for key, ref := range s.tree.AllPrefix(prefix) {
+		v, err := s.store.Get(ref)
+		if err != nil {
+			return err
+		}
+		if !f(key, v) {
+			break
+		}
+	}
+	return nil
+}
+
+// WalkRefs is like Walk but passes the references of the values to f rather
+// than reading them from the store.
+func (s *StoreTree[T]) WalkRefs(prefix []byte, f func(key []byte, ref uint64) bool) {
+	for key, ref := range s.tree.AllPrefix(prefix) {
+		if !f(key, ref) {
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package radixtree
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// mapStore is a ValueStore holding its values in a map.
+type mapStore struct {
+	values map[uint64]string
+	next   uint64
+	fail   bool
+}
+
+var errStore = errors.New("store failed")
+
+func (s *mapStore) Put(value string) (uint64, error) {
+	if s.fail {
+		return 0, errStore
+	}
+	s.next++
+	s.values[s.next] = value
+	return s.next, nil
+}
+
+func (s *mapStore) Get(ref uint64) (string, error) {
+	v, ok := s.values[ref]
+	if !ok || s.fail {
+		return "", errStore
+	}
+	return v, nil
+}
+
+func (s *mapStore) Delete(ref uint64) error {
+	if _, ok := s.values[ref]; !ok {
+		return errStore
+	}
+	delete(s.values, ref)
+	return nil
+}
+
+func TestStoreTree(t *testing.T) {
+	store := &mapStore{values: map[uint64]string{}}
+	tree := NewStoreTree[string](store)
+	for _, key := range words {
+		if err := tree.Insert([]byte(key), key); err != nil {
+			t.Fatalf("Insert(%q): %v", key, err)
+		}
+	}
+	if err := tree.Insert([]byte("toad"), "frog"); err != nil {
+		t.Fatalf("Insert replacing a value: %v", err)
+	}
+	limited := NewStoreTree[string](&mapStore{values: map[uint64]string{}}, WithMaxEntries(1))
+	limited.Insert([]byte("a"), "a")
+	if err := limited.Insert([]byte("b"), "b"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Insert over the entries limit = %v, want %v", err, ErrQuotaExceeded)
+	}
+	if n := len(limited.store.(*mapStore).values); n != 1 || limited.Len() != 1 {
+		t.Errorf("store holds %d values after Insert over a limit, want 1", n)
+	}
+	if len(store.values) != len(words) || tree.Len() != len(words) {
+		t.Errorf("store holds %d values for %d keys, want %d", len(store.values), tree.Len(), len(words))
+	}
+	if v, ok, err := tree.Get([]byte("toad")); !ok || err != nil || v != "frog" {
+		t.Errorf("Get(%q) = (%q, %t, %v), want (%q, true, nil)", "toad", v, ok, err, "frog")
+	}
+	if ref, ok := tree.Ref([]byte("toad")); !ok || store.values[ref] != "frog" {
+		t.Errorf("Ref(%q) = (%d, %t)", "toad", ref, ok)
+	}
+
+	var got []string
+	if err := tree.Walk([]byte("toad"), func(key []byte, v string) bool {
+		got = append(got, v)
+		return true
+	}); err != nil {
+		t.Errorf("Walk: %v", err)
+	}
+	if want := []string{"frog", "toady", "toadyism"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk values = %q, want %q", got, want)
+	}
+
+	if ok, err := tree.Remove([]byte("toad")); !ok || err != nil || len(store.values) != len(words)-1 {
+		t.Errorf("Remove = (%t, %v) leaving %d values in the store", ok, err, len(store.values))
+	}
+	if ok, err := tree.Remove([]byte("toad")); ok || err != nil {
+		t.Errorf("second Remove = (%t, %v)", ok, err)
+	}
+
+	// Errors of the store are returned and a failed Put leaves the tree
+	// unchanged.
+	store.fail = true
+	if err := tree.Insert([]byte("newt"), "newt"); !errors.Is(err, errStore) || tree.Contains([]byte("newt")) {
+		t.Errorf("Insert with a failing store = %v", err)
+	}
+	if _, ok, err := tree.Get([]byte("toa")); !ok || !errors.Is(err, errStore) {
+		t.Errorf("Get with a failing store = (%t, %v)", ok, err)
+	}
+	if err := tree.Walk(nil, func([]byte, string) bool { return true }); !errors.Is(err, errStore) {
+		t.Errorf("Walk with a failing store = %v", err)
+	}
+}
@@ -0,0 +1,535 @@
+package radixtree
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// SyncMap is a sharded map with string keys that is safe for concurrent use,
+// with the methods of sync.Map so that code written against it can switch to a
+// tree and gain ordered iteration and prefix queries. Unlike sync.Map it is
+// backed by trees guarded by mutexes, so it suits mixed workloads better than
+// ones where many goroutines read a mostly fixed set of keys. The options
+// passed to NewSyncMap apply to each of these trees, except that
+// WithBloomFilter sizes a single filter for the whole map, which Load, Get and
+// Contains consult without taking a lock. CompareAndSwap and CompareAndDelete
+// are not provided since the values need not be comparable. The zero value is
+// an empty map ready to use and a SyncMap must not be copied after first use.
+//
+// The keys are striped over a tree for each first byte, plus one for the empty
+// key, each with its own sync.RWMutex, so goroutines that write keys starting
+// with different bytes do not wait for each other and readers of the same
+// tree hold its read lock together. There is no finer locking within a tree:
+// a write waits for every reader and writer of its tree, so keys that share
+// their first byte, such as those under a common prefix, all contend for the
+// same lock. ConcurrentTree locks single nodes instead. Since reading a tree
+// created with WithLookupCache, WithHotPrefixes or WithSharedKeys writes to
+// it, the readers of such trees take the write lock as well.
+type SyncMap[T any] struct {
+	opts     []Option
+	once     sync.Once
+	treeOpts []Option // opts without the bloom filter
+	order    []int    // indexes of shards in ascending key order
+	filter   atomic.Pointer[bloom]
+	// exclusive is true if reading a tree writes to it, so that readers
+	// must lock it exclusively.
+	exclusive bool
+	shards    [257]syncShard[T]
+}
+
+// syncShard is the tree holding the keys of a SyncMap that start with the same
+// byte, or the empty key.
+type syncShard[T any] struct {
+	mu   sync.RWMutex
+	tree *RadixTree[T]
+}
+
+// NewSyncMap returns an empty map backed by trees created with the given
+// options.
+func NewSyncMap[T any](opts ...Option) *SyncMap[T] {
+	return &SyncMap[T]{opts: opts}
+}
+
+// shard locks and returns the shard holding the given key.
+func (m *SyncMap[T]) shard(key string) *syncShard[T] {
+	return m.lock(shardIndex(key))
+}
+
+// shardIndex returns the index of the shard holding the given key.
+func shardIndex[K string | []byte](key K) int {
+	if len(key) == 0 {
+		return 0
+	}
+	return 1 + int(key[0])
+}
+
+// lock locks and returns the shard with the given index.
+func (m *SyncMap[T]) lock(i int) *syncShard[T] {
+	m.once.Do(m.setup)
+	s := &m.shards[i]
+	s.mu.Lock()
+	return s
+}
+
+// rlock locks the shard with the given index for reading and returns it. The
+// shard must be unlocked with runlock.
+func (m *SyncMap[T]) rlock(i int) *syncShard[T] {
+	m.once.Do(m.setup)
+	s := &m.shards[i]
+	if m.exclusive {
+		s.mu.Lock()
+	} else {
+		s.mu.RLock()
+	}
+	return s
+}
+
+// runlock unlocks a shard locked by rlock.
+func (m *SyncMap[T]) runlock(s *syncShard[T]) {
+	if m.exclusive {
+		s.mu.Unlock()
+	} else {
+		s.mu.RUnlock()
+	}
+}
+
+// init returns the tree of a shard, creating it if the shard has not been used
+// before. The mutex of the shard must be held.
+func (m *SyncMap[T]) init(s *syncShard[T]) *RadixTree[T] {
+	if s.tree == nil {
+		s.tree = New[T](m.treeOpts...)
+	}
+	return s.tree
+}
+
+// mayContain returns false if the key is definitely not in the map.
+func (m *SyncMap[T]) mayContain(key []byte) bool {
+	m.once.Do(m.setup)
+	f := m.filter.Load()
+	return f == nil || f.mayContainAtomic(key)
+}
+
+// added adds a key that is about to be inserted to the bloom filter of the
+// map. The mutex of the shard of the key must be held, so that the key is not
+// added to a filter ReplaceAll has replaced.
+func (m *SyncMap[T]) added(key []byte) {
+	if f := m.filter.Load(); f != nil {
+		f.addAtomic(key)
+	}
+}
+
+// shardOrder returns the indexes of the shards in the order of their keys,
+// which is the byte order of the trees.
+func (m *SyncMap[T]) shardOrder() []int {
+	m.once.Do(m.setup)
+	return m.order
+}
+
+// setup applies the options of the map to find the order of the shards and
+// whether reads must lock them exclusively, and creates the bloom filter.
+func (m *SyncMap[T]) setup() {
+	var o options
+	for _, opt := range m.opts {
+		opt(&o)
+	}
+	m.treeOpts = m.opts
+	if o.bloomSize > 0 {
+		m.filter.Store(newBloom(o.bloomSize, o.bloomRate))
+		m.treeOpts = append(m.opts[:len(m.opts):len(m.opts)], WithBloomFilter(0, 0))
+	}
+	m.exclusive = o.cacheSize > 0 || o.hotDepth > 0 || o.sharedKeys
+	m.order = make([]int, len(m.shards))
+	for i := range m.order {
+		m.order[i] = i
+	}
+	// The empty key is the least key and stays first.
+	sort.Slice(m.order[1:], func(i, j int) bool {
+		return o.order.less(byte(m.order[1+i]-1), byte(m.order[1+j]-1))
+	})
+}
+
+// Delete removes the value for a key.
+func (m *SyncMap[T]) Delete(key string) {
+	m.LoadAndDelete(key)
+}
+
+// Len returns the number of keys in the map. The shards are counted in turn so
+// keys stored or deleted during the call may or may not be counted.
+func (m *SyncMap[T]) Len() int {
+	n := 0
+	for i := range m.shards {
+		s := m.rlock(i)
+		n += s.tree.Len()
+		m.runlock(s)
+	}
+	return n
+}
+
+// Load returns the value stored in the map for a key and true, or the zero
+// value for type T and false if the key is not in the map.
+func (m *SyncMap[T]) Load(key string) (T, bool) {
+	return m.Get([]byte(key))
+}
+
+// LoadAndDelete removes the value for a key, returning the previous value and
+// true, or the zero value for type T and false if the key was not in the map.
+func (m *SyncMap[T]) LoadAndDelete(key string) (T, bool) {
+	s := m.shard(key)
+	defer s.mu.Unlock()
+	return m.init(s).Remove([]byte(key))
+}
+
+// LoadOrStore returns the existing value for the key and true if it is in the
+// map. Otherwise it stores the given value and returns it and false.
+func (m *SyncMap[T]) LoadOrStore(key string, value T) (T, bool) {
+	s := m.shard(key)
+	defer s.mu.Unlock()
+	t := m.init(s)
+	if v, ok := t.Get([]byte(key)); ok {
+		return v, true
+	}
+	m.added([]byte(key))
+	t.Insert([]byte(key), value)
+	return value, false
+}
+
+// Range calls f for each key and value in the map in ascending key order. If f
+// returns false Range stops. As with sync.Map, Range does not see a consistent
+// snapshot of the map and f may call any method of it: the entries are read in
+// batches and no mutex is held while f runs, so if a key is stored or
+// deleted during Range, Range may reflect any mapping for it from any point
+// during the call. Every key that is in the map for the whole call is visited
+// exactly once.
+func (m *SyncMap[T]) Range(f func(key string, value T) bool) {
+	m.RangePrefix("", f)
+}
+
+// RangePrefix is like Range for the keys that start with the given prefix.
+func (m *SyncMap[T]) RangePrefix(prefix string, f func(key string, value T) bool) {
+	m.rangePrefix([]byte(prefix), func(key []byte, value T) bool {
+		return f(string(key), value)
+	})
+}
+
+// rangePrefix is like RangePrefix with keys as byte slices, which are copies
+// that f may retain.
+func (m *SyncMap[T]) rangePrefix(prefix []byte, f func(key []byte, value T) bool) {
+	if len(prefix) > 0 {
+		m.rangeShard(1+int(prefix[0]), prefix, f)
+		return
+	}
+	for _, i := range m.shardOrder() {
+		if !m.rangeShard(i, nil, f) {
+			return
+		}
+	}
+}
+
+// rangeShard calls f for each key with the given prefix in the shard with
+// index i as rangePrefix does. It returns false if f did.
+func (m *SyncMap[T]) rangeShard(i int, prefix []byte, f func(key []byte, value T) bool) bool {
+	const size = 64
+	var (
+		batch []Entry[T]
+		last  []byte
+	)
+	for {
+		batch = m.next(batch[:0], i, prefix, last, size)
+		for _, e := range batch {
+			if !f(e.Key, e.Value) {
+				return false
+			}
+		}
+		if len(batch) < size {
+			return true
+		}
+		last = batch[len(batch)-1].Key
+	}
+}
+
+// next appends to dst up to n entries of the shard with index i whose keys
+// start with prefix and are greater than last, or all the keys with the prefix
+// if last is nil, and returns the extended slice. The positions of the keys
+// are found with Rank so that keys inserted or removed between batches do not
+// disturb Range.
+func (m *SyncMap[T]) next(dst []Entry[T], i int, prefix, last []byte, n int) []Entry[T] {
+	s := m.rlock(i)
+	defer m.runlock(s)
+	if s.tree == nil {
+		return dst
+	}
+	t := s.tree
+	// The key is looked up directly so that the traffic counted for
+	// WithHotPrefixes is only that of the callers.
+	var j int
+	if last == nil {
+		j = t.Rank(prefix)
+	} else if j = t.Rank(last); t.lookup(last) != nil {
+		j++
+	}
+	for ; len(dst) < n; j++ {
+		key, value, ok := t.Select(j)
+		if !ok || !bytes.HasPrefix(key, prefix) {
+			break
+		}
+		dst = append(dst, Entry[T]{Key: key, Value: value})
+	}
+	return dst
+}
+
+// ReplaceAll replaces every entry of the map by the entries of src, moving the
+// nodes of src into the trees of the map, and leaves src empty. Every shard of
+// the map is locked while the trees are swapped, so that no method sees some
+// of the old entries together with some of the new ones, but src is divided
+// between the shards before any lock is taken.
+func (m *SyncMap[T]) ReplaceAll(src *RadixTree[T]) {
+	m.once.Do(m.setup)
+	var trees [len(m.shards)]*RadixTree[T]
+	for _, c := range append([]byte(nil), src.root.children.keys...) {
+		t := New[T](m.treeOpts...)
+		t.ReplaceAll(src.Detach([]byte{c}, false))
+		trees[1+int(c)] = t
+	}
+	// Only the empty key can be left in src.
+	trees[0] = New[T](m.treeOpts...)
+	trees[0].ReplaceAll(src)
+	var filter *bloom
+	if f := m.filter.Load(); f != nil {
+		filter = &bloom{bits: make([]uint64, len(f.bits)), k: f.k}
+		for _, t := range trees {
+			if t == nil {
+				continue
+			}
+			walkNodes(t.root, nil, -1, func(key []byte, _ *node[T]) bool {
+				filter.add(key)
+				return true
+			})
+		}
+	}
+
+	for i := range m.shards {
+		m.lock(i)
+	}
+	for i := range m.shards {
+		m.shards[i].tree = trees[i]
+	}
+	if filter != nil {
+		m.filter.Store(filter)
+	}
+	for i := range m.shards {
+		m.shards[i].mu.Unlock()
+	}
+}
+
+// Store sets the value for a key.
+func (m *SyncMap[T]) Store(key string, value T) {
+	m.Swap(key, value)
+}
+
+// Swap stores the value for a key and returns the previous value and true, or
+// the zero value for type T and false if the key was not in the map.
+func (m *SyncMap[T]) Swap(key string, value T) (T, bool) {
+	s := m.shard(key)
+	defer s.mu.Unlock()
+	m.added([]byte(key))
+	return m.init(s).Insert([]byte(key), value)
+}
+
+// The methods below make SyncMap a Tree, so that it can stand in for a
+// RadixTree or a Frozen tree. Each of them locks a single shard at a time,
+// so like Range the traversals do not see a consistent snapshot of the map,
+// and none of them panics with ErrModified.
+
+// Contains returns true if key is in the map, false otherwise.
+func (m *SyncMap[T]) Contains(key []byte) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Find returns the values whose keys start with the given prefix in ascending
+// key order.
+func (m *SyncMap[T]) Find(prefix []byte) []T {
+	var values []T
+	m.Walk(prefix, func(value T) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// Get returns the value stored in the map for a key and true, or the zero
+// value for type T and false if the key is not in the map.
+func (m *SyncMap[T]) Get(key []byte) (T, bool) {
+	if !m.mayContain(key) {
+		var zero T
+		return zero, false
+	}
+	s := m.rlock(shardIndex(key))
+	defer m.runlock(s)
+	return s.tree.Get(key)
+}
+
+// HasPrefix returns true if any key in the map starts with the given prefix.
+func (m *SyncMap[T]) HasPrefix(prefix []byte) bool {
+	if len(prefix) == 0 {
+		return m.Len() > 0
+	}
+	s := m.rlock(shardIndex(prefix))
+	defer m.runlock(s)
+	return s.tree.HasPrefix(prefix)
+}
+
+// InsertErr is like the method of RadixTree for the tree of the shard holding
+// the key, so the limits set by options such as WithMaxEntries apply to each
+// shard rather than to the whole map.
+func (m *SyncMap[T]) InsertErr(key []byte, value T) (T, bool, error) {
+	s := m.lock(shardIndex(key))
+	defer s.mu.Unlock()
+	m.added(key)
+	return m.init(s).InsertErr(key, value)
+}
+
+// InsertManyErr is like the method of RadixTree for the tree of each shard
+// that holds some of the keys, locking one shard at a time in the order of
+// their indexes. If the entries of a shard would exceed its limits none of them
+// is inserted and the error is returned, but the entries inserted into the
+// shards before it remain in the map and those of the shards after it are not
+// inserted.
+func (m *SyncMap[T]) InsertManyErr(entries []Entry[T]) (int, error) {
+	var groups [len(m.shards)][]Entry[T]
+	for _, e := range entries {
+		i := shardIndex(e.Key)
+		groups[i] = append(groups[i], e)
+	}
+	added := 0
+	for i, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		s := m.lock(i)
+		for _, e := range group {
+			m.added(e.Key)
+		}
+		n, err := m.init(s).InsertManyErr(group)
+		s.mu.Unlock()
+		added += n
+		if err != nil {
+			return added, err
+		}
+	}
+	return added, nil
+}
+
+// LongestPrefix returns the value of the longest key in the map that is a
+// prefix of the given key and true, or the zero value for type T and false if
+// there is no such key. Apart from the empty key, which is looked up if no
+// longer key is found, the keys that are prefixes of the given key are all in
+// the same shard.
+func (m *SyncMap[T]) LongestPrefix(key []byte) (T, bool) {
+	if len(key) > 0 {
+		s := m.rlock(shardIndex(key))
+		v, ok := s.tree.LongestPrefix(key)
+		m.runlock(s)
+		if ok {
+			return v, true
+		}
+	}
+	return m.Get(nil)
+}
+
+// Max returns the value of the largest key in the map and true, or false if
+// the map is empty.
+func (m *SyncMap[T]) Max() (T, bool) {
+	order := m.shardOrder()
+	for i := len(order) - 1; i >= 0; i-- {
+		if v, ok := m.bound(order[i], (*RadixTree[T]).Max); ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Min returns the value of the smallest key in the map and true, or false if
+// the map is empty.
+func (m *SyncMap[T]) Min() (T, bool) {
+	for _, i := range m.shardOrder() {
+		if v, ok := m.bound(i, (*RadixTree[T]).Min); ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// bound returns the result of f, either Min or Max, for the tree of the shard
+// with index i.
+func (m *SyncMap[T]) bound(i int, f func(t *RadixTree[T]) (T, bool)) (T, bool) {
+	s := m.rlock(i)
+	defer m.runlock(s)
+	return f(s.tree)
+}
+
+// RemoveErr removes a key and returns its value, or ErrNotFound if the key is
+// not in the map.
+func (m *SyncMap[T]) RemoveErr(key []byte) (T, error) {
+	s := m.lock(shardIndex(key))
+	defer s.mu.Unlock()
+	return m.init(s).RemoveErr(key)
+}
+
+// RemoveManyErr removes the given keys, locking one shard at a time, and
+// returns the number of keys that were removed. The error is always nil.
+func (m *SyncMap[T]) RemoveManyErr(keys [][]byte) (int, error) {
+	var groups [len(m.shards)][][]byte
+	for _, key := range keys {
+		i := shardIndex(key)
+		groups[i] = append(groups[i], key)
+	}
+	removed := 0
+	for i, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		s := m.lock(i)
+		if s.tree != nil {
+			removed += s.tree.RemoveMany(group)
+		}
+		s.mu.Unlock()
+	}
+	return removed, nil
+}
+
+// Walk calls f for each value whose key starts with the given prefix in
+// ascending key order until f returns false. As with Range, f may call any
+// method of the map.
+func (m *SyncMap[T]) Walk(prefix []byte, f func(value T) bool) {
+	m.rangePrefix(prefix, func(_ []byte, value T) bool {
+		return f(value)
+	})
+}
+
+// WalkDepth calls f for each key that starts with the given prefix and is at
+// most depth bytes longer than it, and its value, in ascending key order until
+// f returns false. The key passed to f is a copy that the caller may retain.
+// The keys below the depth are read and skipped, so the cost is that of
+// traversing every key with the prefix.
+func (m *SyncMap[T]) WalkDepth(prefix []byte, depth int, f func(key []byte, value T) bool) {
+	if depth < 0 {
+		return
+	}
+	m.rangePrefix(prefix, func(key []byte, value T) bool {
+		return len(key)-len(prefix) > depth || f(key, value)
+	})
+}
+
+// WalkKeys calls f for each key that starts with the given prefix in ascending
+// order until f returns false. The key passed to f is a copy that the caller
+// may retain.
+func (m *SyncMap[T]) WalkKeys(prefix []byte, f func(key []byte) bool) {
+	m.rangePrefix(prefix, func(key []byte, _ T) bool {
+		return f(key)
+	})
+}
@@ -0,0 +1,179 @@
+package radixtree
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSyncMap(t *testing.T) {
+	var m SyncMap[int]
+	if _, ok := m.Load("a"); ok || m.Len() != 0 {
+		t.Errorf("zero SyncMap is not empty")
+	}
+	m.Store("b", 2)
+	if v, loaded := m.LoadOrStore("b", 3); !loaded || v != 2 {
+		t.Errorf("LoadOrStore(%q) = (%d, %t), want (2, true)", "b", v, loaded)
+	}
+	if v, loaded := m.LoadOrStore("a", 1); loaded || v != 1 {
+		t.Errorf("LoadOrStore(%q) = (%d, %t), want (1, false)", "a", v, loaded)
+	}
+	if v, loaded := m.Swap("a", 10); !loaded || v != 1 {
+		t.Errorf("Swap(%q) = (%d, %t), want (1, true)", "a", v, loaded)
+	}
+	if v, ok := m.Load("a"); !ok || v != 10 {
+		t.Errorf("Load(%q) = (%d, %t), want (10, true)", "a", v, ok)
+	}
+	if v, loaded := m.LoadAndDelete("a"); !loaded || v != 10 {
+		t.Errorf("LoadAndDelete(%q) = (%d, %t), want (10, true)", "a", v, loaded)
+	}
+	m.Delete("b")
+	m.Delete("c")
+	if m.Len() != 0 {
+		t.Errorf("Len = %d after deleting every key", m.Len())
+	}
+
+	// Range visits the keys in order across batches and f may modify the
+	// map.
+	var want []string
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("k%03d", i)
+		m.Store(key, i)
+		want = append(want, key)
+	}
+	m.Store("x", -1)
+	var got []string
+	m.RangePrefix("k", func(key string, value int) bool {
+		got = append(got, key)
+		m.Delete(key)
+		m.Store("j", 0)
+		return true
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RangePrefix\n got: %v\nwant: %v", got, want)
+	}
+	if m.Len() != 2 {
+		t.Errorf("Len = %d after deleting in RangePrefix, want 2", m.Len())
+	}
+	got = nil
+	m.Range(func(key string, _ int) bool {
+		got = append(got, key)
+		return false
+	})
+	if want := []string{"j"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Range\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestSyncMapConcurrent(t *testing.T) {
+	// Reading a tree with a lookup cache writes to it, so its readers must
+	// not share the lock.
+	for name, opts := range map[string][]Option{
+		"Default":     nil,
+		"LookupCache": {WithLookupCache(16)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			m := NewSyncMap[int](opts...)
+			var wg sync.WaitGroup
+			for g := 0; g < 4; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 500; i++ {
+						key := fmt.Sprintf("%d/%d", g, i%50)
+						m.Store(key, i)
+						m.Load(key)
+						m.Load(fmt.Sprintf("%d/%d", (g+1)%4, i%50))
+						m.LongestPrefix([]byte("0/1"))
+						if i%3 == 0 {
+							m.Delete(key)
+						}
+						m.RangePrefix(fmt.Sprint(g), func(string, int) bool { return true })
+					}
+				}(g)
+			}
+			wg.Wait()
+			if m.Len() > 200 {
+				t.Errorf("Len = %d, want at most 200", m.Len())
+			}
+		})
+	}
+}
+
+func TestSyncMapOrder(t *testing.T) {
+	m := NewSyncMap[int](WithByteOrder(caseless()))
+	keys := []string{"b", "A", "", "ab", "Ba", "a"}
+	for i, key := range keys {
+		m.Store(key, i)
+	}
+	var got []string
+	m.Range(func(key string, _ int) bool {
+		got = append(got, key)
+		return true
+	})
+	if want := []string{"", "A", "a", "ab", "Ba", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Range\n got: %q\nwant: %q", got, want)
+	}
+	if m.Len() != len(keys) {
+		t.Errorf("Len = %d, want %d", m.Len(), len(keys))
+	}
+}
+
+func TestSyncMapReplaceAll(t *testing.T) {
+	m := NewSyncMap[string]()
+	m.Store("old", "old")
+	m.Store("", "empty")
+	src := build(append([]string{""}, words...))
+	m.ReplaceAll(src)
+
+	var keys []string
+	m.Range(func(key, value string) bool {
+		if key != value {
+			t.Errorf("Range passed %q with value %q", key, value)
+		}
+		keys = append(keys, key)
+		return true
+	})
+	if want := append([]string{""}, keysOf(build(words))...); !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys after ReplaceAll = %q, want %q", keys, want)
+	}
+	if _, ok := m.Load("old"); ok {
+		t.Error("Load(old) found a replaced key")
+	}
+	if m.Len() != len(words)+1 || src.Len() != 0 {
+		t.Errorf("Len = %d and src Len = %d, want %d and 0", m.Len(), src.Len(), len(words)+1)
+	}
+}
+
+func TestSyncMapBloomFilter(t *testing.T) {
+	m := NewSyncMap[string](WithBloomFilter(1000, 0.01))
+	for _, w := range words {
+		m.Store(w, w)
+	}
+	for _, w := range words {
+		if v, ok := m.Load(w); !ok || v != w {
+			t.Errorf("Load(%q) = (%q, %t), want (%q, true)", w, v, ok, w)
+		}
+	}
+	if m.Contains([]byte("missing")) {
+		t.Error("Contains(missing) = true")
+	}
+	// The map holds one filter rather than one per shard.
+	for i := range m.shards {
+		if s := m.shards[i].tree; s != nil && s.filter != nil {
+			t.Fatalf("shard %d has its own bloom filter", i)
+		}
+	}
+
+	// ReplaceAll rebuilds the filter from the new keys.
+	m.ReplaceAll(build([]string{"x", "yz"}))
+	for _, key := range []string{"x", "yz"} {
+		if !m.Contains([]byte(key)) {
+			t.Errorf("Contains(%q) = false after ReplaceAll", key)
+		}
+	}
+	if m.Contains([]byte(words[0])) {
+		t.Errorf("Contains(%q) = true after ReplaceAll", words[0])
+	}
+}
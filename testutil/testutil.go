@@ -0,0 +1,235 @@
+// Package testutil checks implementations of an ordered byte-keyed map, such
+// as radix trees and the wrappers built around them, against a simple
+// reference model. The same sequence of operations is applied to the
+// implementation and to a Model, which keeps its entries in a Go map, and
+// every result is compared, so a fuzz target only has to produce the
+// operations:
+//
+//	func FuzzTree(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			testutil.Run(t, newTree(), testutil.Decode(data))
+//		})
+//	}
+package testutil
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// Tree is the part of the API of a radix tree that the checks use.
+type Tree[T any] interface {
+	Insert(key []byte, value T) (T, bool)
+	Get(key []byte) (T, bool)
+	Remove(key []byte) (T, bool)
+	Len() int
+	// AllPrefix returns the keys that start with prefix and their values
+	// in ascending key order.
+	AllPrefix(prefix []byte) iter.Seq2[[]byte, T]
+}
+
+// Model is the reference model of a Tree. The zero value is an empty model.
+type Model[T any] struct {
+	m map[string]T
+}
+
+// Insert sets the value of key and returns the previous value and true, or the
+// zero value and false if the key was not in the model.
+func (m *Model[T]) Insert(key []byte, value T) (T, bool) {
+	if m.m == nil {
+		m.m = make(map[string]T)
+	}
+	old, ok := m.m[string(key)]
+	m.m[string(key)] = value
+	return old, ok
+}
+
+// Get returns the value of key and true, or the zero value and false if the key
+// is not in the model.
+func (m *Model[T]) Get(key []byte) (T, bool) {
+	v, ok := m.m[string(key)]
+	return v, ok
+}
+
+// Remove removes key and returns its value and true, or the zero value and
+// false if the key was not in the model.
+func (m *Model[T]) Remove(key []byte) (T, bool) {
+	v, ok := m.m[string(key)]
+	delete(m.m, string(key))
+	return v, ok
+}
+
+// Len returns the number of keys in the model.
+func (m *Model[T]) Len() int {
+	return len(m.m)
+}
+
+// AllPrefix returns the keys that start with prefix and their values in
+// ascending key order. The keys are sorted on every call.
+func (m *Model[T]) AllPrefix(prefix []byte) iter.Seq2[[]byte, T] {
+	var keys []string
+	for k := range m.m {
+		if bytes.HasPrefix([]byte(k), prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return func(yield func([]byte, T) bool) {
+		for _, k := range keys {
+			if !yield([]byte(k), m.m[k]) {
+				return
+			}
+		}
+	}
+}
+
+// Kind is the kind of an operation.
+type Kind uint8
+
+// The kinds of operations.
+const (
+	Insert Kind = iota
+	Remove
+	Get
+	// Scan compares the entries whose keys start with the key of the
+	// operation.
+	Scan
+	numKinds
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Insert:
+		return "Insert"
+	case Remove:
+		return "Remove"
+	case Get:
+		return "Get"
+	case Scan:
+		return "Scan"
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// Op is an operation on a tree. The value is only used by Insert.
+type Op[T any] struct {
+	Kind  Kind
+	Key   []byte
+	Value T
+}
+
+func (op Op[T]) String() string {
+	if op.Kind == Insert {
+		return fmt.Sprintf("Insert(%q, %v)", op.Key, op.Value)
+	}
+	return fmt.Sprintf("%v(%q)", op.Kind, op.Key)
+}
+
+// Decode turns arbitrary bytes, such as those of a fuzz input, into
+// operations. Each operation takes two bytes for its kind and the length of
+// its key, which is at most 7 bytes, and then the bytes of the key, each
+// mapped to one of "a", "b", "c" and "d" so that the keys share prefixes
+// often. The value of an insert is its position in the sequence.
+func Decode(data []byte) []Op[int] {
+	var ops []Op[int]
+	for len(data) >= 2 {
+		kind, n := Kind(data[0]%byte(numKinds)), int(data[1]%8)
+		data = data[2:]
+		n = min(n, len(data))
+		key := make([]byte, n)
+		for i, b := range data[:n] {
+			key[i] = 'a' + b%4
+		}
+		data = data[n:]
+		ops = append(ops, Op[int]{Kind: kind, Key: key, Value: len(ops)})
+	}
+	return ops
+}
+
+// Run applies the operations to tree and to a model, reporting a failure to tb
+// for the first result that differs, and then checks that the tree holds the
+// same entries as the model with Check.
+func Run[T any](tb testing.TB, tree Tree[T], ops []Op[T]) {
+	tb.Helper()
+	var m Model[T]
+	for i, op := range ops {
+		if err := Apply(tree, &m, op); err != nil {
+			tb.Fatalf("operation %d of %d: %v", i, len(ops), err)
+		}
+	}
+	if err := Check(tree, &m); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// Apply applies a single operation to tree and to the model m and returns an
+// error describing the difference if their results differ.
+func Apply[T any](tree Tree[T], m *Model[T], op Op[T]) error {
+	var got, want any
+	switch op.Kind {
+	case Insert:
+		got, want = pair(tree.Insert(op.Key, op.Value)), pair(m.Insert(op.Key, op.Value))
+	case Remove:
+		got, want = pair(tree.Remove(op.Key)), pair(m.Remove(op.Key))
+	case Get:
+		got, want = pair(tree.Get(op.Key)), pair(m.Get(op.Key))
+	case Scan:
+		got, want = collect(tree.AllPrefix(op.Key)), collect(m.AllPrefix(op.Key))
+	default:
+		return fmt.Errorf("%v: unknown operation", op)
+	}
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("%v = %v, want %v", op, got, want)
+	}
+	if got, want := tree.Len(), m.Len(); got != want {
+		return fmt.Errorf("Len() = %d after %v, want %d", got, op, want)
+	}
+	return nil
+}
+
+// Check returns an error describing the difference if tree does not hold the
+// same entries as the model m, in the same order.
+func Check[T any](tree Tree[T], m *Model[T]) error {
+	if got, want := tree.Len(), m.Len(); got != want {
+		return fmt.Errorf("Len() = %d, want %d", got, want)
+	}
+	got, want := collect(tree.AllPrefix(nil)), collect(m.AllPrefix(nil))
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("entries\n got: %v\nwant: %v", got, want)
+	}
+	for k := range m.m {
+		if v, ok := tree.Get([]byte(k)); !ok || !reflect.DeepEqual(v, m.m[k]) {
+			return fmt.Errorf("Get(%q) = %v, %v, want %v, true", k, v, ok, m.m[k])
+		}
+	}
+	return nil
+}
+
+// result is the result of a lookup, printed as the value and whether it was
+// found.
+type result[T any] struct {
+	Value T
+	OK    bool
+}
+
+func pair[T any](v T, ok bool) result[T] {
+	return result[T]{v, ok}
+}
+
+// entry is a key and value visited by a scan.
+type entry[T any] struct {
+	Key   string
+	Value T
+}
+
+func collect[T any](seq iter.Seq2[[]byte, T]) []entry[T] {
+	var entries []entry[T]
+	for k, v := range seq {
+		entries = append(entries, entry[T]{string(k), v})
+	}
+	return entries
+}
@@ -0,0 +1,57 @@
+package testutil
+
+import (
+	"iter"
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	ops := Decode([]byte{0, 3, 1, 2, 7, 1, 1, 4, 3, 9, 5})
+	want := []Op[int]{
+		{Kind: Insert, Key: []byte("bcd"), Value: 0},
+		{Kind: Remove, Key: []byte("a"), Value: 1},
+		{Kind: Scan, Key: []byte("b"), Value: 2},
+	}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("Decode\n got: %v\nwant: %v", ops, want)
+	}
+}
+
+// forgetful is a tree that loses the entries removed after them.
+type forgetful struct {
+	Model[int]
+}
+
+func (f *forgetful) Remove(key []byte) (int, bool) {
+	v, ok := f.Model.Remove(key)
+	for k := range f.Model.AllPrefix(key) {
+		f.Model.Remove(k)
+	}
+	return v, ok
+}
+
+func (f *forgetful) AllPrefix(prefix []byte) iter.Seq2[[]byte, int] {
+	return f.Model.AllPrefix(prefix)
+}
+
+func TestApply(t *testing.T) {
+	ops := []Op[int]{
+		{Kind: Insert, Key: []byte("a"), Value: 1},
+		{Kind: Insert, Key: []byte("ab"), Value: 2},
+		{Kind: Get, Key: []byte("ab")},
+		{Kind: Scan, Key: []byte("a")},
+		{Kind: Remove, Key: []byte("a")},
+		{Kind: Scan},
+	}
+	Run[int](t, &Model[int]{}, ops)
+
+	var tree forgetful
+	var m Model[int]
+	for i, op := range ops {
+		err := Apply[int](&tree, &m, op)
+		if (err != nil) != (i >= 4) {
+			t.Errorf("Apply(%v) returned %v", op, err)
+		}
+	}
+}
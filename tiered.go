@@ -0,0 +1,404 @@
+package radixtree
+
+import (
+	"errors"
+	"iter"
+	"os"
+	"path/filepath"
+)
+
+// tieredEntry is a key written to the memory tier of a Tiered tree since the
+// last compaction: either a value or the removal of a key of the frozen tier.
+type tieredEntry[T any] struct {
+	value   T
+	deleted bool
+}
+
+// Tiered is a radix tree in two tiers for data sets that mostly stay the same:
+// a Frozen tree holding the bulk of the keys and a mutable RadixTree in front
+// of it holding the keys written since, along with tombstones for the keys of
+// the frozen tier that were removed. Lookups consult the memory tier and then
+// the frozen one, and Compact folds the memory tier into a new frozen tier so
+// that the mutable part stays small. Both tiers are held in memory, so for data
+// sets larger than memory use a MappedTiered tree instead. A Tiered tree is
+// not safe for concurrent use, but its frozen tier may be shared with other
+// goroutines.
+type Tiered[T any] struct {
+	mem    *RadixTree[tieredEntry[T]]
+	frozen *Frozen[T]
+	opts   []Option
+	size   int
+}
+
+// NewTiered creates and returns a Tiered tree whose frozen tier is frozen, or
+// empty if frozen is nil, and whose memory tier is configured with the given
+// options. The frozen tree must order keys as the options do, such as by
+// having been frozen from a tree created with them. Options that depend on the
+// value type, such as WithAggregate, cannot be used.
+func NewTiered[T any](frozen *Frozen[T], opts ...Option) *Tiered[T] {
+	if frozen == nil {
+		frozen = New[T](opts...).Freeze()
+	}
+	return &Tiered[T]{
+		mem:    New[tieredEntry[T]](opts...),
+		frozen: frozen,
+		opts:   opts,
+		size:   frozen.Len(),
+	}
+}
+
+// Get returns the value of key and true, or the zero value for type T and
+// false if the key is not in the tree.
+func (t *Tiered[T]) Get(key []byte) (T, bool) {
+	if e, ok := t.mem.Get(key); ok {
+		if e.deleted {
+			var zero T
+			return zero, false
+		}
+		return e.value, true
+	}
+	return t.frozen.Get(key)
+}
+
+// Contains returns true if key is in the tree, false otherwise.
+func (t *Tiered[T]) Contains(key []byte) bool {
+	_, ok := t.Get(key)
+	return ok
+}
+
+// Insert sets the value of key in the memory tier and returns the previous
+// value and true, or the zero value for type T and false if the key was not in
+// the tree.
+func (t *Tiered[T]) Insert(key []byte, value T) (T, bool) {
+	old, ok := t.Get(key)
+	t.mem.Insert(key, tieredEntry[T]{value: value})
+	if !ok {
+		t.size++
+	}
+	return old, ok
+}
+
+// Remove removes key and returns its value and true, or the zero value for
+// type T and false if the key was not in the tree. A key of the frozen tier is
+// removed by a tombstone in the memory tier until the next Compact.
+func (t *Tiered[T]) Remove(key []byte) (T, bool) {
+	old, ok := t.Get(key)
+	if !ok {
+		return old, false
+	}
+	if t.frozen.Contains(key) {
+		t.mem.Insert(key, tieredEntry[T]{deleted: true})
+	} else {
+		t.mem.Remove(key)
+	}
+	t.size--
+	return old, true
+}
+
+// Len returns the number of keys in the tree.
+func (t *Tiered[T]) Len() int {
+	return t.size
+}
+
+// MemLen returns the number of keys held by the memory tier, including the
+// tombstones of removed keys, by which a caller can decide when to Compact.
+func (t *Tiered[T]) MemLen() int {
+	return t.mem.Len()
+}
+
+// Frozen returns the frozen tier, which holds the keys as of the last Compact.
+func (t *Tiered[T]) Frozen() *Frozen[T] {
+	return t.frozen
+}
+
+// Walk traverses the keys that start with the given prefix in ascending order,
+// merging the two tiers, and executes function f for each of them and its
+// value. The key passed to f is a copy that the caller may retain unless the
+// memory tier was created with WithSharedKeys. If f returns true the traversal
+// continues otherwise the traversal stops. If f modifies the tree and returns
+// true Walk panics with ErrModified.
+func (t *Tiered[T]) Walk(prefix []byte, f func(key []byte, value T) bool) {
+	walkTiers(t.mem, t.frozen.all(prefix), prefix, f, f)
+}
+
+// walkTiers merges the keys with the given prefix of the memory tier m and of
+// a frozen tier, whose keys and values are yielded by frozen in the order of
+// m, as Walk does. The keys of the frozen tier are passed to ff with its
+// values and those of the memory tier to fm.
+func walkTiers[T, F any](m *RadixTree[tieredEntry[T]], frozen iter.Seq2[[]byte, F], prefix []byte, ff func(key []byte, value F) bool, fm func(key []byte, value T) bool) {
+	next, stop := iter.Pull2(frozen)
+	defer stop()
+	fk, fv, fok := next()
+	o := m.opts.order
+	n, key := m.findPath(prefix, m.borrow())
+	ok := true
+	if n != nil {
+		gen := m.gen
+		key, ok = walkNodes(n, key, -1, func(key []byte, n *node[tieredEntry[T]]) bool {
+			c := 1
+			for fok {
+				if c = o.compare(fk, key); c >= 0 {
+					break
+				}
+				if !ff(fk, fv) || !m.unmodified(gen) {
+					return false
+				}
+				fk, fv, fok = next()
+			}
+			if fok && c == 0 {
+				// The memory tier overrides the frozen one.
+				fk, fv, fok = next()
+			}
+			e := n.leaf.value
+			return e.deleted || fm(m.yield(key), e.value) && m.unmodified(gen)
+		})
+	}
+	m.release(key)
+	for ok && fok {
+		ok = ff(fk, fv)
+		fk, fv, fok = next()
+	}
+}
+
+// Compact folds the memory tier into a new frozen tier holding every key of
+// the tree and empties the memory tier. The previous frozen tier is not
+// changed, so readers that still use it are not affected.
+func (t *Tiered[T]) Compact() {
+	entries := make([]Entry[T], 0, t.size)
+	t.Walk(nil, func(key []byte, value T) bool {
+		// The key may be shared with the memory tier's buffer.
+		entries = append(entries, Entry[T]{Key: append([]byte(nil), key...), Value: value})
+		return true
+	})
+	tree := New[T](t.opts...)
+	tree.InsertMany(entries)
+	t.frozen = tree.Freeze()
+	t.mem = New[tieredEntry[T]](t.opts...)
+}
+
+// all returns an iterator over the keys that start with prefix and their
+// values in ascending key order. The keys are copies.
+func (f *Frozen[T]) all(prefix []byte) iter.Seq2[[]byte, T] {
+	return func(yield func(key []byte, value T) bool) {
+		if n, key := f.seek(prefix, nil); n != nil {
+			f.walk(n, key, -1, func(key []byte, n *frozenNode) bool {
+				return yield(append([]byte(nil), key...), f.values[n.value-1])
+			})
+		}
+	}
+}
+
+// MappedTiered is a tiered tree like Tiered whose frozen tier is a Mapped tree
+// in a file, for data sets larger than memory: only the memory tier and the
+// pages of the file that lookups touch take memory. Values are encoded when
+// they are written to the file and decoded when they are read from it.
+// Compact streams the merge of the two tiers to a new file, holding only the
+// nodes on the path of the last key written, and replaces the file with it.
+// A MappedTiered tree is not safe for concurrent use.
+type MappedTiered[T any] struct {
+	path   string
+	mem    *RadixTree[tieredEntry[T]]
+	frozen *Mapped[T] // nil if the file does not exist
+	encode func(value T) ([]byte, error)
+	decode func(b []byte) (T, error)
+	opts   []Option
+	size   int
+}
+
+// OpenTiered returns a MappedTiered tree whose frozen tier is the file at
+// path, or empty if the file does not exist, and whose memory tier is
+// configured with the given options. Values are encoded with encode and
+// decoded with decode. The file must have been written by Compact, or by
+// WriteMapped from a tree ordering keys as the options do.
+func OpenTiered[T any](path string, encode func(value T) ([]byte, error), decode func(b []byte) (T, error), opts ...Option) (*MappedTiered[T], error) {
+	t := &MappedTiered[T]{
+		path:   path,
+		mem:    New[tieredEntry[T]](opts...),
+		encode: encode,
+		decode: decode,
+		opts:   opts,
+	}
+	frozen, err := OpenMapped(path, decode)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.frozen = frozen
+	t.size = frozen.Len()
+	return t, nil
+}
+
+// Close closes the file of the frozen tier. The tree must not be used after
+// Close, and the keys of the memory tier that were not compacted are lost.
+func (t *MappedTiered[T]) Close() error {
+	if t.frozen == nil {
+		return nil
+	}
+	return t.frozen.Close()
+}
+
+// Get returns the value of key and true, or the zero value for type T and
+// false if the key is not in the tree. The error is that of reading or
+// decoding a value of the frozen tier.
+func (t *MappedTiered[T]) Get(key []byte) (T, bool, error) {
+	var zero T
+	if e, ok := t.mem.Get(key); ok {
+		if e.deleted {
+			return zero, false, nil
+		}
+		return e.value, true, nil
+	}
+	if t.frozen == nil {
+		return zero, false, nil
+	}
+	return t.frozen.Get(key)
+}
+
+// Insert sets the value of key in the memory tier. The error is that of
+// reading the frozen tier or of exceeding a limit of the options.
+func (t *MappedTiered[T]) Insert(key []byte, value T) error {
+	e, ok := t.mem.Get(key)
+	found := ok && !e.deleted
+	if !ok {
+		var err error
+		if found, err = t.frozenContains(key); err != nil {
+			return err
+		}
+	}
+	if _, _, err := t.mem.InsertErr(key, tieredEntry[T]{value: value}); err != nil {
+		return err
+	}
+	if !found {
+		t.size++
+	}
+	return nil
+}
+
+// Remove removes key and returns true, or false if the key was not in the
+// tree. A key of the frozen tier is removed by a tombstone in the memory tier
+// until the next Compact.
+func (t *MappedTiered[T]) Remove(key []byte) (bool, error) {
+	e, ok := t.mem.Get(key)
+	if ok && e.deleted {
+		return false, nil
+	}
+	frozen, err := t.frozenContains(key)
+	if err != nil || !ok && !frozen {
+		return false, err
+	}
+	if frozen {
+		if _, _, err := t.mem.InsertErr(key, tieredEntry[T]{deleted: true}); err != nil {
+			return false, err
+		}
+	} else {
+		t.mem.Remove(key)
+	}
+	t.size--
+	return true, nil
+}
+
+// frozenContains returns true if key is in the frozen tier.
+func (t *MappedTiered[T]) frozenContains(key []byte) (bool, error) {
+	if t.frozen == nil {
+		return false, nil
+	}
+	return t.frozen.contains(key)
+}
+
+// Len returns the number of keys in the tree.
+func (t *MappedTiered[T]) Len() int {
+	return t.size
+}
+
+// MemLen returns the number of keys held by the memory tier, including the
+// tombstones of removed keys, by which a caller can decide when to Compact.
+func (t *MappedTiered[T]) MemLen() int {
+	return t.mem.Len()
+}
+
+// Walk is like the method of Tiered. If the file of the frozen tier is corrupt
+// or a value cannot be decoded Walk stops and returns the error.
+func (t *MappedTiered[T]) Walk(prefix []byte, f func(key []byte, value T) bool) error {
+	var err error
+	walkTiers(t.mem, t.frozenAll(prefix, &err), prefix, func(key, b []byte) bool {
+		var v T
+		if v, err = t.decode(b); err != nil {
+			return false
+		}
+		return f(key, v)
+	}, f)
+	return err
+}
+
+// frozenAll returns an iterator over the keys with the given prefix of the
+// frozen tier and their encoded values, as Mapped.all does.
+func (t *MappedTiered[T]) frozenAll(prefix []byte, errp *error) iter.Seq2[[]byte, []byte] {
+	if t.frozen == nil {
+		return func(func(key, value []byte) bool) {}
+	}
+	return t.frozen.all(prefix, errp)
+}
+
+// Compact writes every key of the tree to a new file, which then replaces the
+// file of the frozen tier, and empties the memory tier. The values of the
+// frozen tier are copied without decoding them. The new file is written next
+// to the old one and renamed over it, so if Compact fails the tree and its
+// file are unchanged.
+func (t *MappedTiered[T]) Compact() error {
+	f, err := os.CreateTemp(filepath.Dir(t.path), filepath.Base(t.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	err = t.write(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), t.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	frozen, err := OpenMapped(t.path, t.decode)
+	if err != nil {
+		return err
+	}
+	if t.frozen != nil {
+		t.frozen.Close()
+	}
+	t.frozen = frozen
+	t.mem = New[tieredEntry[T]](t.opts...)
+	return nil
+}
+
+// write writes the merge of the two tiers to f and syncs it.
+func (t *MappedTiered[T]) write(f *os.File) error {
+	mw, err := newMappedWriter(f)
+	if err != nil {
+		return err
+	}
+	var frozenErr error
+	walkTiers(t.mem, t.frozenAll(nil, &frozenErr), nil, func(key, b []byte) bool {
+		err = mw.add(key, b)
+		return err == nil
+	}, func(key []byte, value T) bool {
+		var b []byte
+		if b, err = t.encode(value); err == nil {
+			err = mw.add(key, b)
+		}
+		return err == nil
+	})
+	if err == nil {
+		err = frozenErr
+	}
+	if err == nil {
+		err = mw.finish()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+package radixtree
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func tieredEntries(t *testing.T, tt *Tiered[string]) map[string]string {
+	t.Helper()
+	m := map[string]string{}
+	var prev string
+	tt.Walk(nil, func(key []byte, value string) bool {
+		if len(m) > 0 && string(key) <= prev {
+			t.Errorf("Walk passed %q after %q", key, prev)
+		}
+		prev = string(key)
+		m[string(key)] = value
+		return true
+	})
+	return m
+}
+
+func TestTiered(t *testing.T) {
+	tt := NewTiered(build(words).Freeze())
+	want := map[string]string{}
+	for _, w := range words {
+		want[w] = w
+	}
+
+	tt.Insert([]byte("toadstool"), "new")
+	tt.Insert([]byte("macro"), "updated")
+	tt.Remove([]byte("wink"))
+	tt.Remove([]byte("aardvark"))
+	tt.Insert([]byte("aardvark"), "back")
+	tt.Insert([]byte("zebra"), "z")
+	tt.Remove([]byte("zebra"))
+	want["toadstool"] = "new"
+	want["macro"] = "updated"
+	want["aardvark"] = "back"
+	delete(want, "wink")
+
+	check := func(when string) {
+		t.Helper()
+		if got := tieredEntries(t, tt); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: entries\n got: %v\nwant: %v", when, got, want)
+		}
+		if tt.Len() != len(want) {
+			t.Errorf("%s: Len = %d, want %d", when, tt.Len(), len(want))
+		}
+		for _, key := range []string{"wink", "zebra", "toadstool", "macro"} {
+			v, ok := tt.Get([]byte(key))
+			if w, wok := want[key]; v != w || ok != wok {
+				t.Errorf("%s: Get(%q) = %q, %t, want %q, %t", when, key, v, ok, w, wok)
+			}
+		}
+	}
+	check("before Compact")
+	if got := tt.MemLen(); got != 4 {
+		t.Errorf("MemLen = %d, want 4", got)
+	}
+	if _, ok := tt.Frozen().Get([]byte("wink")); !ok {
+		t.Error("frozen tier lost a key before Compact")
+	}
+
+	var keys []string
+	tt.Walk([]byte("toad"), func(key []byte, _ string) bool {
+		keys = append(keys, string(key))
+		return len(keys) < 3
+	})
+	if w := []string{"toad", "toadstool", "toady"}; !reflect.DeepEqual(keys, w) {
+		t.Errorf("Walk(toad) = %q, want %q", keys, w)
+	}
+
+	old := tt.Frozen()
+	tt.Compact()
+	check("after Compact")
+	if tt.MemLen() != 0 || tt.Frozen().Len() != len(want) {
+		t.Errorf("after Compact MemLen, frozen Len = %d, %d, want 0, %d", tt.MemLen(), tt.Frozen().Len(), len(want))
+	}
+	if _, ok := old.Get([]byte("wink")); !ok {
+		t.Error("Compact changed the previous frozen tier")
+	}
+
+	if NewTiered[int](nil).Len() != 0 {
+		t.Error("NewTiered(nil) is not empty")
+	}
+}
+
+func TestMappedTiered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree")
+	open := func() *MappedTiered[string] {
+		t.Helper()
+		tt, err := OpenTiered(path, encodeString, decodeString)
+		if err != nil {
+			t.Fatalf("OpenTiered: %v", err)
+		}
+		t.Cleanup(func() { tt.Close() })
+		return tt
+	}
+	tt := open()
+	want := map[string]string{}
+	for _, w := range words {
+		if err := tt.Insert([]byte(w), w); err != nil {
+			t.Fatalf("Insert(%q): %v", w, err)
+		}
+		want[w] = w
+	}
+	if err := tt.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+
+	tt.Insert([]byte("toadstool"), "new")
+	tt.Insert([]byte("macro"), "updated")
+	tt.Remove([]byte("wink"))
+	tt.Remove([]byte("aardvark"))
+	tt.Insert([]byte("aardvark"), "back")
+	tt.Insert([]byte("zebra"), "z")
+	tt.Remove([]byte("zebra"))
+	if ok, err := tt.Remove([]byte("wink")); ok || err != nil {
+		t.Errorf("second Remove(wink) = %t, %v", ok, err)
+	}
+	want["toadstool"] = "new"
+	want["macro"] = "updated"
+	want["aardvark"] = "back"
+	delete(want, "wink")
+
+	check := func(when string, tt *MappedTiered[string]) {
+		t.Helper()
+		got := map[string]string{}
+		var prev string
+		err := tt.Walk(nil, func(key []byte, value string) bool {
+			if len(got) > 0 && string(key) <= prev {
+				t.Errorf("%s: Walk passed %q after %q", when, key, prev)
+			}
+			prev = string(key)
+			got[string(key)] = value
+			return true
+		})
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: entries, %v\n got: %v\nwant: %v", when, err, got, want)
+		}
+		if tt.Len() != len(want) {
+			t.Errorf("%s: Len = %d, want %d", when, tt.Len(), len(want))
+		}
+		for _, key := range []string{"wink", "zebra", "toadstool", "macro"} {
+			v, ok, err := tt.Get([]byte(key))
+			if w, wok := want[key]; v != w || ok != wok || err != nil {
+				t.Errorf("%s: Get(%q) = %q, %t, %v, want %q, %t", when, key, v, ok, err, w, wok)
+			}
+		}
+	}
+	check("before Compact", tt)
+	if got := tt.MemLen(); got != 4 {
+		t.Errorf("MemLen = %d, want 4", got)
+	}
+	if err := tt.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	check("after Compact", tt)
+	if tt.MemLen() != 0 {
+		t.Errorf("MemLen after Compact = %d", tt.MemLen())
+	}
+	check("after reopening", open())
+
+	// The temporary files of Compact are renamed or removed.
+	if files, _ := filepath.Glob(path + "*"); len(files) != 1 {
+		t.Errorf("files after Compact = %q", files)
+	}
+}
@@ -0,0 +1,66 @@
+package radixtree
+
+import "container/heap"
+
+// TopK returns the k largest values whose keys start with the given prefix
+// according to less, largest first. Of values that are equal the ones with the
+// smaller keys are preferred and come first. The values are kept in a heap of
+// at most k entries during the traversal so the cost is proportional to the
+// number of keys with the prefix times log k, without collecting every value.
+// If less modifies the tree TopK panics with ErrModified.
+func (t *RadixTree[T]) TopK(prefix []byte, k int, less func(a, b T) bool) []T {
+	if k <= 0 {
+		return nil
+	}
+	h := &topK[T]{less: less}
+	i := 0
+	t.Walk(prefix, func(value T) bool {
+		e := ranked[T]{value: value, i: i}
+		i++
+		switch {
+		case len(h.entries) < k:
+			heap.Push(h, e)
+		case h.smaller(h.entries[0], e):
+			h.entries[0] = e
+			heap.Fix(h, 0)
+		}
+		return true
+	})
+	values := make([]T, len(h.entries))
+	for j := len(values) - 1; j >= 0; j-- {
+		values[j] = heap.Pop(h).(ranked[T]).value
+	}
+	return values
+}
+
+// ranked is a value considered by TopK and its position in key order.
+type ranked[T any] struct {
+	value T
+	i     int
+}
+
+// topK is a heap of ranked values whose root is the smallest.
+type topK[T any] struct {
+	entries []ranked[T]
+	less    func(a, b T) bool
+}
+
+// smaller returns true if a ranks below b: it is less, or equal with a larger
+// key.
+func (h *topK[T]) smaller(a, b ranked[T]) bool {
+	if h.less(a.value, b.value) {
+		return true
+	}
+	return !h.less(b.value, a.value) && a.i > b.i
+}
+
+func (h *topK[T]) Len() int           { return len(h.entries) }
+func (h *topK[T]) Less(i, j int) bool { return h.smaller(h.entries[i], h.entries[j]) }
+func (h *topK[T]) Swap(i, j int)      { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+func (h *topK[T]) Push(x any)         { h.entries = append(h.entries, x.(ranked[T])) }
+
+func (h *topK[T]) Pop() any {
+	e := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return e
+}
@@ -0,0 +1,40 @@
+package radixtree
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+	tree := New[int]()
+	for i, w := range words {
+		tree.Insert([]byte(w), len(w)*100+i%3)
+	}
+	longer := func(a, b int) bool { return a/100 < b/100 }
+
+	for _, test := range []struct {
+		prefix string
+		k      int
+	}{
+		{"", 5}, {"to", 2}, {"to", 10}, {"mac", 1}, {"x", 3}, {"", 0},
+	} {
+		// The reference keeps the values in key order and sorts them
+		// stably so that equal values keep their order.
+		want := tree.Find([]byte(test.prefix))
+		sort.SliceStable(want, func(i, j int) bool { return longer(want[j], want[i]) })
+		if len(want) > test.k {
+			want = want[:test.k]
+		}
+		got := tree.TopK([]byte(test.prefix), test.k, longer)
+		if len(want) == 0 {
+			want = nil
+		}
+		if len(got) == 0 {
+			got = nil
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("TopK(%q, %d)\n got: %v\nwant: %v", test.prefix, test.k, got, want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package radixtree
+
+// Tree is the interface shared by RadixTree, Frozen and SyncMap, so that an
+// application can choose among a mutable tree, a frozen snapshot and a map
+// safe for concurrent use by configuration and hold the result as a Tree. The
+// methods behave as documented on RadixTree, except that the mutation methods
+// of a Frozen tree always return ErrFrozen, and that the traversals of a
+// SyncMap do not see a consistent snapshot of the map and never panic with
+// ErrModified. The mutation methods are those of the three types that return
+// errors rather than panicking, so that a caller can tell a frozen tree, or a
+// limit set by an option such as WithMaxEntries, from success.
+type Tree[T any] interface {
+	Reader[T]
+	// InsertErr sets the value of key and returns the previous value and
+	// true, or false if the key was not in the tree, or an error if the key
+	// could not be inserted.
+	InsertErr(key []byte, value T) (T, bool, error)
+	// InsertManyErr sets the value of every entry's key and returns the
+	// number of keys that were not in the tree, or an error if the entries
+	// could not be inserted.
+	InsertManyErr(entries []Entry[T]) (int, error)
+	// RemoveErr removes key and returns its value, or ErrNotFound if the key
+	// is not in the tree, or another error if it could not be removed.
+	RemoveErr(key []byte) (T, error)
+	// RemoveManyErr removes the given keys and returns the number of keys
+	// that were removed, or an error if they could not be removed.
+	RemoveManyErr(keys [][]byte) (int, error)
+}
+
+var (
+	_ Tree[int] = (*RadixTree[int])(nil)
+	_ Tree[int] = (*Frozen[int])(nil)
+	_ Tree[int] = (*SyncMap[int])(nil)
+)
@@ -0,0 +1,74 @@
+package radixtree
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	for name, newTree := range map[string]func() Tree[string]{
+		"RadixTree": func() Tree[string] { return New[string]() },
+		"SyncMap":   func() Tree[string] { return NewSyncMap[string]() },
+	} {
+		tree := newTree()
+		if _, ok := tree.Min(); ok || tree.HasPrefix(nil) {
+			t.Errorf("%s: empty tree has a key", name)
+		}
+		for _, w := range []string{"", "to", "toad", "tea"} {
+			if _, ok, err := tree.InsertErr([]byte(w), w); ok || err != nil {
+				t.Errorf("%s: InsertErr(%q) = (%t, %v), want (false, nil)", name, w, ok, err)
+			}
+		}
+		if old, ok, err := tree.InsertErr([]byte("to"), "TO"); !ok || old != "to" || err != nil {
+			t.Errorf("%s: InsertErr over %q = (%q, %t, %v)", name, "to", old, ok, err)
+		}
+		if v, ok := tree.LongestPrefix([]byte("tox")); !ok || v != "TO" {
+			t.Errorf("%s: LongestPrefix(%q) = (%q, %t), want (%q, true)", name, "tox", v, ok, "TO")
+		}
+		if v, ok := tree.LongestPrefix([]byte("x")); !ok || v != "" {
+			t.Errorf("%s: LongestPrefix(%q) = (%q, %t), want the empty key", name, "x", v, ok)
+		}
+		if v, ok := tree.Max(); !ok || v != "toad" {
+			t.Errorf("%s: Max = (%q, %t), want (%q, true)", name, v, ok, "toad")
+		}
+		if v, err := tree.RemoveErr([]byte("tea")); v != "tea" || err != nil {
+			t.Errorf("%s: RemoveErr(%q) = (%q, %v)", name, "tea", v, err)
+		}
+		if _, err := tree.RemoveErr([]byte("tea")); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: RemoveErr of a missing key returned %v, want %v", name, err, ErrNotFound)
+		}
+		if tree.Len() != 3 {
+			t.Errorf("%s: Len = %d, want 3", name, tree.Len())
+		}
+		entries := []Entry[string]{{Key: []byte("tea"), Value: "tea"}, {Key: []byte("a"), Value: "a"}, {Key: []byte("to"), Value: "to"}}
+		if n, err := tree.InsertManyErr(entries); n != 2 || err != nil {
+			t.Errorf("%s: InsertManyErr = (%d, %v), want (2, nil)", name, n, err)
+		}
+		if v, _ := tree.Get([]byte("to")); v != "to" || tree.Len() != 5 {
+			t.Errorf("%s: Get(%q) = %q and Len = %d after InsertManyErr", name, "to", v, tree.Len())
+		}
+		if n, err := tree.RemoveManyErr([][]byte{[]byte("a"), []byte("b"), []byte("toad")}); n != 2 || err != nil {
+			t.Errorf("%s: RemoveManyErr = (%d, %v), want (2, nil)", name, n, err)
+		}
+		if tree.Len() != 3 || tree.Contains([]byte("toad")) {
+			t.Errorf("%s: Len = %d after RemoveManyErr, want 3", name, tree.Len())
+		}
+	}
+
+	var f Tree[string] = build(words).Freeze()
+	if _, _, err := f.InsertErr([]byte("a"), "a"); err != ErrFrozen {
+		t.Errorf("Frozen: InsertErr returned %v, want %v", err, ErrFrozen)
+	}
+	if _, err := f.RemoveErr([]byte("toad")); err != ErrFrozen {
+		t.Errorf("Frozen: RemoveErr returned %v, want %v", err, ErrFrozen)
+	}
+	if _, err := f.InsertManyErr([]Entry[string]{{Key: []byte("a")}}); err != ErrFrozen {
+		t.Errorf("Frozen: InsertManyErr returned %v, want %v", err, ErrFrozen)
+	}
+	if _, err := f.RemoveManyErr([][]byte{[]byte("toad")}); err != ErrFrozen {
+		t.Errorf("Frozen: RemoveManyErr returned %v, want %v", err, ErrFrozen)
+	}
+	if f.Len() != len(words) {
+		t.Errorf("Frozen: Len = %d after failed mutations, want %d", f.Len(), len(words))
+	}
+}
@@ -0,0 +1,243 @@
+// Package treetest is a conformance suite for implementations of an ordered
+// byte-keyed map, such as RadixTree, the wrappers built around it and the
+// read-only forms made from it, so that every implementation is held to the
+// same expectations of ordering, prefix queries and iteration:
+//
+//	func TestConformance(t *testing.T) {
+//		treetest.Run(t, func() treetest.Tree { return newTree() })
+//	}
+//
+// The keys passed to the iterators of an implementation must be copies that
+// the caller may retain, so trees created with WithSharedKeys do not conform.
+package treetest
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+	"math/rand"
+	"testing"
+
+	"github.com/jhm/go-radixtree/v2/testutil"
+)
+
+// Tree is a mutable implementation, with values of type int.
+type Tree = testutil.Tree[int]
+
+// Reader is the part of Tree that read-only implementations provide.
+type Reader interface {
+	Get(key []byte) (int, bool)
+	Len() int
+	AllPrefix(prefix []byte) iter.Seq2[[]byte, int]
+}
+
+// DepthWalker is implemented by the trees that can limit a traversal to the keys
+// at most depth bytes longer than a prefix, as RadixTree.WalkDepth does.
+// RunReader checks the traversals of the trees that implement it.
+type DepthWalker interface {
+	WalkDepth(prefix []byte, depth int, f func(key []byte, value int) bool)
+}
+
+// Keys is the set of keys the suite stores. They include the empty key, keys
+// that are prefixes of each other, keys that diverge right after a shared
+// prefix and keys with the bytes 0x00 and 0xff, which order at the ends.
+var Keys = []string{
+	"", "\x00", "a", "a\x00", "ab", "abc", "abcd", "abd", "abd\xff", "b",
+	"ba", "bab", "babble", "babbles", "bb", "toad", "toady", "\xff", "\xff\xff",
+}
+
+// Run checks a mutable implementation, calling newTree for an empty tree at the
+// start of each test.
+func Run(t *testing.T, newTree func() Tree) {
+	t.Run("Reader", func(t *testing.T) {
+		RunReader(t, func(keys []string) Reader {
+			tree := newTree()
+			for i, k := range keys {
+				tree.Insert([]byte(k), i)
+			}
+			return tree
+		})
+	})
+
+	t.Run("SplitMerge", func(t *testing.T) {
+		// Each step inserts or removes a key that splits an edge, adds a
+		// branch or leaves a node with a single child to be merged.
+		steps := []struct {
+			key    string
+			remove bool
+		}{
+			{key: "abcd"}, {key: "ab"}, {key: "abx"}, {key: "a"}, {key: ""},
+			{key: "ab", remove: true}, {key: "abx", remove: true}, {key: "abc"},
+			{key: "abcd", remove: true}, {key: "", remove: true}, {key: "a", remove: true},
+			{key: "abc", remove: true}, {key: "abc", remove: true}, {key: "abcd"},
+		}
+		tree, m := newTree(), &testutil.Model[int]{}
+		for i, s := range steps {
+			op := testutil.Op[int]{Kind: testutil.Insert, Key: []byte(s.key), Value: i}
+			if s.remove {
+				op.Kind = testutil.Remove
+			}
+			if err := testutil.Apply(tree, m, op); err != nil {
+				t.Fatalf("step %d: %v", i, err)
+			}
+			if err := testutil.Check(tree, m); err != nil {
+				t.Fatalf("after step %d, %v: %v", i, op, err)
+			}
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		tree := newTree()
+		for i, k := range Keys {
+			tree.Insert([]byte(k), i)
+		}
+		for i, k := range Keys {
+			if old, ok := tree.Insert([]byte(k), -i); !ok || old != i {
+				t.Errorf("Insert(%q) over %d returned %d, %t", k, i, old, ok)
+			}
+		}
+		if tree.Len() != len(Keys) {
+			t.Errorf("Len = %d after updating every key, want %d", tree.Len(), len(Keys))
+		}
+		for i, k := range Keys {
+			if v, ok := tree.Remove([]byte(k)); !ok || v != -i {
+				t.Errorf("Remove(%q) = %d, %t, want %d, true", k, v, ok, -i)
+			}
+		}
+		if tree.Len() != 0 {
+			t.Errorf("Len = %d after removing every key", tree.Len())
+		}
+		for range tree.AllPrefix(nil) {
+			t.Fatal("AllPrefix yielded a key of an empty tree")
+		}
+	})
+
+	t.Run("Random", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 20; i++ {
+			data := make([]byte, 400)
+			rng.Read(data)
+			testutil.Run(t, newTree(), testutil.Decode(data))
+		}
+	})
+}
+
+// RunReader checks a read-only implementation, calling build for a tree that
+// holds the given keys, each with its index in the slice as its value.
+func RunReader(t *testing.T, build func(keys []string) Reader) {
+	m := &testutil.Model[int]{}
+	for i, k := range Keys {
+		m.Insert([]byte(k), i)
+	}
+	r := build(Keys)
+
+	t.Run("Get", func(t *testing.T) {
+		if r.Len() != len(Keys) {
+			t.Errorf("Len = %d, want %d", r.Len(), len(Keys))
+		}
+		for i, k := range Keys {
+			if v, ok := r.Get([]byte(k)); !ok || v != i {
+				t.Errorf("Get(%q) = %d, %t, want %d, true", k, v, ok, i)
+			}
+		}
+		for _, k := range []string{"abcde", "ab\x00", "ac", "aa", "babbl", "c", "to", "\xff\xfe", "\x00\x00"} {
+			if v, ok := r.Get([]byte(k)); ok {
+				t.Errorf("Get(%q) = %d, true for a key that is not in the tree", k, v)
+			}
+		}
+	})
+
+	t.Run("Ordering", func(t *testing.T) {
+		if err := equal(r, m, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Prefixes", func(t *testing.T) {
+		// The prefixes end at a key, inside an edge, past every key and
+		// on a byte that no key has at that position.
+		for _, p := range []string{"", "a", "ab", "abc", "abcd", "abcde", "ba", "babb", "t", "toad", "to", "c", "\xff", "a\x00"} {
+			if err := equal(r, m, []byte(p)); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	t.Run("WalkDepth", func(t *testing.T) {
+		w, ok := r.(DepthWalker)
+		if !ok {
+			t.Skip("the tree does not implement DepthWalker")
+		}
+		// The prefixes include ones that end inside an edge, so that the
+		// node found for them is already deeper than the prefix.
+		for _, p := range []string{"", "a", "ab", "abd", "babb", "bab", "to", "t", "c"} {
+			for depth := -1; depth <= 3; depth++ {
+				var got, want []string
+				w.WalkDepth([]byte(p), depth, func(key []byte, value int) bool {
+					got = append(got, fmt.Sprintf("%q=%d", key, value))
+					return true
+				})
+				for k, v := range m.AllPrefix([]byte(p)) {
+					if len(k)-len(p) <= depth {
+						want = append(want, fmt.Sprintf("%q=%d", k, v))
+					}
+				}
+				if fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Errorf("WalkDepth(%q, %d)\n got: %v\nwant: %v", p, depth, got, want)
+				}
+			}
+		}
+	})
+
+	t.Run("Iterator", func(t *testing.T) {
+		// Breaking out of a range stops the iteration.
+		n := 0
+		for range r.AllPrefix([]byte("ab")) {
+			if n++; n == 2 {
+				break
+			}
+		}
+		if n != 2 {
+			t.Errorf("ranged over %d keys before breaking, want 2", n)
+		}
+
+		// The keys can be retained and iteration can start again.
+		var keys [][]byte
+		for k := range r.AllPrefix(nil) {
+			keys = append(keys, k)
+		}
+		for i, k := range keys {
+			if string(k) != Keys[i] {
+				t.Errorf("retained key %d is %q, want %q", i, k, Keys[i])
+			}
+		}
+		if err := equal(r, m, nil); err != nil {
+			t.Errorf("second iteration: %v", err)
+		}
+	})
+}
+
+// equal returns an error describing the difference if the keys of r that
+// start with prefix and their values are not those of m, in the same order.
+func equal(r Reader, m *testutil.Model[int], prefix []byte) error {
+	type entry struct {
+		key   string
+		value int
+	}
+	var got, want []entry
+	var prev []byte
+	for k, v := range r.AllPrefix(prefix) {
+		if len(got) > 0 && bytes.Compare(k, prev) <= 0 {
+			return fmt.Errorf("AllPrefix(%q) yielded %q after %q", prefix, k, prev)
+		}
+		prev = k
+		got = append(got, entry{string(k), v})
+	}
+	for k, v := range m.AllPrefix(prefix) {
+		want = append(want, entry{string(k), v})
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		return fmt.Errorf("AllPrefix(%q)\n got: %q\nwant: %q", prefix, got, want)
+	}
+	return nil
+}
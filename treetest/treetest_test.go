@@ -0,0 +1,108 @@
+package treetest_test
+
+import (
+	"iter"
+	"testing"
+
+	radixtree "github.com/jhm/go-radixtree/v2"
+	"github.com/jhm/go-radixtree/v2/treetest"
+)
+
+func TestRadixTree(t *testing.T) {
+	for name, opts := range map[string][]radixtree.Option{
+		"Default":       nil,
+		"LookupCache":   {radixtree.WithLookupCache(16)},
+		"BloomFilter":   {radixtree.WithBloomFilter(64, 0.01)},
+		"DeferredMerge": {radixtree.WithDeferredMerge()},
+		"LazyDelete":    {radixtree.WithLazyDelete()},
+		"KeyCopy":       {radixtree.WithKeyCopy()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			treetest.Run(t, func() treetest.Tree { return radixtree.New[int](opts...) })
+		})
+	}
+}
+
+func TestTiered(t *testing.T) {
+	treetest.Run(t, func() treetest.Tree { return tiered{radixtree.NewTiered[int](nil)} })
+	treetest.RunReader(t, func(keys []string) treetest.Reader {
+		// Half of the keys are in the frozen tier, and one of them is
+		// overwritten and another removed and inserted again.
+		tree := radixtree.NewTiered[int](nil)
+		for i, k := range keys[:len(keys)/2] {
+			tree.Insert([]byte(k), i)
+		}
+		tree.Insert([]byte(keys[1]), -1)
+		tree.Compact()
+		tree.Insert([]byte(keys[1]), 1)
+		tree.Remove([]byte(keys[2]))
+		tree.Insert([]byte(keys[2]), 2)
+		for i, k := range keys[len(keys)/2:] {
+			tree.Insert([]byte(k), len(keys)/2+i)
+		}
+		return tiered{tree}
+	})
+}
+
+func TestFrozen(t *testing.T) {
+	treetest.RunReader(t, func(keys []string) treetest.Reader {
+		tree := radixtree.New[int]()
+		for i, k := range keys {
+			tree.Insert([]byte(k), i)
+		}
+		return frozen{tree.Freeze()}
+	})
+}
+
+func TestConcurrentTree(t *testing.T) {
+	treetest.Run(t, func() treetest.Tree { return radixtree.NewConcurrentTree[int]() })
+}
+
+func TestSyncMap(t *testing.T) {
+	treetest.Run(t, func() treetest.Tree { return syncMap{&radixtree.SyncMap[int]{}} })
+}
+
+// tiered adds AllPrefix to a Tiered tree.
+type tiered struct {
+	*radixtree.Tiered[int]
+}
+
+func (t tiered) AllPrefix(prefix []byte) iter.Seq2[[]byte, int] {
+	return func(yield func([]byte, int) bool) { t.Walk(prefix, yield) }
+}
+
+// frozen adds AllPrefix to a Frozen tree.
+type frozen struct {
+	*radixtree.Frozen[int]
+}
+
+func (f frozen) AllPrefix(prefix []byte) iter.Seq2[[]byte, int] {
+	return func(yield func([]byte, int) bool) {
+		f.WalkKeys(prefix, func(key []byte) bool {
+			v, _ := f.Get(key)
+			return yield(key, v)
+		})
+	}
+}
+
+// syncMap presents a SyncMap as a Tree.
+type syncMap struct {
+	m *radixtree.SyncMap[int]
+}
+
+func (s syncMap) Insert(key []byte, value int) (int, bool) { return s.m.Swap(string(key), value) }
+func (s syncMap) Get(key []byte) (int, bool)               { return s.m.Load(string(key)) }
+func (s syncMap) Remove(key []byte) (int, bool)            { return s.m.LoadAndDelete(string(key)) }
+func (s syncMap) Len() int                                 { return s.m.Len() }
+
+func (s syncMap) WalkDepth(prefix []byte, depth int, f func([]byte, int) bool) {
+	s.m.WalkDepth(prefix, depth, f)
+}
+
+func (s syncMap) AllPrefix(prefix []byte) iter.Seq2[[]byte, int] {
+	return func(yield func([]byte, int) bool) {
+		s.m.RangePrefix(string(prefix), func(key string, value int) bool {
+			return yield([]byte(key), value)
+		})
+	}
+}
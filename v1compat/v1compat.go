@@ -0,0 +1,117 @@
+// Package v1compat provides the non-generic API of version 1 of the radixtree
+// package, with values of type interface{}, implemented over the generic tree
+// of version 2. Callers can switch their imports to this package when
+// upgrading the module and then move to the generic API at their own pace,
+// using Generic to reach the underlying tree.
+package v1compat
+
+import radixtree "github.com/jhm/go-radixtree/v2"
+
+// RadixTree implements a mutable radix tree.
+type RadixTree struct {
+	t *radixtree.RadixTree[interface{}]
+}
+
+// New creates and returns an empty radix tree.
+func New() *RadixTree {
+	return &RadixTree{t: radixtree.New[interface{}]()}
+}
+
+// Generic returns the generic tree that holds the entries of t. Changes made
+// through either tree are visible through the other.
+func (t *RadixTree) Generic() *radixtree.RadixTree[interface{}] {
+	return t.t
+}
+
+// Contains returns true if key is in the tree, false otherwise.
+func (t *RadixTree) Contains(key []byte) bool {
+	return t.t.Contains(key)
+}
+
+// Find returns a slice that contains all of the values that have a key that
+// starts with the given prefix. The slice will be ordered in ascending key
+// order.
+func (t *RadixTree) Find(prefix []byte) []interface{} {
+	return t.t.Find(prefix)
+}
+
+// Get returns the value associated with the given key. If the key is found in
+// the tree it returns the associated value and a boolean value of true
+// indicating that a value was found. If the key is not in the tree it returns
+// nil and a false boolean value.
+func (t *RadixTree) Get(key []byte) (interface{}, bool) {
+	return t.t.Get(key)
+}
+
+// Insert adds the value to the radix tree with the given key. If the exact key
+// already exists in the radix tree it updates the value and returns the old
+// value and a boolean value of true indicating that an old value was found. If
+// the key was not in the tree it returns nil and a false boolean value.
+func (t *RadixTree) Insert(key []byte, value interface{}) (interface{}, bool) {
+	return t.t.Insert(key, value)
+}
+
+// Len returns the number of values in the tree.
+func (t *RadixTree) Len() int {
+	return t.t.Len()
+}
+
+// LongestPrefix returns the value associated with the key that has the longest
+// prefix of the given key. If a value is found it returns the value and a
+// boolean value of true. If no value is found it returns nil and a boolean
+// value of false.
+func (t *RadixTree) LongestPrefix(key []byte) (interface{}, bool) {
+	return t.t.LongestPrefix(key)
+}
+
+// Max returns the value associated with the largest key in the tree. The
+// boolean return value will be true if a maximum value was found and false if
+// the tree is empty and therefore has no maximum value.
+func (t *RadixTree) Max() (interface{}, bool) {
+	return t.t.Max()
+}
+
+// Min returns the value associated with the smallest key in the tree. The
+// boolean return value will be true if a minimum value was found and false if
+// the tree is empty and therefore has no minimum value.
+func (t *RadixTree) Min() (interface{}, bool) {
+	return t.t.Min()
+}
+
+// Predecessor returns the value that is associated with the key that
+// immediately precedes the given key. If a predecessor is found, its value and
+// a boolean value of true will returned. If there is no predecessor, or the
+// given key does not exist in the tree, nil and a boolean value of false will
+// be returned.
+func (t *RadixTree) Predecessor(key []byte) (interface{}, bool) {
+	return t.t.Predecessor(key)
+}
+
+// Remove removes the key and its associated value from the tree and returns the
+// old value and a boolean value of true indicating that the given key was
+// found. If the key was not present in the tree it will return nil and a
+// boolean value of false.
+func (t *RadixTree) Remove(key []byte) (interface{}, bool) {
+	return t.t.Remove(key)
+}
+
+// Successor returns the value that is associated with the key that immediately
+// follows the given key. If a successor is found, its value and a boolean value
+// of true will be returned. If there is no successor, or the given key does not
+// exist in the tree, nil and a boolean value of false will be returned.
+func (t *RadixTree) Successor(key []byte) (interface{}, bool) {
+	return t.t.Successor(key)
+}
+
+// Values returns all of the values in the tree in the ascending order of their
+// keys.
+func (t *RadixTree) Values() []interface{} {
+	return t.t.Values()
+}
+
+// Walk traverses the tree rooted at the given prefix and executes function f
+// for each value. If f returns true the traversal continues otherwise the
+// traversal stops.
+func (t *RadixTree) Walk(prefix []byte, f func(value interface{}) bool) {
+	t.t.Walk(prefix, f)
+}
@@ -0,0 +1,65 @@
+package v1compat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRadixTree(t *testing.T) {
+	tree := New()
+	for i, key := range []string{"apple", "applesauce", "banana", "cherry"} {
+		if _, ok := tree.Insert([]byte(key), i); ok {
+			t.Errorf("Insert(%q) replaced a value", key)
+		}
+	}
+	if old, ok := tree.Insert([]byte("banana"), "yellow"); !ok || old != 2 {
+		t.Errorf("Insert = %v, %v, want 2, true", old, ok)
+	}
+
+	if v, ok := tree.Get([]byte("banana")); !ok || v != "yellow" {
+		t.Errorf("Get = %v, %v, want yellow, true", v, ok)
+	}
+	if v, ok := tree.Get([]byte("durian")); ok || v != nil {
+		t.Errorf("Get of a missing key = %v, %v, want nil, false", v, ok)
+	}
+	if !tree.Contains([]byte("apple")) || tree.Contains([]byte("app")) {
+		t.Error("Contains is wrong")
+	}
+	if got, want := tree.Find([]byte("apple")), []interface{}{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Find = %v, want %v", got, want)
+	}
+	if got, want := tree.Values(), []interface{}{0, 1, "yellow", 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values = %v, want %v", got, want)
+	}
+	if v, ok := tree.LongestPrefix([]byte("applesauce!")); !ok || v != 1 {
+		t.Errorf("LongestPrefix = %v, %v, want 1, true", v, ok)
+	}
+	if v, _ := tree.Min(); v != 0 {
+		t.Errorf("Min = %v, want 0", v)
+	}
+	if v, _ := tree.Max(); v != 3 {
+		t.Errorf("Max = %v, want 3", v)
+	}
+	if v, _ := tree.Predecessor([]byte("banana")); v != 1 {
+		t.Errorf("Predecessor = %v, want 1", v)
+	}
+	if v, _ := tree.Successor([]byte("banana")); v != 3 {
+		t.Errorf("Successor = %v, want 3", v)
+	}
+
+	var walked []interface{}
+	tree.Walk([]byte("a"), func(v interface{}) bool {
+		walked = append(walked, v)
+		return false
+	})
+	if !reflect.DeepEqual(walked, []interface{}{0}) {
+		t.Errorf("Walk visited %v, want only the first value", walked)
+	}
+
+	if v, ok := tree.Remove([]byte("apple")); !ok || v != 0 {
+		t.Errorf("Remove = %v, %v, want 0, true", v, ok)
+	}
+	if tree.Len() != 3 || tree.Generic().Len() != 3 {
+		t.Errorf("Len = %d, want 3", tree.Len())
+	}
+}
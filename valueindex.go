@@ -0,0 +1,184 @@
+package radixtree
+
+import (
+	"cmp"
+	"container/heap"
+)
+
+// valueIndex is the aggregator of a tree created with WithValueIndex, whose
+// aggregates are the ranges of the values in each subtree.
+type valueIndex[T any, V cmp.Ordered] struct {
+	extract func(v T) V
+}
+
+// valueRange is the least and greatest value in a subtree, if ok is true.
+type valueRange[V cmp.Ordered] struct {
+	min, max V
+	ok       bool
+}
+
+// valueWalker is implemented by value indexes for the methods of RadixTree,
+// which do not know the type of the values they are ordered by.
+type valueWalker[T any] interface {
+	walk(n *node[T], key []byte, descending bool, compare func(a, b []byte) int, f func(key []byte, n *node[T]) bool)
+}
+
+func (x *valueIndex[T, V]) value(v T) any {
+	e := x.extract(v)
+	return valueRange[V]{min: e, max: e, ok: true}
+}
+
+func (x *valueIndex[T, V]) aggregate(n *node[T]) any {
+	var r valueRange[V]
+	if n.hasValue() {
+		e := x.extract(n.leaf.value)
+		r = valueRange[V]{min: e, max: e, ok: true}
+	}
+	for _, child := range n.children.nodes {
+		c := child.agg.(valueRange[V])
+		switch {
+		case !c.ok:
+		case !r.ok:
+			r = c
+		default:
+			if cmp.Less(c.min, r.min) {
+				r.min = c.min
+			}
+			if cmp.Less(r.max, c.max) {
+				r.max = c.max
+			}
+		}
+	}
+	return r
+}
+
+// walk calls f for the entries in the subtree rooted at n, whose key is key,
+// in ascending or descending order of their values until f returns false. The
+// subtrees are kept in a heap by the least or greatest value they hold, so
+// only the subtrees that hold the values visited so far, and their siblings,
+// are expanded.
+func (x *valueIndex[T, V]) walk(n *node[T], key []byte, descending bool, compare func(a, b []byte) int, f func(key []byte, n *node[T]) bool) {
+	h := &byValue[T, V]{descending: descending, compare: compare}
+	h.push(n, key)
+	for len(h.items) > 0 {
+		it := heap.Pop(h).(valued[T, V])
+		if it.leaf {
+			if !f(it.key, it.n) {
+				return
+			}
+			continue
+		}
+		if it.n.hasValue() {
+			heap.Push(h, valued[T, V]{n: it.n, key: it.key, v: x.extract(it.n.leaf.value), leaf: true})
+		}
+		for _, child := range it.n.children.nodes {
+			h.push(child, append(it.key[:len(it.key):len(it.key)], child.prefix...))
+		}
+	}
+}
+
+// valued is a subtree, or the value of its root if leaf is true, with the
+// full key of its root and the value it is ordered by.
+type valued[T any, V cmp.Ordered] struct {
+	n    *node[T]
+	key  []byte
+	v    V
+	leaf bool
+}
+
+// byValue is a heap of subtrees and values whose root is the next in the
+// order of a walk. Of equal values the one with the smaller key comes first
+// and a value comes before the subtree below it, whose keys are all greater
+// than that of its root.
+type byValue[T any, V cmp.Ordered] struct {
+	items      []valued[T, V]
+	descending bool
+	compare    func(a, b []byte) int
+}
+
+// push adds the subtree rooted at n, if it holds any values.
+func (h *byValue[T, V]) push(n *node[T], key []byte) {
+	r := n.agg.(valueRange[V])
+	if !r.ok {
+		return
+	}
+	v := r.min
+	if h.descending {
+		v = r.max
+	}
+	heap.Push(h, valued[T, V]{n: n, key: key, v: v})
+}
+
+func (h *byValue[T, V]) Len() int { return len(h.items) }
+
+func (h *byValue[T, V]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if c := cmp.Compare(a.v, b.v); c != 0 {
+		return c < 0 != h.descending
+	}
+	if c := h.compare(a.key, b.key); c != 0 {
+		return c < 0
+	}
+	return a.leaf && !b.leaf
+}
+
+func (h *byValue[T, V]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *byValue[T, V]) Push(x any)    { h.items = append(h.items, x.(valued[T, V])) }
+
+func (h *byValue[T, V]) Pop() any {
+	it := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return it
+}
+
+// index returns the value index of the tree, panicking if it has none.
+func (t *RadixTree[T]) index() valueWalker[T] {
+	x, ok := t.agg.(valueWalker[T])
+	if !ok {
+		panic("radixtree: tree was not created with WithValueIndex")
+	}
+	return x
+}
+
+// WalkByValue executes f for every entry whose key starts with the given
+// prefix in ascending order of their values, or descending order if
+// descending is true, as given by the function passed to WithValueIndex.
+// Entries with equal values are visited in ascending order of their keys. If f
+// returns false WalkByValue stops. The cost of visiting the first k entries is
+// proportional to k times the length of their keys and the log of the number
+// of subtrees considered, rather than to the number of entries with the
+// prefix. If f modifies the tree WalkByValue panics with ErrModified. It
+// panics if the tree was not created with WithValueIndex.
+func (t *RadixTree[T]) WalkByValue(prefix []byte, descending bool, f func(key []byte, value T) bool) {
+	x := t.index()
+	n, key := t.findPath(prefix, nil)
+	if n == nil {
+		return
+	}
+	gen := t.gen
+	x.walk(n, key, descending, t.opts.order.compare, func(key []byte, n *node[T]) bool {
+		return f(t.yield(key), n.leaf.value) && t.unmodified(gen)
+	})
+}
+
+// MinByValue returns the key and value of the entry with the least value whose
+// key starts with the given prefix, as ordered by WalkByValue. The boolean
+// return value is false if no key starts with the prefix. It panics if the
+// tree was not created with WithValueIndex.
+func (t *RadixTree[T]) MinByValue(prefix []byte) ([]byte, T, bool) {
+	return t.firstByValue(prefix, false)
+}
+
+// MaxByValue is like MinByValue for the greatest value. Of several entries with
+// the greatest value the one with the smallest key is returned.
+func (t *RadixTree[T]) MaxByValue(prefix []byte) ([]byte, T, bool) {
+	return t.firstByValue(prefix, true)
+}
+
+func (t *RadixTree[T]) firstByValue(prefix []byte, descending bool) (key []byte, value T, ok bool) {
+	t.WalkByValue(prefix, descending, func(k []byte, v T) bool {
+		key, value, ok = k, v, true
+		return false
+	})
+	return key, value, ok
+}
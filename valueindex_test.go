@@ -0,0 +1,67 @@
+package radixtree
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWalkByValue(t *testing.T) {
+	tree := New[int](WithValueIndex(func(v int) int { return v }))
+	for _, key := range words {
+		tree.Insert([]byte(key), len(key)%5)
+	}
+	tree.Remove([]byte("winkleman"))
+	tree.DeleteRange([]byte("macroa"), []byte("macrob"))
+
+	for _, descending := range []bool{false, true} {
+		for _, prefix := range []string{"", "m", "wi", "toad", "zzz"} {
+			var want []string
+			for _, key := range words {
+				if tree.Contains([]byte(key)) && strings.HasPrefix(key, prefix) {
+					want = append(want, key)
+				}
+			}
+			sort.SliceStable(want, func(i, j int) bool {
+				a, b := len(want[i])%5, len(want[j])%5
+				if descending {
+					return a > b
+				}
+				return a < b
+			})
+			var got []string
+			tree.WalkByValue([]byte(prefix), descending, func(key []byte, value int) bool {
+				if value != len(key)%5 {
+					t.Errorf("WalkByValue visited %q with %d", key, value)
+				}
+				got = append(got, string(key))
+				return true
+			})
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("WalkByValue(%q, %v)\n got: %v\nwant: %v", prefix, descending, got, want)
+			}
+		}
+	}
+
+	if key, v, ok := tree.MaxByValue(nil); !ok || string(key) != "backtrack" || v != 4 {
+		t.Errorf("MaxByValue() = (%q, %d, %v), want (backtrack, 4, true)", key, v, ok)
+	}
+	tree.Insert([]byte("toadstool"), 9)
+	if key, v, ok := tree.MaxByValue([]byte("t")); !ok || string(key) != "toadstool" || v != 9 {
+		t.Errorf("MaxByValue(t) = (%q, %d, %v), want (toadstool, 9, true)", key, v, ok)
+	}
+	if key, v, ok := tree.MinByValue([]byte("w")); !ok || string(key) != "winkle" || v != 1 {
+		t.Errorf("MinByValue(w) = (%q, %d, %v), want (winkle, 1, true)", key, v, ok)
+	}
+	if _, _, ok := tree.MinByValue([]byte("x")); ok {
+		t.Error("MinByValue of a missing prefix returned true")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("WalkByValue without a value index did not panic")
+		}
+	}()
+	build(words).MinByValue(nil)
+}
@@ -0,0 +1,39 @@
+package radixtree
+
+// Visitor is implemented by types that traverse the nodes of a tree with
+// Accept, such as exporters and analyzers that need the shape of the tree
+// rather than only its entries.
+type Visitor interface {
+	// EnterNode is called for each node, before the nodes below it, with
+	// the part of the key that the node adds to the key of its parent and
+	// whether the node holds a value. The root has an empty prefix. The
+	// prefix must not be modified. If EnterNode returns false the nodes
+	// below the node are skipped.
+	EnterNode(prefix []byte, hasValue bool) bool
+	// LeaveNode is called for each node after the nodes below it, so that
+	// the calls to EnterNode and LeaveNode are nested like the nodes.
+	LeaveNode()
+}
+
+// Accept traverses the nodes of the tree depth first, calling the methods of v
+// for each of them with the children of a node in ascending key order. Nodes
+// left without any values by WithLazyDelete are skipped, except the root. If v
+// modifies the tree Accept panics with ErrModified.
+func (t *RadixTree[T]) Accept(v Visitor) {
+	gen := t.gen
+	t.accept(t.root, v, gen)
+}
+
+func (t *RadixTree[T]) accept(n *node[T], v Visitor, gen uint64) {
+	enter := v.EnterNode(n.prefix, n.hasValue())
+	t.unmodified(gen)
+	if enter {
+		for _, child := range n.children.nodes {
+			if child.count > 0 {
+				t.accept(child, v, gen)
+			}
+		}
+	}
+	v.LeaveNode()
+	t.unmodified(gen)
+}
@@ -0,0 +1,52 @@
+package radixtree
+
+import (
+	"strings"
+	"testing"
+)
+
+// outliner renders the nodes of a tree as an indented outline.
+type outliner struct {
+	b     strings.Builder
+	depth int
+	skip  string
+}
+
+func (o *outliner) EnterNode(prefix []byte, hasValue bool) bool {
+	o.b.WriteString(strings.Repeat(" ", o.depth) + string(prefix))
+	if hasValue {
+		o.b.WriteString("*")
+	}
+	o.b.WriteString("\n")
+	o.depth++
+	return o.skip == "" || string(prefix) != o.skip
+}
+
+func (o *outliner) LeaveNode() {
+	o.depth--
+}
+
+func TestAccept(t *testing.T) {
+	tree := build([]string{"to", "toa", "toad", "toady", "tea", "ten"})
+	o := &outliner{skip: "a"}
+	tree.Accept(o)
+	want := "\n t\n  e\n   a*\n   n*\n  o*\n   a*\n"
+	if got := o.b.String(); got != want {
+		t.Errorf("Accept outline\n got: %q\nwant: %q", got, want)
+	}
+	if o.depth != 0 {
+		t.Errorf("Accept left the depth at %d, want 0", o.depth)
+	}
+
+	lazy := New[string](WithLazyDelete())
+	for _, key := range []string{"a", "ab", "b"} {
+		lazy.Insert([]byte(key), key)
+	}
+	lazy.Remove([]byte("ab"))
+	lazy.Remove([]byte("b"))
+	o = &outliner{}
+	lazy.Accept(o)
+	if got, want := o.b.String(), "\n a*\n"; got != want {
+		t.Errorf("Accept outline with lazy deletes\n got: %q\nwant: %q", got, want)
+	}
+}
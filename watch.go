@@ -0,0 +1,216 @@
+package radixtree
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+)
+
+// KeyEvent is a change to a key watched with WatchKey: either its new value or
+// its removal.
+type KeyEvent[T any] struct {
+	Value   T
+	Removed bool
+}
+
+// watches holds the watches of a tree by key. The mutex lets a watch be
+// cancelled from any goroutine.
+type watches[T any] struct {
+	mu   sync.Mutex
+	keys map[string][]*keyWatch[T]
+}
+
+// keyWatch is a single watch of a key. present records whether the key was in
+// the tree after the last change, so that removing a key that is not in the
+// tree is not reported. ws is the set holding the watch, which changes when
+// Join moves it to the joined tree.
+type keyWatch[T any] struct {
+	ch      chan KeyEvent[T]
+	present bool
+	ws      atomic.Pointer[watches[T]]
+}
+
+// WatchKey returns a channel that receives an event each time the value of key
+// is set, by Insert, a Handle or a bulk operation such as InsertMany or Graft,
+// and each time the key is removed, together with a function that cancels the
+// watch and closes the channel. The channel holds a single event: an event that
+// has not been received when the key changes again is replaced by the newer
+// one, so a slow receiver sees the latest state of the key rather than every
+// change, and the tree never waits for it. Values changed through pointers
+// returned by GetRef are not reported, and snapshots and forks of the tree do
+// not share its watches, but Join moves them to the joined tree. The cancel
+// function may be called from any goroutine and more than once.
+func (t *RadixTree[T]) WatchKey(key []byte) (<-chan KeyEvent[T], func()) {
+	if t.watches == nil {
+		t.watches = &watches[T]{keys: make(map[string][]*keyWatch[T])}
+	}
+	ws := t.watches
+	w := &keyWatch[T]{ch: make(chan KeyEvent[T], 1), present: t.lookup(key) != nil}
+	w.ws.Store(ws)
+	k := string(key)
+	ws.mu.Lock()
+	ws.keys[k] = append(ws.keys[k], w)
+	ws.mu.Unlock()
+
+	var once sync.Once
+	return w.ch, func() {
+		once.Do(func() {
+			ws := w.lock()
+			defer ws.mu.Unlock()
+			list := ws.keys[k]
+			for i, x := range list {
+				if x == w {
+					list = append(list[:i:i], list[i+1:]...)
+					break
+				}
+			}
+			if len(list) == 0 {
+				delete(ws.keys, k)
+			} else {
+				ws.keys[k] = list
+			}
+			close(w.ch)
+		})
+	}
+}
+
+// lock locks and returns the set holding the watch.
+func (w *keyWatch[T]) lock() *watches[T] {
+	for {
+		ws := w.ws.Load()
+		ws.mu.Lock()
+		if w.ws.Load() == ws {
+			return ws
+		}
+		ws.mu.Unlock()
+	}
+}
+
+// moveWatches moves the watches of from, whose nodes have been merged into t,
+// to t, reporting the keys that are now in t to the watches that saw them
+// absent.
+func (t *RadixTree[T]) moveWatches(from *RadixTree[T]) {
+	ws := from.watches
+	if ws == nil {
+		return
+	}
+	from.watches = nil
+	if t.watches == nil {
+		t.watches = &watches[T]{keys: make(map[string][]*keyWatch[T])}
+	}
+	to := t.watches
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	to.mu.Lock()
+	defer to.mu.Unlock()
+	for k, list := range ws.keys {
+		lf := t.lookup([]byte(k))
+		for _, w := range list {
+			w.ws.Store(to)
+			if lf != nil && !w.present {
+				w.send(lf.value, true)
+			}
+		}
+		to.keys[k] = append(to.keys[k], list...)
+	}
+	clear(ws.keys)
+}
+
+// notify reports to the watches of key that it now has the value, or that it
+// was removed if present is false.
+func (t *RadixTree[T]) notify(key []byte, value T, present bool) {
+	if t.watches == nil {
+		return
+	}
+	ws := t.watches
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for _, w := range ws.keys[string(key)] {
+		w.send(value, present)
+	}
+}
+
+// send reports the state of the key to the watch, replacing an event that has
+// not been received.
+func (w *keyWatch[T]) send(value T, present bool) {
+	if !present && !w.present {
+		return
+	}
+	w.present = present
+	e := KeyEvent[T]{Value: value, Removed: !present}
+	select {
+	case w.ch <- e:
+	default:
+		// Only notify sends, with the mutex held, so there is room once
+		// the pending event is dropped.
+		select {
+		case <-w.ch:
+		default:
+		}
+		w.ch <- e
+	}
+}
+
+// notifyRemoved reports the removal of the keys described by kind, start and
+// end, as recorded by logRemoval, to their watches.
+func (t *RadixTree[T]) notifyRemoved(kind byte, start, end []byte) {
+	if t.watches == nil {
+		return
+	}
+	if kind == backupKey {
+		var zero T
+		t.notify(start, zero, false)
+		return
+	}
+	ws := t.watches
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	var zero T
+	for k, list := range ws.keys {
+		key := []byte(k)
+		removed := bytes.HasPrefix(key, start)
+		if kind == backupRange {
+			removed = t.opts.order.compare(key, start) >= 0 && (end == nil || t.opts.order.compare(key, end) < 0)
+		}
+		if removed {
+			for _, w := range list {
+				w.send(zero, false)
+			}
+		}
+	}
+}
+
+// notifySubtree reports the values of the watched keys in the subtree rooted
+// at n, which is about to be merged into the tree below prefix, to their
+// watches.
+func (t *RadixTree[T]) notifySubtree(prefix []byte, n *node[T]) {
+	if t.watches == nil {
+		return
+	}
+	ws := t.watches
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for k, list := range ws.keys {
+		rest, ok := bytes.CutPrefix([]byte(k), prefix)
+		if !ok {
+			continue
+		}
+		if lf := lookupBelow(n, rest); lf != nil {
+			for _, w := range list {
+				w.send(lf.value, true)
+			}
+		}
+	}
+}
+
+// lookupBelow returns the leaf of key, relative to n, in the subtree rooted at
+// n, or nil if the key is not in it.
+func lookupBelow[T any](n *node[T], key []byte) *leaf[T] {
+	for len(key) > 0 {
+		if n = n.children.get(key[0]); n == nil || !bytes.HasPrefix(key, n.prefix) {
+			return nil
+		}
+		key = key[len(n.prefix):]
+	}
+	return n.leaf
+}
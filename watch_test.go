@@ -0,0 +1,78 @@
+package radixtree
+
+import (
+	"testing"
+)
+
+func TestWatchKey(t *testing.T) {
+	tree := build(words)
+	events, cancel := tree.WatchKey([]byte("toad"))
+	other, cancelOther := tree.WatchKey([]byte("zebra"))
+	defer cancelOther()
+
+	next := func(when string, want KeyEvent[string]) {
+		t.Helper()
+		select {
+		case e := <-events:
+			if e != want {
+				t.Errorf("%s: event %+v, want %+v", when, e, want)
+			}
+		default:
+			t.Errorf("%s: no event, want %+v", when, want)
+		}
+	}
+	none := func(when string) {
+		t.Helper()
+		select {
+		case e := <-events:
+			t.Errorf("%s: unexpected event %+v", when, e)
+		default:
+		}
+	}
+
+	tree.Insert([]byte("toady"), "other key")
+	tree.Remove([]byte("to"))
+	none("changes to other keys")
+
+	tree.Insert([]byte("toad"), "v1")
+	next("Insert", KeyEvent[string]{Value: "v1"})
+	tree.Insert([]byte("toad"), "v2")
+	tree.Insert([]byte("toad"), "v3")
+	next("two Inserts", KeyEvent[string]{Value: "v3"})
+
+	tree.Remove([]byte("toad"))
+	next("Remove", KeyEvent[string]{Removed: true})
+	tree.Remove([]byte("toad"))
+	tree.DeleteRange([]byte("t"), []byte("u"))
+	none("removing a missing key")
+
+	tree.InsertMany([]Entry[string]{{Key: []byte("toad"), Value: "bulk"}, {Key: []byte("x")}})
+	next("InsertMany", KeyEvent[string]{Value: "bulk"})
+	tree.Detach([]byte("to"), false)
+	next("Detach", KeyEvent[string]{Removed: true})
+
+	sub := build([]string{"ad", "ady"})
+	tree.Graft([]byte("to"), sub)
+	next("Graft", KeyEvent[string]{Value: "ad"})
+	if h, ok := tree.Handle([]byte("toad")); ok {
+		h.Set("handle")
+		next("Handle.Set", KeyEvent[string]{Value: "handle"})
+	}
+	tree.ReplaceAll(build([]string{"toad"}))
+	next("ReplaceAll", KeyEvent[string]{Value: "toad"})
+	tree.Split([]byte("m"))
+	next("Split", KeyEvent[string]{Removed: true})
+
+	select {
+	case e := <-other:
+		t.Errorf("watch of a key never inserted received %+v", e)
+	default:
+	}
+
+	cancel()
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("channel not closed by cancel")
+	}
+	tree.Insert([]byte("toad"), "after cancel")
+}
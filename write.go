@@ -0,0 +1,49 @@
+package radixtree
+
+import (
+	"bufio"
+	"io"
+)
+
+// WriteKeys writes every key in the tree to w in ascending order, each one
+// followed by sep. Keys are built in a single reused buffer and written through
+// a buffered writer so the memory used is bounded by the length of the longest
+// key regardless of the number of keys. It returns the number of bytes written
+// and the first error encountered.
+func (t *RadixTree[T]) WriteKeys(w io.Writer, sep []byte) (int64, error) {
+	return t.WriteKeysPrefix(w, nil, sep)
+}
+
+// WriteKeysPrefix is like WriteKeys but only writes the keys that start with
+// the given prefix.
+func (t *RadixTree[T]) WriteKeysPrefix(w io.Writer, prefix, sep []byte) (int64, error) {
+	key := t.borrow()
+	n, key := t.findPath(prefix, key)
+	if n == nil {
+		t.release(key)
+		return 0, nil
+	}
+
+	bw := bufio.NewWriter(w)
+	var written int64
+	var err error
+	key, _ = walkNodes(n, key, -1, func(key []byte, _ *node[T]) bool {
+		var c int
+		c, err = bw.Write(key)
+		written += int64(c)
+		if err == nil {
+			c, err = bw.Write(sep)
+			written += int64(c)
+		}
+		return err == nil
+	})
+	t.release(key)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		// Only count the bytes that reached w.
+		written -= int64(bw.Buffered())
+	}
+	return written, err
+}
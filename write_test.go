@@ -0,0 +1,67 @@
+package radixtree
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// limitWriter accepts up to n bytes and then fails.
+type limitWriter struct {
+	n int
+}
+
+var errLimit = errors.New("limit reached")
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errLimit
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteKeys(t *testing.T) {
+	tree := build(words)
+	var buf bytes.Buffer
+	n, err := tree.WriteKeys(&buf, []byte("\n"))
+	want := strings.Join(words, "\n") + "\n"
+	if err != nil || buf.String() != want || n != int64(len(want)) {
+		t.Errorf("WriteKeys\n got: (%q, %d, %v)\nwant: (%q, %d, nil)", buf.String(), n, err, want, len(want))
+	}
+
+	buf.Reset()
+	if n, err := New[int]().WriteKeys(&buf, []byte("\n")); n != 0 || err != nil || buf.Len() != 0 {
+		t.Errorf("WriteKeys on empty tree = (%d, %v) and wrote %q", n, err, buf.String())
+	}
+
+	// Enough keys to fill the buffer of the writer before failing.
+	big := New[int]()
+	for i := 0; i < 10000; i++ {
+		big.Insert([]byte(strings.Repeat("k", i%50)+string(rune('a'+i%26))), i)
+	}
+	w := &limitWriter{n: 5000}
+	if n, err := big.WriteKeys(w, []byte("\n")); err != errLimit || n != 5000 {
+		t.Errorf("WriteKeys to failing writer = (%d, %v), want (5000, %v)", n, err, errLimit)
+	}
+}
+
+func TestWriteKeysPrefix(t *testing.T) {
+	tree := build(words)
+	for _, prefix := range []string{"", "mac", "macroa", "toad", "x", "wilx"} {
+		var buf bytes.Buffer
+		if _, err := tree.WriteKeysPrefix(&buf, []byte(prefix), []byte(",")); err != nil {
+			t.Errorf("WriteKeysPrefix(%q) returned error %v", prefix, err)
+		}
+		want := ""
+		for _, key := range hasPrefix(prefix, words) {
+			want += key + ","
+		}
+		if buf.String() != want {
+			t.Errorf("WriteKeysPrefix(%q)\n got: %q\nwant: %q", prefix, buf.String(), want)
+		}
+	}
+}